@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+
+	"FoodStore-AdvProg2/cmd/api-gateway/middleware"
+	"FoodStore-AdvProg2/utils"
+)
+
+// actorContext возвращает контекст gRPC вызова с инициатором запроса в метаданных.
+// Inventory Service записывает его в журнал движения товаров.
+func actorContext(c *gin.Context) context.Context {
+	return utils.WithActor(context.Background(), requestActor(c))
+}
+
+// requestActor определяет инициатора: ID пользователя из проверенного токена,
+// для анонимных запросов и запросов с неверным токеном - IP клиента
+func requestActor(c *gin.Context) string {
+	if userID, ok := middleware.AuthenticatedUserID(c); ok {
+		return "user:" + userID
+	}
+	return "ip:" + c.ClientIP()
+}
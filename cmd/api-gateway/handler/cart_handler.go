@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"FoodStore-AdvProg2/cmd/api-gateway/middleware"
+	"FoodStore-AdvProg2/proto/order"
+)
+
+// CartHandler - корзины покупателей в Order Service
+type CartHandler struct {
+	client order.OrderServiceClient
+}
+
+func NewCartHandler(client order.OrderServiceClient) *CartHandler {
+	return &CartHandler{client: client}
+}
+
+// cartUserID возвращает покупателя из проверенного токена; без токена запрос гостевой
+func cartUserID(c *gin.Context) string {
+	userID, _ := middleware.AuthenticatedUserID(c)
+	return userID
+}
+
+// CreateCart заводит гостевую корзину или, с токеном, возвращает корзину покупателя
+func (h *CartHandler) CreateCart(c *gin.Context) {
+	resp, err := h.client.CreateCart(context.Background(), &order.CreateCartRequest{UserId: cartUserID(c)})
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetUserCart возвращает корзину покупателя из токена
+func (h *CartHandler) GetUserCart(c *gin.Context) {
+	resp, err := h.client.GetUserCart(context.Background(), &order.GetUserCartRequest{UserId: c.GetString("user_id")})
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *CartHandler) GetCart(c *gin.Context) {
+	resp, err := h.client.GetCart(context.Background(), &order.GetCartRequest{Id: c.Param("id"), UserId: cartUserID(c)})
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// AddCartItem добавляет товар; если он уже в корзине, количество складывается
+func (h *CartHandler) AddCartItem(c *gin.Context) {
+	var reqBody struct {
+		ProductID string  `json:"product_id" binding:"required"`
+		Quantity  float64 `json:"quantity" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.AddCartItem(context.Background(), &order.AddCartItemRequest{
+		CartId:    c.Param("id"),
+		ProductId: reqBody.ProductID,
+		Quantity:  reqBody.Quantity,
+		UserId:    cartUserID(c),
+	})
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// UpdateCartItem задает количество товара; 0 убирает его из корзины
+func (h *CartHandler) UpdateCartItem(c *gin.Context) {
+	var reqBody struct {
+		Quantity *float64 `json:"quantity" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.UpdateCartItem(context.Background(), &order.UpdateCartItemRequest{
+		CartId:    c.Param("id"),
+		ProductId: c.Param("product_id"),
+		Quantity:  *reqBody.Quantity,
+		UserId:    cartUserID(c),
+	})
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *CartHandler) RemoveCartItem(c *gin.Context) {
+	resp, err := h.client.RemoveCartItem(context.Background(), &order.RemoveCartItemRequest{
+		CartId:    c.Param("id"),
+		ProductId: c.Param("product_id"),
+		UserId:    cartUserID(c),
+	})
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// RefreshCart принимает текущие цены и возвращает позиции, цена которых изменилась
+func (h *CartHandler) RefreshCart(c *gin.Context) {
+	resp, err := h.client.RefreshCart(context.Background(), &order.RefreshCartRequest{CartId: c.Param("id"), UserId: cartUserID(c)})
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cart": resp.Cart, "changed": resp.Changed})
+}
+
+// MergeCart переносит гостевую корзину в корзину покупателя из токена после входа
+func (h *CartHandler) MergeCart(c *gin.Context) {
+	resp, err := h.client.MergeCarts(context.Background(), &order.MergeCartsRequest{
+		GuestCartId: c.Param("id"),
+		UserId:      c.GetString("user_id"),
+	})
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// CheckoutCart оформляет корзину покупателя из токена в заказ; после успеха корзина удаляется
+func (h *CartHandler) CheckoutCart(c *gin.Context) {
+	var reqBody struct {
+		StoreID           string         `json:"store_id"`
+		DeliveryAddress   *order.Address `json:"delivery_address"`
+		CouponCodes       []string       `json:"coupon_codes"`
+		SlotReservationID string         `json:"slot_reservation_id"`
+	}
+
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&reqBody); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	resp, err := h.client.CheckoutCart(context.Background(), &order.CheckoutCartRequest{
+		CartId:            c.Param("id"),
+		StoreId:           reqBody.StoreID,
+		DeliveryAddress:   reqBody.DeliveryAddress,
+		CouponCodes:       reqBody.CouponCodes,
+		SlotReservationId: reqBody.SlotReservationID,
+		UserId:            c.GetString("user_id"),
+	})
+	if err != nil {
+		respondCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"order_id": resp.OrderId})
+}
+
+func respondCartError(c *gin.Context, err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch st.Code() {
+	case codes.InvalidArgument:
+		c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+	case codes.NotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": st.Message()})
+	case codes.FailedPrecondition:
+		c.JSON(http.StatusConflict, gin.H{"error": st.Message()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+	}
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"FoodStore-AdvProg2/proto/inventory"
+	"FoodStore-AdvProg2/utils"
+)
+
+const (
+	// maxCatalogUploadSize - максимальный размер файла импорта каталога
+	maxCatalogUploadSize = 32 << 20
+	// catalogChunkSize - размер части файла в одном сообщении потока импорта
+	catalogChunkSize = 64 << 10
+)
+
+// catalogContentTypes - Content-Type выгрузки по формату
+var catalogContentTypes = map[string]string{
+	"csv":   "text/csv; charset=utf-8",
+	"jsonl": "application/x-ndjson",
+}
+
+// catalogFormat определяет формат файла: параметр format, затем расширение файла, затем Content-Type
+func catalogFormat(c *gin.Context, filename string) string {
+	if format := strings.ToLower(c.Query("format")); format != "" {
+		return format
+	}
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return "csv"
+	case ".jsonl", ".ndjson":
+		return "jsonl"
+	}
+	switch c.ContentType() {
+	case "application/x-ndjson", "application/jsonl":
+		return "jsonl"
+	}
+	return "csv"
+}
+
+// importSource возвращает файл импорта: поле "file" формы multipart/form-data или тело запроса целиком
+func importSource(c *gin.Context) (io.Reader, string, error) {
+	if c.ContentType() != "multipart/form-data" {
+		return c.Request.Body, "", nil
+	}
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, "", err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, "", errors.New("file field is required")
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if part.FormName() == "file" {
+			return part, part.FileName(), nil
+		}
+	}
+}
+
+// ImportProducts загружает каталог из CSV или JSON Lines и создает или обновляет товары по SKU.
+// dry_run=true только проверяет файл, atomic=true не сохраняет ничего при ошибке в любой строке.
+// Если из-за ошибок не сохранено ничего, возвращается 422 с тем же отчетом.
+func (h *ProductHandler) ImportProducts(c *gin.Context) {
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	atomic, _ := strconv.ParseBool(c.DefaultQuery("atomic", "false"))
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxCatalogUploadSize)
+	source, filename, err := importSource(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stream, err := h.client.ImportProducts(utils.WithActor(c.Request.Context(), requestActor(c)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = stream.Send(&inventory.ImportProductsRequest{Payload: &inventory.ImportProductsRequest_Options{
+		Options: &inventory.ImportOptions{Format: catalogFormat(c, filename), DryRun: dryRun, Atomic: atomic},
+	}})
+	buf := make([]byte, catalogChunkSize)
+	for err == nil {
+		var n int
+		n, err = io.ReadFull(source, buf)
+		if n > 0 {
+			chunk := append([]byte(nil), buf[:n]...)
+			if sendErr := stream.Send(&inventory.ImportProductsRequest{Payload: &inventory.ImportProductsRequest_Chunk{Chunk: chunk}}); sendErr != nil {
+				err = sendErr
+			}
+		}
+	}
+	// io.EOF от Send значит, что сервер уже ответил; причину вернет CloseAndRecv
+	if err != io.EOF && err != io.ErrUnexpectedEOF {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "catalog file is larger than 32 MB"})
+			return
+		}
+		if _, ok := status.FromError(err); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		if st, ok := status.FromError(err); ok && st.Code() == codes.InvalidArgument {
+			c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !resp.DryRun && !resp.Committed && resp.Failed > 0 {
+		c.JSON(http.StatusUnprocessableEntity, resp)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// ExportProducts выгружает каталог в CSV или JSON Lines; archived=true выгружает архив.
+// Файл передается клиенту по мере получения частей от Inventory Service.
+func (h *ProductHandler) ExportProducts(c *gin.Context) {
+	format := catalogFormat(c, "")
+	contentType, ok := catalogContentTypes[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or jsonl"})
+		return
+	}
+	archived, _ := strconv.ParseBool(c.DefaultQuery("archived", "false"))
+
+	stream, err := h.client.ExportProducts(c.Request.Context(), &inventory.ExportProductsRequest{Format: format, Archived: archived})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Заголовки отправляются после первой части, чтобы ошибку можно было вернуть статусом
+	chunk, err := stream.Recv()
+	if err != nil && err != io.EOF {
+		if st, ok := status.FromError(err); ok && st.Code() == codes.InvalidArgument {
+			c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="products.`+format+`"`)
+	c.Status(http.StatusOK)
+	for err == nil {
+		if _, err = c.Writer.Write(chunk.Data); err != nil {
+			return
+		}
+		c.Writer.Flush()
+		chunk, err = stream.Recv()
+	}
+	// Ошибку посреди выгрузки клиенту уже не передать статусом: файл обрывается
+	if err != io.EOF {
+		c.Error(err)
+	}
+}
@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"FoodStore-AdvProg2/cmd/api-gateway/middleware"
+	"FoodStore-AdvProg2/proto/order"
+)
+
+// DeliveryHandler - курьеры, доставка заказов и отслеживание заказа покупателем
+type DeliveryHandler struct {
+	client order.OrderServiceClient
+}
+
+func NewDeliveryHandler(client order.OrderServiceClient) *DeliveryHandler {
+	return &DeliveryHandler{client: client}
+}
+
+// ListCouriers возвращает курьеров; status и zone фильтруют список
+func (h *DeliveryHandler) ListCouriers(c *gin.Context) {
+	resp, err := h.client.ListCouriers(context.Background(), &order.ListCouriersRequest{
+		Status: c.Query("status"),
+		Zone:   c.Query("zone"),
+	})
+	if err != nil {
+		respondDeliveryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"couriers": resp.Couriers})
+}
+
+func (h *DeliveryHandler) CreateCourier(c *gin.Context) {
+	var reqBody struct {
+		UserID string `json:"user_id"`
+		Name   string `json:"name" binding:"required"`
+		Phone  string `json:"phone" binding:"required"`
+		Zone   string `json:"zone"`
+		Status string `json:"status"`
+	}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.CreateCourier(context.Background(), &order.CreateCourierRequest{
+		UserId: reqBody.UserID,
+		Name:   reqBody.Name,
+		Phone:  reqBody.Phone,
+		Zone:   reqBody.Zone,
+		Status: reqBody.Status,
+	})
+	if err != nil {
+		respondDeliveryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// UpdateCourierStatus выводит курьера на смену (available) или снимает с нее (offline)
+func (h *DeliveryHandler) UpdateCourierStatus(c *gin.Context) {
+	var reqBody struct {
+		Status string `json:"status" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.UpdateCourierStatus(context.Background(), &order.UpdateCourierStatusRequest{
+		Id:     c.Param("id"),
+		Status: reqBody.Status,
+	})
+	if err != nil {
+		respondDeliveryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// RecordLocation принимает текущие координаты курьера из приложения
+func (h *DeliveryHandler) RecordLocation(c *gin.Context) {
+	var reqBody struct {
+		Latitude   *float64 `json:"latitude" binding:"required"`
+		Longitude  *float64 `json:"longitude" binding:"required"`
+		RecordedAt string   `json:"recorded_at"`
+	}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.RecordCourierLocation(context.Background(), &order.RecordCourierLocationRequest{
+		CourierId:  c.Param("id"),
+		Latitude:   *reqBody.Latitude,
+		Longitude:  *reqBody.Longitude,
+		RecordedAt: reqBody.RecordedAt,
+	})
+	if err != nil {
+		respondDeliveryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListCourierDeliveries возвращает доставки курьера, новые первыми
+func (h *DeliveryHandler) ListCourierDeliveries(c *gin.Context) {
+	h.listDeliveries(c, c.Param("id"))
+}
+
+// ListDeliveries возвращает доставки всех курьеров; courier_id и status фильтруют список
+func (h *DeliveryHandler) ListDeliveries(c *gin.Context) {
+	h.listDeliveries(c, c.Query("courier_id"))
+}
+
+func (h *DeliveryHandler) listDeliveries(c *gin.Context, courierID string) {
+	resp, err := h.client.ListDeliveries(context.Background(), &order.ListDeliveriesRequest{
+		CourierId: courierID,
+		Status:    c.Query("status"),
+	})
+	if err != nil {
+		respondDeliveryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": resp.Deliveries})
+}
+
+// AssignCourier передает заказ курьеру; без courier_id - ближайшему свободному
+func (h *DeliveryHandler) AssignCourier(c *gin.Context) {
+	var reqBody struct {
+		CourierID string `json:"courier_id"`
+	}
+
+	// Тело необязательно: пустой запрос означает автоматический выбор курьера
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&reqBody); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	resp, err := h.client.AssignCourier(context.Background(), &order.AssignCourierRequest{
+		OrderId:   c.Param("id"),
+		CourierId: reqBody.CourierID,
+	})
+	if err != nil {
+		respondDeliveryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// UpdateDeliveryStatus - курьер забрал заказ (out_for_delivery), вручил (delivered) или не смог (failed).
+// Курьер определяется по токену; администратор меняет любую доставку как диспетчер.
+func (h *DeliveryHandler) UpdateDeliveryStatus(c *gin.Context) {
+	var reqBody struct {
+		Status string `json:"status" binding:"required"`
+		Note   string `json:"note"`
+	}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.UpdateDeliveryStatus(context.Background(), &order.UpdateDeliveryStatusRequest{
+		Id:         c.Param("id"),
+		Status:     reqBody.Status,
+		Note:       reqBody.Note,
+		UserId:     c.GetString("user_id"),
+		Dispatcher: middleware.IsAdmin(c),
+	})
+	if err != nil {
+		respondDeliveryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// UploadProof принимает фото вручения в поле "photo" формы multipart/form-data
+func (h *DeliveryHandler) UploadProof(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImageUploadSize)
+	fileHeader, err := c.FormFile("photo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "photo file is required (max 8 MB)"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.UploadDeliveryProof(context.Background(), &order.UploadDeliveryProofRequest{
+		Id:         c.Param("id"),
+		Data:       data,
+		UserId:     c.GetString("user_id"),
+		Dispatcher: middleware.IsAdmin(c),
+	})
+	if err != nil {
+		respondDeliveryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// TrackOrder показывает покупателю из токена курьера и ход доставки его заказа
+func (h *DeliveryHandler) TrackOrder(c *gin.Context) {
+	resp, err := h.client.TrackOrder(context.Background(), &order.TrackOrderRequest{
+		OrderId: c.Param("id"),
+		UserId:  c.GetString("user_id"),
+	})
+	if err != nil {
+		respondDeliveryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func respondDeliveryError(c *gin.Context, err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch st.Code() {
+	case codes.InvalidArgument:
+		c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+	case codes.NotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": st.Message()})
+	case codes.AlreadyExists, codes.FailedPrecondition:
+		c.JSON(http.StatusConflict, gin.H{"error": st.Message()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+	}
+}
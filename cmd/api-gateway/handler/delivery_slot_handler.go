@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"FoodStore-AdvProg2/proto/order"
+)
+
+// DeliverySlotHandler - интервалы доставки и брони мест в них
+type DeliverySlotHandler struct {
+	client order.OrderServiceClient
+}
+
+func NewDeliverySlotHandler(client order.OrderServiceClient) *DeliverySlotHandler {
+	return &DeliverySlotHandler{client: client}
+}
+
+// ListSlots возвращает интервалы со свободными местами; include_full=true показывает и полные
+func (h *DeliverySlotHandler) ListSlots(c *gin.Context) {
+	resp, err := h.client.ListAvailableSlots(context.Background(), &order.ListAvailableSlotsRequest{
+		Zone:        c.Query("zone"),
+		From:        c.Query("from"),
+		To:          c.Query("to"),
+		IncludeFull: c.Query("include_full") == "true",
+	})
+	if err != nil {
+		respondSlotError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"slots": resp.Slots})
+}
+
+// CreateSlot создает интервал доставки в зоне
+func (h *DeliverySlotHandler) CreateSlot(c *gin.Context) {
+	var reqBody struct {
+		Zone     string `json:"zone" binding:"required"`
+		StartsAt string `json:"starts_at" binding:"required"`
+		EndsAt   string `json:"ends_at" binding:"required"`
+		Capacity int32  `json:"capacity" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.CreateDeliverySlot(context.Background(), &order.CreateDeliverySlotRequest{
+		Zone:     reqBody.Zone,
+		StartsAt: reqBody.StartsAt,
+		EndsAt:   reqBody.EndsAt,
+		Capacity: reqBody.Capacity,
+	})
+	if err != nil {
+		respondSlotError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ReserveSlot удерживает место в интервале за покупателем, пока он оформляет заказ
+func (h *DeliverySlotHandler) ReserveSlot(c *gin.Context) {
+	resp, err := h.client.ReserveDeliverySlot(context.Background(), &order.ReserveDeliverySlotRequest{
+		SlotId: c.Param("id"),
+		UserId: c.GetString("user_id"),
+	})
+	if err != nil {
+		respondSlotError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ReleaseReservation снимает неподтвержденную бронь покупателя из токена
+func (h *DeliverySlotHandler) ReleaseReservation(c *gin.Context) {
+	resp, err := h.client.ReleaseSlotReservation(context.Background(), &order.ReleaseSlotReservationRequest{
+		Id:     c.Param("id"),
+		UserId: c.GetString("user_id"),
+	})
+	if err != nil {
+		respondSlotError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func respondSlotError(c *gin.Context, err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch st.Code() {
+	case codes.InvalidArgument:
+		c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+	case codes.NotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": st.Message()})
+	case codes.AlreadyExists, codes.FailedPrecondition:
+		c.JSON(http.StatusConflict, gin.H{"error": st.Message()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+	}
+}
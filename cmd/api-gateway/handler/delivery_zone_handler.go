@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"FoodStore-AdvProg2/proto/order"
+)
+
+// DeliveryZoneHandler - зоны доставки и расчет стоимости доставки по адресу
+type DeliveryZoneHandler struct {
+	client order.OrderServiceClient
+}
+
+func NewDeliveryZoneHandler(client order.OrderServiceClient) *DeliveryZoneHandler {
+	return &DeliveryZoneHandler{client: client}
+}
+
+type deliveryZoneBody struct {
+	Code                  string            `json:"code" binding:"required"`
+	Name                  string            `json:"name"`
+	Polygon               []*order.GeoPoint `json:"polygon"`
+	PostalCodes           []string          `json:"postal_codes"`
+	Fee                   float64           `json:"fee"`
+	FreeDeliveryThreshold float64           `json:"free_delivery_threshold"`
+	MinOrderAmount        float64           `json:"min_order_amount"`
+	Priority              int32             `json:"priority"`
+	// Active по умолчанию true: новая зона сразу принимает заказы
+	Active *bool `json:"active"`
+}
+
+func (b deliveryZoneBody) toProto(id string) *order.DeliveryZone {
+	active := true
+	if b.Active != nil {
+		active = *b.Active
+	}
+	return &order.DeliveryZone{
+		Id:                    id,
+		Code:                  b.Code,
+		Name:                  b.Name,
+		Polygon:               b.Polygon,
+		PostalCodes:           b.PostalCodes,
+		Fee:                   b.Fee,
+		FreeDeliveryThreshold: b.FreeDeliveryThreshold,
+		MinOrderAmount:        b.MinOrderAmount,
+		Priority:              b.Priority,
+		Active:                active,
+	}
+}
+
+// ListZones возвращает зоны по приоритету; active=true - только действующие
+func (h *DeliveryZoneHandler) ListZones(c *gin.Context) {
+	resp, err := h.client.ListDeliveryZones(context.Background(), &order.ListDeliveryZonesRequest{
+		ActiveOnly: c.Query("active") == "true",
+	})
+	if err != nil {
+		respondZoneError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"zones": resp.Zones})
+}
+
+func (h *DeliveryZoneHandler) CreateZone(c *gin.Context) {
+	var reqBody deliveryZoneBody
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.CreateDeliveryZone(context.Background(), &order.CreateDeliveryZoneRequest{
+		Zone: reqBody.toProto(""),
+	})
+	if err != nil {
+		respondZoneError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// UpdateZone заменяет границы и условия зоны целиком
+func (h *DeliveryZoneHandler) UpdateZone(c *gin.Context) {
+	var reqBody deliveryZoneBody
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.UpdateDeliveryZone(context.Background(), &order.UpdateDeliveryZoneRequest{
+		Zone: reqBody.toProto(c.Param("id")),
+	})
+	if err != nil {
+		respondZoneError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Quote подбирает зону для адреса и считает стоимость доставки для суммы товаров
+func (h *DeliveryZoneHandler) Quote(c *gin.Context) {
+	var reqBody struct {
+		Address  *order.Address `json:"address" binding:"required"`
+		Subtotal float64        `json:"subtotal"`
+	}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.QuoteDelivery(context.Background(), &order.QuoteDeliveryRequest{
+		Address:  reqBody.Address,
+		Subtotal: reqBody.Subtotal,
+	})
+	if err != nil {
+		respondZoneError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func respondZoneError(c *gin.Context, err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch st.Code() {
+	case codes.InvalidArgument:
+		c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+	case codes.NotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": st.Message()})
+	case codes.AlreadyExists:
+		c.JSON(http.StatusConflict, gin.H{"error": st.Message()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+	}
+}
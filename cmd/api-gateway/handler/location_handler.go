@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"FoodStore-AdvProg2/proto/inventory"
+)
+
+// LocationHandler - склады и магазины, на которых хранится товар
+type LocationHandler struct {
+	client inventory.InventoryServiceClient
+}
+
+func NewLocationHandler(client inventory.InventoryServiceClient) *LocationHandler {
+	return &LocationHandler{client: client}
+}
+
+func (h *LocationHandler) ListLocations(c *gin.Context) {
+	includeInactive, _ := strconv.ParseBool(c.DefaultQuery("include_inactive", "false"))
+
+	resp, err := h.client.ListLocations(context.Background(), &inventory.ListLocationsRequest{
+		IncludeInactive: includeInactive,
+	})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"locations": resp.Locations})
+}
+
+func (h *LocationHandler) CreateLocation(c *gin.Context) {
+	var reqBody struct {
+		Code    string             `json:"code" binding:"required"`
+		Name    string             `json:"name" binding:"required"`
+		Type    string             `json:"type"`
+		Address *inventory.Address `json:"address"`
+	}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.CreateLocation(context.Background(), &inventory.CreateLocationRequest{
+		Code:    reqBody.Code,
+		Name:    reqBody.Name,
+		Type:    reqBody.Type,
+		Address: reqBody.Address,
+	})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// UpdateLocation меняет данные точки; active=false выводит ее из выполнения заказов
+func (h *LocationHandler) UpdateLocation(c *gin.Context) {
+	var reqBody struct {
+		Name    string             `json:"name" binding:"required"`
+		Type    string             `json:"type"`
+		Address *inventory.Address `json:"address"`
+		Active  *bool              `json:"active" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.UpdateLocation(context.Background(), &inventory.UpdateLocationRequest{
+		Id:      c.Param("id"),
+		Name:    reqBody.Name,
+		Type:    reqBody.Type,
+		Address: reqBody.Address,
+		Active:  *reqBody.Active,
+	})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"FoodStore-AdvProg2/cmd/api-gateway/middleware"
+	"FoodStore-AdvProg2/proto/order"
+)
+
+// sseHeartbeatInterval - период пустых комментариев, чтобы прокси не закрывали тихое соединение
+const sseHeartbeatInterval = 15 * time.Second
+
+// OrderEvents транслирует изменения заказа в Server-Sent Events. Первое событие - snapshot
+// с текущим состоянием; после финального статуса (delivered, completed, cancelled) поток закрывается.
+// Покупатель видит только свои заказы, администратор - любой.
+func (h *OrderHandler) OrderEvents(c *gin.Context) {
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	stream, err := h.client.WatchOrder(ctx, &order.WatchOrderRequest{
+		Id:       c.Param("id"),
+		UserId:   c.GetString("user_id"),
+		AllUsers: middleware.IsAdmin(c),
+	})
+	if err != nil {
+		respondOrderEventsError(c, err)
+		return
+	}
+	// Ошибки потока (заказ не найден) приходят с первым событием, поэтому ждем его до заголовков SSE
+	first, err := stream.Recv()
+	if err != nil {
+		respondOrderEventsError(c, err)
+		return
+	}
+
+	streamOrderEvents(c, first, stream.Recv)
+}
+
+// UserOrderEvents транслирует созданные заказы и изменения заказов покупателя из токена
+func (h *OrderHandler) UserOrderEvents(c *gin.Context) {
+	h.watchOrders(c, &order.WatchUserOrdersRequest{UserId: c.GetString("user_id")})
+}
+
+// AllOrderEvents транслирует заказы всех покупателей для панели администратора
+func (h *OrderHandler) AllOrderEvents(c *gin.Context) {
+	h.watchOrders(c, &order.WatchUserOrdersRequest{AllUsers: true})
+}
+
+func (h *OrderHandler) watchOrders(c *gin.Context, req *order.WatchUserOrdersRequest) {
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	stream, err := h.client.WatchUserOrders(ctx, req)
+	if err != nil {
+		respondOrderEventsError(c, err)
+		return
+	}
+
+	streamOrderEvents(c, nil, stream.Recv)
+}
+
+// streamOrderEvents пишет события first и recv в ответ, пока поток не закончится или клиент не отключится
+func streamOrderEvents(c *gin.Context, first *order.OrderEvent, recv func() (*order.OrderEvent, error)) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// nginx иначе буферизует ответ и события приходят пачками
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	events := make(chan *order.OrderEvent)
+	done := make(chan error, 1)
+	go func() {
+		for {
+			event, err := recv()
+			if err != nil {
+				done <- err
+				return
+			}
+			select {
+			case events <- event:
+			case <-c.Request.Context().Done():
+				return
+			}
+		}
+	}()
+
+	if first != nil {
+		writeOrderEvent(c, first)
+	} else {
+		c.Writer.Flush()
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event := <-events:
+			writeOrderEvent(c, event)
+		case err := <-done:
+			if !errors.Is(err, io.EOF) && status.Code(err) != codes.Canceled {
+				c.SSEvent("error", gin.H{"error": status.Convert(err).Message()})
+				c.Writer.Flush()
+			}
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+func writeOrderEvent(c *gin.Context, event *order.OrderEvent) {
+	c.SSEvent(event.Type, event)
+	c.Writer.Flush()
+}
+
+func respondOrderEventsError(c *gin.Context, err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch st.Code() {
+	case codes.InvalidArgument:
+		c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+	case codes.NotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": st.Message()})
+	case codes.Unimplemented:
+		c.JSON(http.StatusNotImplemented, gin.H{"error": st.Message()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+	}
+}
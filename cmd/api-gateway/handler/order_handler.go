@@ -5,6 +5,8 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"FoodStore-AdvProg2/proto/order"
 )
@@ -19,8 +21,11 @@ func NewOrderHandler(client order.OrderServiceClient) *OrderHandler {
 
 func (h *OrderHandler) CreateOrder(c *gin.Context) {
 	var reqBody struct {
-		UserID string                   `json:"user_id" binding:"required"`
-		Items  []*order.CreateOrderItem `json:"items" binding:"required"`
+		Items             []*order.CreateOrderItem `json:"items" binding:"required"`
+		StoreID           string                   `json:"store_id"`
+		DeliveryAddress   *order.Address           `json:"delivery_address"`
+		CouponCodes       []string                 `json:"coupon_codes"`
+		SlotReservationID string                   `json:"slot_reservation_id"`
 	}
 
 	if err := c.ShouldBindJSON(&reqBody); err != nil {
@@ -28,12 +33,32 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 		return
 	}
 
+	// Заказ оформляется на покупателя из токена: от него зависят лимиты купонов и бронь интервала
 	req := &order.CreateOrderRequest{
-		UserId: reqBody.UserID,
-		Items:  reqBody.Items,
+		UserId:            c.GetString("user_id"),
+		Items:             reqBody.Items,
+		StoreId:           reqBody.StoreID,
+		DeliveryAddress:   reqBody.DeliveryAddress,
+		CouponCodes:       reqBody.CouponCodes,
+		SlotReservationId: reqBody.SlotReservationID,
 	}
 
 	resp, err := h.client.CreateOrder(context.Background(), req)
+	if status.Code(err) == codes.InvalidArgument {
+		// Дробное количество штучного товара, неположительное количество или неизвестный купон
+		c.JSON(http.StatusBadRequest, gin.H{"error": status.Convert(err).Message()})
+		return
+	}
+	if status.Code(err) == codes.FailedPrecondition {
+		// Не хватает товара, выбранный магазин не может собрать заказ, купон неприменим или бронь интервала истекла
+		c.JSON(http.StatusConflict, gin.H{"error": status.Convert(err).Message()})
+		return
+	}
+	if status.Code(err) == codes.NotFound {
+		// Бронь интервала доставки не найдена или принадлежит другому покупателю
+		c.JSON(http.StatusNotFound, gin.H{"error": status.Convert(err).Message()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -76,7 +101,19 @@ func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 	}
 
 	resp, err := h.client.UpdateOrderStatus(context.Background(), req)
-	if err != nil {
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.InvalidArgument:
+		c.JSON(http.StatusBadRequest, gin.H{"error": status.Convert(err).Message()})
+		return
+	case codes.NotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": status.Convert(err).Message()})
+		return
+	case codes.FailedPrecondition:
+		// Переход не разрешен: заказ уже у курьера, завершен или отменен
+		c.JSON(http.StatusConflict, gin.H{"error": status.Convert(err).Message()})
+		return
+	default:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
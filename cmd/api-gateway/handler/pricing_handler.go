@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"FoodStore-AdvProg2/proto/inventory"
+)
+
+// PricingHandler - история цен товаров и запланированные цены
+type PricingHandler struct {
+	client inventory.InventoryServiceClient
+}
+
+func NewPricingHandler(client inventory.InventoryServiceClient) *PricingHandler {
+	return &PricingHandler{client: client}
+}
+
+// GetPriceAt возвращает цену товара в момент at (RFC 3339), по умолчанию - текущую
+func (h *PricingHandler) GetPriceAt(c *gin.Context) {
+	resp, err := h.client.GetPriceAt(context.Background(), &inventory.GetPriceAtRequest{
+		ProductId: c.Param("id"),
+		At:        c.Query("at"),
+	})
+	if err != nil {
+		respondPricingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListPriceHistory возвращает последние изменения цены товара, новые первыми
+func (h *PricingHandler) ListPriceHistory(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a non-negative integer"})
+		return
+	}
+
+	resp, err := h.client.ListPriceHistory(context.Background(), &inventory.ListPriceHistoryRequest{
+		ProductId: c.Param("id"),
+		Limit:     int32(limit),
+	})
+	if err != nil {
+		respondPricingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"changes": resp.Changes})
+}
+
+// SchedulePrice планирует цену товара: с ends_at - акцию, без него - отложенное изменение
+func (h *PricingHandler) SchedulePrice(c *gin.Context) {
+	var reqBody struct {
+		Price    *float64 `json:"price" binding:"required"`
+		StartsAt string   `json:"starts_at" binding:"required"`
+		EndsAt   string   `json:"ends_at"`
+	}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.SchedulePrice(actorContext(c), &inventory.SchedulePriceRequest{
+		ProductId: c.Param("id"),
+		Price:     *reqBody.Price,
+		StartsAt:  reqBody.StartsAt,
+		EndsAt:    reqBody.EndsAt,
+	})
+	if err != nil {
+		respondPricingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListProductPriceSchedules возвращает запланированные цены товара
+func (h *PricingHandler) ListProductPriceSchedules(c *gin.Context) {
+	h.listPriceSchedules(c, c.Param("id"))
+}
+
+// ListPriceSchedules возвращает запланированные цены всех товаров или товара из product_id
+func (h *PricingHandler) ListPriceSchedules(c *gin.Context) {
+	h.listPriceSchedules(c, c.Query("product_id"))
+}
+
+func (h *PricingHandler) listPriceSchedules(c *gin.Context, productID string) {
+	resp, err := h.client.ListPriceSchedules(context.Background(), &inventory.ListPriceSchedulesRequest{
+		ProductId: productID,
+		Status:    c.Query("status"),
+	})
+	if err != nil {
+		respondPricingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"schedules": resp.Schedules})
+}
+
+// CancelPriceSchedule отменяет запланированную цену; действующая акция завершается досрочно
+func (h *PricingHandler) CancelPriceSchedule(c *gin.Context) {
+	resp, err := h.client.CancelPriceSchedule(actorContext(c), &inventory.CancelPriceScheduleRequest{
+		Id: c.Param("id"),
+	})
+	if err != nil {
+		respondPricingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func respondPricingError(c *gin.Context, err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch st.Code() {
+	case codes.InvalidArgument:
+		c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+	case codes.NotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": st.Message()})
+	case codes.AlreadyExists, codes.FailedPrecondition:
+		c.JSON(http.StatusConflict, gin.H{"error": st.Message()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+	}
+}
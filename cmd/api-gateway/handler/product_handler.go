@@ -2,12 +2,16 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 
 	"FoodStore-AdvProg2/proto/inventory"
 )
@@ -36,12 +40,60 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 	c.JSON(http.StatusOK, resp.Product)
 }
 
+// ListVariants возвращает варианты товара: упаковки разного объема, вкусы
+func (h *ProductHandler) ListVariants(c *gin.Context) {
+	req := &inventory.ListProductsRequest{
+		Filter:     &inventory.FilterParams{ParentId: c.Param("id")},
+		Pagination: &inventory.PaginationParams{Page: 1, PerPage: 100},
+		Sort:       "name",
+	}
+
+	resp, err := h.client.ListProducts(context.Background(), req)
+	if err != nil {
+		respondProductUpdateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"variants": resp.Products})
+}
+
+// GetProductByBarcode ищет товар по отсканированному штрихкоду EAN-13, EAN-8 или UPC-A
+func (h *ProductHandler) GetProductByBarcode(c *gin.Context) {
+	req := &inventory.GetProductByBarcodeRequest{
+		Barcode: c.Param("barcode"),
+	}
+
+	resp, err := h.client.GetProductByBarcode(context.Background(), req)
+	if err != nil {
+		respondProductUpdateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp.Product)
+}
+
+// queryList читает параметр-список: значения через запятую или повтором параметра
+// (?free_from=milk,nuts или ?free_from=milk&free_from=nuts)
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, param := range c.QueryArray(key) {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
 func (h *ProductHandler) ListProducts(c *gin.Context) {
 
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "6"))
 
 	name := c.Query("name")
+	query := c.Query("q")
+	archived, _ := strconv.ParseBool(c.DefaultQuery("archived", "false"))
 	minPriceStr := c.Query("min_price")
 	maxPriceStr := c.Query("max_price")
 
@@ -57,34 +109,64 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 	req := &inventory.ListProductsRequest{
 		Filter: &inventory.FilterParams{
 			Name:     name,
+			Query:    query,
 			MinPrice: minPrice,
 			MaxPrice: maxPrice,
+			Archived: archived,
+			// Аллергены и пищевые метки: allergens/diet - все перечисленные, free_from/exclude_diet - ни одного
+			WithAllergens:      queryList(c, "allergens"),
+			WithoutAllergens:   queryList(c, "free_from"),
+			WithDietaryTags:    queryList(c, "diet"),
+			WithoutDietaryTags: queryList(c, "exclude_diet"),
 		},
 		Pagination: &inventory.PaginationParams{
 			Page:    int32(page),
 			PerPage: int32(perPage),
+			Cursor:  c.Query("cursor"),
 		},
+		Sort: c.Query("sort"),
 	}
 
 	resp, err := h.client.ListProducts(context.Background(), req)
 	if err != nil {
+		if st, ok := status.FromError(err); ok && st.Code() == codes.InvalidArgument {
+			c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"products": resp.Products,
-		"total":    resp.Total,
-		"page":     resp.Page,
-		"per_page": resp.PerPage,
+		"products":    resp.Products,
+		"total":       resp.Total,
+		"page":        resp.Page,
+		"per_page":    resp.PerPage,
+		"next_cursor": resp.NextCursor,
+		"sort":        resp.Sort,
 	})
 }
 
 func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	var reqBody struct {
-		Name  string  `json:"Name" binding:"required"`
-		Price float64 `json:"Price" binding:"required,min=0"`
-		Stock int     `json:"Stock" binding:"required,min=0"`
+		Name        string   `json:"Name" binding:"required"`
+		Description string   `json:"Description"`
+		Price       float64  `json:"Price" binding:"required,min=0"`
+		Stock       int      `json:"Stock" binding:"required,min=0"`
+		SKU         string   `json:"SKU"`
+		Unit        string   `json:"Unit"`
+		Barcodes    []string `json:"Barcodes"`
+
+		Nutrition   *inventory.NutritionFacts `json:"Nutrition"`
+		Ingredients []string                  `json:"Ingredients"`
+		Allergens   []string                  `json:"Allergens"`
+		DietaryTags []string                  `json:"DietaryTags"`
+
+		Kind        string                       `json:"Kind"`
+		ParentID    string                       `json:"ParentID"`
+		VariantName string                       `json:"VariantName"`
+		Components  []*inventory.BundleComponent `json:"Components"`
+		Category    string                       `json:"Category"`
 	}
 
 	if err := c.ShouldBindJSON(&reqBody); err != nil {
@@ -93,27 +175,56 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	}
 
 	req := &inventory.CreateProductRequest{
-		Name:  reqBody.Name,
-		Price: reqBody.Price,
-		Stock: int32(reqBody.Stock),
+		Name:        reqBody.Name,
+		Description: reqBody.Description,
+		Price:       reqBody.Price,
+		Stock:       int32(reqBody.Stock),
+		Sku:         reqBody.SKU,
+		Unit:        reqBody.Unit,
+		Barcodes:    reqBody.Barcodes,
+		Nutrition:   reqBody.Nutrition,
+		Ingredients: reqBody.Ingredients,
+		Allergens:   reqBody.Allergens,
+		DietaryTags: reqBody.DietaryTags,
+		Kind:        reqBody.Kind,
+		ParentId:    reqBody.ParentID,
+		VariantName: reqBody.VariantName,
+		Components:  reqBody.Components,
+		Category:    reqBody.Category,
 	}
 
-	resp, err := h.client.CreateProduct(context.Background(), req)
+	resp, err := h.client.CreateProduct(actorContext(c), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondProductUpdateError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, resp.Product)
 }
 
+// UpdateProduct заменяет карточку товара целиком. Version - версия товара,
+// которую видел клиент; если товар успели изменить, возвращается 409.
 func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	id := c.Param("id")
 
 	var reqBody struct {
-		Name  string  `json:"Name" binding:"required"`
-		Price float64 `json:"Price" binding:"required,min=0"`
-		Stock int     `json:"Stock" binding:"required,min=0"`
+		Name        string   `json:"Name" binding:"required"`
+		Description string   `json:"Description"`
+		Price       float64  `json:"Price" binding:"required,min=0"`
+		Stock       int      `json:"Stock" binding:"required,min=0"`
+		Version     int64    `json:"Version" binding:"required,min=1"`
+		SKU         string   `json:"SKU"`
+		Barcodes    []string `json:"Barcodes"`
+
+		Nutrition   *inventory.NutritionFacts `json:"Nutrition"`
+		Ingredients []string                  `json:"Ingredients"`
+		Allergens   []string                  `json:"Allergens"`
+		DietaryTags []string                  `json:"DietaryTags"`
+
+		ParentID    string                       `json:"ParentID"`
+		VariantName string                       `json:"VariantName"`
+		Components  []*inventory.BundleComponent `json:"Components"`
+		Category    string                       `json:"Category"`
 	}
 
 	if err := c.ShouldBindJSON(&reqBody); err != nil {
@@ -122,21 +233,146 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	}
 
 	req := &inventory.UpdateProductRequest{
-		Id:    id,
-		Name:  reqBody.Name,
-		Price: reqBody.Price,
-		Stock: int32(reqBody.Stock),
+		Id:          id,
+		Name:        reqBody.Name,
+		Description: reqBody.Description,
+		Price:       reqBody.Price,
+		Stock:       int32(reqBody.Stock),
+		Version:     reqBody.Version,
+		Sku:         reqBody.SKU,
+		Barcodes:    reqBody.Barcodes,
+		Nutrition:   reqBody.Nutrition,
+		Ingredients: reqBody.Ingredients,
+		Allergens:   reqBody.Allergens,
+		DietaryTags: reqBody.DietaryTags,
+		ParentId:    reqBody.ParentID,
+		VariantName: reqBody.VariantName,
+		Components:  reqBody.Components,
+		Category:    reqBody.Category,
 	}
 
-	resp, err := h.client.UpdateProduct(context.Background(), req)
+	resp, err := h.client.UpdateProduct(actorContext(c), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondProductUpdateError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, resp)
 }
 
+// productPatchFields - поля тела PATCH и соответствующие им пути маски обновления
+var productPatchFields = map[string]string{
+	"Name":        "name",
+	"Description": "description",
+	"Price":       "price",
+	"Stock":       "stock",
+	"SKU":         "sku",
+	"Barcodes":    "barcodes",
+	"Nutrition":   "nutrition",
+	"Ingredients": "ingredients",
+	"Allergens":   "allergens",
+	"DietaryTags": "dietary_tags",
+	"ParentID":    "parent_id",
+	"VariantName": "variant_name",
+	"Components":  "components",
+	"Category":    "category",
+}
+
+// PatchProduct обновляет только переданные поля карточки товара,
+// например смена цены не трогает остаток
+func (h *ProductHandler) PatchProduct(c *gin.Context) {
+	var body map[string]json.RawMessage
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req := &inventory.UpdateProductRequest{Id: c.Param("id"), UpdateMask: &fieldmaskpb.FieldMask{}}
+	for key, value := range body {
+		var err error
+		switch key {
+		case "Version":
+			err = json.Unmarshal(value, &req.Version)
+		case "Name":
+			err = json.Unmarshal(value, &req.Name)
+		case "Description":
+			err = json.Unmarshal(value, &req.Description)
+		case "Price":
+			err = json.Unmarshal(value, &req.Price)
+		case "Stock":
+			err = json.Unmarshal(value, &req.Stock)
+		case "SKU":
+			err = json.Unmarshal(value, &req.Sku)
+		case "Barcodes":
+			err = json.Unmarshal(value, &req.Barcodes)
+		case "Nutrition":
+			// null очищает пищевую ценность
+			err = json.Unmarshal(value, &req.Nutrition)
+		case "Ingredients":
+			err = json.Unmarshal(value, &req.Ingredients)
+		case "Allergens":
+			err = json.Unmarshal(value, &req.Allergens)
+		case "DietaryTags":
+			err = json.Unmarshal(value, &req.DietaryTags)
+		case "ParentID":
+			err = json.Unmarshal(value, &req.ParentId)
+		case "VariantName":
+			err = json.Unmarshal(value, &req.VariantName)
+		case "Components":
+			err = json.Unmarshal(value, &req.Components)
+		case "Category":
+			err = json.Unmarshal(value, &req.Category)
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown field " + key})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + key + ": " + err.Error()})
+			return
+		}
+		if path, ok := productPatchFields[key]; ok {
+			req.UpdateMask.Paths = append(req.UpdateMask.Paths, path)
+		}
+	}
+
+	if req.Version <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Version is required"})
+		return
+	}
+	if len(req.UpdateMask.Paths) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no fields to update"})
+		return
+	}
+
+	resp, err := h.client.UpdateProduct(actorContext(c), req)
+	if err != nil {
+		respondProductUpdateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func respondProductUpdateError(c *gin.Context, err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch st.Code() {
+	case codes.InvalidArgument:
+		c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+	case codes.NotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": st.Message()})
+	case codes.Aborted, codes.AlreadyExists:
+		c.JSON(http.StatusConflict, gin.H{"error": st.Message()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+	}
+}
+
+// DeleteProduct переносит товар в архив
 func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 	id := c.Param("id")
 
@@ -146,18 +382,117 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 
 	resp, err := h.client.DeleteProduct(context.Background(), req)
 	if err != nil {
+		respondProductError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": resp})
+}
+
+// RestoreProduct возвращает товар из архива в каталог
+func (h *ProductHandler) RestoreProduct(c *gin.Context) {
+	resp, err := h.client.RestoreProduct(context.Background(), &inventory.RestoreProductRequest{Id: c.Param("id")})
+	if err != nil {
+		respondProductError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// PurgeProduct окончательно удаляет архивный товар, на который нет ссылок
+func (h *ProductHandler) PurgeProduct(c *gin.Context) {
+	resp, err := h.client.PurgeProduct(context.Background(), &inventory.PurgeProductRequest{Id: c.Param("id")})
+	if err != nil {
+		respondProductError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": resp})
+}
+
+// respondProductError: 404 - товара нет, 409 - товар не в архиве или на него есть ссылки
+func respondProductError(c *gin.Context, err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch st.Code() {
+	case codes.NotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": st.Message()})
+	case codes.FailedPrecondition:
+		c.JSON(http.StatusConflict, gin.H{"error": st.Message()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+	}
+}
+
+// maxImageUploadSize ограничивает тело multipart запроса; точный лимит на файл проверяет Inventory Service
+const maxImageUploadSize = 9 << 20
+
+// UploadProductImage принимает изображение товара в поле "image" формы multipart/form-data
+func (h *ProductHandler) UploadProductImage(c *gin.Context) {
+	id := c.Param("id")
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImageUploadSize)
+	fileHeader, err := c.FormFile("image")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "image file is required (max 8 MB)"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-		st, ok := status.FromError(err)
-		if ok {
+	req := &inventory.UploadProductImageRequest{
+		ProductId: id,
+		Filename:  fileHeader.Filename,
+		Data:      data,
+	}
+
+	resp, err := h.client.UploadProductImage(context.Background(), req)
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
 			switch st.Code() {
+			case codes.InvalidArgument:
+				c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+				return
 			case codes.NotFound:
 				c.JSON(http.StatusNotFound, gin.H{"error": st.Message()})
-			default:
-				c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+				return
 			}
-			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *ProductHandler) DeleteProductImage(c *gin.Context) {
+	req := &inventory.DeleteProductImageRequest{
+		ProductId: c.Param("id"),
+		ImageId:   c.Param("image_id"),
+	}
+
+	resp, err := h.client.DeleteProductImage(context.Background(), req)
+	if err != nil {
+		if st, ok := status.FromError(err); ok && st.Code() == codes.NotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": st.Message()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"FoodStore-AdvProg2/proto/order"
+)
+
+// PromotionHandler - акции и купоны Order Service
+type PromotionHandler struct {
+	client order.OrderServiceClient
+}
+
+func NewPromotionHandler(client order.OrderServiceClient) *PromotionHandler {
+	return &PromotionHandler{client: client}
+}
+
+// CreatePromotion создает акцию; с coupon_code она применяется только по купону
+func (h *PromotionHandler) CreatePromotion(c *gin.Context) {
+	var reqBody struct {
+		Name         string   `json:"name" binding:"required"`
+		Type         string   `json:"type" binding:"required"`
+		Value        float64  `json:"value"`
+		BuyQuantity  int32    `json:"buy_quantity"`
+		GetQuantity  int32    `json:"get_quantity"`
+		MinSubtotal  float64  `json:"min_subtotal"`
+		ProductIDs   []string `json:"product_ids"`
+		Categories   []string `json:"categories"`
+		CouponCode   string   `json:"coupon_code"`
+		UsageLimit   int32    `json:"usage_limit"`
+		PerUserLimit int32    `json:"per_user_limit"`
+		StartsAt     string   `json:"starts_at"`
+		EndsAt       string   `json:"ends_at"`
+		// Stackable по умолчанию true: акции складываются, пока их явно не сделали несуммируемыми
+		Stackable *bool `json:"stackable"`
+		Priority  int32 `json:"priority"`
+	}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	stackable := true
+	if reqBody.Stackable != nil {
+		stackable = *reqBody.Stackable
+	}
+
+	resp, err := h.client.CreatePromotion(actorContext(c), &order.CreatePromotionRequest{
+		Name:         reqBody.Name,
+		Type:         reqBody.Type,
+		Value:        reqBody.Value,
+		BuyQuantity:  reqBody.BuyQuantity,
+		GetQuantity:  reqBody.GetQuantity,
+		MinSubtotal:  reqBody.MinSubtotal,
+		ProductIds:   reqBody.ProductIDs,
+		Categories:   reqBody.Categories,
+		CouponCode:   reqBody.CouponCode,
+		UsageLimit:   reqBody.UsageLimit,
+		PerUserLimit: reqBody.PerUserLimit,
+		StartsAt:     reqBody.StartsAt,
+		EndsAt:       reqBody.EndsAt,
+		Stackable:    stackable,
+		Priority:     reqBody.Priority,
+	})
+	if err != nil {
+		respondPromotionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListPromotions возвращает акции; active=true - только включенные
+func (h *PromotionHandler) ListPromotions(c *gin.Context) {
+	resp, err := h.client.ListPromotions(context.Background(), &order.ListPromotionsRequest{
+		ActiveOnly: c.Query("active") == "true",
+	})
+	if err != nil {
+		respondPromotionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"promotions": resp.Promotions})
+}
+
+// UpdatePromotion включает или выключает акцию
+func (h *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	var reqBody struct {
+		Active *bool `json:"active" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.SetPromotionActive(actorContext(c), &order.SetPromotionActiveRequest{
+		Id:     c.Param("id"),
+		Active: *reqBody.Active,
+	})
+	if err != nil {
+		respondPromotionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func respondPromotionError(c *gin.Context, err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch st.Code() {
+	case codes.InvalidArgument:
+		c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+	case codes.NotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": st.Message()})
+	case codes.AlreadyExists:
+		c.JSON(http.StatusConflict, gin.H{"error": st.Message()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+	}
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"FoodStore-AdvProg2/proto/inventory"
+)
+
+// PurchasingHandler - поставщики, заказы на пополнение остатков и приемка товара по ним
+type PurchasingHandler struct {
+	client inventory.InventoryServiceClient
+}
+
+func NewPurchasingHandler(client inventory.InventoryServiceClient) *PurchasingHandler {
+	return &PurchasingHandler{client: client}
+}
+
+func (h *PurchasingHandler) ListSuppliers(c *gin.Context) {
+	resp, err := h.client.ListSuppliers(context.Background(), &inventory.ListSuppliersRequest{})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"suppliers": resp.Suppliers})
+}
+
+func (h *PurchasingHandler) CreateSupplier(c *gin.Context) {
+	var reqBody struct {
+		Name    string `json:"name" binding:"required"`
+		Email   string `json:"email"`
+		Phone   string `json:"phone"`
+		Address string `json:"address"`
+	}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.CreateSupplier(context.Background(), &inventory.CreateSupplierRequest{
+		Name:    reqBody.Name,
+		Email:   reqBody.Email,
+		Phone:   reqBody.Phone,
+		Address: reqBody.Address,
+	})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *PurchasingHandler) ListPurchaseOrders(c *gin.Context) {
+	resp, err := h.client.ListPurchaseOrders(context.Background(), &inventory.ListPurchaseOrdersRequest{
+		Status:     c.Query("status"),
+		SupplierId: c.Query("supplier_id"),
+	})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"purchase_orders": resp.PurchaseOrders})
+}
+
+// CreatePurchaseOrder создает черновик заказа поставщику
+func (h *PurchasingHandler) CreatePurchaseOrder(c *gin.Context) {
+	var reqBody struct {
+		SupplierID string                              `json:"supplier_id" binding:"required"`
+		LocationID string                              `json:"location_id"`
+		ExpectedAt string                              `json:"expected_at"`
+		Notes      string                              `json:"notes"`
+		Lines      []*inventory.PurchaseOrderLineInput `json:"lines" binding:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.CreatePurchaseOrder(actorContext(c), &inventory.CreatePurchaseOrderRequest{
+		SupplierId: reqBody.SupplierID,
+		LocationId: reqBody.LocationID,
+		ExpectedAt: reqBody.ExpectedAt,
+		Notes:      reqBody.Notes,
+		Lines:      reqBody.Lines,
+	})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *PurchasingHandler) GetPurchaseOrder(c *gin.Context) {
+	resp, err := h.client.GetPurchaseOrder(context.Background(), &inventory.GetPurchaseOrderRequest{
+		Id: c.Param("id"),
+	})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// UpdatePurchaseOrderStatus отправляет заказ поставщику (sent) или отменяет его (cancelled)
+func (h *PurchasingHandler) UpdatePurchaseOrderStatus(c *gin.Context) {
+	var reqBody struct {
+		Status string `json:"status" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.UpdatePurchaseOrderStatus(context.Background(), &inventory.UpdatePurchaseOrderStatusRequest{
+		Id:     c.Param("id"),
+		Status: reqBody.Status,
+	})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ReceivePurchaseOrder принимает товар по строкам заказа поставщику
+func (h *PurchasingHandler) ReceivePurchaseOrder(c *gin.Context) {
+	var reqBody struct {
+		Lines []*inventory.PurchaseReceiptLine `json:"lines" binding:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.ReceivePurchaseOrder(actorContext(c), &inventory.ReceivePurchaseOrderRequest{
+		Id:    c.Param("id"),
+		Lines: reqBody.Lines,
+	})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
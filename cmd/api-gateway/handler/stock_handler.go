@@ -0,0 +1,308 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"FoodStore-AdvProg2/proto/inventory"
+)
+
+// StockHandler - складские операции: партии товаров, сроки годности и перемещения между точками
+type StockHandler struct {
+	client inventory.InventoryServiceClient
+}
+
+func NewStockHandler(client inventory.InventoryServiceClient) *StockHandler {
+	return &StockHandler{client: client}
+}
+
+// ListBatches возвращает партии товара в порядке FEFO
+func (h *StockHandler) ListBatches(c *gin.Context) {
+	includeEmpty, _ := strconv.ParseBool(c.DefaultQuery("include_empty", "false"))
+
+	resp, err := h.client.ListBatches(context.Background(), &inventory.ListBatchesRequest{
+		ProductId:    c.Param("id"),
+		IncludeEmpty: includeEmpty,
+		LocationId:   c.Query("location_id"),
+	})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"batches": resp.Batches})
+}
+
+// ReceiveBatch оприходует партию товара
+func (h *StockHandler) ReceiveBatch(c *gin.Context) {
+	var reqBody struct {
+		LotNumber  string `json:"lot_number" binding:"required"`
+		ReceivedAt string `json:"received_at"`
+		ExpiresAt  string `json:"expires_at"`
+		Quantity   int    `json:"quantity" binding:"required,min=1"`
+		LocationID string `json:"location_id"`
+	}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.ReceiveBatch(actorContext(c), &inventory.ReceiveBatchRequest{
+		ProductId:  c.Param("id"),
+		LotNumber:  reqBody.LotNumber,
+		ReceivedAt: reqBody.ReceivedAt,
+		ExpiresAt:  reqBody.ExpiresAt,
+		Quantity:   int32(reqBody.Quantity),
+		LocationId: reqBody.LocationID,
+	})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListExpiringBatches возвращает партии, срок годности которых истекает в ближайшие days дней
+func (h *StockHandler) ListExpiringBatches(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "3"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be an integer"})
+		return
+	}
+
+	resp, err := h.client.ListExpiringBatches(context.Background(), &inventory.ListExpiringBatchesRequest{
+		Days: int32(days),
+	})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"batches": resp.Batches})
+}
+
+// WriteOffBatch списывает товар с партии с указанием причины
+func (h *StockHandler) WriteOffBatch(c *gin.Context) {
+	var reqBody struct {
+		Quantity int    `json:"quantity" binding:"required,min=1"`
+		Reason   string `json:"reason" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.WriteOffBatch(actorContext(c), &inventory.WriteOffBatchRequest{
+		BatchId:  c.Param("batch_id"),
+		Quantity: int32(reqBody.Quantity),
+		Reason:   reqBody.Reason,
+	})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetStockMovements возвращает журнал движения товаров для аудита
+func (h *StockHandler) GetStockMovements(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "50"))
+
+	resp, err := h.client.GetStockMovements(context.Background(), &inventory.GetStockMovementsRequest{
+		ProductId:   c.Query("product_id"),
+		LocationId:  c.Query("location_id"),
+		BatchId:     c.Query("batch_id"),
+		Type:        c.Query("type"),
+		ReferenceId: c.Query("reference_id"),
+		Actor:       c.Query("actor"),
+		From:        c.Query("from"),
+		To:          c.Query("to"),
+		Page:        int32(page),
+		PerPage:     int32(perPage),
+	})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"movements": resp.Movements,
+		"total":     resp.Total,
+		"page":      resp.Page,
+		"per_page":  resp.PerPage,
+	})
+}
+
+// ReconcileStock сверяет остатки товаров с журналом движений
+func (h *StockHandler) ReconcileStock(c *gin.Context) {
+	onlyMismatched, _ := strconv.ParseBool(c.DefaultQuery("only_mismatched", "false"))
+
+	resp, err := h.client.ReconcileStock(context.Background(), &inventory.ReconcileStockRequest{
+		ProductId:      c.Query("product_id"),
+		OnlyMismatched: onlyMismatched,
+	})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": resp.Items})
+}
+
+// GetStockLevels возвращает доступный остаток товара по складам и магазинам
+func (h *StockHandler) GetStockLevels(c *gin.Context) {
+	resp, err := h.client.GetStockLevels(context.Background(), &inventory.GetStockLevelsRequest{
+		ProductId: c.Param("id"),
+	})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"levels": resp.Levels})
+}
+
+// TransferStock перемещает товар между точками
+func (h *StockHandler) TransferStock(c *gin.Context) {
+	var reqBody struct {
+		ProductID      string `json:"product_id" binding:"required"`
+		FromLocationID string `json:"from_location_id" binding:"required"`
+		ToLocationID   string `json:"to_location_id" binding:"required"`
+		Quantity       int    `json:"quantity" binding:"required,min=1"`
+		Reason         string `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.TransferStock(actorContext(c), &inventory.TransferStockRequest{
+		ProductId:      reqBody.ProductID,
+		FromLocationId: reqBody.FromLocationID,
+		ToLocationId:   reqBody.ToLocationID,
+		Quantity:       int32(reqBody.Quantity),
+		Reason:         reqBody.Reason,
+	})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// respondStockError преобразует gRPC статус Inventory Service в HTTP ответ
+func respondStockError(c *gin.Context, err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch st.Code() {
+	case codes.InvalidArgument:
+		c.JSON(http.StatusBadRequest, gin.H{"error": st.Message()})
+	case codes.NotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": st.Message()})
+	case codes.FailedPrecondition:
+		c.JSON(http.StatusConflict, gin.H{"error": st.Message()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": st.Message()})
+	}
+}
+
+// SetReorderPolicy задает точку заказа и минимальную партию пополнения товара
+func (h *StockHandler) SetReorderPolicy(c *gin.Context) {
+	var reqBody struct {
+		ReorderPoint    int32 `json:"reorder_point" binding:"min=0"`
+		ReorderQuantity int32 `json:"reorder_quantity" binding:"min=0"`
+	}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.SetReorderPolicy(context.Background(), &inventory.SetReorderPolicyRequest{
+		ProductId:       c.Param("id"),
+		ReorderPoint:    reqBody.ReorderPoint,
+		ReorderQuantity: reqBody.ReorderQuantity,
+	})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ListLowStockAlerts возвращает алерты о низком остатке
+func (h *StockHandler) ListLowStockAlerts(c *gin.Context) {
+	openOnly, _ := strconv.ParseBool(c.DefaultQuery("open_only", "false"))
+
+	resp, err := h.client.ListLowStockAlerts(context.Background(), &inventory.ListLowStockAlertsRequest{OpenOnly: openOnly})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"alerts": resp.Alerts})
+}
+
+// CheckLowStock запускает проверку остатков по точкам заказа вне расписания
+func (h *StockHandler) CheckLowStock(c *gin.Context) {
+	resp, err := h.client.CheckLowStock(context.Background(), &inventory.CheckLowStockRequest{})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"alerts": resp.Alerts})
+}
+
+// ListReorderSuggestions возвращает предложения на пополнение
+func (h *StockHandler) ListReorderSuggestions(c *gin.Context) {
+	resp, err := h.client.ListReorderSuggestions(context.Background(), &inventory.ListReorderSuggestionsRequest{
+		Status: c.Query("status"),
+	})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"suggestions": resp.Suggestions})
+}
+
+// UpdateReorderSuggestionStatus отмечает предложение заказанным или отклоненным
+func (h *StockHandler) UpdateReorderSuggestionStatus(c *gin.Context) {
+	var reqBody struct {
+		Status string `json:"status" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.client.UpdateReorderSuggestionStatus(context.Background(), &inventory.UpdateReorderSuggestionStatusRequest{
+		Id:     c.Param("id"),
+		Status: reqBody.Status,
+	})
+	if err != nil {
+		respondStockError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"FoodStore-AdvProg2/proto/user"
+	"FoodStore-AdvProg2/utils"
 )
 
 // UserHandler обрабатывает HTTP-запросы к User API
@@ -70,7 +71,7 @@ func (h *UserHandler) AuthenticateUser(c *gin.Context) {
 	}
 
 	// Отправляем запрос к gRPC сервису
-	resp, err := h.client.Login(context.Background(), &user.LoginRequest{
+	resp, err := h.client.AuthenticateUser(context.Background(), &user.AuthRequest{
 		Username: request.Username,
 		Password: request.Password,
 	})
@@ -80,12 +81,21 @@ func (h *UserHandler) AuthenticateUser(c *gin.Context) {
 		return
 	}
 
+	// Токен передается в заголовке Authorization: Bearer <token>
+	token, err := utils.GenerateToken(resp.User.Id, resp.User.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Возвращаем успешный ответ
 	c.JSON(http.StatusOK, gin.H{
 		"id":        resp.User.Id,
 		"username":  resp.User.Username,
 		"email":     resp.User.Email,
 		"full_name": resp.User.FullName,
+		"role":      resp.User.Role,
+		"token":     token,
 	})
 }
 
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"crypto/rand"
 	"log"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -15,6 +18,7 @@ import (
 	"FoodStore-AdvProg2/proto/inventory"
 	"FoodStore-AdvProg2/proto/order"
 	"FoodStore-AdvProg2/proto/user"
+	"FoodStore-AdvProg2/utils"
 )
 
 func main() {
@@ -23,6 +27,8 @@ func main() {
 		log.Printf("Warning: Error loading .env file: %s", err)
 	}
 
+	utils.SetJWTSecret(jwtSecret())
+
 	// Запускаем отдельный сервер для главной страницы
 	RunHomepageServer()
 
@@ -52,7 +58,16 @@ func main() {
 
 	// Создание обработчиков
 	productHandler := handler.NewProductHandler(inventoryClient)
+	stockHandler := handler.NewStockHandler(inventoryClient)
+	pricingHandler := handler.NewPricingHandler(inventoryClient)
+	locationHandler := handler.NewLocationHandler(inventoryClient)
+	purchasingHandler := handler.NewPurchasingHandler(inventoryClient)
 	orderHandler := handler.NewOrderHandler(orderClient)
+	promotionHandler := handler.NewPromotionHandler(orderClient)
+	cartHandler := handler.NewCartHandler(orderClient)
+	deliverySlotHandler := handler.NewDeliverySlotHandler(orderClient)
+	deliveryZoneHandler := handler.NewDeliveryZoneHandler(orderClient)
+	deliveryHandler := handler.NewDeliveryHandler(orderClient)
 	userHandler := handler.NewUserHandler(userClient)
 
 	// Инициализация Gin с нуля
@@ -63,6 +78,7 @@ func main() {
 
 	// Статические файлы и шаблоны
 	r.Static("/static", "./public")
+	r.Static("/media", mediaDir())
 	r.LoadHTMLGlob("./public/*.html")
 
 	// Регистрируем тестовые маршруты
@@ -87,34 +103,22 @@ func main() {
 
 	// API маршруты
 	api := r.Group("/api")
-	{
-		// Product routes
-		products := api.Group("/products")
-		{
-			products.GET("", productHandler.ListProducts)
-			products.GET("/:id", productHandler.GetProduct)
-			products.POST("", productHandler.CreateProduct)
-			products.PUT("/:id", productHandler.UpdateProduct)
-			products.DELETE("/:id", productHandler.DeleteProduct)
-		}
-
-		// Order routes
-		orders := api.Group("/orders")
-		{
-			orders.POST("", orderHandler.CreateOrder)
-			orders.GET("", orderHandler.GetOrders)
-			orders.GET("/:id", orderHandler.GetOrder)
-			orders.PATCH("/:id", orderHandler.UpdateOrderStatus)
-		}
-
-		// User routes
-		users := api.Group("/users")
-		{
-			users.POST("/register", userHandler.RegisterUser)
-			users.POST("/login", userHandler.AuthenticateUser)
-			users.GET("/profile", middleware.AuthMiddleware(), userHandler.GetUserProfile)
-		}
-	}
+	api.Use(middleware.RateLimit(rateLimitConfig()))
+	registerAPI(api, apiHandlers{
+		product:      productHandler,
+		stock:        stockHandler,
+		pricing:      pricingHandler,
+		location:     locationHandler,
+		purchasing:   purchasingHandler,
+		order:        orderHandler,
+		promotion:    promotionHandler,
+		cart:         cartHandler,
+		deliverySlot: deliverySlotHandler,
+		deliveryZone: deliveryZoneHandler,
+		delivery:     deliveryHandler,
+		user:         userHandler,
+		deprecations: middleware.NewDeprecationCounter(),
+	})
 
 	// Запуск сервера
 	port := os.Getenv("API_GATEWAY_PORT")
@@ -132,6 +136,72 @@ func main() {
 	}
 }
 
+// rateLimitConfig задает лимиты запросов для публичного API.
+// Маршруты указываются без префикса версии и действуют одинаково для /api/v1 и алиаса /api.
+// Ключи партнеров перечисляются через запятую в API_KEYS; с другими ключами лимит считается по IP.
+func rateLimitConfig() middleware.RateLimitConfig {
+	apiKey := middleware.KeyByAPIKey(strings.Split(os.Getenv("API_KEYS"), ","))
+	routes := map[string]middleware.RateLimitPolicy{
+		// Каталог чаще всего скрейпят, партнеры ходят с API-ключом
+		"GET /products":     {Name: "products-list", Rate: 5, Burst: 20, Key: apiKey},
+		"GET /products/:id": {Name: "products-get", Rate: 10, Burst: 30, Key: apiKey},
+		// Защита от перебора паролей: 5 попыток, затем одна в 12 секунд
+		"POST /users/login":    {Name: "login", Rate: 5.0 / 60, Burst: 5, Key: middleware.KeyByIP},
+		"POST /users/register": {Name: "register", Rate: 1.0 / 60, Burst: 3, Key: middleware.KeyByIP},
+		"POST /orders":         {Name: "orders-create", Rate: 1, Burst: 5, Key: middleware.KeyByUser},
+		// Оформление корзины создает заказ так же, как POST /orders
+		"POST /cart/:id/checkout": {Name: "cart-checkout", Rate: 1, Burst: 5, Key: middleware.KeyByUser},
+		// Бронь держит место в интервале: частые брони могут занять все места
+		"POST /delivery-slots/:id/reserve": {Name: "slot-reserve", Rate: 0.2, Burst: 5, Key: middleware.KeyByUser},
+		// Загрузка и обработка изображений заметно нагружает Inventory Service
+		"POST /products/:id/images": {Name: "images-upload", Rate: 0.5, Burst: 5, Key: middleware.KeyByIP},
+		// Фото вручения передаются в Order Service целиком
+		"POST /deliveries/:id/proof": {Name: "proof-upload", Rate: 0.5, Burst: 5, Key: middleware.KeyByIP},
+		// Импорт и выгрузка обрабатывают весь каталог за один запрос
+		"POST /products/import": {Name: "catalog-import", Rate: 1.0 / 30, Burst: 3, Key: middleware.KeyByIP},
+		"GET /products/export":  {Name: "catalog-export", Rate: 1.0 / 30, Burst: 3, Key: middleware.KeyByIP},
+		// Внеочередная проверка остатков пересчитывает все товары с точкой заказа
+		"POST /stock/alerts/check": {Name: "low-stock-check", Rate: 1.0 / 60, Burst: 2, Key: middleware.KeyByIP},
+	}
+
+	cfg := middleware.RateLimitConfig{
+		Store: middleware.NewMemoryRateLimitStore(10 * time.Minute),
+		Default: &middleware.RateLimitPolicy{
+			Name: "default", Rate: 10, Burst: 30, Key: middleware.KeyByIP,
+		},
+		Routes: make(map[string]middleware.RateLimitPolicy),
+	}
+	for route, policy := range routes {
+		method, path, _ := strings.Cut(route, " ")
+		for _, prefix := range []string{"/api/v1", "/api"} {
+			cfg.Routes[method+" "+prefix+path] = policy
+		}
+	}
+	return cfg
+}
+
+// jwtSecret возвращает ключ подписи токенов из JWT_SECRET. Без него генерируется
+// случайный ключ, и выданные токены перестают действовать после перезапуска шлюза.
+func jwtSecret() []byte {
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	log.Printf("Warning: JWT_SECRET is not set, using a random key")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatalf("Failed to generate JWT secret: %v", err)
+	}
+	return secret
+}
+
+// mediaDir - каталог с изображениями товаров, общий с Inventory Service
+func mediaDir() string {
+	if dir := os.Getenv("MEDIA_DIR"); dir != "" {
+		return dir
+	}
+	return "./media"
+}
+
 // Функция для подключения к gRPC сервису
 func connectToService(envVarName, defaultURL string) (*grpc.ClientConn, error) {
 	serviceURL := os.Getenv(envVarName)
@@ -5,33 +5,71 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/utils"
 )
 
-// AuthMiddleware проверяет авторизацию пользователя
+// BearerToken возвращает токен из заголовка "Authorization: Bearer <token>" или пустую строку
+func BearerToken(c *gin.Context) string {
+	scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !ok || scheme != "Bearer" {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// AuthenticatedUserID возвращает ID пользователя, если запрос несет действительный токен.
+// После AuthMiddleware берется из контекста, иначе токен проверяется на месте.
+func AuthenticatedUserID(c *gin.Context) (string, bool) {
+	if userID := c.GetString("user_id"); userID != "" {
+		return userID, true
+	}
+	token := BearerToken(c)
+	if token == "" {
+		return "", false
+	}
+	claims, err := utils.ParseToken(token)
+	if err != nil {
+		return "", false
+	}
+	return claims.UserID, true
+}
+
+// AuthMiddleware проверяет подпись и срок JWT токена и кладет ID пользователя в контекст
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Получаем токен из заголовка
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			c.Abort()
+		token := BearerToken(c)
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
 			return
 		}
 
-		// Проверяем формат заголовка
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization format"})
-			c.Abort()
+		claims, err := utils.ParseToken(token)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			return
 		}
 
-		// Упрощенная версия без проверки JWT
-		// Используем значение токена как user_id
-		userId := parts[1]
+		// Устанавливаем ID и роль пользователя в контекст
+		c.Set("user_id", claims.UserID)
+		c.Set("user_role", claims.Role)
+		c.Next()
+	}
+}
+
+// IsAdmin сообщает, что токен запроса выдан администратору; ставится после AuthMiddleware
+func IsAdmin(c *gin.Context) bool {
+	return c.GetString("user_role") == domain.UserRoleAdmin
+}
 
-		// Устанавливаем ID пользователя в контекст
-		c.Set("user_id", userId)
+// RequireAdmin пропускает только администраторов; ставится после AuthMiddleware
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !IsAdmin(c) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin role required"})
+			return
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeprecationPolicy описывает устаревшую версию API
+type DeprecationPolicy struct {
+	Since  time.Time // с какого момента маршрут считается устаревшим
+	Sunset time.Time // когда маршрут будет отключен, нулевое значение - дата не назначена
+	// Префиксы для заголовка Link: путь запроса с заменой OldPrefix на NewPrefix
+	OldPrefix string
+	NewPrefix string
+}
+
+// DeprecatedCall - статистика обращений к одному устаревшему маршруту
+type DeprecatedCall struct {
+	Route    string    `json:"route"`
+	Count    int64     `json:"count"`
+	LastCall time.Time `json:"last_call"`
+}
+
+// DeprecationCounter считает обращения к устаревшим маршрутам
+type DeprecationCounter struct {
+	mu    sync.Mutex
+	calls map[string]*DeprecatedCall
+}
+
+func NewDeprecationCounter() *DeprecationCounter {
+	return &DeprecationCounter{calls: make(map[string]*DeprecatedCall)}
+}
+
+// Inc учитывает один вызов маршрута
+func (dc *DeprecationCounter) Inc(route string) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	call, ok := dc.calls[route]
+	if !ok {
+		call = &DeprecatedCall{Route: route}
+		dc.calls[route] = call
+		log.Printf("[API-GATEWAY] first call to deprecated route %s", route)
+	}
+	call.Count++
+	call.LastCall = time.Now()
+}
+
+// Snapshot возвращает копию статистики, отсортированную по маршруту
+func (dc *DeprecationCounter) Snapshot() []DeprecatedCall {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	result := make([]DeprecatedCall, 0, len(dc.calls))
+	for _, call := range dc.calls {
+		result = append(result, *call)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Route < result[j].Route
+	})
+	return result
+}
+
+// Deprecated - middleware для устаревших маршрутов.
+// Добавляет заголовки Deprecation (RFC 9745), Sunset (RFC 8594) и Link на замену,
+// а также учитывает вызов в counter.
+func Deprecated(policy DeprecationPolicy, counter *DeprecationCounter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Deprecation", fmt.Sprintf("@%d", policy.Since.Unix()))
+		if !policy.Sunset.IsZero() {
+			c.Header("Sunset", policy.Sunset.UTC().Format(http.TimeFormat))
+		}
+
+		if policy.NewPrefix != "" && strings.HasPrefix(c.Request.URL.Path, policy.OldPrefix) {
+			successor := policy.NewPrefix + strings.TrimPrefix(c.Request.URL.Path, policy.OldPrefix)
+			c.Header("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+		}
+
+		if counter != nil {
+			counter.Inc(c.Request.Method + " " + c.FullPath())
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KeyFunc определяет, по какому признаку считаются запросы клиента
+type KeyFunc func(c *gin.Context) string
+
+// KeyByIP группирует запросы по IP клиента
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// KeyByUser группирует запросы по ID пользователя из действительного токена,
+// а для анонимных и запросов с неверным токеном - по IP
+func KeyByUser(c *gin.Context) string {
+	if userID, ok := AuthenticatedUserID(c); ok {
+		return "user:" + userID
+	}
+	return KeyByIP(c)
+}
+
+// KeyByAPIKey группирует запросы по заголовку X-API-Key, если ключ входит в keys.
+// Запросы без ключа или с неизвестным ключом считаются по IP.
+func KeyByAPIKey(keys []string) KeyFunc {
+	known := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		if key != "" {
+			known[key] = struct{}{}
+		}
+	}
+
+	return func(c *gin.Context) string {
+		apiKey := c.GetHeader("X-API-Key")
+		if _, ok := known[apiKey]; ok {
+			return "key:" + apiKey
+		}
+		return KeyByIP(c)
+	}
+}
+
+// RateLimitPolicy описывает корзину токенов: Rate токенов в секунду, не больше Burst
+type RateLimitPolicy struct {
+	Name  string
+	Rate  float64
+	Burst int
+	Key   KeyFunc
+}
+
+func (p RateLimitPolicy) validate() error {
+	if p.Rate <= 0 || math.IsNaN(p.Rate) || math.IsInf(p.Rate, 0) {
+		return fmt.Errorf("rate limit policy %q: rate must be positive, got %v", p.Name, p.Rate)
+	}
+	if p.Burst <= 0 {
+		return fmt.Errorf("rate limit policy %q: burst must be positive, got %d", p.Name, p.Burst)
+	}
+	return nil
+}
+
+// RateLimitConfig - настройки middleware ограничения частоты запросов.
+// Routes индексируется строкой "METHOD /path" в формате маршрутов gin,
+// например "GET /api/products" или "PATCH /api/orders/:id".
+type RateLimitConfig struct {
+	Store   RateLimitStore
+	Default *RateLimitPolicy
+	Routes  map[string]RateLimitPolicy
+}
+
+// RateLimit - middleware, ограничивающий частоту запросов по алгоритму token bucket.
+// При превышении лимита возвращает 429 с заголовками Retry-After и RateLimit-*.
+// Паникует, если у политики неположительные Rate или Burst: это ошибка конфигурации.
+func RateLimit(cfg RateLimitConfig) gin.HandlerFunc {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryRateLimitStore(0)
+	}
+	if cfg.Default != nil {
+		if err := cfg.Default.validate(); err != nil {
+			panic(err)
+		}
+	}
+	for route, policy := range cfg.Routes {
+		if err := policy.validate(); err != nil {
+			panic(fmt.Sprintf("%s: %v", route, err))
+		}
+	}
+
+	return func(c *gin.Context) {
+		policy, ok := cfg.Routes[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			if cfg.Default == nil {
+				c.Next()
+				return
+			}
+			policy = *cfg.Default
+		}
+
+		keyFunc := policy.Key
+		if keyFunc == nil {
+			keyFunc = KeyByIP
+		}
+
+		result, err := cfg.Store.Take(c.Request.Context(), policy.Name+":"+keyFunc(c), policy.Rate, policy.Burst)
+		if err != nil {
+			// Недоступность хранилища не должна ронять весь API
+			log.Printf("[API-GATEWAY] rate limit store error: %v", err)
+			c.Next()
+			return
+		}
+
+		window := int(math.Ceil(float64(policy.Burst) / policy.Rate))
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Burst, window))
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			retryAfter := ceilSeconds(result.RetryAfter)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":       "Too many requests",
+				"retry_after": retryAfter,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
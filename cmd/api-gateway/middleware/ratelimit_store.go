@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimitResult - результат попытки взять токен из корзины
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration // сколько ждать до следующего токена, если запрос отклонён
+	Reset      time.Duration // через сколько корзина заполнится полностью
+}
+
+// RateLimitStore хранит состояние корзин токенов.
+// Сейчас есть только in-memory реализация, но интерфейс позволяет
+// подключить общее хранилище (например, Redis) для нескольких экземпляров шлюза.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, rate float64, burst int) (RateLimitResult, error)
+}
+
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// MemoryRateLimitStore - хранилище корзин в памяти процесса
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	ttl       time.Duration
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryRateLimitStore создает in-memory хранилище.
+// Корзины, к которым не обращались дольше ttl, периодически удаляются.
+func NewMemoryRateLimitStore(ttl time.Duration) *MemoryRateLimitStore {
+	if ttl <= 0 {
+		ttl = 10 * time.Minute
+	}
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]*tokenBucket),
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+// Take пытается списать один токен из корзины key
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, rate float64, burst int) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(burst), lastSeen: now}
+		s.buckets[key] = b
+	}
+
+	// Пополняем корзину пропорционально прошедшему времени
+	elapsed := now.Sub(b.lastSeen).Seconds()
+	b.tokens = math.Min(float64(burst), b.tokens+elapsed*rate)
+	b.lastSeen = now
+
+	result := RateLimitResult{Limit: burst}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / rate)
+	}
+
+	result.Remaining = int(math.Floor(b.tokens))
+	result.Reset = secondsToDuration((float64(burst) - b.tokens) / rate)
+
+	return result, nil
+}
+
+// sweep удаляет устаревшие корзины не чаще одного раза за ttl
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.ttl {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if now.Sub(b.lastSeen) > s.ttl {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
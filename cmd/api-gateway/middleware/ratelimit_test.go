@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"FoodStore-AdvProg2/utils"
+)
+
+func TestMemoryRateLimitStoreRefill(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryRateLimitStore(time.Hour)
+
+	// Корзина на 2 токена, пополняется на 1 токен в секунду
+	steps := []struct {
+		name       string
+		at         time.Duration
+		allowed    bool
+		remaining  int
+		retryAfter time.Duration
+	}{
+		{"first request", 0, true, 1, 0},
+		{"burst used up", 0, true, 0, 0},
+		{"empty bucket", 0, false, 0, time.Second},
+		{"half a token", 500 * time.Millisecond, false, 0, 500 * time.Millisecond},
+		{"refilled one token", time.Second, true, 0, 0},
+		{"refill capped at burst", time.Minute, true, 1, 0},
+	}
+
+	for _, step := range steps {
+		store.now = func() time.Time { return start.Add(step.at) }
+		result, err := store.Take(context.Background(), "k", 1, 2)
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if result.Allowed != step.allowed || result.Remaining != step.remaining || result.RetryAfter != step.retryAfter {
+			t.Errorf("%s: got allowed=%v remaining=%d retryAfter=%s, want %v %d %s", step.name,
+				result.Allowed, result.Remaining, result.RetryAfter, step.allowed, step.remaining, step.retryAfter)
+		}
+		if result.Limit != 2 {
+			t.Errorf("%s: limit = %d, want 2", step.name, result.Limit)
+		}
+	}
+}
+
+func TestRateLimitRespondsWithRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RateLimit(RateLimitConfig{
+		Default: &RateLimitPolicy{Name: "test", Rate: 0.5, Burst: 1, Key: KeyByIP},
+	}))
+	r.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		status     int
+		remaining  string
+		retryAfter string
+	}{
+		{http.StatusOK, "0", ""},
+		{http.StatusTooManyRequests, "0", "2"},
+	}
+
+	for i, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("request %d: status = %d, want %d", i, w.Code, tt.status)
+		}
+		if got := w.Header().Get("RateLimit-Remaining"); got != tt.remaining {
+			t.Errorf("request %d: RateLimit-Remaining = %q, want %q", i, got, tt.remaining)
+		}
+		if got := w.Header().Get("Retry-After"); got != tt.retryAfter {
+			t.Errorf("request %d: Retry-After = %q, want %q", i, got, tt.retryAfter)
+		}
+		if got := w.Header().Get("RateLimit-Policy"); got != "1;w=2" {
+			t.Errorf("request %d: RateLimit-Policy = %q, want %q", i, got, "1;w=2")
+		}
+	}
+}
+
+func TestRateLimitRejectsInvalidPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RateLimitPolicy
+	}{
+		{"zero rate", RateLimitPolicy{Name: "p", Rate: 0, Burst: 5}},
+		{"negative rate", RateLimitPolicy{Name: "p", Rate: -1, Burst: 5}},
+		{"NaN rate", RateLimitPolicy{Name: "p", Rate: math.NaN(), Burst: 5}},
+		{"zero burst", RateLimitPolicy{Name: "p", Rate: 1, Burst: 0}},
+	}
+
+	for _, tt := range tests {
+		for _, cfg := range []RateLimitConfig{
+			{Default: &tt.policy},
+			{Routes: map[string]RateLimitPolicy{"GET /ping": tt.policy}},
+		} {
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("%s: RateLimit did not reject the policy", tt.name)
+					}
+				}()
+				RateLimit(cfg)
+			}()
+		}
+	}
+}
+
+func TestKeyFuncs(t *testing.T) {
+	utils.SetJWTSecret([]byte("test-secret"))
+	token, err := utils.GenerateToken("42", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiKey := KeyByAPIKey([]string{"partner-key", ""})
+
+	tests := []struct {
+		name    string
+		key     KeyFunc
+		headers map[string]string
+		want    string
+	}{
+		{"user with valid token", KeyByUser, map[string]string{"Authorization": "Bearer " + token}, "user:42"},
+		{"user with forged token", KeyByUser, map[string]string{"Authorization": "Bearer 42-1700000000"}, "ip:192.0.2.1"},
+		{"user with wrong scheme", KeyByUser, map[string]string{"Authorization": "Basic " + token}, "ip:192.0.2.1"},
+		{"anonymous user", KeyByUser, nil, "ip:192.0.2.1"},
+		{"known api key", apiKey, map[string]string{"X-API-Key": "partner-key"}, "key:partner-key"},
+		{"unknown api key", apiKey, map[string]string{"X-API-Key": "made-up"}, "ip:192.0.2.1"},
+		{"no api key", apiKey, nil, "ip:192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		for name, value := range tt.headers {
+			req.Header.Set(name, value)
+		}
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = req
+
+		if got := tt.key(c); got != tt.want {
+			t.Errorf("%s: key = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
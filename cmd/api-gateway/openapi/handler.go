@@ -0,0 +1,34 @@
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+var (
+	specOnce sync.Once
+	spec     *Document
+)
+
+// SpecHandler отдает OpenAPI документ в JSON
+func SpecHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		specOnce.Do(func() {
+			spec = Spec()
+		})
+		c.JSON(http.StatusOK, spec)
+	}
+}
+
+// DocsHandler отдает встроенную страницу документации, которая читает /api/openapi.json
+func DocsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+	}
+}
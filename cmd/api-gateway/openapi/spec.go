@@ -0,0 +1,1817 @@
+package openapi
+
+import (
+	"strings"
+
+	"FoodStore-AdvProg2/proto/inventory"
+	"FoodStore-AdvProg2/proto/order"
+	"FoodStore-AdvProg2/proto/user"
+)
+
+// bearerAuth - требование JWT из POST /users/login в заголовке Authorization
+var bearerAuth = []map[string][]string{{"bearerAuth": {}}}
+
+// route описывает один REST маршрут шлюза
+type route struct {
+	Method    string // GET, POST, ...
+	Path      string // путь в формате gin относительно версии, например /orders/:id
+	Operation *Operation
+}
+
+// Spec строит OpenAPI документ по таблице маршрутов шлюза
+func Spec() *Document {
+	reg := newSchemaRegistry()
+
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "Food Store API Gateway",
+			Version:     "1.0.0",
+			Description: "REST API шлюза. Поля со значением по умолчанию (0, false, \"\") не передаются в ответах.",
+		},
+		Paths: make(map[string]PathItem),
+		Components: Components{
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer"},
+				"apiKey":     {Type: "apiKey", In: "header", Name: "X-API-Key"},
+			},
+		},
+	}
+
+	add := func(prefix string, rt route) {
+		path := PathFromGin(prefix + rt.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+		op := *rt.Operation
+		op.Responses["429"] = tooManyRequests()
+		doc.Paths[path][strings.ToLower(rt.Method)] = &op
+	}
+
+	for _, rt := range routes(reg) {
+		add("/api/v1", rt)
+
+		// Алиас /api без версии помечен как устаревший
+		legacy := *rt.Operation
+		legacy.OperationID += "Legacy"
+		legacy.Deprecated = true
+		legacy.Summary += " (устарело, используйте /api/v1)"
+		add("/api", route{rt.Method, rt.Path, &legacy})
+	}
+
+	add("/api/v1", route{"GET", "/deprecations", &Operation{
+		OperationID: "getDeprecatedCalls",
+		Summary:     "Статистика обращений к устаревшим маршрутам",
+		Tags:        []string{"docs"},
+		Responses: map[string]Response{
+			"200": jsonResponse("Счетчики по маршрутам", &Schema{Type: "array", Items: object(map[string]*Schema{
+				"route":     {Type: "string"},
+				"count":     {Type: "integer", Format: "int64"},
+				"last_call": {Type: "string", Format: "date-time"},
+			}, "route", "count", "last_call")}),
+		},
+	}})
+
+	reg.schemas["Error"] = object(map[string]*Schema{
+		"error": {Type: "string"},
+	}, "error")
+	doc.Components.Schemas = reg.schemas
+
+	return doc
+}
+
+// routes возвращает описание маршрутов, которые регистрирует registerV1Routes
+func routes(reg *schemaRegistry) []route {
+	idParam := pathParam("id")
+
+	sku := &Schema{Type: "string", Description: "Артикул до 64 символов, уникален среди всех товаров"}
+	barcodes := &Schema{Type: "array", Items: &Schema{Type: "string"},
+		Description: "Штрихкоды EAN-13, EAN-8 или UPC-A; каждый может принадлежать только одному товару"}
+	allergenCodes := []string{"gluten", "crustaceans", "eggs", "fish", "peanuts", "soy", "milk",
+		"nuts", "celery", "mustard", "sesame", "sulphites", "lupin", "molluscs"}
+	dietaryTags := []string{"vegan", "vegetarian", "gluten_free", "lactose_free", "halal", "kosher"}
+	cardFields := map[string]*Schema{
+		"Nutrition":   reg.Message(&inventory.NutritionFacts{}),
+		"Ingredients": {Type: "array", Items: &Schema{Type: "string"}, Description: "Состав в порядке, указанном на упаковке"},
+		"Allergens":   {Type: "array", Items: &Schema{Type: "string", Enum: allergenCodes}},
+		"DietaryTags": {Type: "array", Items: &Schema{Type: "string", Enum: dietaryTags},
+			Description: "Метка не может противоречить аллергенам: vegan без milk, eggs, fish, crustaceans, molluscs"},
+	}
+	components := &Schema{Type: "array", Items: reg.Message(&inventory.BundleComponent{}),
+		Description: "Состав набора: product_id и quantity в базовых единицах компонента; только для Kind=bundle"}
+	// Поля варианта и набора, как и пищевая ценность, общие для создания и обновления товара
+	cardFields["ParentID"] = &Schema{Type: "string", Format: "uuid",
+		Description: "Родительский товар; вариант не может иметь своих вариантов, пусто - самостоятельный товар"}
+	cardFields["VariantName"] = &Schema{Type: "string", Description: "Название варианта до 100 символов, обязательно при ParentID"}
+	cardFields["Components"] = components
+	cardFields["Category"] = &Schema{Type: "string",
+		Description: "Код категории до 50 символов: латинские буквы, цифры, _ и -; по нему настраиваются акции"}
+	withCardFields := func(fields map[string]*Schema) map[string]*Schema {
+		for name, schema := range cardFields {
+			fields[name] = schema
+		}
+		return fields
+	}
+	dietList := func(name string, values []string, description string) Parameter {
+		return queryParam(name, &Schema{Type: "array", Items: &Schema{Type: "string", Enum: values}},
+			description+"; значения через запятую или повтором параметра")
+	}
+	productBody := object(withCardFields(map[string]*Schema{
+		"Name":        {Type: "string"},
+		"Description": {Type: "string"},
+		"Price":       {Type: "number", Format: "double", Minimum: float(0)},
+		"Stock":       {Type: "integer", Minimum: float(0)},
+		"SKU":         sku,
+		"Unit": {Type: "string", Enum: []string{"piece", "kg", "litre"}, Default: "piece",
+			Description: "Цена указывается за единицу, Stock - в штуках, граммах или миллилитрах; после создания не меняется"},
+		"Barcodes": barcodes,
+		"Kind": {Type: "string", Enum: []string{"simple", "bundle"}, Default: "simple",
+			Description: "Остаток набора считается по компонентам, поэтому Stock набора должен быть 0"},
+	}), "Name", "Price", "Stock")
+
+	// Обновление товара передает версию, которую видел клиент
+	version := &Schema{Type: "integer", Format: "int64", Minimum: float(1), Description: "Текущая версия товара (поле version)"}
+	productUpdateBody := object(withCardFields(map[string]*Schema{
+		"Name":        {Type: "string"},
+		"Description": {Type: "string"},
+		"Price":       {Type: "number", Format: "double", Minimum: float(0)},
+		"Stock":       {Type: "integer", Minimum: float(0)},
+		"SKU":         sku,
+		"Barcodes":    barcodes,
+		"Version":     version,
+	}), "Name", "Price", "Stock", "Version")
+	productPatchBody := object(withCardFields(map[string]*Schema{
+		"Name":        {Type: "string"},
+		"Description": {Type: "string"},
+		"Price":       {Type: "number", Format: "double", Minimum: float(0)},
+		"Stock":       {Type: "integer", Minimum: float(0)},
+		"SKU":         sku,
+		"Barcodes":    barcodes,
+		"Version":     version,
+	}), "Version")
+
+	catalogFormat := queryParam("format", &Schema{Type: "string", Enum: []string{"csv", "jsonl"}, Default: "csv"}, "Формат файла")
+
+	batchList := object(map[string]*Schema{
+		"batches": {Type: "array", Items: reg.Message(&inventory.StockBatch{}), Nullable: true},
+	}, "batches")
+
+	return []route{
+		{"GET", "/openapi.json", &Operation{
+			OperationID: "getOpenAPISpec",
+			Summary:     "OpenAPI спецификация шлюза",
+			Tags:        []string{"docs"},
+			Responses: map[string]Response{
+				"200": jsonResponse("OpenAPI 3 документ", &Schema{Type: "object"}),
+			},
+		}},
+		{"GET", "/docs", &Operation{
+			OperationID: "getAPIDocs",
+			Summary:     "HTML страница с документацией",
+			Tags:        []string{"docs"},
+			Responses: map[string]Response{
+				"200": {Description: "HTML страница", Content: map[string]MediaType{
+					"text/html": {Schema: &Schema{Type: "string"}},
+				}},
+			},
+		}},
+
+		// Products
+		{"GET", "/products", &Operation{
+			OperationID: "listProducts",
+			Summary:     "Список товаров с фильтрацией и пагинацией",
+			Tags:        []string{"products"},
+			Parameters: []Parameter{
+				queryParam("page", &Schema{Type: "integer", Default: 1, Minimum: float(1)}, "Номер страницы"),
+				queryParam("per_page", &Schema{Type: "integer", Default: 6, Minimum: float(1)}, "Размер страницы"),
+				queryParam("name", &Schema{Type: "string"}, "Фильтр по подстроке в названии"),
+				queryParam("q", &Schema{Type: "string"}, "Полнотекстовый поиск по названию и описанию, слова ищутся по префиксу"),
+				queryParam("sort", &Schema{Type: "string", Enum: []string{
+					"relevance", "price_asc", "price_desc", "name", "newest", "popularity",
+				}}, "Сортировка; по умолчанию relevance при заданном q, иначе newest. popularity - по числу проданных единиц без отмененных заказов"),
+				queryParam("cursor", &Schema{Type: "string"}, "Курсор из next_cursor; если задан, page игнорируется и total не считается"),
+				queryParam("min_price", &Schema{Type: "number"}, "Минимальная цена"),
+				queryParam("max_price", &Schema{Type: "number"}, "Максимальная цена"),
+				queryParam("archived", &Schema{Type: "boolean", Default: false}, "Только архивные товары вместо активных"),
+				dietList("allergens", allergenCodes, "Товары, содержащие все перечисленные аллергены"),
+				dietList("free_from", allergenCodes, "Товары без перечисленных аллергенов"),
+				dietList("diet", dietaryTags, "Товары со всеми перечисленными пищевыми метками"),
+				dietList("exclude_diet", dietaryTags, "Товары без перечисленных пищевых меток"),
+			},
+			Responses: map[string]Response{
+				"200": jsonResponse("Страница товаров", object(map[string]*Schema{
+					"products":    {Type: "array", Items: reg.Message(&inventory.Product{}), Nullable: true},
+					"total":       {Type: "integer"},
+					"page":        {Type: "integer"},
+					"per_page":    {Type: "integer"},
+					"next_cursor": {Type: "string", Description: "Пустая строка на последней странице"},
+					"sort":        {Type: "string"},
+				}, "products", "total", "page", "per_page", "next_cursor", "sort")),
+				"400": errorResponse("Неизвестная сортировка, аллерген, пищевая метка или неверный курсор"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"GET", "/products/export", &Operation{
+			OperationID: "exportProducts",
+			Summary:     "Выгрузить каталог",
+			Description: "Файл с колонками id, sku, name, description, price, stock передается по мере чтения каталога.",
+			Tags:        []string{"products"},
+			Parameters: []Parameter{
+				catalogFormat,
+				queryParam("archived", &Schema{Type: "boolean", Default: false}, "Выгрузить архив вместо каталога"),
+			},
+			Responses: map[string]Response{
+				"200": {Description: "Файл каталога", Content: map[string]MediaType{
+					"text/csv":             {Schema: &Schema{Type: "string"}},
+					"application/x-ndjson": {Schema: &Schema{Type: "string"}},
+				}},
+				"400": errorResponse("Неизвестный формат"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"POST", "/products/import", &Operation{
+			OperationID: "importProducts",
+			Summary:     "Загрузить каталог из файла",
+			Description: "CSV с заголовком или JSON Lines до 32 МБ и 10000 строк: поля sku, name, price и необязательные description, stock " +
+				"(id игнорируется). Товар с тем же sku обновляется, иначе создается. Пустой stock не меняет остаток. " +
+				"Ошибки возвращаются по строкам; если из-за ошибок ничего не сохранено, ответ 422 с тем же отчетом.",
+			Tags: []string{"products"},
+			Parameters: []Parameter{
+				queryParam("format", &Schema{Type: "string", Enum: []string{"csv", "jsonl"}},
+					"Формат файла; по умолчанию по расширению файла или Content-Type, иначе csv"),
+				queryParam("dry_run", &Schema{Type: "boolean", Default: false}, "Только проверить файл и посчитать изменения"),
+				queryParam("atomic", &Schema{Type: "boolean", Default: false}, "Все или ничего: при ошибке в любой строке не сохраняется ни одна"),
+			},
+			RequestBody: &RequestBody{
+				Required: true,
+				Content: map[string]MediaType{
+					"multipart/form-data": {Schema: object(map[string]*Schema{
+						"file": {Type: "string", Format: "binary"},
+					}, "file")},
+					"text/csv":             {Schema: &Schema{Type: "string"}},
+					"application/x-ndjson": {Schema: &Schema{Type: "string"}},
+				},
+			},
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Отчет об импорте", reg.Message(&inventory.ImportProductsResponse{})),
+				"400": errorResponse("Неизвестный формат, нет заголовка или файл не разобрать"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"413": errorResponse("Файл больше 32 МБ"),
+				"422": jsonResponse("Ничего не сохранено из-за ошибок в строках", reg.Message(&inventory.ImportProductsResponse{})),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"GET", "/products/barcode/:barcode", &Operation{
+			OperationID: "getProductByBarcode",
+			Summary:     "Товар по штрихкоду",
+			Description: "UPC-A из 12 цифр и тот же код в EAN-13 с ведущим нулем находят один товар.",
+			Tags:        []string{"products"},
+			Parameters:  []Parameter{pathParam("barcode")},
+			Responses: map[string]Response{
+				"200": jsonResponse("Товар", reg.Message(&inventory.Product{})),
+				"400": errorResponse("Штрихкод не EAN-13, EAN-8 или UPC-A либо неверная контрольная цифра"),
+				"404": errorResponse("Товар не найден"),
+			},
+		}},
+		{"GET", "/products/:id", &Operation{
+			OperationID: "getProduct",
+			Summary:     "Товар по ID",
+			Tags:        []string{"products"},
+			Parameters:  []Parameter{idParam},
+			Responses: map[string]Response{
+				"200": jsonResponse("Товар", reg.Message(&inventory.Product{})),
+				"404": errorResponse("Товар не найден"),
+			},
+		}},
+		{"POST", "/products", &Operation{
+			OperationID: "createProduct",
+			Summary:     "Создать товар",
+			Tags:        []string{"products"},
+			RequestBody: jsonBody(productBody),
+			Security:    bearerAuth,
+			Responses: map[string]Response{
+				"201": jsonResponse("Созданный товар", reg.Message(&inventory.Product{})),
+				"400": errorResponse("Некорректное тело запроса, единица или штрихкод"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"409": errorResponse("Артикул или штрихкод занят другим товаром"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"PUT", "/products/:id", &Operation{
+			OperationID: "updateProduct",
+			Summary:     "Обновить товар целиком",
+			Description: "Version должна совпадать с текущей версией товара, иначе товар не меняется и возвращается 409.",
+			Tags:        []string{"products"},
+			Parameters:  []Parameter{idParam},
+			RequestBody: jsonBody(productUpdateBody),
+			Security:    bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Обновленный товар", reg.Message(&inventory.Product{})),
+				"400": errorResponse("Некорректное тело запроса"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Товар не найден"),
+				"409": errorResponse("Товар изменили после того, как клиент его прочитал, или артикул либо штрихкод занят другим товаром"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"PATCH", "/products/:id", &Operation{
+			OperationID: "patchProduct",
+			Summary:     "Обновить отдельные поля товара",
+			Description: "Меняются только переданные поля: смена цены не трогает остаток. " +
+				"Version должна совпадать с текущей версией товара, иначе возвращается 409.",
+			Tags:        []string{"products"},
+			Parameters:  []Parameter{idParam},
+			RequestBody: jsonBody(productPatchBody),
+			Security:    bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Обновленный товар", reg.Message(&inventory.Product{})),
+				"400": errorResponse("Неизвестное поле, нет полей для обновления или нет Version"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Товар не найден"),
+				"409": errorResponse("Товар изменили после того, как клиент его прочитал, или артикул либо штрихкод занят другим товаром"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"DELETE", "/products/:id", &Operation{
+			OperationID: "deleteProduct",
+			Summary:     "Перенести товар в архив",
+			Description: "Архивный товар пропадает из каталога и не продается, но остается в истории заказов " +
+				"и может быть восстановлен. Окончательное удаление - POST /products/{id}/purge.",
+			Tags:       []string{"products"},
+			Parameters: []Parameter{idParam},
+			Security:   bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Результат архивации", object(map[string]*Schema{
+					"message": reg.Message(&inventory.DeleteProductResponse{}),
+				}, "message")),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Товар не найден"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"GET", "/products/:id/variants", &Operation{
+			OperationID: "listProductVariants",
+			Summary:     "Варианты товара",
+			Tags:        []string{"products"},
+			Parameters:  []Parameter{idParam},
+			Responses: map[string]Response{
+				"200": jsonResponse("Варианты по названию", object(map[string]*Schema{
+					"variants": {Type: "array", Items: reg.Message(&inventory.Product{}), Nullable: true},
+				}, "variants")),
+				"400": errorResponse("Некорректный ID"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"POST", "/products/:id/restore", &Operation{
+			OperationID: "restoreProduct",
+			Summary:     "Вернуть товар из архива",
+			Tags:        []string{"products"},
+			Parameters:  []Parameter{idParam},
+			Security:    bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Восстановленный товар", reg.Message(&inventory.Product{})),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Товар не найден"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"POST", "/products/:id/purge", &Operation{
+			OperationID: "purgeProduct",
+			Summary:     "Окончательно удалить товар",
+			Description: "Удаляется только архивный товар, на который не ссылаются заказы, заказы поставщикам, " +
+				"партии (в том числе пустые), журнал движений и наборы, то есть товар, который ни разу не приходовали, " +
+				"не заказывали и не закупали. Остальные товары остаются в архиве. Изображения товара удаляются вместе с ним.",
+			Tags:       []string{"products"},
+			Parameters: []Parameter{idParam},
+			Security:   bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Результат удаления", object(map[string]*Schema{
+					"message": reg.Message(&inventory.PurgeProductResponse{}),
+				}, "message")),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Товар не найден"),
+				"409": errorResponse("Товар не в архиве, у него есть история заказов, закупок или остатков, или он входит в набор"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"POST", "/products/:id/images", &Operation{
+			OperationID: "uploadProductImage",
+			Summary:     "Загрузить изображение товара",
+			Description: "JPEG, PNG или GIF до 8 МБ. Сохраняется оригинал, копия 1024x1024 и миниатюра 256x256.",
+			Tags:        []string{"products"},
+			Parameters:  []Parameter{idParam},
+			RequestBody: &RequestBody{
+				Required: true,
+				Content: map[string]MediaType{"multipart/form-data": {Schema: object(map[string]*Schema{
+					"image": {Type: "string", Format: "binary"},
+				}, "image")}},
+			},
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"201": jsonResponse("Загруженное изображение", reg.Message(&inventory.ProductImage{})),
+				"400": errorResponse("Нет файла, неподдерживаемый формат или слишком большой файл"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Товар не найден"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"DELETE", "/products/:id/images/:image_id", &Operation{
+			OperationID: "deleteProductImage",
+			Summary:     "Удалить изображение товара",
+			Tags:        []string{"products"},
+			Parameters:  []Parameter{idParam, pathParam("image_id")},
+			Security:    bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Результат удаления", object(map[string]*Schema{
+					"message": reg.Message(&inventory.DeleteProductImageResponse{}),
+				}, "message")),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Изображение не найдено"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+
+		// Stock
+		{"GET", "/products/:id/batches", &Operation{
+			OperationID: "listProductBatches",
+			Summary:     "Партии товара в порядке FEFO",
+			Tags:        []string{"stock"},
+			Parameters: []Parameter{
+				idParam,
+				queryParam("include_empty", &Schema{Type: "boolean", Default: false}, "Показывать израсходованные партии"),
+				queryParam("location_id", &Schema{Type: "string"}, "Только партии на точке"),
+			},
+			Responses: map[string]Response{
+				"200": jsonResponse("Партии", batchList),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"POST", "/products/:id/batches", &Operation{
+			OperationID: "receiveProductBatch",
+			Summary:     "Оприходовать партию товара",
+			Tags:        []string{"stock"},
+			Parameters:  []Parameter{idParam},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"lot_number":  {Type: "string"},
+				"received_at": {Type: "string", Format: "date", Description: "По умолчанию - сегодня"},
+				"expires_at":  {Type: "string", Format: "date", Description: "Не указывается для товаров без срока годности"},
+				"quantity":    {Type: "integer", Minimum: float(1)},
+				"location_id": {Type: "string", Description: "Точка приемки; по умолчанию - основной склад"},
+			}, "lot_number", "quantity")),
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"201": jsonResponse("Созданная партия", reg.Message(&inventory.StockBatch{})),
+				"400": errorResponse("Некорректные данные партии или точка неактивна"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Товар или точка не найдены"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"GET", "/batches/expiring", &Operation{
+			OperationID: "listExpiringBatches",
+			Summary:     "Партии, срок годности которых истекает в ближайшие дни",
+			Description: "Включает уже просроченные, но не списанные партии (expired = true).",
+			Tags:        []string{"stock"},
+			Parameters: []Parameter{
+				queryParam("days", &Schema{Type: "integer", Default: 3, Minimum: float(0), Maximum: float(365)}, "Горизонт в днях"),
+			},
+			Responses: map[string]Response{
+				"200": jsonResponse("Партии", batchList),
+				"400": errorResponse("Некорректный горизонт"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"POST", "/batches/:batch_id/write-off", &Operation{
+			OperationID: "writeOffBatch",
+			Summary:     "Списать товар с партии",
+			Description: "Списание попадает в журнал движений с типом write_off. Можно списывать и просроченные партии.",
+			Tags:        []string{"stock"},
+			Parameters:  []Parameter{pathParam("batch_id")},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"quantity": {Type: "integer", Minimum: float(1)},
+				"reason":   {Type: "string", Description: "Причина: порча, просрочка, недостача"},
+			}, "quantity", "reason")),
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Партия после списания", reg.Message(&inventory.StockBatch{})),
+				"400": errorResponse("Некорректные данные"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Партия не найдена"),
+				"409": errorResponse("В партии меньше товара, чем нужно списать"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"GET", "/stock/movements", &Operation{
+			OperationID: "getStockMovements",
+			Summary:     "Журнал движения товаров",
+			Description: "Новые записи первыми. Журнал только дополняется; сумма quantity по товару равна его остатку.",
+			Tags:        []string{"stock"},
+			Parameters: []Parameter{
+				queryParam("product_id", &Schema{Type: "string"}, "Товар"),
+				queryParam("location_id", &Schema{Type: "string"}, "Склад или магазин"),
+				queryParam("batch_id", &Schema{Type: "string"}, "Партия"),
+				queryParam("type", &Schema{Type: "string", Enum: []string{
+					"opening_balance", "receipt", "order", "cancel_restock", "adjustment", "write_off",
+					"transfer_out", "transfer_in",
+				}}, "Тип движения"),
+				queryParam("reference_id", &Schema{Type: "string"}, "Связанный объект, например ID заказа"),
+				queryParam("actor", &Schema{Type: "string"}, "Инициатор, например user:42"),
+				queryParam("from", &Schema{Type: "string", Format: "date-time"}, "Начало периода включительно"),
+				queryParam("to", &Schema{Type: "string", Format: "date-time"}, "Конец периода не включительно"),
+				queryParam("page", &Schema{Type: "integer", Default: 1, Minimum: float(1)}, "Номер страницы"),
+				queryParam("per_page", &Schema{Type: "integer", Default: 50, Minimum: float(1), Maximum: float(500)}, "Размер страницы"),
+			},
+			Responses: map[string]Response{
+				"200": jsonResponse("Страница журнала", object(map[string]*Schema{
+					"movements": {Type: "array", Items: reg.Message(&inventory.StockMovement{}), Nullable: true},
+					"total":     {Type: "integer"},
+					"page":      {Type: "integer"},
+					"per_page":  {Type: "integer"},
+				}, "movements", "total", "page", "per_page")),
+				"400": errorResponse("Некорректный фильтр"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"GET", "/stock/reconciliation", &Operation{
+			OperationID: "reconcileStock",
+			Summary:     "Сверка остатков с журналом движений и партиями",
+			Tags:        []string{"stock"},
+			Parameters: []Parameter{
+				queryParam("product_id", &Schema{Type: "string"}, "Сверить один товар"),
+				queryParam("only_mismatched", &Schema{Type: "boolean", Default: false}, "Вернуть только расхождения"),
+			},
+			Responses: map[string]Response{
+				"200": jsonResponse("Результаты сверки", object(map[string]*Schema{
+					"items": {Type: "array", Items: reg.Message(&inventory.StockReconciliation{}), Nullable: true},
+				}, "items")),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"GET", "/products/:id/stock-levels", &Operation{
+			OperationID: "getStockLevels",
+			Summary:     "Доступный остаток товара по складам и магазинам",
+			Description: "Учитываются только активные точки и непросроченные партии; точки без остатка не включаются.",
+			Tags:        []string{"stock"},
+			Parameters:  []Parameter{idParam},
+			Responses: map[string]Response{
+				"200": jsonResponse("Остатки по точкам", object(map[string]*Schema{
+					"levels": {Type: "array", Items: reg.Message(&inventory.LocationStock{}), Nullable: true},
+				}, "levels")),
+				"404": errorResponse("Товар не найден"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"POST", "/stock/transfers", &Operation{
+			OperationID: "transferStock",
+			Summary:     "Переместить товар между точками",
+			Description: "Партии списываются с точки-отправителя по FEFO и заводятся на точке-получателе с теми же лотами " +
+				"и сроками годности в одной транзакции. В журнале появляются движения transfer_out и transfer_in с reference_id = transfer_id.",
+			Tags: []string{"stock"},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"product_id":       {Type: "string"},
+				"from_location_id": {Type: "string"},
+				"to_location_id":   {Type: "string"},
+				"quantity":         {Type: "integer", Minimum: float(1)},
+				"reason":           {Type: "string"},
+			}, "product_id", "from_location_id", "to_location_id", "quantity")),
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"201": jsonResponse("Выполненное перемещение", reg.Message(&inventory.TransferStockResponse{})),
+				"400": errorResponse("Некорректные данные или точка-получатель неактивна"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Товар или точка не найдены"),
+				"409": errorResponse("На точке-отправителе не хватает непросроченного товара"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"PUT", "/products/:id/reorder-policy", &Operation{
+			OperationID: "setReorderPolicy",
+			Summary:     "Задать точку заказа товара",
+			Description: "Когда доступный остаток опускается до reorder_point, Inventory Service открывает алерт, " +
+				"рассылает уведомление и готовит черновик пополнения не меньше reorder_quantity. reorder_point = 0 отключает отслеживание.",
+			Tags:       []string{"stock"},
+			Parameters: []Parameter{idParam},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"reorder_point":    {Type: "integer", Minimum: float(0)},
+				"reorder_quantity": {Type: "integer", Minimum: float(0)},
+			}, "reorder_point")),
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Точка заказа", reg.Message(&inventory.ReorderPolicy{})),
+				"400": errorResponse("Отрицательные значения"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Товар не найден"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"GET", "/stock/alerts", &Operation{
+			OperationID: "listLowStockAlerts",
+			Summary:     "Алерты о низком остатке, новые первыми",
+			Description: "Алерт закрывается сам, когда доступный остаток снова превышает точку заказа.",
+			Tags:        []string{"stock"},
+			Parameters: []Parameter{
+				queryParam("open_only", &Schema{Type: "boolean", Default: false}, "Только открытые алерты"),
+			},
+			Responses: map[string]Response{
+				"200": jsonResponse("Алерты", object(map[string]*Schema{
+					"alerts": {Type: "array", Items: reg.Message(&inventory.LowStockAlert{}), Nullable: true},
+				}, "alerts")),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"POST", "/stock/alerts/check", &Operation{
+			OperationID: "checkLowStock",
+			Summary:     "Проверить остатки по точкам заказа",
+			Description: "Та же проверка, которую Inventory Service запускает по таймеру. Возвращает только алерты, открытые этой проверкой.",
+			Tags:        []string{"stock"},
+			Security:    bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Новые алерты", object(map[string]*Schema{
+					"alerts": {Type: "array", Items: reg.Message(&inventory.LowStockAlert{}), Nullable: true},
+				}, "alerts")),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"GET", "/stock/reorder-suggestions", &Operation{
+			OperationID: "listReorderSuggestions",
+			Summary:     "Предложения на пополнение, новые первыми",
+			Description: "Предложение создается вместе с алертом. Объем рассчитывается по продажам за последние sales_window_days дней.",
+			Tags:        []string{"stock"},
+			Parameters: []Parameter{
+				queryParam("status", &Schema{Type: "string", Enum: reorderSuggestionStatuses}, "Статус"),
+			},
+			Responses: map[string]Response{
+				"200": jsonResponse("Предложения", object(map[string]*Schema{
+					"suggestions": {Type: "array", Items: reg.Message(&inventory.ReorderSuggestion{}), Nullable: true},
+				}, "suggestions")),
+				"400": errorResponse("Неизвестный статус"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"PATCH", "/stock/reorder-suggestions/:id", &Operation{
+			OperationID: "updateReorderSuggestionStatus",
+			Summary:     "Отметить предложение заказанным или отклонить",
+			Tags:        []string{"stock"},
+			Parameters:  []Parameter{idParam},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"status": {Type: "string", Enum: []string{"ordered", "dismissed"}},
+			}, "status")),
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Предложение", reg.Message(&inventory.ReorderSuggestion{})),
+				"400": errorResponse("Недопустимая смена статуса"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Предложение не найдено"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+
+		// Pricing
+		{"GET", "/products/:id/price", &Operation{
+			OperationID: "getPriceAt",
+			Summary:     "Цена товара в заданный момент",
+			Description: "Отвечает по истории цен, по какой цене продавался товар в момент at, - для разбора спорных заказов. " +
+				"effective_at - время фактической смены цены.",
+			Tags: []string{"pricing"},
+			Parameters: []Parameter{idParam,
+				queryParam("at", &Schema{Type: "string", Format: "date-time"}, "Момент времени в RFC 3339, по умолчанию - текущий"),
+			},
+			Responses: map[string]Response{
+				"200": jsonResponse("Запись истории цены", reg.Message(&inventory.PriceChange{})),
+				"400": errorResponse("Неверный формат at"),
+				"404": errorResponse("Товар не найден или в этот момент еще не продавался"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"GET", "/products/:id/price-history", &Operation{
+			OperationID: "listPriceHistory",
+			Summary:     "История цены товара, новые изменения первыми",
+			Tags:        []string{"pricing"},
+			Parameters: []Parameter{idParam,
+				queryParam("limit", &Schema{Type: "integer", Minimum: float(0), Maximum: float(1000), Default: 100}, "Число записей"),
+			},
+			Responses: map[string]Response{
+				"200": jsonResponse("История цены", object(map[string]*Schema{
+					"changes": {Type: "array", Items: reg.Message(&inventory.PriceChange{}), Nullable: true},
+				}, "changes")),
+				"400": errorResponse("Неверный limit"),
+				"404": errorResponse("Товар не найден"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"GET", "/products/:id/price-schedules", &Operation{
+			OperationID: "listProductPriceSchedules",
+			Summary:     "Запланированные цены товара",
+			Tags:        []string{"pricing"},
+			Parameters: []Parameter{idParam,
+				queryParam("status", &Schema{Type: "string", Enum: priceScheduleStatuses}, "Статус"),
+			},
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Запланированные цены", object(map[string]*Schema{
+					"schedules": {Type: "array", Items: reg.Message(&inventory.PriceSchedule{}), Nullable: true},
+				}, "schedules")),
+				"400": errorResponse("Неизвестный статус"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Товар не найден"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"POST", "/products/:id/price-schedules", &Operation{
+			OperationID: "schedulePrice",
+			Summary:     "Запланировать цену товара",
+			Description: "С ends_at это акция: в starts_at цена меняется, в ends_at возвращается прежняя, если ее не меняли вручную. " +
+				"Без ends_at - отложенное постоянное изменение. Цены применяет планировщик Inventory Service " +
+				"(PRICE_SCHEDULER_INTERVAL, по умолчанию раз в минуту). Акции товара не могут пересекаться.",
+			Tags:       []string{"pricing"},
+			Parameters: []Parameter{idParam},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"price":     {Type: "number", Format: "double", Minimum: float(0)},
+				"starts_at": {Type: "string", Format: "date-time"},
+				"ends_at":   {Type: "string", Format: "date-time"},
+			}, "price", "starts_at")),
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"201": jsonResponse("Запланированная цена", reg.Message(&inventory.PriceSchedule{})),
+				"400": errorResponse("Неверная цена или период"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Товар не найден"),
+				"409": errorResponse("Период пересекается с другой запланированной ценой"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"GET", "/price-schedules", &Operation{
+			OperationID: "listPriceSchedules",
+			Summary:     "Запланированные цены всех товаров",
+			Tags:        []string{"pricing"},
+			Parameters: []Parameter{
+				queryParam("product_id", &Schema{Type: "string", Format: "uuid"}, "Только цены товара"),
+				queryParam("status", &Schema{Type: "string", Enum: priceScheduleStatuses}, "Статус"),
+			},
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Запланированные цены", object(map[string]*Schema{
+					"schedules": {Type: "array", Items: reg.Message(&inventory.PriceSchedule{}), Nullable: true},
+				}, "schedules")),
+				"400": errorResponse("Неизвестный статус"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Товар не найден"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"POST", "/price-schedules/:id/cancel", &Operation{
+			OperationID: "cancelPriceSchedule",
+			Summary:     "Отменить запланированную цену",
+			Description: "Ожидающая цена отменяется без изменений; действующая акция завершается досрочно с возвратом прежней цены.",
+			Tags:        []string{"pricing"},
+			Parameters:  []Parameter{idParam},
+			Security:    bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Отмененная цена", reg.Message(&inventory.PriceSchedule{})),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Запланированная цена не найдена"),
+				"409": errorResponse("Цена уже завершена или отменена"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+
+		// Locations
+		{"GET", "/locations", &Operation{
+			OperationID: "listLocations",
+			Summary:     "Склады и магазины",
+			Tags:        []string{"locations"},
+			Parameters: []Parameter{
+				queryParam("include_inactive", &Schema{Type: "boolean", Default: false}, "Показывать выключенные точки"),
+			},
+			Responses: map[string]Response{
+				"200": jsonResponse("Точки", object(map[string]*Schema{
+					"locations": {Type: "array", Items: reg.Message(&inventory.Location{}), Nullable: true},
+				}, "locations")),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"POST", "/locations", &Operation{
+			OperationID: "createLocation",
+			Summary:     "Добавить склад или магазин",
+			Tags:        []string{"locations"},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"code":    {Type: "string"},
+				"name":    {Type: "string"},
+				"type":    {Type: "string", Enum: []string{"warehouse", "store"}, Default: "warehouse"},
+				"address": reg.Message(&inventory.Address{}),
+			}, "code", "name")),
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"201": jsonResponse("Созданная точка", reg.Message(&inventory.Location{})),
+				"400": errorResponse("Некорректные данные точки"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"500": errorResponse("Ошибка Inventory Service или код уже занят"),
+			},
+		}},
+		{"PUT", "/locations/:id", &Operation{
+			OperationID: "updateLocation",
+			Summary:     "Изменить склад или магазин",
+			Description: "Код точки не меняется. Склад по умолчанию нельзя выключить.",
+			Tags:        []string{"locations"},
+			Parameters:  []Parameter{idParam},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"name":    {Type: "string"},
+				"type":    {Type: "string", Enum: []string{"warehouse", "store"}, Default: "warehouse"},
+				"address": reg.Message(&inventory.Address{}),
+				"active":  {Type: "boolean"},
+			}, "name", "active")),
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Обновленная точка", reg.Message(&inventory.Location{})),
+				"400": errorResponse("Некорректные данные точки"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Точка не найдена"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+
+		// Purchasing
+		{"GET", "/suppliers", &Operation{
+			OperationID: "listSuppliers",
+			Summary:     "Поставщики",
+			Tags:        []string{"purchasing"},
+			Security:    bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Поставщики", object(map[string]*Schema{
+					"suppliers": {Type: "array", Items: reg.Message(&inventory.Supplier{}), Nullable: true},
+				}, "suppliers")),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"POST", "/suppliers", &Operation{
+			OperationID: "createSupplier",
+			Summary:     "Добавить поставщика",
+			Tags:        []string{"purchasing"},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"name":    {Type: "string"},
+				"email":   {Type: "string", Format: "email"},
+				"phone":   {Type: "string"},
+				"address": {Type: "string"},
+			}, "name")),
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"201": jsonResponse("Созданный поставщик", reg.Message(&inventory.Supplier{})),
+				"400": errorResponse("Некорректные данные поставщика"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"GET", "/purchase-orders", &Operation{
+			OperationID: "listPurchaseOrders",
+			Summary:     "Заказы поставщикам, новые первыми",
+			Tags:        []string{"purchasing"},
+			Parameters: []Parameter{
+				queryParam("status", &Schema{Type: "string", Enum: purchaseOrderStatuses}, "Статус"),
+				queryParam("supplier_id", &Schema{Type: "string"}, "Поставщик"),
+			},
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Заказы со строками", object(map[string]*Schema{
+					"purchase_orders": {Type: "array", Items: reg.Message(&inventory.PurchaseOrder{}), Nullable: true},
+				}, "purchase_orders")),
+				"400": errorResponse("Неизвестный статус"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"POST", "/purchase-orders", &Operation{
+			OperationID: "createPurchaseOrder",
+			Summary:     "Создать заказ поставщику",
+			Description: "Заказ создается в статусе draft. Без location_id товар принимается на склад по умолчанию.",
+			Tags:        []string{"purchasing"},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"supplier_id": {Type: "string"},
+				"location_id": {Type: "string"},
+				"expected_at": {Type: "string", Format: "date", Description: "Ожидаемая дата поставки"},
+				"notes":       {Type: "string"},
+				"lines":       {Type: "array", Items: reg.Message(&inventory.PurchaseOrderLineInput{})},
+			}, "supplier_id", "lines")),
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"201": jsonResponse("Созданный заказ", reg.Message(&inventory.PurchaseOrder{})),
+				"400": errorResponse("Некорректные данные заказа"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Поставщик, товар или точка не найдены"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"GET", "/purchase-orders/:id", &Operation{
+			OperationID: "getPurchaseOrder",
+			Summary:     "Заказ поставщику со строками",
+			Tags:        []string{"purchasing"},
+			Parameters:  []Parameter{idParam},
+			Security:    bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Заказ", reg.Message(&inventory.PurchaseOrder{})),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Заказ не найден"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"PATCH", "/purchase-orders/:id", &Operation{
+			OperationID: "updatePurchaseOrderStatus",
+			Summary:     "Отправить или отменить заказ поставщику",
+			Description: "draft -> sent, draft или sent -> cancelled. Статусы partially_received и received выставляет приемка.",
+			Tags:        []string{"purchasing"},
+			Parameters:  []Parameter{idParam},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"status": {Type: "string", Enum: []string{"sent", "cancelled"}},
+			}, "status")),
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Заказ", reg.Message(&inventory.PurchaseOrder{})),
+				"400": errorResponse("Недопустимая смена статуса"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Заказ не найден"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+		{"POST", "/purchase-orders/:id/receipts", &Operation{
+			OperationID: "receivePurchaseOrder",
+			Summary:     "Принять товар по заказу поставщику",
+			Description: "Каждая строка приемки заводится партией на точке заказа и попадает в журнал как receipt " +
+				"с reference_id = ID заказа. Можно принимать частями; после приемки всех строк заказ получает статус received.",
+			Tags:       []string{"purchasing"},
+			Parameters: []Parameter{idParam},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"lines": {Type: "array", Items: reg.Message(&inventory.PurchaseReceiptLine{})},
+			}, "lines")),
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Заказ и заведенные партии", reg.Message(&inventory.ReceivePurchaseOrderResponse{})),
+				"400": errorResponse("Некорректные строки приемки"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Заказ не найден"),
+				"409": errorResponse("Заказ не отправлен, закрыт или принимается больше заказанного"),
+				"500": errorResponse("Ошибка Inventory Service"),
+			},
+		}},
+
+		// Orders
+		{"POST", "/orders", &Operation{
+			OperationID: "createOrder",
+			Summary:     "Создать заказ",
+			Description: "Заказ оформляется на покупателя из токена. " +
+				"Заказ собирается целиком с одной точки: с выбранного магазина (store_id) или с точки, " +
+				"ближайшей к адресу доставки (по координатам, затем по индексу и городу). " +
+				"Количество указывается в единицах товара: дробное (до 0.001) допускается только для kg и litre. " +
+				"Действующие акции применяются автоматически, купоны - по coupon_codes; скидки записываются в позиции заказа. " +
+				"Доставка оплачивается только без store_id: адрес должен входить в активную зону доставки, " +
+				"стоимость и минимальный заказ берутся из зоны (пока зон нет - ORDER_DELIVERY_FEE). " +
+				"НДС считается по ставке категории товара со стоимости позиции после скидок; в режиме цен без НДС " +
+				"(ORDER_TAX_MODE=exclusive) налог добавляется к total_price. " +
+				"slot_reservation_id закрепляет за заказом интервал доставки; окно записывается в заказ.",
+			Tags:     []string{"orders"},
+			Security: bearerAuth,
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"items":               {Type: "array", Items: reg.Message(&order.CreateOrderItem{})},
+				"store_id":            {Type: "string", Description: "Магазин для самовывоза; заказ собирается только с него"},
+				"delivery_address":    reg.Message(&order.Address{}),
+				"coupon_codes":        {Type: "array", Items: &Schema{Type: "string"}, Description: "Коды купонов без учета регистра"},
+				"slot_reservation_id": {Type: "string", Description: "Бронь интервала доставки из POST /delivery-slots/{id}/reserve; не для самовывоза"},
+			}, "items")),
+			Responses: map[string]Response{
+				"201": jsonResponse("ID созданного заказа", object(map[string]*Schema{
+					"order_id": {Type: "string"},
+				}, "order_id")),
+				"400": errorResponse("Некорректное тело запроса, количество не подходит для единицы товара, " +
+					"купон не найден или интервал доставки указан для самовывоза"),
+				"401": errorResponse("Нет или неверный токен"),
+				"404": errorResponse("Бронь интервала доставки не найдена"),
+				"409": errorResponse("Не хватает товара, ни одна точка не может собрать заказ целиком, " +
+					"купон не дает скидки на этот заказ, исчерпан его лимит, бронь интервала истекла, " +
+					"адрес вне зон доставки или сумма меньше минимального заказа зоны"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"GET", "/orders", &Operation{
+			OperationID: "listOrders",
+			Summary:     "Все заказы или заказы пользователя",
+			Tags:        []string{"orders"},
+			Parameters: []Parameter{
+				queryParam("user_id", &Schema{Type: "string"}, "Вернуть только заказы пользователя"),
+			},
+			Responses: map[string]Response{
+				"200": jsonResponse("Заказы без позиций", &Schema{
+					Type: "array", Items: reg.Message(&order.Order{}), Nullable: true,
+				}),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"GET", "/orders/:id", &Operation{
+			OperationID: "getOrder",
+			Summary:     "Заказ по ID вместе с позициями",
+			Tags:        []string{"orders"},
+			Parameters:  []Parameter{idParam},
+			Responses: map[string]Response{
+				"200": jsonResponse("Заказ", reg.Message(&order.Order{})),
+				"404": errorResponse("Заказ не найден"),
+			},
+		}},
+		{"GET", "/orders/:id/events", &Operation{
+			OperationID: "watchOrder",
+			Summary:     "Изменения заказа (Server-Sent Events)",
+			Description: "Поток text/event-stream. Первое событие snapshot - текущий заказ, затем created, status_changed " +
+				"и courier_assigned; в data - OrderEvent в JSON. После финального статуса (delivered, completed, " +
+				"cancelled) поток закрывается, и клиент не должен переподключаться. Каждые 15 секунд приходит комментарий-пинг. " +
+				"Покупатель может следить только за своим заказом, администратор - за любым.",
+			Tags:       []string{"orders"},
+			Parameters: []Parameter{idParam},
+			Security:   bearerAuth,
+			Responses: map[string]Response{
+				"200": orderEventStream,
+				"401": errorResponse("Нет или неверный токен"),
+				"404": errorResponse("Заказ не найден или принадлежит другому покупателю"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"GET", "/orders/events", &Operation{
+			OperationID: "watchOrders",
+			Summary:     "Изменения заказов покупателя (Server-Sent Events)",
+			Description: "Поток text/event-stream с событиями created, status_changed и courier_assigned по заказам " +
+				"покупателя из токена, пока клиент не отключится. EventSource не передает заголовки, поэтому клиент " +
+				"читает поток через fetch с заголовком Authorization.",
+			Tags:     []string{"orders"},
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"200": orderEventStream,
+				"401": errorResponse("Нет или неверный токен"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"GET", "/orders/events/all", &Operation{
+			OperationID: "watchAllOrders",
+			Summary:     "Изменения заказов всех покупателей (Server-Sent Events)",
+			Description: "То же, что GET /orders/events, но по заказам всех покупателей; для панели администратора.",
+			Tags:        []string{"orders"},
+			Security:    bearerAuth,
+			Responses: map[string]Response{
+				"200": orderEventStream,
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"PATCH", "/orders/:id", &Operation{
+			OperationID: "updateOrderStatus",
+			Summary:     "Изменить статус заказа",
+			Description: "Вручную можно завершить или отменить только ожидающий заказ. Отмена снимает заказ " +
+				"с курьера и возвращает товар на склад. Заказ, который курьер уже забрал, нельзя отменить или завершить: " +
+				"статусы out_for_delivery и delivered выставляет доставка (PATCH /deliveries/{id}).",
+			Tags:       []string{"orders"},
+			Parameters: []Parameter{idParam},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"status": {Type: "string", Enum: []string{"completed", "cancelled"}},
+			}, "status")),
+			Responses: map[string]Response{
+				"200": jsonResponse("Результат", object(map[string]*Schema{
+					"success": {Type: "boolean"},
+				}, "success")),
+				"400": errorResponse("Некорректное тело запроса или неизвестный статус"),
+				"404": errorResponse("Заказ не найден"),
+				"409": errorResponse("Заказ нельзя перевести из текущего статуса в запрошенный"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+
+		// Cart
+		{"POST", "/cart", &Operation{
+			OperationID: "createCart",
+			Summary:     "Создать корзину",
+			Description: "Без токена создается гостевая корзина: клиент хранит ее id и после входа покупателя " +
+				"переносит ее через POST /cart/{id}/merge. С токеном возвращается корзина покупателя, у него она одна. " +
+				"Корзина удаляется через CART_TTL (по умолчанию 720h) после последнего изменения.",
+			Tags: []string{"cart"},
+			Responses: map[string]Response{
+				"201": jsonResponse("Корзина", reg.Message(&order.Cart{})),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"GET", "/cart", &Operation{
+			OperationID: "getUserCart",
+			Summary:     "Корзина покупателя",
+			Description: "Покупатель берется из токена.",
+			Tags:        []string{"cart"},
+			Security:    bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Корзина с текущими ценами", reg.Message(&order.Cart{})),
+				"401": errorResponse("Нет или неверный токен"),
+				"404": errorResponse("У покупателя нет корзины"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"GET", "/cart/:id", &Operation{
+			OperationID: "getCart",
+			Summary:     "Корзина по ID",
+			Description: "price - цена, которую видел покупатель, current_price - текущая; subtotal считается по текущим ценам доступных позиций. " +
+				"Гостевая корзина доступна по ID, корзина покупателя - только с его токеном, для остальных ответ 404.",
+			Tags:       []string{"cart"},
+			Parameters: []Parameter{idParam},
+			Responses: map[string]Response{
+				"200": jsonResponse("Корзина с текущими ценами", reg.Message(&order.Cart{})),
+				"404": errorResponse("Корзина не найдена или истекла"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"POST", "/cart/:id/items", &Operation{
+			OperationID: "addCartItem",
+			Summary:     "Добавить товар в корзину",
+			Description: "Если товар уже в корзине, количество складывается. Количество - в единицах товара.",
+			Tags:        []string{"cart"},
+			Parameters:  []Parameter{idParam},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"product_id": {Type: "string", Format: "uuid"},
+				"quantity":   {Type: "number", Format: "double"},
+			}, "product_id", "quantity")),
+			Responses: map[string]Response{
+				"200": jsonResponse("Корзина", reg.Message(&order.Cart{})),
+				"400": errorResponse("Количество не подходит для единицы товара"),
+				"404": errorResponse("Корзина или товар не найдены"),
+				"409": errorResponse("Товар снят с продажи"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"PATCH", "/cart/:id/items/:product_id", &Operation{
+			OperationID: "updateCartItem",
+			Summary:     "Изменить количество товара в корзине",
+			Tags:        []string{"cart"},
+			Parameters:  []Parameter{idParam, pathParam("product_id")},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"quantity": {Type: "number", Format: "double", Minimum: float(0), Description: "0 убирает товар"},
+			}, "quantity")),
+			Responses: map[string]Response{
+				"200": jsonResponse("Корзина", reg.Message(&order.Cart{})),
+				"400": errorResponse("Товара нет в корзине или количество не подходит для единицы товара"),
+				"404": errorResponse("Корзина или товар не найдены"),
+				"409": errorResponse("Товар снят с продажи"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"DELETE", "/cart/:id/items/:product_id", &Operation{
+			OperationID: "removeCartItem",
+			Summary:     "Убрать товар из корзины",
+			Tags:        []string{"cart"},
+			Parameters:  []Parameter{idParam, pathParam("product_id")},
+			Responses: map[string]Response{
+				"200": jsonResponse("Корзина", reg.Message(&order.Cart{})),
+				"404": errorResponse("Корзина не найдена"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"POST", "/cart/:id/refresh", &Operation{
+			OperationID: "refreshCart",
+			Summary:     "Принять текущие цены корзины",
+			Description: "Запоминает текущие цены как увиденные покупателем и возвращает позиции, цена которых изменилась, с прежней ценой в price.",
+			Tags:        []string{"cart"},
+			Parameters:  []Parameter{idParam},
+			Responses: map[string]Response{
+				"200": jsonResponse("Обновленная корзина и изменившиеся позиции", object(map[string]*Schema{
+					"cart":    reg.Message(&order.Cart{}),
+					"changed": {Type: "array", Items: reg.Message(&order.CartItem{}), Nullable: true},
+				}, "cart", "changed")),
+				"404": errorResponse("Корзина не найдена"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"POST", "/cart/:id/merge", &Operation{
+			OperationID: "mergeCart",
+			Summary:     "Перенести гостевую корзину в корзину покупателя",
+			Description: "Вызывается после входа, покупатель берется из токена. Количества одинаковых товаров складываются, " +
+				"гостевая корзина удаляется. Если у покупателя корзины нет, гостевая закрепляется за ним.",
+			Tags:       []string{"cart"},
+			Security:   bearerAuth,
+			Parameters: []Parameter{idParam},
+			Responses: map[string]Response{
+				"200": jsonResponse("Корзина покупателя", reg.Message(&order.Cart{})),
+				"401": errorResponse("Нет или неверный токен"),
+				"404": errorResponse("Гостевая корзина не найдена или принадлежит другому покупателю"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"POST", "/cart/:id/checkout", &Operation{
+			OperationID: "checkoutCart",
+			Summary:     "Оформить корзину в заказ",
+			Description: "Создает заказ так же, как POST /orders, по текущим ценам, и удаляет корзину. " +
+				"Оформить можно только свою корзину: покупатель берется из токена, гостевую корзину нужно сначала перенести ему. " +
+				"Если заказ не создан, корзина не меняется.",
+			Tags:       []string{"cart"},
+			Security:   bearerAuth,
+			Parameters: []Parameter{idParam},
+			RequestBody: &RequestBody{
+				Content: map[string]MediaType{"application/json": {Schema: object(map[string]*Schema{
+					"store_id":            {Type: "string", Description: "Магазин для самовывоза"},
+					"delivery_address":    reg.Message(&order.Address{}),
+					"coupon_codes":        {Type: "array", Items: &Schema{Type: "string"}},
+					"slot_reservation_id": {Type: "string", Description: "Бронь интервала доставки"},
+				})}},
+			},
+			Responses: map[string]Response{
+				"201": jsonResponse("ID созданного заказа", object(map[string]*Schema{
+					"order_id": {Type: "string"},
+				}, "order_id")),
+				"400": errorResponse("Некорректное тело запроса, количество или купон"),
+				"401": errorResponse("Нет или неверный токен"),
+				"404": errorResponse("Корзина или бронь интервала не найдены"),
+				"409": errorResponse("Корзина пуста, гостевая или уже оформляется, не хватает товара, купон неприменим, " +
+					"бронь интервала истекла, адрес вне зон доставки или сумма меньше минимального заказа зоны"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+
+		// Delivery slots
+		{"GET", "/delivery-slots", &Operation{
+			OperationID: "listDeliverySlots",
+			Summary:     "Интервалы доставки со свободными местами",
+			Description: "Место занимают заказы и брони, которые еще не истекли. По умолчанию - интервалы, " +
+				"начинающиеся в ближайшую неделю; полные и начавшиеся интервалы пропускаются.",
+			Tags: []string{"delivery"},
+			Parameters: []Parameter{
+				queryParam("zone", &Schema{Type: "string"}, "Зона доставки; пусто - все зоны"),
+				queryParam("from", &Schema{Type: "string", Format: "date-time"}, "Начало периода, по умолчанию - сейчас"),
+				queryParam("to", &Schema{Type: "string", Format: "date-time"}, "Конец периода, по умолчанию - неделя от from"),
+				queryParam("include_full", &Schema{Type: "boolean"}, "Показать и полные интервалы"),
+			},
+			Responses: map[string]Response{
+				"200": jsonResponse("Интервалы по времени начала", object(map[string]*Schema{
+					"slots": {Type: "array", Items: reg.Message(&order.DeliverySlot{}), Nullable: true},
+				}, "slots")),
+				"400": errorResponse("Некорректный период"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"POST", "/delivery-slots", &Operation{
+			OperationID: "createDeliverySlot",
+			Summary:     "Создать интервал доставки",
+			Tags:        []string{"delivery"},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"zone":      {Type: "string"},
+				"starts_at": {Type: "string", Format: "date-time"},
+				"ends_at":   {Type: "string", Format: "date-time"},
+				"capacity":  {Type: "integer", Format: "int32", Minimum: float(1), Description: "Сколько заказов доставляется в интервале"},
+			}, "zone", "starts_at", "ends_at", "capacity")),
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"201": jsonResponse("Интервал", reg.Message(&order.DeliverySlot{})),
+				"400": errorResponse("Интервал в прошлом, конец раньше начала или неположительная вместимость"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"409": errorResponse("В зоне уже есть интервал с тем же началом"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"POST", "/delivery-slots/:id/reserve", &Operation{
+			OperationID: "reserveDeliverySlot",
+			Summary:     "Забронировать место в интервале",
+			Description: "Место удерживается SLOT_HOLD_TTL (по умолчанию 15m), пока покупатель из токена оформляет заказ " +
+				"с slot_reservation_id. Новая бронь снимает прежнюю неподтвержденную бронь покупателя.",
+			Tags:       []string{"delivery"},
+			Parameters: []Parameter{idParam},
+			Security:   bearerAuth,
+			Responses: map[string]Response{
+				"201": jsonResponse("Бронь", reg.Message(&order.SlotReservation{})),
+				"401": errorResponse("Нет или неверный токен"),
+				"404": errorResponse("Интервал не найден"),
+				"409": errorResponse("Свободных мест нет или интервал уже начался"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"DELETE", "/slot-reservations/:id", &Operation{
+			OperationID: "releaseSlotReservation",
+			Summary:     "Снять бронь интервала",
+			Description: "Снимается только бронь, по которой еще не оформлен заказ; место заказа освобождается его отменой.",
+			Tags:        []string{"delivery"},
+			Parameters:  []Parameter{idParam},
+			Security:    bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Снятая бронь", reg.Message(&order.SlotReservation{})),
+				"401": errorResponse("Нет или неверный токен"),
+				"404": errorResponse("Бронь не найдена или принадлежит другому покупателю"),
+				"409": errorResponse("Бронь уже истекла или подтверждена заказом"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+
+		// Delivery zones
+		{"GET", "/delivery-zones", &Operation{
+			OperationID: "listDeliveryZones",
+			Summary:     "Зоны доставки",
+			Tags:        []string{"delivery"},
+			Parameters: []Parameter{
+				queryParam("active", &Schema{Type: "boolean"}, "Только действующие зоны"),
+			},
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Зоны по приоритету", object(map[string]*Schema{
+					"zones": {Type: "array", Items: reg.Message(&order.DeliveryZone{}), Nullable: true},
+				}, "zones")),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"POST", "/delivery-zones", &Operation{
+			OperationID: "createDeliveryZone",
+			Summary:     "Создать зону доставки",
+			Description: "Адрес с координатами попадает в зону по многоугольнику, адрес без них - по индексу. " +
+				"Нужен многоугольник или список индексов.",
+			Tags: []string{"delivery"},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"code":                    {Type: "string", Description: "Код зоны; интервалы доставки указывают его в zone"},
+				"name":                    {Type: "string"},
+				"polygon":                 {Type: "array", Items: reg.Message(&order.GeoPoint{}), Description: "Вершины многоугольника, не меньше 3"},
+				"postal_codes":            {Type: "array", Items: &Schema{Type: "string"}, Description: "Индексы для адресов без координат"},
+				"fee":                     {Type: "number", Format: "double", Minimum: float(0)},
+				"free_delivery_threshold": {Type: "number", Format: "double", Minimum: float(0), Description: "Сумма товаров, с которой доставка бесплатна; 0 - порога нет"},
+				"min_order_amount":        {Type: "number", Format: "double", Minimum: float(0)},
+				"priority":                {Type: "integer", Format: "int32", Description: "Пересекающиеся зоны проверяются от большего приоритета"},
+				"active":                  {Type: "boolean", Description: "По умолчанию true"},
+			}, "code")),
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"201": jsonResponse("Зона", reg.Message(&order.DeliveryZone{})),
+				"400": errorResponse("Нет кода, границ зоны или отрицательная сумма"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"409": errorResponse("Зона с таким кодом уже есть"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"PUT", "/delivery-zones/:id", &Operation{
+			OperationID: "updateDeliveryZone",
+			Summary:     "Изменить зону доставки",
+			Description: "Границы и условия зоны заменяются целиком; уже оформленные заказы не пересчитываются.",
+			Tags:        []string{"delivery"},
+			Parameters:  []Parameter{idParam},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"code":                    {Type: "string", Description: "Код зоны; интервалы доставки указывают его в zone"},
+				"name":                    {Type: "string"},
+				"polygon":                 {Type: "array", Items: reg.Message(&order.GeoPoint{}), Description: "Вершины многоугольника, не меньше 3"},
+				"postal_codes":            {Type: "array", Items: &Schema{Type: "string"}, Description: "Индексы для адресов без координат"},
+				"fee":                     {Type: "number", Format: "double", Minimum: float(0)},
+				"free_delivery_threshold": {Type: "number", Format: "double", Minimum: float(0), Description: "Сумма товаров, с которой доставка бесплатна; 0 - порога нет"},
+				"min_order_amount":        {Type: "number", Format: "double", Minimum: float(0)},
+				"priority":                {Type: "integer", Format: "int32", Description: "Пересекающиеся зоны проверяются от большего приоритета"},
+				"active":                  {Type: "boolean", Description: "По умолчанию true"},
+			}, "code")),
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Зона", reg.Message(&order.DeliveryZone{})),
+				"400": errorResponse("Нет кода, границ зоны или отрицательная сумма"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Зона не найдена"),
+				"409": errorResponse("Зона с таким кодом уже есть"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"POST", "/delivery-zones/quote", &Operation{
+			OperationID: "quoteDelivery",
+			Summary:     "Рассчитать доставку по адресу",
+			Description: "Подбирает зону для адреса и считает стоимость доставки для суммы товаров до скидок. " +
+				"amount_to_minimum и amount_to_free_delivery показывают, сколько добрать до минимального заказа и бесплатной доставки.",
+			Tags: []string{"delivery"},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"address":  reg.Message(&order.Address{}),
+				"subtotal": {Type: "number", Format: "double"},
+			}, "address")),
+			Responses: map[string]Response{
+				"200": jsonResponse("Зона и стоимость доставки", reg.Message(&order.DeliveryQuote{})),
+				"400": errorResponse("Не указан адрес"),
+				"404": errorResponse("Адрес не входит ни в одну зону доставки"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+
+		// Couriers and deliveries
+		{"GET", "/couriers", &Operation{
+			OperationID: "listCouriers",
+			Summary:     "Курьеры",
+			Tags:        []string{"delivery"},
+			Parameters: []Parameter{
+				queryParam("status", &Schema{Type: "string", Enum: courierStatuses}, "Статус курьера"),
+				queryParam("zone", &Schema{Type: "string"}, "Код зоны доставки"),
+			},
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Курьеры по имени", object(map[string]*Schema{
+					"couriers": {Type: "array", Items: reg.Message(&order.Courier{}), Nullable: true},
+				}, "couriers")),
+				"400": errorResponse("Неизвестный статус"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"POST", "/couriers", &Operation{
+			OperationID: "createCourier",
+			Summary:     "Добавить курьера",
+			Description: "Новый курьер по умолчанию не на смене (offline).",
+			Tags:        []string{"delivery"},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"user_id": {Type: "string", Description: "Учетная запись курьера"},
+				"name":    {Type: "string"},
+				"phone":   {Type: "string"},
+				"zone":    {Type: "string", Description: "Код зоны доставки; пусто - любая зона"},
+				"status":  {Type: "string", Enum: courierStatuses},
+			}, "name", "phone")),
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"201": jsonResponse("Курьер", reg.Message(&order.Courier{})),
+				"400": errorResponse("Нет имени или телефона, неизвестный статус"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"409": errorResponse("Курьер с таким телефоном уже есть"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"PATCH", "/couriers/:id", &Operation{
+			OperationID: "updateCourierStatus",
+			Summary:     "Начать или закончить смену курьера",
+			Description: "Курьер, ушедший со смены, довозит взятый заказ, но новых не получает.",
+			Tags:        []string{"delivery"},
+			Parameters:  []Parameter{idParam},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"status": {Type: "string", Enum: courierStatuses},
+			}, "status")),
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Курьер", reg.Message(&order.Courier{})),
+				"400": errorResponse("Неизвестный статус"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Курьер не найден"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"POST", "/couriers/:id/location", &Operation{
+			OperationID: "recordCourierLocation",
+			Summary:     "Отправить координаты курьера",
+			Description: "Точка добавляется в маршрут курьера. Для подбора ближайшего курьера точка годится " +
+				"COURIER_LOCATION_MAX_AGE (по умолчанию 10m).",
+			Tags:       []string{"delivery"},
+			Parameters: []Parameter{idParam},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"latitude":    {Type: "number", Format: "double"},
+				"longitude":   {Type: "number", Format: "double"},
+				"recorded_at": {Type: "string", Format: "date-time", Description: "Время замера, по умолчанию - время получения"},
+			}, "latitude", "longitude")),
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Курьер с текущим положением", reg.Message(&order.Courier{})),
+				"400": errorResponse("Координаты вне диапазона или время из будущего"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Курьер не найден"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"GET", "/couriers/:id/deliveries", &Operation{
+			OperationID: "listCourierDeliveries",
+			Summary:     "Доставки курьера",
+			Tags:        []string{"delivery"},
+			Parameters: []Parameter{
+				idParam,
+				queryParam("status", &Schema{Type: "string", Enum: deliveryStatuses}, "Статус доставки"),
+			},
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Доставки, новые первыми", object(map[string]*Schema{
+					"deliveries": {Type: "array", Items: reg.Message(&order.Delivery{}), Nullable: true},
+				}, "deliveries")),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"GET", "/deliveries", &Operation{
+			OperationID: "listDeliveries",
+			Summary:     "Доставки",
+			Tags:        []string{"delivery"},
+			Parameters: []Parameter{
+				queryParam("courier_id", &Schema{Type: "string"}, "Курьер"),
+				queryParam("status", &Schema{Type: "string", Enum: deliveryStatuses}, "Статус доставки"),
+			},
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Доставки, новые первыми", object(map[string]*Schema{
+					"deliveries": {Type: "array", Items: reg.Message(&order.Delivery{}), Nullable: true},
+				}, "deliveries")),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"POST", "/orders/:id/assign", &Operation{
+			OperationID: "assignCourier",
+			Summary:     "Назначить курьера на заказ",
+			Description: "Без courier_id заказ получает ближайший к точке сборки свободный курьер зоны заказа; " +
+				"курьеры без свежих координат идут после остальных. Курьер везет один заказ за раз.",
+			Tags:       []string{"delivery"},
+			Parameters: []Parameter{idParam},
+			RequestBody: &RequestBody{
+				Content: map[string]MediaType{"application/json": {Schema: object(map[string]*Schema{
+					"courier_id": {Type: "string"},
+				})}},
+			},
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"201": jsonResponse("Доставка", reg.Message(&order.Delivery{})),
+				"400": errorResponse("Некорректное тело запроса"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Заказ или курьер не найдены"),
+				"409": errorResponse("Заказ не ожидает доставки или уже у курьера, курьер занят или не на смене, свободных курьеров нет"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"PATCH", "/deliveries/:id", &Operation{
+			OperationID: "updateDeliveryStatus",
+			Summary:     "Изменить статус доставки",
+			Description: "assigned -> out_for_delivery -> delivered; из assigned и out_for_delivery возможен failed. " +
+				"Заказ переходит в out_for_delivery и delivered вместе с доставкой, после failed снова ждет курьера (pending). " +
+				"Для delivered нужно подтверждение: note или фото из POST /deliveries/{id}/proof. " +
+				"Курьер определяется по токену и меняет только свои доставки; администратор меняет любую как диспетчер.",
+			Tags:       []string{"delivery"},
+			Security:   bearerAuth,
+			Parameters: []Parameter{idParam},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"status": {Type: "string", Enum: []string{"out_for_delivery", "delivered", "failed"}},
+				"note":   {Type: "string", Description: "Кому вручен заказ или причина неудачи"},
+			}, "status")),
+			Responses: map[string]Response{
+				"200": jsonResponse("Доставка", reg.Message(&order.Delivery{})),
+				"400": errorResponse("Некорректное тело запроса"),
+				"401": errorResponse("Нет или неверный токен"),
+				"404": errorResponse("Доставка не найдена или учетная запись не привязана к курьеру"),
+				"409": errorResponse("Недопустимый переход, нет подтверждения вручения или заказ отменен"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"POST", "/deliveries/:id/proof", &Operation{
+			OperationID: "uploadDeliveryProof",
+			Summary:     "Загрузить фото вручения",
+			Description: "JPEG, PNG или GIF до 8 МБ; загружается до перевода доставки в delivered. " +
+				"Курьер определяется по токену; администратор загружает фото к любой доставке.",
+			Tags:       []string{"delivery"},
+			Security:   bearerAuth,
+			Parameters: []Parameter{idParam},
+			RequestBody: &RequestBody{
+				Required: true,
+				Content: map[string]MediaType{"multipart/form-data": {Schema: object(map[string]*Schema{
+					"photo": {Type: "string", Format: "binary"},
+				}, "photo")}},
+			},
+			Responses: map[string]Response{
+				"200": jsonResponse("Доставка с proof_photo_url", reg.Message(&order.Delivery{})),
+				"400": errorResponse("Нет файла, неподдерживаемый формат или слишком большой файл"),
+				"401": errorResponse("Нет или неверный токен"),
+				"404": errorResponse("Доставка не найдена или учетная запись не привязана к курьеру"),
+				"409": errorResponse("Доставка уже завершена"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"GET", "/orders/:id/tracking", &Operation{
+			OperationID: "trackOrder",
+			Summary:     "Отследить доставку заказа",
+			Description: "Курьер и ход доставки для покупателя. Координаты курьера и оценка прибытия " +
+				"(по прямой, COURIER_SPEED_KMH) есть, только пока заказ в пути. Покупатель берется из токена; " +
+				"чужой заказ для него не существует.",
+			Tags:       []string{"delivery"},
+			Security:   bearerAuth,
+			Parameters: []Parameter{idParam},
+			Responses: map[string]Response{
+				"200": jsonResponse("Состояние доставки", reg.Message(&order.OrderTracking{})),
+				"401": errorResponse("Нет или неверный токен"),
+				"404": errorResponse("Заказ не найден"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+
+		// Promotions
+		{"GET", "/promotions", &Operation{
+			OperationID: "listPromotions",
+			Summary:     "Акции и купоны",
+			Tags:        []string{"promotions"},
+			Parameters: []Parameter{
+				queryParam("active", &Schema{Type: "boolean"}, "Только включенные акции"),
+			},
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Акции, новые первыми", object(map[string]*Schema{
+					"promotions": {Type: "array", Items: reg.Message(&order.Promotion{}), Nullable: true},
+				}, "promotions")),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"POST", "/promotions", &Operation{
+			OperationID: "createPromotion",
+			Summary:     "Создать акцию или купон",
+			Description: "percentage - скидка value процентов, fixed_amount - скидка value на подходящие позиции, " +
+				"bogo - купи buy_quantity, получи get_quantity бесплатно (только штучные товары), " +
+				"free_delivery - бесплатная доставка при сумме товаров после скидок от min_subtotal. " +
+				"product_ids и categories ограничивают позиции; пусто - весь заказ. " +
+				"Суммируемые акции складываются по priority; несуммируемая применяется одна, если она выгоднее. " +
+				"Лимиты считают использования по неотмененным заказам; 0 - без ограничения.",
+			Tags: []string{"promotions"},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"name":           {Type: "string"},
+				"type":           {Type: "string", Enum: []string{"percentage", "fixed_amount", "bogo", "free_delivery"}},
+				"value":          {Type: "number", Format: "double", Minimum: float(0)},
+				"buy_quantity":   {Type: "integer", Format: "int32", Minimum: float(0)},
+				"get_quantity":   {Type: "integer", Format: "int32", Minimum: float(0)},
+				"min_subtotal":   {Type: "number", Format: "double", Minimum: float(0)},
+				"product_ids":    {Type: "array", Items: &Schema{Type: "string", Format: "uuid"}},
+				"categories":     {Type: "array", Items: &Schema{Type: "string"}},
+				"coupon_code":    {Type: "string", Description: "3-50 символов A-Z, 0-9, '-' и '_'; без кода акция автоматическая"},
+				"usage_limit":    {Type: "integer", Format: "int32", Minimum: float(0)},
+				"per_user_limit": {Type: "integer", Format: "int32", Minimum: float(0)},
+				"starts_at":      {Type: "string", Format: "date-time"},
+				"ends_at":        {Type: "string", Format: "date-time"},
+				"stackable":      {Type: "boolean", Default: true},
+				"priority":       {Type: "integer", Format: "int32"},
+			}, "name", "type")),
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"201": jsonResponse("Акция", reg.Message(&order.Promotion{})),
+				"400": errorResponse("Некорректная акция"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"409": errorResponse("Купон с таким кодом уже есть"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+		{"PATCH", "/promotions/:id", &Operation{
+			OperationID: "updatePromotion",
+			Summary:     "Включить или выключить акцию",
+			Tags:        []string{"promotions"},
+			Parameters:  []Parameter{idParam},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"active": {Type: "boolean"},
+			}, "active")),
+			Security: bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Акция", reg.Message(&order.Promotion{})),
+				"400": errorResponse("Некорректное тело запроса"),
+				"401": errorResponse("Нет или неверный токен"),
+				"403": errorResponse("Требуется роль администратора"),
+				"404": errorResponse("Акция не найдена"),
+				"500": errorResponse("Ошибка Order Service"),
+			},
+		}},
+
+		// Users
+		{"POST", "/users/register", &Operation{
+			OperationID: "registerUser",
+			Summary:     "Регистрация пользователя",
+			Tags:        []string{"users"},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"username":  {Type: "string"},
+				"email":     {Type: "string", Format: "email"},
+				"full_name": {Type: "string"},
+				"password":  {Type: "string", Format: "password", Description: "Не короче 6 символов"},
+			}, "username", "email", "full_name", "password")),
+			Responses: map[string]Response{
+				"201": jsonResponse("Созданный пользователь", reg.Message(&user.User{})),
+				"400": errorResponse("Некорректное тело запроса"),
+				"500": errorResponse("Ошибка User Service"),
+			},
+		}},
+		{"POST", "/users/login", &Operation{
+			OperationID: "loginUser",
+			Summary:     "Вход пользователя",
+			Tags:        []string{"users"},
+			RequestBody: jsonBody(object(map[string]*Schema{
+				"username": {Type: "string"},
+				"password": {Type: "string", Format: "password"},
+			}, "username", "password")),
+			Responses: map[string]Response{
+				"200": jsonResponse("Пользователь и токен доступа", object(map[string]*Schema{
+					"id":        {Type: "string"},
+					"username":  {Type: "string"},
+					"email":     {Type: "string"},
+					"full_name": {Type: "string"},
+					"token":     {Type: "string", Description: "JWT для заголовка Authorization: Bearer"},
+				}, "id", "username", "token")),
+				"400": errorResponse("Некорректное тело запроса"),
+				"401": errorResponse("Неверное имя пользователя или пароль"),
+			},
+		}},
+		{"GET", "/users/profile", &Operation{
+			OperationID: "getUserProfile",
+			Summary:     "Профиль текущего пользователя",
+			Tags:        []string{"users"},
+			Security:    bearerAuth,
+			Responses: map[string]Response{
+				"200": jsonResponse("Пользователь", reg.Message(&user.User{})),
+				"401": errorResponse("Нет или неверный заголовок Authorization"),
+				"500": errorResponse("Ошибка User Service"),
+			},
+		}},
+	}
+}
+
+func object(props map[string]*Schema, required ...string) *Schema {
+	return &Schema{Type: "object", Properties: props, Required: required}
+}
+
+// orderEventStream - ответ потоков изменений заказов; каждое событие несет OrderEvent в data
+var orderEventStream = Response{
+	Description: "Поток событий",
+	Content:     map[string]MediaType{"text/event-stream": {Schema: &Schema{Type: "string"}}},
+}
+
+// courierStatuses и deliveryStatuses - статусы курьера и доставки, см. domain.CourierStatus* и domain.DeliveryStatus*
+var (
+	courierStatuses  = []string{"available", "offline"}
+	deliveryStatuses = []string{"assigned", "out_for_delivery", "delivered", "failed", "cancelled"}
+)
+
+// purchaseOrderStatuses - статусы заказа поставщику, см. domain.PurchaseOrder*
+var purchaseOrderStatuses = []string{"draft", "sent", "partially_received", "received", "cancelled"}
+
+// reorderSuggestionStatuses - статусы предложения на пополнение, см. domain.ReorderSuggestion*
+var reorderSuggestionStatuses = []string{"draft", "ordered", "dismissed"}
+
+// priceScheduleStatuses - статусы запланированной цены, см. domain.PriceSchedule*
+var priceScheduleStatuses = []string{"pending", "active", "completed", "cancelled"}
+
+func float(v float64) *float64 {
+	return &v
+}
+
+func pathParam(name string) Parameter {
+	return Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}}
+}
+
+func queryParam(name string, schema *Schema, description string) Parameter {
+	return Parameter{Name: name, In: "query", Schema: schema, Description: description}
+}
+
+func jsonBody(schema *Schema) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content:  map[string]MediaType{"application/json": {Schema: schema}},
+	}
+}
+
+func jsonResponse(description string, schema *Schema) Response {
+	return Response{
+		Description: description,
+		Content:     map[string]MediaType{"application/json": {Schema: schema}},
+	}
+}
+
+func errorResponse(description string) Response {
+	return jsonResponse(description, &Schema{Ref: "#/components/schemas/Error"})
+}
+
+func tooManyRequests() Response {
+	return Response{
+		Description: "Превышен лимит запросов",
+		Headers: map[string]Header{
+			"Retry-After": {Description: "Через сколько секунд можно повторить запрос", Schema: &Schema{Type: "integer"}},
+		},
+		Content: map[string]MediaType{"application/json": {Schema: object(map[string]*Schema{
+			"error":       {Type: "string"},
+			"retry_after": {Type: "integer"},
+		}, "error", "retry_after")}},
+	}
+}
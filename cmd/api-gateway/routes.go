@@ -0,0 +1,210 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"FoodStore-AdvProg2/cmd/api-gateway/handler"
+	"FoodStore-AdvProg2/cmd/api-gateway/middleware"
+	"FoodStore-AdvProg2/cmd/api-gateway/openapi"
+)
+
+// apiHandlers - обработчики, которые используют версии API
+type apiHandlers struct {
+	product      *handler.ProductHandler
+	stock        *handler.StockHandler
+	pricing      *handler.PricingHandler
+	location     *handler.LocationHandler
+	purchasing   *handler.PurchasingHandler
+	order        *handler.OrderHandler
+	promotion    *handler.PromotionHandler
+	cart         *handler.CartHandler
+	deliverySlot *handler.DeliverySlotHandler
+	deliveryZone *handler.DeliveryZoneHandler
+	delivery     *handler.DeliveryHandler
+	user         *handler.UserHandler
+	deprecations *middleware.DeprecationCounter
+}
+
+// legacyAPIDeprecation - политика для маршрутов /api без версии
+var legacyAPIDeprecation = middleware.DeprecationPolicy{
+	Since:     time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+	Sunset:    time.Date(2027, time.May, 1, 0, 0, 0, 0, time.UTC),
+	OldPrefix: "/api",
+	NewPrefix: "/api/v1",
+}
+
+// registerAPI регистрирует все версии REST API шлюза.
+// Каждый маршрут должен быть описан в openapi/spec.go, иначе упадет TestOpenAPISpecCoversRoutes.
+// Новая версия (например, v2) регистрируется отдельной группой со своими обработчиками
+// и может переиспользовать обработчики v1 для маршрутов, которые не менялись.
+func registerAPI(api *gin.RouterGroup, h apiHandlers) {
+	v1 := api.Group("/v1")
+	registerV1Routes(v1, h)
+
+	// Статистика обращений к устаревшим маршрутам
+	v1.GET("/deprecations", func(c *gin.Context) {
+		c.JSON(http.StatusOK, h.deprecations.Snapshot())
+	})
+
+	// /api без версии - алиас v1 для старых клиентов
+	legacy := api.Group("", middleware.Deprecated(legacyAPIDeprecation, h.deprecations))
+	registerV1Routes(legacy, h)
+}
+
+// registerV1Routes регистрирует маршруты первой версии API
+func registerV1Routes(api *gin.RouterGroup, h apiHandlers) {
+	// Документация API
+	api.GET("/openapi.json", openapi.SpecHandler())
+	api.GET("/docs", openapi.DocsHandler())
+
+	// Изменения остатков, цен, акций и закупок попадают в журналы с автором из токена;
+	// управление каталогом, складом, закупками и доставкой доступно только администраторам
+	auth := middleware.AuthMiddleware()
+	admin := middleware.RequireAdmin()
+
+	// Product routes
+	products := api.Group("/products")
+	{
+		products.GET("", h.product.ListProducts)
+		products.GET("/export", h.product.ExportProducts)
+		products.POST("/import", auth, admin, h.product.ImportProducts)
+		products.GET("/barcode/:barcode", h.product.GetProductByBarcode)
+		products.GET("/:id", h.product.GetProduct)
+		products.POST("", auth, admin, h.product.CreateProduct)
+		products.PUT("/:id", auth, admin, h.product.UpdateProduct)
+		products.PATCH("/:id", auth, admin, h.product.PatchProduct)
+		products.DELETE("/:id", auth, admin, h.product.DeleteProduct)
+		products.GET("/:id/variants", h.product.ListVariants)
+		products.POST("/:id/restore", auth, admin, h.product.RestoreProduct)
+		products.POST("/:id/purge", auth, admin, h.product.PurgeProduct)
+		products.POST("/:id/images", auth, admin, h.product.UploadProductImage)
+		products.DELETE("/:id/images/:image_id", auth, admin, h.product.DeleteProductImage)
+		products.GET("/:id/batches", h.stock.ListBatches)
+		products.POST("/:id/batches", auth, admin, h.stock.ReceiveBatch)
+		products.GET("/:id/stock-levels", h.stock.GetStockLevels)
+		products.PUT("/:id/reorder-policy", auth, admin, h.stock.SetReorderPolicy)
+		products.GET("/:id/price", h.pricing.GetPriceAt)
+		products.GET("/:id/price-history", h.pricing.ListPriceHistory)
+		products.GET("/:id/price-schedules", auth, admin, h.pricing.ListProductPriceSchedules)
+		products.POST("/:id/price-schedules", auth, admin, h.pricing.SchedulePrice)
+	}
+
+	// Pricing routes
+	api.GET("/price-schedules", auth, admin, h.pricing.ListPriceSchedules)
+	api.POST("/price-schedules/:id/cancel", auth, admin, h.pricing.CancelPriceSchedule)
+
+	// Stock routes
+	api.GET("/batches/expiring", h.stock.ListExpiringBatches)
+	api.POST("/batches/:batch_id/write-off", auth, admin, h.stock.WriteOffBatch)
+	stock := api.Group("/stock")
+	{
+		stock.GET("/movements", h.stock.GetStockMovements)
+		stock.GET("/reconciliation", h.stock.ReconcileStock)
+		stock.POST("/transfers", auth, admin, h.stock.TransferStock)
+		stock.GET("/alerts", h.stock.ListLowStockAlerts)
+		stock.POST("/alerts/check", auth, admin, h.stock.CheckLowStock)
+		stock.GET("/reorder-suggestions", h.stock.ListReorderSuggestions)
+		stock.PATCH("/reorder-suggestions/:id", auth, admin, h.stock.UpdateReorderSuggestionStatus)
+	}
+
+	// Location routes
+	locations := api.Group("/locations")
+	{
+		locations.GET("", h.location.ListLocations)
+		locations.POST("", auth, admin, h.location.CreateLocation)
+		locations.PUT("/:id", auth, admin, h.location.UpdateLocation)
+	}
+
+	// Purchasing routes
+	api.GET("/suppliers", auth, admin, h.purchasing.ListSuppliers)
+	api.POST("/suppliers", auth, admin, h.purchasing.CreateSupplier)
+	purchaseOrders := api.Group("/purchase-orders", auth, admin)
+	{
+		purchaseOrders.GET("", h.purchasing.ListPurchaseOrders)
+		purchaseOrders.POST("", h.purchasing.CreatePurchaseOrder)
+		purchaseOrders.GET("/:id", h.purchasing.GetPurchaseOrder)
+		purchaseOrders.PATCH("/:id", h.purchasing.UpdatePurchaseOrderStatus)
+		purchaseOrders.POST("/:id/receipts", h.purchasing.ReceivePurchaseOrder)
+	}
+
+	// Order routes
+	orders := api.Group("/orders")
+	{
+		orders.POST("", auth, h.order.CreateOrder)
+		orders.GET("", h.order.GetOrders)
+		orders.GET("/events", auth, h.order.UserOrderEvents)
+		orders.GET("/events/all", auth, admin, h.order.AllOrderEvents)
+		orders.GET("/:id", h.order.GetOrder)
+		orders.GET("/:id/events", auth, h.order.OrderEvents)
+		orders.PATCH("/:id", h.order.UpdateOrderStatus)
+		orders.POST("/:id/assign", auth, admin, h.delivery.AssignCourier)
+		orders.GET("/:id/tracking", auth, h.delivery.TrackOrder)
+	}
+
+	// Courier and delivery routes
+	couriers := api.Group("/couriers", auth, admin)
+	{
+		couriers.GET("", h.delivery.ListCouriers)
+		couriers.POST("", h.delivery.CreateCourier)
+		couriers.PATCH("/:id", h.delivery.UpdateCourierStatus)
+		couriers.POST("/:id/location", h.delivery.RecordLocation)
+		couriers.GET("/:id/deliveries", h.delivery.ListCourierDeliveries)
+	}
+	deliveries := api.Group("/deliveries")
+	{
+		deliveries.GET("", auth, admin, h.delivery.ListDeliveries)
+		deliveries.PATCH("/:id", auth, h.delivery.UpdateDeliveryStatus)
+		deliveries.POST("/:id/proof", auth, h.delivery.UploadProof)
+	}
+
+	// Cart routes: a guest cart is reachable by ID, a customer's cart only with their token
+	cart := api.Group("/cart")
+	{
+		cart.POST("", h.cart.CreateCart)
+		cart.GET("", auth, h.cart.GetUserCart)
+		cart.GET("/:id", h.cart.GetCart)
+		cart.POST("/:id/items", h.cart.AddCartItem)
+		cart.PATCH("/:id/items/:product_id", h.cart.UpdateCartItem)
+		cart.DELETE("/:id/items/:product_id", h.cart.RemoveCartItem)
+		cart.POST("/:id/refresh", h.cart.RefreshCart)
+		cart.POST("/:id/merge", auth, h.cart.MergeCart)
+		cart.POST("/:id/checkout", auth, h.cart.CheckoutCart)
+	}
+
+	// Delivery slot routes
+	deliverySlots := api.Group("/delivery-slots")
+	{
+		deliverySlots.GET("", h.deliverySlot.ListSlots)
+		deliverySlots.POST("", auth, admin, h.deliverySlot.CreateSlot)
+		deliverySlots.POST("/:id/reserve", auth, h.deliverySlot.ReserveSlot)
+	}
+	api.DELETE("/slot-reservations/:id", auth, h.deliverySlot.ReleaseReservation)
+
+	// Delivery zone routes
+	deliveryZones := api.Group("/delivery-zones")
+	{
+		deliveryZones.GET("", auth, admin, h.deliveryZone.ListZones)
+		deliveryZones.POST("", auth, admin, h.deliveryZone.CreateZone)
+		deliveryZones.PUT("/:id", auth, admin, h.deliveryZone.UpdateZone)
+		deliveryZones.POST("/quote", h.deliveryZone.Quote)
+	}
+
+	// Promotion routes
+	promotions := api.Group("/promotions", auth, admin)
+	{
+		promotions.GET("", h.promotion.ListPromotions)
+		promotions.POST("", h.promotion.CreatePromotion)
+		promotions.PATCH("/:id", h.promotion.UpdatePromotion)
+	}
+
+	// User routes
+	users := api.Group("/users")
+	{
+		users.POST("/register", h.user.RegisterUser)
+		users.POST("/login", h.user.AuthenticateUser)
+		users.GET("/profile", auth, h.user.GetUserProfile)
+	}
+}
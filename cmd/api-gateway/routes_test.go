@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"FoodStore-AdvProg2/cmd/api-gateway/handler"
+	"FoodStore-AdvProg2/cmd/api-gateway/middleware"
+	"FoodStore-AdvProg2/cmd/api-gateway/openapi"
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/utils"
+)
+
+func newTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	registerAPI(r.Group("/api"), apiHandlers{
+		product:      handler.NewProductHandler(nil),
+		stock:        handler.NewStockHandler(nil),
+		pricing:      handler.NewPricingHandler(nil),
+		location:     handler.NewLocationHandler(nil),
+		purchasing:   handler.NewPurchasingHandler(nil),
+		order:        handler.NewOrderHandler(nil),
+		promotion:    handler.NewPromotionHandler(nil),
+		cart:         handler.NewCartHandler(nil),
+		deliverySlot: handler.NewDeliverySlotHandler(nil),
+		deliveryZone: handler.NewDeliveryZoneHandler(nil),
+		delivery:     handler.NewDeliveryHandler(nil),
+		user:         handler.NewUserHandler(nil),
+		deprecations: middleware.NewDeprecationCounter(),
+	})
+	return r
+}
+
+func TestOpenAPISpecCoversRoutes(t *testing.T) {
+	r := newTestRouter()
+	doc := openapi.Spec()
+
+	registered := make(map[string]bool)
+	for _, route := range r.Routes() {
+		path := openapi.PathFromGin(route.Path)
+		method := strings.ToLower(route.Method)
+		registered[method+" "+path] = true
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			t.Errorf("route %s %s is missing from the OpenAPI spec", route.Method, route.Path)
+			continue
+		}
+		if _, ok := item[method]; !ok {
+			t.Errorf("route %s %s is missing from the OpenAPI spec", route.Method, route.Path)
+		}
+	}
+
+	for path, item := range doc.Paths {
+		for method := range item {
+			if !registered[method+" "+path] {
+				t.Errorf("OpenAPI spec describes %s %s, but the route is not registered", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
+
+func TestOpenAPISpecRefsResolve(t *testing.T) {
+	doc := openapi.Spec()
+
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("marshal spec: %v", err)
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		t.Fatalf("unmarshal spec: %v", err)
+	}
+
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if ref, ok := v["$ref"].(string); ok {
+				name := strings.TrimPrefix(ref, "#/components/schemas/")
+				if _, ok := doc.Components.Schemas[name]; !ok {
+					t.Errorf("unresolved $ref %s", ref)
+				}
+			}
+			for _, child := range v {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(generic)
+}
+
+func TestOpenAPISpecServed(t *testing.T) {
+	r := newTestRouter()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /api/v1/openapi.json: status %d", w.Code)
+	}
+
+	var doc openapi.Document
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode spec: %v", err)
+	}
+	if doc.OpenAPI == "" || len(doc.Paths) == 0 {
+		t.Fatalf("served spec is empty: %+v", doc)
+	}
+}
+
+func TestLegacyRoutesAreDeprecated(t *testing.T) {
+	r := newTestRouter()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /api/openapi.json: status %d", w.Code)
+	}
+	if w.Header().Get("Deprecation") == "" || w.Header().Get("Sunset") == "" {
+		t.Errorf("legacy route is missing Deprecation/Sunset headers: %v", w.Header())
+	}
+	if link := w.Header().Get("Link"); link != `</api/v1/openapi.json>; rel="successor-version"` {
+		t.Errorf("unexpected Link header %q", link)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	if w.Header().Get("Deprecation") != "" {
+		t.Errorf("v1 route must not be deprecated")
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/deprecations", nil))
+
+	var calls []middleware.DeprecatedCall
+	if err := json.Unmarshal(w.Body.Bytes(), &calls); err != nil {
+		t.Fatalf("decode deprecations: %v", err)
+	}
+	if len(calls) != 1 || calls[0].Route != "GET /api/openapi.json" || calls[0].Count != 1 {
+		t.Errorf("unexpected deprecated call stats: %+v", calls)
+	}
+}
+
+func TestSecuredRoutesRequireToken(t *testing.T) {
+	r := newTestRouter()
+	doc := openapi.Spec()
+
+	for _, route := range r.Routes() {
+		item, ok := doc.Paths[openapi.PathFromGin(route.Path)]
+		if !ok {
+			continue
+		}
+		op, ok := item[strings.ToLower(route.Method)]
+		if !ok || len(op.Security) == 0 {
+			continue
+		}
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(route.Method, concretePath(route.Path), nil))
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s without a token: status %d, want 401", route.Method, route.Path, w.Code)
+		}
+	}
+}
+
+func TestAdminRoutesRejectCustomers(t *testing.T) {
+	utils.SetJWTSecret([]byte("test-secret"))
+	token, err := utils.GenerateToken("42", domain.UserRoleCustomer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := newTestRouter()
+	doc := openapi.Spec()
+	checked := 0
+	for _, route := range r.Routes() {
+		item, ok := doc.Paths[openapi.PathFromGin(route.Path)]
+		if !ok {
+			continue
+		}
+		op, ok := item[strings.ToLower(route.Method)]
+		if !ok {
+			continue
+		}
+		if _, admin := op.Responses["403"]; !admin {
+			continue
+		}
+		checked++
+
+		req := httptest.NewRequest(route.Method, concretePath(route.Path), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s %s with a customer token: status %d, want 403", route.Method, route.Path, w.Code)
+		}
+	}
+	if checked == 0 {
+		t.Error("no admin routes found in the spec")
+	}
+}
+
+// concretePath подставляет значения вместо параметров пути gin
+func concretePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") {
+			segments[i] = "x"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/proto/inventory"
+	"FoodStore-AdvProg2/usecase"
+)
+
+// exportChunkSize - размер части файла выгрузки в одном сообщении потока
+const exportChunkSize = 64 << 10
+
+// ImportProducts принимает файл каталога потоком: первое сообщение с параметрами,
+// дальше части файла. Файл разбирается по мере получения и целиком в памяти не хранится.
+func (s *InventoryServiceServer) ImportProducts(stream inventory.InventoryService_ImportProductsServer) error {
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "import options are required")
+	}
+	if err != nil {
+		return err
+	}
+	opts := first.GetOptions()
+	if opts == nil {
+		return status.Error(codes.InvalidArgument, "first message must carry import options")
+	}
+
+	result, err := s.catalogUC.Import(&importStreamReader{stream: stream}, domain.ProductImportOptions{
+		Format: opts.Format,
+		DryRun: opts.DryRun,
+		Atomic: opts.Atomic,
+	}, actorFromContext(stream.Context()))
+	if err != nil {
+		return catalogError(err)
+	}
+
+	return stream.SendAndClose(toProtoImportResult(result))
+}
+
+// importStreamReader читает части файла из потока импорта как io.Reader
+type importStreamReader struct {
+	stream inventory.InventoryService_ImportProductsServer
+	buf    []byte
+}
+
+func (r *importStreamReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		msg, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		if msg.GetOptions() != nil {
+			return 0, status.Error(codes.InvalidArgument, "import options must be sent only once")
+		}
+		r.buf = msg.GetChunk()
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// ExportProducts отдает каталог потоком частей файла по мере чтения товаров из базы
+func (s *InventoryServiceServer) ExportProducts(req *inventory.ExportProductsRequest, stream inventory.InventoryService_ExportProductsServer) error {
+	if !domain.IsValidCatalogFormat(req.Format) {
+		return status.Errorf(codes.InvalidArgument, "unsupported format %q", req.Format)
+	}
+
+	w := bufio.NewWriterSize(exportStreamWriter{stream: stream}, exportChunkSize)
+	if err := s.catalogUC.Export(w, req.Format, domain.FilterParams{Archived: req.Archived}); err != nil {
+		return catalogError(err)
+	}
+	return w.Flush()
+}
+
+// exportStreamWriter отправляет каждую запись отдельным сообщением потока
+type exportStreamWriter struct {
+	stream inventory.InventoryService_ExportProductsServer
+}
+
+func (w exportStreamWriter) Write(p []byte) (int, error) {
+	if err := w.stream.Send(&inventory.ExportProductsChunk{Data: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func catalogError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, usecase.ErrUnsupportedCatalogFormat), errors.Is(err, usecase.ErrInvalidCatalogFile):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "catalog operation failed: %v", err)
+	}
+}
+
+func toProtoImportResult(result domain.ProductImportResult) *inventory.ImportProductsResponse {
+	resp := &inventory.ImportProductsResponse{
+		Rows:      int32(result.Rows),
+		Created:   int32(result.Created),
+		Updated:   int32(result.Updated),
+		Failed:    int32(result.Failed),
+		DryRun:    result.DryRun,
+		Committed: result.Committed,
+	}
+	for _, e := range result.Errors {
+		resp.Errors = append(resp.Errors, &inventory.ImportRowError{
+			Line:    int32(e.Line),
+			Sku:     e.SKU,
+			Field:   e.Field,
+			Message: e.Message,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/infrastructure/events"
+	"FoodStore-AdvProg2/infrastructure/postgres"
+	"FoodStore-AdvProg2/repository"
+	"FoodStore-AdvProg2/usecase"
+)
+
+// lowStockConsumer - имя потребителя событий остатков для проверки точек заказа
+const lowStockConsumer = "inventory-low-stock"
+
+// runLowStockConsumer будит проверку остатков, когда товар списан, не дожидаясь следующей проверки по расписанию
+func runLowStockConsumer(ctx context.Context, bus repository.EventBus, check chan<- struct{}) {
+	if bus == nil {
+		return
+	}
+
+	consumer := usecase.NewEventConsumer(lowStockConsumer, postgres.NewOutboxPostgresRepo())
+	consumer.ReplayWindow = events.DurationFromEnv("EVENT_REPLAY_WINDOW", usecase.DefaultEventReplayWindow)
+	consumer.On(domain.EventStockChanged, func(ctx context.Context, event domain.Event) error {
+		var change domain.StockChangedEvent
+		if err := event.Decode(&change); err != nil {
+			return err
+		}
+		if change.Quantity < 0 {
+			select {
+			case check <- struct{}{}:
+			default:
+			}
+		}
+		return nil
+	})
+	consumer.Run(ctx, bus)
+}
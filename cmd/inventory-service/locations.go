@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/proto/inventory"
+)
+
+// CreateLocation добавляет склад или магазин
+func (s *InventoryServiceServer) CreateLocation(ctx context.Context, req *inventory.CreateLocationRequest) (*inventory.Location, error) {
+	location, err := s.locationUC.Create(domain.Location{
+		Code:    req.Code,
+		Name:    req.Name,
+		Type:    req.Type,
+		Address: fromProtoAddress(req.Address),
+		Active:  true,
+	})
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	return toProtoLocation(location), nil
+}
+
+// UpdateLocation меняет данные точки; склад по умолчанию нельзя выключить
+func (s *InventoryServiceServer) UpdateLocation(ctx context.Context, req *inventory.UpdateLocationRequest) (*inventory.Location, error) {
+	location, err := s.locationUC.Update(domain.Location{
+		ID:      req.Id,
+		Name:    req.Name,
+		Type:    req.Type,
+		Address: fromProtoAddress(req.Address),
+		Active:  req.Active,
+	})
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	return toProtoLocation(location), nil
+}
+
+func (s *InventoryServiceServer) ListLocations(ctx context.Context, req *inventory.ListLocationsRequest) (*inventory.ListLocationsResponse, error) {
+	locations, err := s.locationUC.List(req.IncludeInactive)
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	return toProtoLocations(locations), nil
+}
+
+// FindFulfillingLocations возвращает точки, которые могут собрать заказ целиком
+func (s *InventoryServiceServer) FindFulfillingLocations(ctx context.Context, req *inventory.FindFulfillingLocationsRequest) (*inventory.ListLocationsResponse, error) {
+	locations, err := s.stockUC.FulfillingLocations(fromProtoItems(req.Items))
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	return toProtoLocations(locations), nil
+}
+
+// GetStockLevels возвращает доступный остаток товара по точкам
+func (s *InventoryServiceServer) GetStockLevels(ctx context.Context, req *inventory.GetStockLevelsRequest) (*inventory.GetStockLevelsResponse, error) {
+	levels, err := s.stockUC.StockLevels(req.ProductId)
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	resp := &inventory.GetStockLevelsResponse{}
+	for _, l := range levels {
+		resp.Levels = append(resp.Levels, &inventory.LocationStock{
+			LocationId: l.LocationID,
+			ProductId:  l.ProductID,
+			Available:  int32(l.Available),
+		})
+	}
+	return resp, nil
+}
+
+// TransferStock перемещает товар между точками
+func (s *InventoryServiceServer) TransferStock(ctx context.Context, req *inventory.TransferStockRequest) (*inventory.TransferStockResponse, error) {
+	transfer, err := s.stockUC.Transfer(domain.StockTransfer{
+		ProductID:      req.ProductId,
+		FromLocationID: req.FromLocationId,
+		ToLocationID:   req.ToLocationId,
+		Quantity:       int(req.Quantity),
+	}, domain.StockChange{
+		Actor:  actorFromContext(ctx),
+		Reason: req.Reason,
+	})
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	return &inventory.TransferStockResponse{
+		TransferId:  transfer.ID,
+		Allocations: toProtoAllocations(transfer.Allocations),
+	}, nil
+}
+
+func fromProtoItems(items []*inventory.OrderItem) []domain.OrderItemRequest {
+	result := make([]domain.OrderItemRequest, 0, len(items))
+	for _, item := range items {
+		result = append(result, domain.OrderItemRequest{
+			ProductID: item.ProductId,
+			Quantity:  int(item.Quantity),
+		})
+	}
+	return result
+}
+
+func fromProtoAddress(a *inventory.Address) domain.Address {
+	if a == nil {
+		return domain.Address{}
+	}
+	return domain.Address{
+		Street:     a.Street,
+		City:       a.City,
+		PostalCode: a.PostalCode,
+		Latitude:   a.Latitude,
+		Longitude:  a.Longitude,
+	}
+}
+
+func toProtoLocation(l domain.Location) *inventory.Location {
+	return &inventory.Location{
+		Id:   l.ID,
+		Code: l.Code,
+		Name: l.Name,
+		Type: l.Type,
+		Address: &inventory.Address{
+			Street:     l.Address.Street,
+			City:       l.Address.City,
+			PostalCode: l.Address.PostalCode,
+			Latitude:   l.Address.Latitude,
+			Longitude:  l.Address.Longitude,
+		},
+		IsDefault: l.IsDefault,
+		Active:    l.Active,
+	}
+}
+
+func toProtoLocations(locations []domain.Location) *inventory.ListLocationsResponse {
+	resp := &inventory.ListLocationsResponse{}
+	for _, l := range locations {
+		resp.Locations = append(resp.Locations, toProtoLocation(l))
+	}
+	return resp
+}
@@ -2,9 +2,11 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net"
 	"os"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
@@ -14,9 +16,12 @@ import (
 	"google.golang.org/grpc/status"
 
 	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/infrastructure/events"
 	"FoodStore-AdvProg2/infrastructure/postgres"
+	"FoodStore-AdvProg2/infrastructure/storage"
 	"FoodStore-AdvProg2/proto/inventory"
 	"FoodStore-AdvProg2/usecase"
+	"FoodStore-AdvProg2/utils"
 )
 
 func main() {
@@ -39,7 +44,30 @@ func main() {
 
 	// Создание репозитория и use case
 	productRepo := postgres.NewProductPostgresRepo()
-	productUC := usecase.NewProductUseCase(productRepo)
+	batchRepo := postgres.NewStockBatchPostgresRepo()
+	locationRepo := postgres.NewLocationPostgresRepo()
+	productUC := usecase.NewProductUseCase(productRepo, batchRepo)
+	stockUC := usecase.NewStockUseCase(batchRepo, postgres.NewStockMovementPostgresRepo(), productRepo, locationRepo)
+	locationUC := usecase.NewLocationUseCase(locationRepo)
+	purchasingUC := usecase.NewPurchasingUseCase(postgres.NewSupplierPostgresRepo(), postgres.NewPurchaseOrderPostgresRepo(), productRepo, locationRepo)
+	replenishmentUC := usecase.NewReplenishmentUseCase(postgres.NewReplenishmentPostgresRepo(), productRepo, newNotifier())
+	catalogUC := usecase.NewCatalogUseCase(productRepo, postgres.NewProductImportPostgresRepo())
+	pricingUC := usecase.NewPricingUseCase(postgres.NewPricePostgresRepo(), productRepo)
+
+	// Хранилище изображений: локальный каталог, который раздает API Gateway
+	mediaDir := os.Getenv("MEDIA_DIR")
+	if mediaDir == "" {
+		mediaDir = "./media"
+	}
+	mediaBaseURL := os.Getenv("MEDIA_BASE_URL")
+	if mediaBaseURL == "" {
+		mediaBaseURL = "/media"
+	}
+	blobStore, err := storage.NewLocalBlobStore(mediaDir, mediaBaseURL)
+	if err != nil {
+		log.Fatalf("Failed to initialize media storage: %v", err)
+	}
+	mediaUC := usecase.NewMediaUseCase(postgres.NewProductImagePostgresRepo(), productRepo, blobStore)
 
 	// Настройка gRPC сервера
 	port := os.Getenv("INVENTORY_SERVICE_PORT")
@@ -52,10 +80,29 @@ func main() {
 		log.Fatalf("Failed to listen: %v", err)
 	}
 
-	server := grpc.NewServer()
-	inventoryServer := NewInventoryServiceServer(productUC)
+	// Изображения передаются целиком в одном сообщении, поэтому лимит выше стандартных 4 МБ
+	server := grpc.NewServer(grpc.MaxRecvMsgSize(usecase.MaxImageSize + 1<<20))
+	inventoryServer := NewInventoryServiceServer(productUC, stockUC, mediaUC, locationUC, purchasingUC, replenishmentUC, catalogUC, pricingUC)
 	inventory.RegisterInventoryServiceServer(server, inventoryServer)
 
+	// Доменные события: relay отправляет outbox в брокер, списания будят проверку остатков
+	eventBus, err := events.OpenFromEnv("inventory-service")
+	if err != nil {
+		log.Fatalf("Failed to open event bus: %v", err)
+	}
+	lowStockCheck := make(chan struct{}, 1)
+	go events.RunOutboxRelay(context.Background(), eventBus)
+	go runLowStockConsumer(context.Background(), eventBus, lowStockCheck)
+
+	// Фоновая проверка остатков по точкам заказа
+	if interval := lowStockInterval(); interval > 0 {
+		go runLowStockMonitor(context.Background(), replenishmentUC, interval, lowStockCheck)
+	}
+	// Применение запланированных цен и возврат цен после акций
+	if interval := priceSchedulerInterval(); interval > 0 {
+		go runPriceScheduler(context.Background(), pricingUC, interval)
+	}
+
 	// Включаем reflection для отладки
 	reflection.Register(server)
 
@@ -68,12 +115,127 @@ func main() {
 // InventoryServiceServer реализует gRPC сервер для Inventory Service
 type InventoryServiceServer struct {
 	inventory.UnimplementedInventoryServiceServer
-	productUC *usecase.ProductUseCase
+	productUC       *usecase.ProductUseCase
+	stockUC         *usecase.StockUseCase
+	mediaUC         *usecase.MediaUseCase
+	locationUC      *usecase.LocationUseCase
+	purchasingUC    *usecase.PurchasingUseCase
+	replenishmentUC *usecase.ReplenishmentUseCase
+	catalogUC       *usecase.CatalogUseCase
+	pricingUC       *usecase.PricingUseCase
 }
 
-func NewInventoryServiceServer(productUC *usecase.ProductUseCase) *InventoryServiceServer {
+func NewInventoryServiceServer(productUC *usecase.ProductUseCase, stockUC *usecase.StockUseCase, mediaUC *usecase.MediaUseCase, locationUC *usecase.LocationUseCase, purchasingUC *usecase.PurchasingUseCase, replenishmentUC *usecase.ReplenishmentUseCase, catalogUC *usecase.CatalogUseCase, pricingUC *usecase.PricingUseCase) *InventoryServiceServer {
 	return &InventoryServiceServer{
-		productUC: productUC,
+		productUC:       productUC,
+		stockUC:         stockUC,
+		mediaUC:         mediaUC,
+		locationUC:      locationUC,
+		purchasingUC:    purchasingUC,
+		replenishmentUC: replenishmentUC,
+		catalogUC:       catalogUC,
+		pricingUC:       pricingUC,
+	}
+}
+
+// toProtoProduct преобразует доменную модель продукта в proto сообщение
+func toProtoProduct(p domain.Product) *inventory.Product {
+	images := make([]*inventory.ProductImage, 0, len(p.Images))
+	for _, img := range p.Images {
+		images = append(images, toProtoImage(img))
+	}
+
+	product := &inventory.Product{
+		Id:              p.ID,
+		Sku:             p.SKU,
+		Unit:            p.Unit,
+		Barcodes:        p.Barcodes,
+		Nutrition:       toProtoNutrition(p.Nutrition),
+		Ingredients:     p.Ingredients,
+		Allergens:       p.Allergens,
+		DietaryTags:     p.DietaryTags,
+		Kind:            p.Kind,
+		ParentId:        p.ParentID,
+		VariantName:     p.VariantName,
+		Category:        p.Category,
+		Components:      toProtoComponents(p.Components),
+		Name:            p.Name,
+		Description:     p.Description,
+		Price:           p.Price,
+		Stock:           int32(p.Stock),
+		Images:          images,
+		ReorderPoint:    int32(p.ReorderPoint),
+		ReorderQuantity: int32(p.ReorderQuantity),
+		Version:         p.Version,
+	}
+	if p.ArchivedAt != nil {
+		product.ArchivedAt = p.ArchivedAt.Format(time.RFC3339)
+	}
+	return product
+}
+
+func toProtoComponents(components []domain.BundleComponent) []*inventory.BundleComponent {
+	var result []*inventory.BundleComponent
+	for _, c := range components {
+		result = append(result, &inventory.BundleComponent{
+			ProductId: c.ProductID,
+			Quantity:  int32(c.Quantity),
+			Name:      c.Name,
+			Unit:      c.Unit,
+		})
+	}
+	return result
+}
+
+func fromProtoComponents(components []*inventory.BundleComponent) []domain.BundleComponent {
+	var result []domain.BundleComponent
+	for _, c := range components {
+		result = append(result, domain.BundleComponent{ProductID: c.ProductId, Quantity: int(c.Quantity)})
+	}
+	return result
+}
+
+func toProtoNutrition(n *domain.NutritionFacts) *inventory.NutritionFacts {
+	if n == nil {
+		return nil
+	}
+	return &inventory.NutritionFacts{
+		EnergyKcal:    n.EnergyKcal,
+		Proteins:      n.Proteins,
+		Fats:          n.Fats,
+		SaturatedFats: n.SaturatedFats,
+		Carbohydrates: n.Carbohydrates,
+		Sugars:        n.Sugars,
+		Fiber:         n.Fiber,
+		Salt:          n.Salt,
+	}
+}
+
+func fromProtoNutrition(n *inventory.NutritionFacts) *domain.NutritionFacts {
+	if n == nil {
+		return nil
+	}
+	return &domain.NutritionFacts{
+		EnergyKcal:    n.EnergyKcal,
+		Proteins:      n.Proteins,
+		Fats:          n.Fats,
+		SaturatedFats: n.SaturatedFats,
+		Carbohydrates: n.Carbohydrates,
+		Sugars:        n.Sugars,
+		Fiber:         n.Fiber,
+		Salt:          n.Salt,
+	}
+}
+
+func toProtoImage(img domain.ProductImage) *inventory.ProductImage {
+	return &inventory.ProductImage{
+		Id:           img.ID,
+		Url:          img.URL,
+		ThumbnailUrl: img.ThumbnailURL,
+		OriginalUrl:  img.OriginalURL,
+		Width:        int32(img.Width),
+		Height:       int32(img.Height),
+		Position:     int32(img.Position),
 	}
 }
 
@@ -84,48 +246,112 @@ func (s *InventoryServiceServer) GetProduct(ctx context.Context, req *inventory.
 		return nil, status.Error(codes.NotFound, "product not found")
 	}
 
+	products := []domain.Product{product}
+	if err := s.mediaUC.AttachImages(products); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load images: %v", err)
+	}
+	product = products[0]
+
+	return &inventory.GetProductResponse{
+		Product: toProtoProduct(product),
+	}, nil
+}
+
+// GetProductByBarcode возвращает товар по отсканированному штрихкоду
+func (s *InventoryServiceServer) GetProductByBarcode(ctx context.Context, req *inventory.GetProductByBarcodeRequest) (*inventory.GetProductResponse, error) {
+	product, err := s.productUC.GetByBarcode(req.Barcode)
+	if errors.Is(err, domain.ErrInvalidBarcode) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "product not found")
+	}
+
+	products := []domain.Product{product}
+	if err := s.mediaUC.AttachImages(products); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load images: %v", err)
+	}
+
 	return &inventory.GetProductResponse{
-		Product: &inventory.Product{
-			Id:    product.ID,
-			Name:  product.Name,
-			Price: product.Price,
-			Stock: int32(product.Stock),
-		},
+		Product: toProtoProduct(products[0]),
 	}, nil
 }
 
 // CreateProduct создает новый продукт
 func (s *InventoryServiceServer) CreateProduct(ctx context.Context, req *inventory.CreateProductRequest) (*inventory.CreateProductResponse, error) {
 	product := domain.Product{
-		ID:    uuid.New().String(),
-		Name:  req.Name,
-		Price: req.Price,
-		Stock: int(req.Stock),
+		ID:          uuid.New().String(),
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+		Stock:       int(req.Stock),
+		SKU:         domain.NormalizeSKU(req.Sku),
+		Unit:        req.Unit,
+		Barcodes:    req.Barcodes,
+		Nutrition:   fromProtoNutrition(req.Nutrition),
+		Ingredients: req.Ingredients,
+		Allergens:   req.Allergens,
+		DietaryTags: req.DietaryTags,
+		Kind:        req.Kind,
+		ParentID:    req.ParentId,
+		VariantName: req.VariantName,
+		Components:  fromProtoComponents(req.Components),
+		Category:    req.Category,
 	}
 
-	if err := s.productUC.Create(product); err != nil {
+	err := s.productUC.Create(product, actorFromContext(ctx))
+	switch {
+	case errors.Is(err, domain.ErrDuplicateSKU), errors.Is(err, domain.ErrDuplicateBarcode):
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, usecase.ErrInvalidProduct), errors.Is(err, domain.ErrInvalidBarcode):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case err != nil:
 		return nil, status.Errorf(codes.Internal, "failed to create product: %v", err)
 	}
 
+	// Единица и штрихкоды нормализуются при сохранении, поэтому карточка перечитывается
+	if saved, err := s.productUC.GetByID(product.ID); err == nil {
+		product = saved
+	}
+
 	return &inventory.CreateProductResponse{
-		Product: &inventory.Product{
-			Id:    product.ID,
-			Name:  product.Name,
-			Price: product.Price,
-			Stock: int32(product.Stock),
-		},
+		Product: toProtoProduct(product),
 	}, nil
 }
 
 // UpdateProduct обновляет существующий продукт
 func (s *InventoryServiceServer) UpdateProduct(ctx context.Context, req *inventory.UpdateProductRequest) (*inventory.Product, error) {
-	product := domain.Product{
-		Name:  req.Name,
-		Price: req.Price,
-		Stock: int(req.Stock),
+	update := domain.ProductUpdate{
+		ID:          req.Id,
+		Version:     req.Version,
+		Fields:      req.UpdateMask.GetPaths(),
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+		Stock:       int(req.Stock),
+		SKU:         req.Sku,
+		Barcodes:    req.Barcodes,
+		Nutrition:   fromProtoNutrition(req.Nutrition),
+		Ingredients: req.Ingredients,
+		Allergens:   req.Allergens,
+		DietaryTags: req.DietaryTags,
+		ParentID:    req.ParentId,
+		VariantName: req.VariantName,
+		Components:  fromProtoComponents(req.Components),
+		Category:    req.Category,
 	}
 
-	if err := s.productUC.Update(req.Id, product); err != nil {
+	err := s.productUC.Update(update, actorFromContext(ctx))
+	switch {
+	case errors.Is(err, domain.ErrProductVersionConflict):
+		return nil, status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, domain.ErrDuplicateSKU), errors.Is(err, domain.ErrDuplicateBarcode):
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, usecase.ErrInvalidProduct), errors.Is(err, domain.ErrInvalidBarcode):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrProductNotFound):
+		return nil, status.Error(codes.NotFound, "product not found")
+	case err != nil:
 		return nil, status.Errorf(codes.Internal, "failed to update product: %v", err)
 	}
 
@@ -134,24 +360,20 @@ func (s *InventoryServiceServer) UpdateProduct(ctx context.Context, req *invento
 		return nil, status.Error(codes.NotFound, "product not found after update")
 	}
 
-	return &inventory.Product{
-		Id:    updatedProduct.ID,
-		Name:  updatedProduct.Name,
-		Price: updatedProduct.Price,
-		Stock: int32(updatedProduct.Stock),
-	}, nil
+	products := []domain.Product{updatedProduct}
+	if err := s.mediaUC.AttachImages(products); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load images: %v", err)
+	}
+	updatedProduct = products[0]
+
+	return toProtoProduct(updatedProduct), nil
 }
 
-// DeleteProduct удаляет продукт по ID
+// DeleteProduct переносит товар в архив. Изображения сохраняются, чтобы товар
+// можно было восстановить; они удаляются только при окончательном удалении.
 func (s *InventoryServiceServer) DeleteProduct(ctx context.Context, req *inventory.DeleteProductRequest) (*inventory.DeleteProductResponse, error) {
-	// Проверяем существование продукта перед удалением
-	_, err := s.productUC.GetByID(req.Id)
-	if err != nil {
-		return &inventory.DeleteProductResponse{Success: false}, status.Error(codes.NotFound, "product not found")
-	}
-
-	if err := s.productUC.Delete(req.Id); err != nil {
-		return &inventory.DeleteProductResponse{Success: false}, status.Errorf(codes.Internal, "failed to delete product: %v", err)
+	if err := s.productUC.Archive(req.Id); err != nil {
+		return &inventory.DeleteProductResponse{Success: false}, productError(err)
 	}
 
 	return &inventory.DeleteProductResponse{
@@ -159,85 +381,414 @@ func (s *InventoryServiceServer) DeleteProduct(ctx context.Context, req *invento
 	}, nil
 }
 
-// ListProducts возвращает список продуктов с фильтрацией и пагинацией
+// RestoreProduct возвращает архивный товар в каталог
+func (s *InventoryServiceServer) RestoreProduct(ctx context.Context, req *inventory.RestoreProductRequest) (*inventory.Product, error) {
+	if err := s.productUC.Restore(req.Id); err != nil {
+		return nil, productError(err)
+	}
+
+	product, err := s.productUC.GetByID(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "product not found")
+	}
+
+	products := []domain.Product{product}
+	if err := s.mediaUC.AttachImages(products); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load images: %v", err)
+	}
+	return toProtoProduct(products[0]), nil
+}
+
+// PurgeProduct окончательно удаляет архивный товар, на который нет ссылок, вместе с изображениями
+func (s *InventoryServiceServer) PurgeProduct(ctx context.Context, req *inventory.PurgeProductRequest) (*inventory.PurgeProductResponse, error) {
+	if err := s.productUC.CheckPurge(req.Id); err != nil {
+		return &inventory.PurgeProductResponse{Success: false}, productError(err)
+	}
+
+	if err := s.mediaUC.DeleteProductMedia(ctx, req.Id); err != nil {
+		return &inventory.PurgeProductResponse{Success: false}, status.Errorf(codes.Internal, "failed to delete product images: %v", err)
+	}
+
+	if err := s.productUC.Purge(req.Id); err != nil {
+		return &inventory.PurgeProductResponse{Success: false}, productError(err)
+	}
+
+	return &inventory.PurgeProductResponse{Success: true}, nil
+}
+
+// productError преобразует ошибки ProductUseCase в gRPC статусы
+func productError(err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrProductNotFound):
+		return status.Error(codes.NotFound, "product not found")
+	case errors.Is(err, usecase.ErrProductNotArchived), errors.Is(err, usecase.ErrProductInUse):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "product operation failed: %v", err)
+	}
+}
+
+// ListProducts возвращает список продуктов с поиском, сортировкой и пагинацией
 func (s *InventoryServiceServer) ListProducts(ctx context.Context, req *inventory.ListProductsRequest) (*inventory.ListProductsResponse, error) {
+	if !domain.IsValidProductSort(req.Sort) {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown sort %q", req.Sort)
+	}
+
 	filter := domain.FilterParams{
-		Name:     req.Filter.Name,
-		MinPrice: req.Filter.MinPrice,
-		MaxPrice: req.Filter.MaxPrice,
+		Name:     req.GetFilter().GetName(),
+		Query:    req.GetFilter().GetQuery(),
+		MinPrice: req.GetFilter().GetMinPrice(),
+		MaxPrice: req.GetFilter().GetMaxPrice(),
+		Archived: req.GetFilter().GetArchived(),
+
+		WithAllergens:      req.GetFilter().GetWithAllergens(),
+		WithoutAllergens:   req.GetFilter().GetWithoutAllergens(),
+		WithDietaryTags:    req.GetFilter().GetWithDietaryTags(),
+		WithoutDietaryTags: req.GetFilter().GetWithoutDietaryTags(),
+		ParentID:           req.GetFilter().GetParentId(),
+	}
+	if filter.ParentID != "" {
+		if _, err := uuid.Parse(filter.ParentID); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid parent_id")
+		}
 	}
 
 	pagination := domain.PaginationParams{
-		Page:    int(req.Pagination.Page),
-		PerPage: int(req.Pagination.PerPage),
+		Page:    int(req.GetPagination().GetPage()),
+		PerPage: int(req.GetPagination().GetPerPage()),
+		Cursor:  req.GetPagination().GetCursor(),
 	}
 
-	products, total, err := s.productUC.List(filter, pagination)
+	page, err := s.productUC.Search(filter, req.Sort, pagination)
+	if errors.Is(err, postgres.ErrInvalidCursor) {
+		return nil, status.Error(codes.InvalidArgument, "invalid cursor")
+	}
+	if errors.Is(err, domain.ErrInvalidDietInfo) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to list products: %v", err)
 	}
 
+	if err := s.mediaUC.AttachImages(page.Products); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load images: %v", err)
+	}
+
 	var protoProducts []*inventory.Product
-	for _, p := range products {
-		protoProducts = append(protoProducts, &inventory.Product{
-			Id:    p.ID,
-			Name:  p.Name,
-			Price: p.Price,
-			Stock: int32(p.Stock),
-		})
+	for _, p := range page.Products {
+		protoProducts = append(protoProducts, toProtoProduct(p))
 	}
 
 	return &inventory.ListProductsResponse{
-		Products: protoProducts,
-		Total:    int32(total),
-		Page:     int32(pagination.Page),
-		PerPage:  int32(pagination.PerPage),
+		Products:   protoProducts,
+		Total:      int32(page.Total),
+		Page:       int32(pagination.Page),
+		PerPage:    int32(pagination.PerPage),
+		NextCursor: page.NextCursor,
+		Sort:       page.Sort,
 	}, nil
 }
 
-// CheckStock проверяет наличие товаров на складе
-func (s *InventoryServiceServer) CheckStock(ctx context.Context, req *inventory.CheckStockRequest) (*inventory.CheckStockResponse, error) {
-	for _, item := range req.Items {
-		product, err := s.productUC.GetByID(item.ProductId)
-		if err != nil {
-			return &inventory.CheckStockResponse{
-				Available:            false,
-				UnavailableProductId: item.ProductId,
-			}, nil
-		}
+// UploadProductImage сохраняет изображение товара и его уменьшенные копии
+func (s *InventoryServiceServer) UploadProductImage(ctx context.Context, req *inventory.UploadProductImageRequest) (*inventory.ProductImage, error) {
+	img, err := s.mediaUC.UploadProductImage(ctx, req.ProductId, req.Data)
+	switch {
+	case errors.Is(err, usecase.ErrImageTooLarge), errors.Is(err, usecase.ErrUnsupportedImage):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrProductNotFound):
+		return nil, status.Error(codes.NotFound, "product not found")
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "failed to upload image: %v", err)
+	}
 
-		if product.Stock < int(item.Quantity) {
-			return &inventory.CheckStockResponse{
-				Available:            false,
-				UnavailableProductId: item.ProductId,
-			}, nil
-		}
+	log.Printf("Uploaded image %s (%s) for product %s", img.ID, req.Filename, req.ProductId)
+	return toProtoImage(img), nil
+}
+
+// DeleteProductImage удаляет изображение товара
+func (s *InventoryServiceServer) DeleteProductImage(ctx context.Context, req *inventory.DeleteProductImageRequest) (*inventory.DeleteProductImageResponse, error) {
+	err := s.mediaUC.DeleteProductImage(ctx, req.ProductId, req.ImageId)
+	if errors.Is(err, usecase.ErrImageNotFound) {
+		return nil, status.Error(codes.NotFound, "image not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete image: %v", err)
+	}
+
+	return &inventory.DeleteProductImageResponse{Success: true}, nil
+}
+
+// CheckStock проверяет наличие товаров на точке или суммарно по всем точкам
+func (s *InventoryServiceServer) CheckStock(ctx context.Context, req *inventory.CheckStockRequest) (*inventory.CheckStockResponse, error) {
+	unavailable, err := s.stockUC.CheckAvailability(req.LocationId, fromProtoItems(req.Items))
+	if err != nil {
+		return nil, stockError(err)
 	}
 
 	return &inventory.CheckStockResponse{
-		Available: true,
+		Available:            unavailable == "",
+		UnavailableProductId: unavailable,
 	}, nil
 }
 
-// UpdateStock обновляет количество товаров на складе
+// UpdateStock списывает товары под заказ с партий точки по FEFO
 func (s *InventoryServiceServer) UpdateStock(ctx context.Context, req *inventory.UpdateStockRequest) (*inventory.UpdateStockResponse, error) {
-	for _, item := range req.Items {
-		product, err := s.productUC.GetByID(item.ProductId)
-		if err != nil {
-			return nil, status.Errorf(codes.NotFound, "product not found: %s", item.ProductId)
-		}
+	allocations, err := s.stockUC.Allocate(req.OrderId, req.LocationId, fromProtoItems(req.Items), domain.StockChange{Actor: actorFromContext(ctx)})
+	if err != nil {
+		return nil, stockError(err)
+	}
 
-		product.Stock -= int(item.Quantity)
-		if product.Stock < 0 {
-			product.Stock = 0
-		}
+	return &inventory.UpdateStockResponse{
+		Success:     true,
+		Allocations: toProtoAllocations(allocations),
+	}, nil
+}
 
-		if err := s.productUC.Update(product.ID, product); err != nil {
-			return nil, status.Errorf(codes.Internal, "failed to update stock: %v", err)
-		}
+// ReleaseStock возвращает на партии товары отмененного заказа
+func (s *InventoryServiceServer) ReleaseStock(ctx context.Context, req *inventory.ReleaseStockRequest) (*inventory.ReleaseStockResponse, error) {
+	allocations, err := s.stockUC.Release(req.OrderId, domain.StockChange{
+		Actor:  actorFromContext(ctx),
+		Reason: req.Reason,
+	})
+	if err != nil {
+		return nil, stockError(err)
 	}
 
-	return &inventory.UpdateStockResponse{
-		Success: true,
+	return &inventory.ReleaseStockResponse{
+		Success:     true,
+		Allocations: toProtoAllocations(allocations),
 	}, nil
 }
+
+// ReceiveBatch оприходует партию товара
+func (s *InventoryServiceServer) ReceiveBatch(ctx context.Context, req *inventory.ReceiveBatchRequest) (*inventory.StockBatch, error) {
+	receivedAt, err := parseDate(req.ReceivedAt)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid received_at: %v", err)
+	}
+	expiresAt, err := parseDate(req.ExpiresAt)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid expires_at: %v", err)
+	}
+
+	batch := domain.StockBatch{
+		ProductID:  req.ProductId,
+		LocationID: req.LocationId,
+		LotNumber:  req.LotNumber,
+		ExpiresAt:  expiresAt,
+		Quantity:   int(req.Quantity),
+	}
+	if receivedAt != nil {
+		batch.ReceivedAt = *receivedAt
+	}
+
+	batch, err = s.stockUC.ReceiveBatch(batch, domain.StockChange{Actor: actorFromContext(ctx)})
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	return toProtoBatch(batch), nil
+}
+
+// ListBatches возвращает партии товара в порядке FEFO
+func (s *InventoryServiceServer) ListBatches(ctx context.Context, req *inventory.ListBatchesRequest) (*inventory.ListBatchesResponse, error) {
+	batches, err := s.stockUC.ListBatches(req.ProductId, req.LocationId, req.IncludeEmpty)
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	return toProtoBatches(batches), nil
+}
+
+// ListExpiringBatches возвращает партии, которые истекают в ближайшие N дней
+func (s *InventoryServiceServer) ListExpiringBatches(ctx context.Context, req *inventory.ListExpiringBatchesRequest) (*inventory.ListBatchesResponse, error) {
+	batches, err := s.stockUC.ExpiringBatches(int(req.Days))
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	return toProtoBatches(batches), nil
+}
+
+// WriteOffBatch списывает товар с партии
+func (s *InventoryServiceServer) WriteOffBatch(ctx context.Context, req *inventory.WriteOffBatchRequest) (*inventory.StockBatch, error) {
+	batch, err := s.stockUC.WriteOff(req.BatchId, int(req.Quantity), domain.StockChange{
+		Actor:  actorFromContext(ctx),
+		Reason: req.Reason,
+	})
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	return toProtoBatch(batch), nil
+}
+
+// GetStockMovements возвращает журнал движения товаров с фильтрами
+func (s *InventoryServiceServer) GetStockMovements(ctx context.Context, req *inventory.GetStockMovementsRequest) (*inventory.GetStockMovementsResponse, error) {
+	filter := domain.StockMovementFilter{
+		ProductID:   req.ProductId,
+		LocationID:  req.LocationId,
+		BatchID:     req.BatchId,
+		Type:        req.Type,
+		ReferenceID: req.ReferenceId,
+		Actor:       req.Actor,
+	}
+
+	var err error
+	if filter.From, err = parseTimestamp(req.From); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid from: %v", err)
+	}
+	if filter.To, err = parseTimestamp(req.To); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid to: %v", err)
+	}
+
+	pagination := domain.PaginationParams{Page: int(req.Page), PerPage: int(req.PerPage)}
+	movements, total, err := s.stockUC.ListMovements(filter, pagination)
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	resp := &inventory.GetStockMovementsResponse{
+		Total:   int32(total),
+		Page:    req.Page,
+		PerPage: req.PerPage,
+	}
+	for _, m := range movements {
+		resp.Movements = append(resp.Movements, &inventory.StockMovement{
+			Id:           m.ID,
+			ProductId:    m.ProductID,
+			LocationId:   m.LocationID,
+			BatchId:      m.BatchID,
+			Type:         m.Type,
+			Quantity:     int32(m.Quantity),
+			BalanceAfter: int32(m.BalanceAfter),
+			ReferenceId:  m.ReferenceID,
+			Actor:        m.Actor,
+			Reason:       m.Reason,
+			CreatedAt:    m.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return resp, nil
+}
+
+// ReconcileStock сверяет остатки товаров с журналом движений и партиями
+func (s *InventoryServiceServer) ReconcileStock(ctx context.Context, req *inventory.ReconcileStockRequest) (*inventory.ReconcileStockResponse, error) {
+	items, err := s.stockUC.Reconcile(req.ProductId, req.OnlyMismatched)
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	resp := &inventory.ReconcileStockResponse{}
+	for _, rec := range items {
+		resp.Items = append(resp.Items, &inventory.StockReconciliation{
+			ProductId:     rec.ProductID,
+			RecordedStock: int32(rec.RecordedStock),
+			LedgerStock:   int32(rec.LedgerStock),
+			BatchStock:    int32(rec.BatchStock),
+			Consistent:    rec.Consistent(),
+		})
+	}
+	return resp, nil
+}
+
+// actorFromContext возвращает инициатора изменения из метаданных вызова
+func actorFromContext(ctx context.Context) string {
+	return utils.ActorFromContext(ctx, "unknown")
+}
+
+// stockError преобразует ошибки работы с остатками, точками и закупками в gRPC статусы
+func stockError(err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidStockRequest), errors.Is(err, usecase.ErrInvalidPurchaseOrder):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrProductNotFound):
+		return status.Error(codes.NotFound, "product not found")
+	case errors.Is(err, usecase.ErrBatchNotFound):
+		return status.Error(codes.NotFound, "batch not found")
+	case errors.Is(err, usecase.ErrLocationNotFound):
+		return status.Error(codes.NotFound, "location not found")
+	case errors.Is(err, usecase.ErrInvalidLocation):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrSupplierNotFound):
+		return status.Error(codes.NotFound, "supplier not found")
+	case errors.Is(err, usecase.ErrPurchaseOrderNotFound):
+		return status.Error(codes.NotFound, "purchase order not found")
+	case errors.Is(err, usecase.ErrReorderSuggestionNotFound):
+		return status.Error(codes.NotFound, "reorder suggestion not found")
+	case errors.Is(err, domain.ErrInsufficientStock), errors.Is(err, domain.ErrPurchaseOrderNotReceivable),
+		errors.Is(err, domain.ErrOverReceipt):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "stock operation failed: %v", err)
+	}
+}
+
+const dateLayout = "2006-01-02"
+
+// parseDate разбирает дату YYYY-MM-DD; пустая строка означает отсутствие даты
+func parseDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// parseTimestamp разбирает время в RFC 3339; пустая строка означает отсутствие границы
+func parseTimestamp(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(dateLayout)
+}
+
+func toProtoBatch(b domain.StockBatch) *inventory.StockBatch {
+	return &inventory.StockBatch{
+		Id:              b.ID,
+		ProductId:       b.ProductID,
+		LocationId:      b.LocationID,
+		LotNumber:       b.LotNumber,
+		ReceivedAt:      b.ReceivedAt.Format(dateLayout),
+		ExpiresAt:       formatDate(b.ExpiresAt),
+		Quantity:        int32(b.Quantity),
+		InitialQuantity: int32(b.InitialQuantity),
+		Expired:         b.IsExpired(time.Now()),
+	}
+}
+
+func toProtoBatches(batches []domain.StockBatch) *inventory.ListBatchesResponse {
+	resp := &inventory.ListBatchesResponse{}
+	for _, b := range batches {
+		resp.Batches = append(resp.Batches, toProtoBatch(b))
+	}
+	return resp
+}
+
+func toProtoAllocations(allocations []domain.BatchAllocation) []*inventory.BatchAllocation {
+	var result []*inventory.BatchAllocation
+	for _, a := range allocations {
+		result = append(result, &inventory.BatchAllocation{
+			BatchId:    a.BatchID,
+			ProductId:  a.ProductID,
+			LocationId: a.LocationID,
+			LotNumber:  a.LotNumber,
+			Quantity:   int32(a.Quantity),
+			ExpiresAt:  formatDate(a.ExpiresAt),
+		})
+	}
+	return result
+}
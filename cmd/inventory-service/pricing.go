@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/proto/inventory"
+	"FoodStore-AdvProg2/usecase"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultPriceSchedulerInterval - период проверки запланированных цен, если PRICE_SCHEDULER_INTERVAL не задан
+const defaultPriceSchedulerInterval = time.Minute
+
+// priceSchedulerInterval читает период проверки запланированных цен из PRICE_SCHEDULER_INTERVAL
+// (например, 30s); 0 отключает планировщик
+func priceSchedulerInterval() time.Duration {
+	value := os.Getenv("PRICE_SCHEDULER_INTERVAL")
+	if value == "" {
+		return defaultPriceSchedulerInterval
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval < 0 {
+		log.Printf("Invalid PRICE_SCHEDULER_INTERVAL %q, using %s", value, defaultPriceSchedulerInterval)
+		return defaultPriceSchedulerInterval
+	}
+	return interval
+}
+
+// runPriceScheduler периодически применяет запланированные цены до отмены ctx
+func runPriceScheduler(ctx context.Context, uc *usecase.PricingUseCase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		applied, err := uc.ApplyDue(ctx)
+		if err != nil {
+			log.Printf("Price scheduler failed: %v", err)
+		} else if applied > 0 {
+			log.Printf("Price scheduler changed %d price(s)", applied)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// priceError преобразует ошибки истории и планирования цен в gRPC статусы
+func priceError(err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrProductNotFound):
+		return status.Error(codes.NotFound, "product not found")
+	case errors.Is(err, domain.ErrPriceNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrPriceScheduleNotFound):
+		return status.Error(codes.NotFound, "price schedule not found")
+	case errors.Is(err, domain.ErrInvalidPriceSchedule):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrPriceScheduleOverlap):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, domain.ErrPriceScheduleClosed):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "price operation failed: %v", err)
+	}
+}
+
+// GetPriceAt отвечает, по какой цене продавался товар в момент at
+func (s *InventoryServiceServer) GetPriceAt(ctx context.Context, req *inventory.GetPriceAtRequest) (*inventory.PriceChange, error) {
+	at, err := parseTimestamp(req.At)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "at must be an RFC 3339 timestamp")
+	}
+	var moment time.Time
+	if at != nil {
+		moment = *at
+	}
+
+	change, err := s.pricingUC.PriceAt(req.ProductId, moment)
+	if err != nil {
+		return nil, priceError(err)
+	}
+	return toProtoPriceChange(change), nil
+}
+
+func (s *InventoryServiceServer) ListPriceHistory(ctx context.Context, req *inventory.ListPriceHistoryRequest) (*inventory.ListPriceHistoryResponse, error) {
+	history, err := s.pricingUC.History(req.ProductId, int(req.Limit))
+	if err != nil {
+		return nil, priceError(err)
+	}
+
+	resp := &inventory.ListPriceHistoryResponse{}
+	for _, change := range history {
+		resp.Changes = append(resp.Changes, toProtoPriceChange(change))
+	}
+	return resp, nil
+}
+
+func (s *InventoryServiceServer) SchedulePrice(ctx context.Context, req *inventory.SchedulePriceRequest) (*inventory.PriceSchedule, error) {
+	startsAt, err := parseTimestamp(req.StartsAt)
+	if err != nil || startsAt == nil {
+		return nil, status.Error(codes.InvalidArgument, "starts_at must be an RFC 3339 timestamp")
+	}
+	endsAt, err := parseTimestamp(req.EndsAt)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "ends_at must be an RFC 3339 timestamp")
+	}
+
+	schedule, err := s.pricingUC.Schedule(domain.PriceSchedule{
+		ProductID: req.ProductId,
+		Price:     req.Price,
+		StartsAt:  *startsAt,
+		EndsAt:    endsAt,
+	}, actorFromContext(ctx))
+	if err != nil {
+		return nil, priceError(err)
+	}
+	return toProtoPriceSchedule(schedule), nil
+}
+
+func (s *InventoryServiceServer) ListPriceSchedules(ctx context.Context, req *inventory.ListPriceSchedulesRequest) (*inventory.ListPriceSchedulesResponse, error) {
+	schedules, err := s.pricingUC.ListSchedules(req.ProductId, req.Status)
+	if err != nil {
+		return nil, priceError(err)
+	}
+
+	resp := &inventory.ListPriceSchedulesResponse{}
+	for _, schedule := range schedules {
+		resp.Schedules = append(resp.Schedules, toProtoPriceSchedule(schedule))
+	}
+	return resp, nil
+}
+
+func (s *InventoryServiceServer) CancelPriceSchedule(ctx context.Context, req *inventory.CancelPriceScheduleRequest) (*inventory.PriceSchedule, error) {
+	schedule, err := s.pricingUC.Cancel(req.Id, actorFromContext(ctx))
+	if err != nil {
+		return nil, priceError(err)
+	}
+	return toProtoPriceSchedule(schedule), nil
+}
+
+func toProtoPriceChange(c domain.PriceChange) *inventory.PriceChange {
+	return &inventory.PriceChange{
+		Id:          c.ID,
+		ProductId:   c.ProductID,
+		Price:       c.Price,
+		EffectiveAt: c.EffectiveAt.Format(time.RFC3339),
+		Source:      c.Source,
+		Actor:       c.Actor,
+		ScheduleId:  c.ScheduleID,
+	}
+}
+
+func toProtoPriceSchedule(s domain.PriceSchedule) *inventory.PriceSchedule {
+	schedule := &inventory.PriceSchedule{
+		Id:            s.ID,
+		ProductId:     s.ProductID,
+		Price:         s.Price,
+		StartsAt:      s.StartsAt.Format(time.RFC3339),
+		Status:        s.Status,
+		PreviousPrice: s.PreviousPrice,
+		CreatedBy:     s.CreatedBy,
+		CreatedAt:     s.CreatedAt.Format(time.RFC3339),
+	}
+	if s.EndsAt != nil {
+		schedule.EndsAt = s.EndsAt.Format(time.RFC3339)
+	}
+	return schedule
+}
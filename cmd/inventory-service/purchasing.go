@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/proto/inventory"
+)
+
+func (s *InventoryServiceServer) CreateSupplier(ctx context.Context, req *inventory.CreateSupplierRequest) (*inventory.Supplier, error) {
+	supplier, err := s.purchasingUC.CreateSupplier(domain.Supplier{
+		Name:    req.Name,
+		Email:   req.Email,
+		Phone:   req.Phone,
+		Address: req.Address,
+	})
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	return toProtoSupplier(supplier), nil
+}
+
+func (s *InventoryServiceServer) ListSuppliers(ctx context.Context, req *inventory.ListSuppliersRequest) (*inventory.ListSuppliersResponse, error) {
+	suppliers, err := s.purchasingUC.ListSuppliers()
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	resp := &inventory.ListSuppliersResponse{}
+	for _, supplier := range suppliers {
+		resp.Suppliers = append(resp.Suppliers, toProtoSupplier(supplier))
+	}
+	return resp, nil
+}
+
+// CreatePurchaseOrder создает черновик заказа поставщику
+func (s *InventoryServiceServer) CreatePurchaseOrder(ctx context.Context, req *inventory.CreatePurchaseOrderRequest) (*inventory.PurchaseOrder, error) {
+	expectedAt, err := parseDate(req.ExpectedAt)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid expected_at: %v", err)
+	}
+
+	order := domain.PurchaseOrder{
+		SupplierID: req.SupplierId,
+		LocationID: req.LocationId,
+		ExpectedAt: expectedAt,
+		Notes:      req.Notes,
+		CreatedBy:  actorFromContext(ctx),
+	}
+	for _, line := range req.Lines {
+		order.Lines = append(order.Lines, domain.PurchaseOrderLine{
+			ProductID:       line.ProductId,
+			QuantityOrdered: int(line.Quantity),
+			UnitCost:        line.UnitCost,
+		})
+	}
+
+	order, err = s.purchasingUC.CreatePurchaseOrder(order)
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	return toProtoPurchaseOrder(order), nil
+}
+
+func (s *InventoryServiceServer) GetPurchaseOrder(ctx context.Context, req *inventory.GetPurchaseOrderRequest) (*inventory.PurchaseOrder, error) {
+	order, err := s.purchasingUC.GetPurchaseOrder(req.Id)
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	return toProtoPurchaseOrder(order), nil
+}
+
+func (s *InventoryServiceServer) ListPurchaseOrders(ctx context.Context, req *inventory.ListPurchaseOrdersRequest) (*inventory.ListPurchaseOrdersResponse, error) {
+	orders, err := s.purchasingUC.ListPurchaseOrders(domain.PurchaseOrderFilter{
+		Status:     req.Status,
+		SupplierID: req.SupplierId,
+	})
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	resp := &inventory.ListPurchaseOrdersResponse{}
+	for _, order := range orders {
+		resp.PurchaseOrders = append(resp.PurchaseOrders, toProtoPurchaseOrder(order))
+	}
+	return resp, nil
+}
+
+// UpdatePurchaseOrderStatus отправляет или отменяет заказ поставщику
+func (s *InventoryServiceServer) UpdatePurchaseOrderStatus(ctx context.Context, req *inventory.UpdatePurchaseOrderStatusRequest) (*inventory.PurchaseOrder, error) {
+	order, err := s.purchasingUC.UpdateStatus(req.Id, req.Status)
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	return toProtoPurchaseOrder(order), nil
+}
+
+// ReceivePurchaseOrder принимает товар по заказу поставщику
+func (s *InventoryServiceServer) ReceivePurchaseOrder(ctx context.Context, req *inventory.ReceivePurchaseOrderRequest) (*inventory.ReceivePurchaseOrderResponse, error) {
+	receipts := make([]domain.PurchaseReceipt, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		expiresAt, err := parseDate(line.ExpiresAt)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid expires_at: %v", err)
+		}
+		receipts = append(receipts, domain.PurchaseReceipt{
+			LineID:    line.LineId,
+			Quantity:  int(line.Quantity),
+			LotNumber: line.LotNumber,
+			ExpiresAt: expiresAt,
+		})
+	}
+
+	order, batches, err := s.purchasingUC.Receive(req.Id, receipts, domain.StockChange{Actor: actorFromContext(ctx)})
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	resp := &inventory.ReceivePurchaseOrderResponse{PurchaseOrder: toProtoPurchaseOrder(order)}
+	for _, b := range batches {
+		resp.Batches = append(resp.Batches, toProtoBatch(b))
+	}
+	return resp, nil
+}
+
+func toProtoSupplier(s domain.Supplier) *inventory.Supplier {
+	return &inventory.Supplier{
+		Id:        s.ID,
+		Name:      s.Name,
+		Email:     s.Email,
+		Phone:     s.Phone,
+		Address:   s.Address,
+		CreatedAt: s.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toProtoPurchaseOrder(po domain.PurchaseOrder) *inventory.PurchaseOrder {
+	order := &inventory.PurchaseOrder{
+		Id:         po.ID,
+		SupplierId: po.SupplierID,
+		LocationId: po.LocationID,
+		Status:     po.Status,
+		ExpectedAt: formatDate(po.ExpectedAt),
+		Notes:      po.Notes,
+		CreatedBy:  po.CreatedBy,
+		CreatedAt:  po.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  po.UpdatedAt.Format(time.RFC3339),
+		Total:      po.Total(),
+	}
+	for _, l := range po.Lines {
+		order.Lines = append(order.Lines, &inventory.PurchaseOrderLine{
+			Id:               l.ID,
+			ProductId:        l.ProductID,
+			QuantityOrdered:  int32(l.QuantityOrdered),
+			QuantityReceived: int32(l.QuantityReceived),
+			UnitCost:         l.UnitCost,
+		})
+	}
+	return order
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/infrastructure/notify"
+	"FoodStore-AdvProg2/proto/inventory"
+	"FoodStore-AdvProg2/repository"
+	"FoodStore-AdvProg2/usecase"
+)
+
+// defaultLowStockInterval - период проверки остатков, если LOW_STOCK_CHECK_INTERVAL не задан
+const defaultLowStockInterval = 5 * time.Minute
+
+// newNotifier собирает каналы уведомлений из окружения. Лог включен всегда,
+// вебхук - при LOW_STOCK_WEBHOOK_URL, почта - при SMTP_ADDR и ALERT_EMAIL_TO.
+func newNotifier() repository.Notifier {
+	notifiers := []repository.Notifier{notify.NewLogNotifier(nil)}
+
+	if url := os.Getenv("LOW_STOCK_WEBHOOK_URL"); url != "" {
+		notifiers = append(notifiers, notify.NewWebhookNotifier(url))
+	}
+
+	smtpAddr := os.Getenv("SMTP_ADDR")
+	var recipients []string
+	for _, to := range strings.Split(os.Getenv("ALERT_EMAIL_TO"), ",") {
+		if to = strings.TrimSpace(to); to != "" {
+			recipients = append(recipients, to)
+		}
+	}
+	if smtpAddr != "" && len(recipients) > 0 {
+		from := os.Getenv("ALERT_EMAIL_FROM")
+		if from == "" {
+			from = "foodstore@localhost"
+		}
+		notifiers = append(notifiers, notify.NewEmailNotifier(smtpAddr, os.Getenv("SMTP_USER"), os.Getenv("SMTP_PASSWORD"), from, recipients))
+	}
+
+	return notify.NewMultiNotifier(notifiers...)
+}
+
+// lowStockInterval читает период проверки остатков из LOW_STOCK_CHECK_INTERVAL (например, 1m);
+// 0 отключает фоновую проверку
+func lowStockInterval() time.Duration {
+	value := os.Getenv("LOW_STOCK_CHECK_INTERVAL")
+	if value == "" {
+		return defaultLowStockInterval
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval < 0 {
+		log.Printf("Invalid LOW_STOCK_CHECK_INTERVAL %q, using %s", value, defaultLowStockInterval)
+		return defaultLowStockInterval
+	}
+	return interval
+}
+
+// runLowStockMonitor периодически проверяет остатки до отмены ctx; сигнал check запускает проверку сразу
+func runLowStockMonitor(ctx context.Context, uc *usecase.ReplenishmentUseCase, interval time.Duration, check <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		alerts, err := uc.CheckLowStock(ctx)
+		if err != nil {
+			log.Printf("Low stock check failed: %v", err)
+		} else if len(alerts) > 0 {
+			log.Printf("Low stock check opened %d alert(s)", len(alerts))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-check:
+		}
+	}
+}
+
+// SetReorderPolicy задает точку заказа товара
+func (s *InventoryServiceServer) SetReorderPolicy(ctx context.Context, req *inventory.SetReorderPolicyRequest) (*inventory.ReorderPolicy, error) {
+	policy, err := s.replenishmentUC.SetPolicy(domain.ReorderPolicy{
+		ProductID:       req.ProductId,
+		ReorderPoint:    int(req.ReorderPoint),
+		ReorderQuantity: int(req.ReorderQuantity),
+	})
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	return &inventory.ReorderPolicy{
+		ProductId:       policy.ProductID,
+		ReorderPoint:    int32(policy.ReorderPoint),
+		ReorderQuantity: int32(policy.ReorderQuantity),
+	}, nil
+}
+
+func (s *InventoryServiceServer) ListLowStockAlerts(ctx context.Context, req *inventory.ListLowStockAlertsRequest) (*inventory.ListLowStockAlertsResponse, error) {
+	alerts, err := s.replenishmentUC.ListAlerts(req.OpenOnly)
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	return &inventory.ListLowStockAlertsResponse{Alerts: toProtoAlerts(alerts)}, nil
+}
+
+// CheckLowStock запускает проверку остатков вне расписания
+func (s *InventoryServiceServer) CheckLowStock(ctx context.Context, req *inventory.CheckLowStockRequest) (*inventory.CheckLowStockResponse, error) {
+	alerts, err := s.replenishmentUC.CheckLowStock(ctx)
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	return &inventory.CheckLowStockResponse{Alerts: toProtoAlerts(alerts)}, nil
+}
+
+func (s *InventoryServiceServer) ListReorderSuggestions(ctx context.Context, req *inventory.ListReorderSuggestionsRequest) (*inventory.ListReorderSuggestionsResponse, error) {
+	suggestions, err := s.replenishmentUC.ListSuggestions(req.Status)
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	resp := &inventory.ListReorderSuggestionsResponse{}
+	for _, suggestion := range suggestions {
+		resp.Suggestions = append(resp.Suggestions, toProtoSuggestion(suggestion))
+	}
+	return resp, nil
+}
+
+func (s *InventoryServiceServer) UpdateReorderSuggestionStatus(ctx context.Context, req *inventory.UpdateReorderSuggestionStatusRequest) (*inventory.ReorderSuggestion, error) {
+	suggestion, err := s.replenishmentUC.UpdateSuggestionStatus(req.Id, req.Status)
+	if err != nil {
+		return nil, stockError(err)
+	}
+
+	return toProtoSuggestion(suggestion), nil
+}
+
+func toProtoAlerts(alerts []domain.LowStockAlert) []*inventory.LowStockAlert {
+	result := make([]*inventory.LowStockAlert, 0, len(alerts))
+	for _, a := range alerts {
+		alert := &inventory.LowStockAlert{
+			Id:           a.ID,
+			ProductId:    a.ProductID,
+			ProductName:  a.ProductName,
+			Available:    int32(a.Available),
+			ReorderPoint: int32(a.ReorderPoint),
+			TriggeredAt:  a.TriggeredAt.Format(time.RFC3339),
+		}
+		if a.ResolvedAt != nil {
+			alert.ResolvedAt = a.ResolvedAt.Format(time.RFC3339)
+		}
+		result = append(result, alert)
+	}
+	return result
+}
+
+func toProtoSuggestion(s domain.ReorderSuggestion) *inventory.ReorderSuggestion {
+	return &inventory.ReorderSuggestion{
+		Id:                s.ID,
+		AlertId:           s.AlertID,
+		ProductId:         s.ProductID,
+		ProductName:       s.ProductName,
+		Available:         int32(s.Available),
+		ReorderPoint:      int32(s.ReorderPoint),
+		ReorderQuantity:   int32(s.ReorderQuantity),
+		UnitsSold:         int32(s.UnitsSold),
+		SalesWindowDays:   int32(s.SalesWindowDays),
+		DailySales:        s.DailySales,
+		SuggestedQuantity: int32(s.SuggestedQuantity),
+		Status:            s.Status,
+		CreatedAt:         s.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:         s.UpdatedAt.Format(time.RFC3339),
+	}
+}
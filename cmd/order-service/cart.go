@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/proto/order"
+	"FoodStore-AdvProg2/usecase"
+)
+
+// defaultCartCleanupInterval - период удаления истекших корзин, если CART_CLEANUP_INTERVAL не задан
+const defaultCartCleanupInterval = time.Hour
+
+// cartTTL читает срок хранения корзины без изменений из CART_TTL (например, 720h)
+func cartTTL() time.Duration {
+	return durationFromEnv("CART_TTL", usecase.DefaultCartTTL)
+}
+
+// cartCleanupInterval читает период удаления истекших корзин из CART_CLEANUP_INTERVAL; 0 отключает очистку
+func cartCleanupInterval() time.Duration {
+	return durationFromEnv("CART_CLEANUP_INTERVAL", defaultCartCleanupInterval)
+}
+
+func durationFromEnv(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		log.Printf("Invalid %s %q, using %s", name, value, fallback)
+		return fallback
+	}
+	return d
+}
+
+// runCartCleanup периодически удаляет истекшие корзины до отмены ctx
+func runCartCleanup(ctx context.Context, uc *usecase.CartUseCase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := uc.DeleteExpired()
+		if err != nil {
+			log.Printf("Cart cleanup failed: %v", err)
+		} else if deleted > 0 {
+			log.Printf("Cart cleanup deleted %d expired cart(s)", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// cartError преобразует ошибки корзины в gRPC статусы
+func cartError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrCartNotFound):
+		return status.Error(codes.NotFound, "cart not found")
+	case errors.Is(err, usecase.ErrProductNotFound):
+		return status.Error(codes.NotFound, "product not found")
+	case errors.Is(err, domain.ErrInvalidCartItem):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, usecase.ErrProductArchived), errors.Is(err, domain.ErrCartEmpty),
+		errors.Is(err, domain.ErrCartOwner), errors.Is(err, domain.ErrCartCheckoutInProgress):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "cart operation failed: %v", err)
+	}
+}
+
+func (s *OrderServiceServer) CreateCart(ctx context.Context, req *order.CreateCartRequest) (*order.Cart, error) {
+	cart, err := s.cartUC.Create(req.UserId)
+	if err != nil {
+		return nil, cartError(err)
+	}
+	return toProtoCart(cart), nil
+}
+
+func (s *OrderServiceServer) GetCart(ctx context.Context, req *order.GetCartRequest) (*order.Cart, error) {
+	cart, err := s.cartUC.Get(req.Id, req.UserId)
+	if err != nil {
+		return nil, cartError(err)
+	}
+	return toProtoCart(cart), nil
+}
+
+func (s *OrderServiceServer) GetUserCart(ctx context.Context, req *order.GetUserCartRequest) (*order.Cart, error) {
+	cart, err := s.cartUC.GetByUser(req.UserId)
+	if err != nil {
+		return nil, cartError(err)
+	}
+	return toProtoCart(cart), nil
+}
+
+func (s *OrderServiceServer) AddCartItem(ctx context.Context, req *order.AddCartItemRequest) (*order.Cart, error) {
+	cart, err := s.cartUC.AddItem(req.CartId, req.UserId, req.ProductId, req.Quantity)
+	if err != nil {
+		return nil, cartError(err)
+	}
+	return toProtoCart(cart), nil
+}
+
+func (s *OrderServiceServer) UpdateCartItem(ctx context.Context, req *order.UpdateCartItemRequest) (*order.Cart, error) {
+	cart, err := s.cartUC.UpdateItem(req.CartId, req.UserId, req.ProductId, req.Quantity)
+	if err != nil {
+		return nil, cartError(err)
+	}
+	return toProtoCart(cart), nil
+}
+
+func (s *OrderServiceServer) RemoveCartItem(ctx context.Context, req *order.RemoveCartItemRequest) (*order.Cart, error) {
+	cart, err := s.cartUC.RemoveItem(req.CartId, req.UserId, req.ProductId)
+	if err != nil {
+		return nil, cartError(err)
+	}
+	return toProtoCart(cart), nil
+}
+
+func (s *OrderServiceServer) RefreshCart(ctx context.Context, req *order.RefreshCartRequest) (*order.RefreshCartResponse, error) {
+	before, after, err := s.cartUC.Refresh(req.CartId, req.UserId)
+	if err != nil {
+		return nil, cartError(err)
+	}
+
+	resp := &order.RefreshCartResponse{Cart: toProtoCart(after)}
+	for _, item := range before.Items {
+		if item.Name != "" && item.PriceChanged() {
+			resp.Changed = append(resp.Changed, toProtoCartItem(item))
+		}
+	}
+	return resp, nil
+}
+
+func (s *OrderServiceServer) MergeCarts(ctx context.Context, req *order.MergeCartsRequest) (*order.Cart, error) {
+	cart, err := s.cartUC.Merge(req.GuestCartId, req.UserId)
+	if err != nil {
+		return nil, cartError(err)
+	}
+	return toProtoCart(cart), nil
+}
+
+func (s *OrderServiceServer) CheckoutCart(ctx context.Context, req *order.CheckoutCartRequest) (*order.CreateOrderResponse, error) {
+	orderID, err := s.cartUC.Checkout(req.CartId, req.UserId, usecase.CartCheckout{
+		StoreID:           req.StoreId,
+		DeliveryAddress:   fromProtoAddress(req.DeliveryAddress),
+		CouponCodes:       req.CouponCodes,
+		SlotReservationID: req.SlotReservationId,
+	})
+	switch {
+	case err == nil:
+		return &order.CreateOrderResponse{OrderId: orderID}, nil
+	case errors.Is(err, domain.ErrCartNotFound), errors.Is(err, domain.ErrCartEmpty),
+		errors.Is(err, domain.ErrCartOwner), errors.Is(err, domain.ErrCartCheckoutInProgress):
+		return nil, cartError(err)
+	default:
+		return nil, createOrderError(err)
+	}
+}
+
+func toProtoCart(c domain.Cart) *order.Cart {
+	cart := &order.Cart{
+		Id:        c.ID,
+		UserId:    c.UserID,
+		Subtotal:  c.Subtotal,
+		CreatedAt: c.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: c.UpdatedAt.Format(time.RFC3339),
+		ExpiresAt: c.ExpiresAt.Format(time.RFC3339),
+	}
+	for _, item := range c.Items {
+		cart.Items = append(cart.Items, toProtoCartItem(item))
+	}
+	return cart
+}
+
+func toProtoCartItem(i domain.CartItem) *order.CartItem {
+	return &order.CartItem{
+		ProductId:    i.ProductID,
+		Name:         i.Name,
+		Unit:         i.Unit,
+		Quantity:     i.Quantity,
+		Price:        i.Price,
+		CurrentPrice: i.CurrentPrice,
+		PriceChanged: i.PriceChanged(),
+		LineTotal:    i.LineTotal,
+		Available:    i.Available,
+		AddedAt:      i.AddedAt.Format(time.RFC3339),
+	}
+}
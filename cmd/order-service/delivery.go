@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/proto/order"
+	"FoodStore-AdvProg2/usecase"
+)
+
+// courierLocationMaxAge читает из COURIER_LOCATION_MAX_AGE, сколько точка курьера годится для подбора ближайшего
+func courierLocationMaxAge() time.Duration {
+	return durationFromEnv("COURIER_LOCATION_MAX_AGE", usecase.DefaultCourierLocationMaxAge)
+}
+
+// courierSpeedKmh читает среднюю скорость курьера для оценки прибытия из COURIER_SPEED_KMH
+func courierSpeedKmh() float64 {
+	value := os.Getenv("COURIER_SPEED_KMH")
+	if value == "" {
+		return usecase.DefaultCourierSpeedKmh
+	}
+	speed, err := strconv.ParseFloat(value, 64)
+	if err != nil || speed <= 0 {
+		log.Printf("Invalid COURIER_SPEED_KMH %q, using %.0f", value, usecase.DefaultCourierSpeedKmh)
+		return usecase.DefaultCourierSpeedKmh
+	}
+	return speed
+}
+
+// deliveryError преобразует ошибки курьеров и доставок в gRPC статусы
+func deliveryError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidCourier), errors.Is(err, usecase.ErrImageTooLarge),
+		errors.Is(err, usecase.ErrUnsupportedImage):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrCourierNotFound), errors.Is(err, domain.ErrDeliveryNotFound),
+		errors.Is(err, domain.ErrOrderNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrDuplicateCourier):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, domain.ErrInvalidDelivery), errors.Is(err, domain.ErrCourierUnavailable),
+		errors.Is(err, domain.ErrNoCourierAvailable), errors.Is(err, domain.ErrDeliveryAlreadyAssigned),
+		errors.Is(err, domain.ErrInvalidOrderTransition):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "delivery operation failed: %v", err)
+	}
+}
+
+func (s *OrderServiceServer) CreateCourier(ctx context.Context, req *order.CreateCourierRequest) (*order.Courier, error) {
+	courier, err := s.deliveryUC.CreateCourier(domain.Courier{
+		UserID: req.UserId,
+		Name:   req.Name,
+		Phone:  req.Phone,
+		Zone:   req.Zone,
+		Status: req.Status,
+	})
+	if err != nil {
+		return nil, deliveryError(err)
+	}
+	return toProtoCourier(courier), nil
+}
+
+func (s *OrderServiceServer) ListCouriers(ctx context.Context, req *order.ListCouriersRequest) (*order.ListCouriersResponse, error) {
+	couriers, err := s.deliveryUC.ListCouriers(req.Status, req.Zone)
+	if err != nil {
+		return nil, deliveryError(err)
+	}
+
+	resp := &order.ListCouriersResponse{}
+	for _, c := range couriers {
+		resp.Couriers = append(resp.Couriers, toProtoCourier(c))
+	}
+	return resp, nil
+}
+
+func (s *OrderServiceServer) UpdateCourierStatus(ctx context.Context, req *order.UpdateCourierStatusRequest) (*order.Courier, error) {
+	courier, err := s.deliveryUC.SetCourierStatus(req.Id, req.Status)
+	if err != nil {
+		return nil, deliveryError(err)
+	}
+	return toProtoCourier(courier), nil
+}
+
+func (s *OrderServiceServer) RecordCourierLocation(ctx context.Context, req *order.RecordCourierLocationRequest) (*order.Courier, error) {
+	recordedAt, err := parseTimestamp(req.RecordedAt)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "recorded_at must be an RFC 3339 timestamp")
+	}
+	var at time.Time
+	if recordedAt != nil {
+		at = *recordedAt
+	}
+
+	courier, err := s.deliveryUC.RecordLocation(req.CourierId,
+		domain.GeoPoint{Latitude: req.Latitude, Longitude: req.Longitude}, at)
+	if err != nil {
+		return nil, deliveryError(err)
+	}
+	return toProtoCourier(courier), nil
+}
+
+func (s *OrderServiceServer) AssignCourier(ctx context.Context, req *order.AssignCourierRequest) (*order.Delivery, error) {
+	delivery, err := s.deliveryUC.AssignCourier(req.OrderId, req.CourierId)
+	if err != nil {
+		return nil, deliveryError(err)
+	}
+	log.Printf("Order %s assigned to courier %s", delivery.OrderID, delivery.CourierID)
+	return toProtoDelivery(delivery), nil
+}
+
+// deliveryCourier возвращает курьера, от имени которого меняется доставка: диспетчеру (пустой ID)
+// доступна любая доставка, остальным - только доставки курьера их учетной записи
+func (s *OrderServiceServer) deliveryCourier(userID string, dispatcher bool) (string, error) {
+	if dispatcher {
+		return "", nil
+	}
+	courier, err := s.deliveryUC.CourierByUser(userID)
+	if err != nil {
+		return "", err
+	}
+	return courier.ID, nil
+}
+
+func (s *OrderServiceServer) UpdateDeliveryStatus(ctx context.Context, req *order.UpdateDeliveryStatusRequest) (*order.Delivery, error) {
+	courierID, err := s.deliveryCourier(req.UserId, req.Dispatcher)
+	if err != nil {
+		return nil, deliveryError(err)
+	}
+	delivery, err := s.deliveryUC.UpdateDeliveryStatus(req.Id, courierID, req.Status, req.Note)
+	if err != nil {
+		return nil, deliveryError(err)
+	}
+	return toProtoDelivery(delivery), nil
+}
+
+func (s *OrderServiceServer) UploadDeliveryProof(ctx context.Context, req *order.UploadDeliveryProofRequest) (*order.Delivery, error) {
+	courierID, err := s.deliveryCourier(req.UserId, req.Dispatcher)
+	if err != nil {
+		return nil, deliveryError(err)
+	}
+	delivery, err := s.deliveryUC.AttachProofPhoto(ctx, req.Id, courierID, req.Data)
+	if err != nil {
+		return nil, deliveryError(err)
+	}
+	return toProtoDelivery(delivery), nil
+}
+
+func (s *OrderServiceServer) ListDeliveries(ctx context.Context, req *order.ListDeliveriesRequest) (*order.ListDeliveriesResponse, error) {
+	deliveries, err := s.deliveryUC.ListDeliveries(req.CourierId, req.Status)
+	if err != nil {
+		return nil, deliveryError(err)
+	}
+
+	resp := &order.ListDeliveriesResponse{}
+	for _, d := range deliveries {
+		resp.Deliveries = append(resp.Deliveries, toProtoDelivery(d))
+	}
+	return resp, nil
+}
+
+func (s *OrderServiceServer) TrackOrder(ctx context.Context, req *order.TrackOrderRequest) (*order.OrderTracking, error) {
+	tracking, err := s.deliveryUC.Track(req.OrderId, req.UserId)
+	if err != nil {
+		return nil, deliveryError(err)
+	}
+
+	resp := &order.OrderTracking{
+		OrderId:           tracking.OrderID,
+		OrderStatus:       tracking.OrderStatus,
+		CourierName:       tracking.CourierName,
+		CourierLocationAt: formatTimestamp(tracking.CourierLocationAt),
+		EtaMinutes:        int32(tracking.EtaMinutes),
+		DeliveryStartsAt:  formatTimestamp(tracking.DeliveryStartsAt),
+		DeliveryEndsAt:    formatTimestamp(tracking.DeliveryEndsAt),
+	}
+	if tracking.Delivery != nil {
+		resp.Delivery = toProtoDelivery(*tracking.Delivery)
+	}
+	if tracking.CourierLocation != nil {
+		resp.CourierLocation = &order.GeoPoint{
+			Latitude:  tracking.CourierLocation.Latitude,
+			Longitude: tracking.CourierLocation.Longitude,
+		}
+	}
+	return resp, nil
+}
+
+func toProtoCourier(c domain.Courier) *order.Courier {
+	courier := &order.Courier{
+		Id:         c.ID,
+		UserId:     c.UserID,
+		Name:       c.Name,
+		Phone:      c.Phone,
+		Zone:       c.Zone,
+		Status:     c.Status,
+		LocationAt: formatTimestamp(c.LocationAt),
+		Busy:       c.Busy,
+		CreatedAt:  c.CreatedAt.Format(time.RFC3339),
+	}
+	if c.Location != nil {
+		courier.Location = &order.GeoPoint{Latitude: c.Location.Latitude, Longitude: c.Location.Longitude}
+	}
+	return courier
+}
+
+func toProtoDelivery(d domain.Delivery) *order.Delivery {
+	return &order.Delivery{
+		Id:            d.ID,
+		OrderId:       d.OrderID,
+		CourierId:     d.CourierID,
+		Status:        d.Status,
+		ProofNote:     d.ProofNote,
+		ProofPhotoUrl: d.ProofPhotoURL,
+		AssignedAt:    d.AssignedAt.Format(time.RFC3339),
+		PickedUpAt:    formatTimestamp(d.PickedUpAt),
+		DeliveredAt:   formatTimestamp(d.DeliveredAt),
+		UpdatedAt:     d.UpdatedAt.Format(time.RFC3339),
+	}
+}
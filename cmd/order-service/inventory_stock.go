@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/proto/inventory"
+	"FoodStore-AdvProg2/utils"
+)
+
+// inventoryStock реализует usecase.StockAllocator через Inventory Service
+type inventoryStock struct {
+	client  inventory.InventoryServiceClient
+	timeout time.Duration
+}
+
+func newInventoryStock(client inventory.InventoryServiceClient) *inventoryStock {
+	return &inventoryStock{client: client, timeout: 5 * time.Second}
+}
+
+func (s *inventoryStock) FulfillingLocations(items []domain.OrderItemRequest) ([]domain.Location, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	resp, err := s.client.FindFulfillingLocations(ctx, &inventory.FindFulfillingLocationsRequest{Items: toProtoItems(items)})
+	if err != nil {
+		return nil, err
+	}
+
+	locations := make([]domain.Location, 0, len(resp.Locations))
+	for _, l := range resp.Locations {
+		location := domain.Location{
+			ID:        l.Id,
+			Code:      l.Code,
+			Name:      l.Name,
+			Type:      l.Type,
+			IsDefault: l.IsDefault,
+			Active:    l.Active,
+		}
+		if a := l.Address; a != nil {
+			location.Address = domain.Address{
+				Street:     a.Street,
+				City:       a.City,
+				PostalCode: a.PostalCode,
+				Latitude:   a.Latitude,
+				Longitude:  a.Longitude,
+			}
+		}
+		locations = append(locations, location)
+	}
+	return locations, nil
+}
+
+func (s *inventoryStock) Allocate(orderID, locationID string, items []domain.OrderItemRequest, change domain.StockChange) ([]domain.BatchAllocation, error) {
+	ctx, cancel := s.context(change)
+	defer cancel()
+
+	req := &inventory.UpdateStockRequest{
+		OrderId:    orderID,
+		LocationId: locationID,
+		Items:      toProtoItems(items),
+	}
+
+	resp, err := s.client.UpdateStock(ctx, req)
+	if status.Code(err) == codes.FailedPrecondition {
+		return nil, domain.ErrInsufficientStock
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return fromProtoAllocations(resp.Allocations), nil
+}
+
+func (s *inventoryStock) Release(orderID string, change domain.StockChange) ([]domain.BatchAllocation, error) {
+	ctx, cancel := s.context(change)
+	defer cancel()
+
+	resp, err := s.client.ReleaseStock(ctx, &inventory.ReleaseStockRequest{OrderId: orderID, Reason: change.Reason})
+	if err != nil {
+		return nil, err
+	}
+
+	return fromProtoAllocations(resp.Allocations), nil
+}
+
+// context передает инициатора изменения в Inventory Service для журнала движений
+func (s *inventoryStock) context(change domain.StockChange) (context.Context, context.CancelFunc) {
+	actor := change.Actor
+	if actor == "" {
+		actor = "order-service"
+	}
+	return context.WithTimeout(utils.WithActor(context.Background(), actor), s.timeout)
+}
+
+func toProtoItems(items []domain.OrderItemRequest) []*inventory.OrderItem {
+	result := make([]*inventory.OrderItem, 0, len(items))
+	for _, item := range items {
+		result = append(result, &inventory.OrderItem{
+			ProductId: item.ProductID,
+			Quantity:  int32(item.Quantity),
+		})
+	}
+	return result
+}
+
+func fromProtoAllocations(allocations []*inventory.BatchAllocation) []domain.BatchAllocation {
+	var result []domain.BatchAllocation
+	for _, a := range allocations {
+		allocation := domain.BatchAllocation{
+			BatchID:    a.BatchId,
+			ProductID:  a.ProductId,
+			LocationID: a.LocationId,
+			LotNumber:  a.LotNumber,
+			Quantity:   int(a.Quantity),
+		}
+		if expiresAt, err := time.Parse("2006-01-02", a.ExpiresAt); err == nil {
+			allocation.ExpiresAt = &expiresAt
+		}
+		result = append(result, allocation)
+	}
+	return result
+}
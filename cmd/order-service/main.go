@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net"
 	"os"
@@ -14,7 +15,9 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/infrastructure/events"
 	"FoodStore-AdvProg2/infrastructure/postgres"
+	"FoodStore-AdvProg2/infrastructure/storage"
 	"FoodStore-AdvProg2/proto/inventory"
 	"FoodStore-AdvProg2/proto/order"
 	"FoodStore-AdvProg2/usecase"
@@ -54,7 +57,54 @@ func main() {
 	// Создание репозитория и use case
 	orderRepo := postgres.NewOrderPostgresRepo()
 	productRepo := postgres.NewProductPostgresRepo()
-	orderUC := usecase.NewOrderUseCase(orderRepo, productRepo)
+	tax, err := taxPolicy()
+	if err != nil {
+		log.Fatalf("Invalid tax configuration: %v", err)
+	}
+	promotionRepo := postgres.NewPromotionPostgresRepo()
+	slotRepo := postgres.NewDeliverySlotPostgresRepo()
+	zoneRepo := postgres.NewDeliveryZonePostgresRepo()
+	orderUC := usecase.NewOrderUseCase(orderRepo, productRepo, newInventoryStock(inventoryClient), promotionRepo, slotRepo, zoneRepo,
+		deliveryFee(), tax)
+	promotionUC := usecase.NewPromotionUseCase(promotionRepo)
+	cartUC := usecase.NewCartUseCase(postgres.NewCartPostgresRepo(), productRepo, orderUC, cartTTL())
+	slotUC := usecase.NewDeliverySlotUseCase(slotRepo, slotHoldTTL())
+	zoneUC := usecase.NewDeliveryZoneUseCase(zoneRepo)
+
+	// Фото вручения хранятся в том же каталоге, что и изображения товаров; его раздает API Gateway
+	mediaDir := os.Getenv("MEDIA_DIR")
+	if mediaDir == "" {
+		mediaDir = "./media"
+	}
+	mediaBaseURL := os.Getenv("MEDIA_BASE_URL")
+	if mediaBaseURL == "" {
+		mediaBaseURL = "/media"
+	}
+	blobStore, err := storage.NewLocalBlobStore(mediaDir, mediaBaseURL)
+	if err != nil {
+		log.Fatalf("Failed to initialize media storage: %v", err)
+	}
+	deliveryUC := usecase.NewDeliveryUseCase(postgres.NewDeliveryPostgresRepo(), orderRepo, postgres.NewLocationPostgresRepo(), blobStore)
+	deliveryUC.LocationMaxAge = courierLocationMaxAge()
+	deliveryUC.SpeedKmh = courierSpeedKmh()
+
+	// Изменения заказов для WatchOrder и WatchUserOrders
+	orderUpdates := usecase.NewOrderBroker()
+	orderUC.Updates = orderUpdates
+	deliveryUC.Updates = orderUpdates
+
+	if interval := cartCleanupInterval(); interval > 0 {
+		go runCartCleanup(context.Background(), cartUC, interval)
+	}
+	if interval := slotExpiryInterval(); interval > 0 {
+		go runSlotExpiry(context.Background(), slotUC, interval)
+	}
+	// Доменные события из outbox уходят в брокер для других сервисов; EVENT_BUS=off отключает отправку
+	eventBus, err := events.OpenFromEnv("order-service")
+	if err != nil {
+		log.Fatalf("Failed to open event bus: %v", err)
+	}
+	go events.RunOutboxRelay(context.Background(), eventBus)
 
 	// Настройка gRPC сервера
 	port := os.Getenv("ORDER_SERVICE_PORT")
@@ -67,8 +117,9 @@ func main() {
 		log.Fatalf("Failed to listen: %v", err)
 	}
 
-	server := grpc.NewServer()
-	orderServer := NewOrderServiceServer(orderUC, inventoryClient)
+	// Фото вручения передается целиком в одном сообщении, поэтому лимит выше стандартных 4 МБ
+	server := grpc.NewServer(grpc.MaxRecvMsgSize(usecase.MaxImageSize + 1<<20))
+	orderServer := NewOrderServiceServer(orderUC, promotionUC, cartUC, slotUC, zoneUC, deliveryUC, inventoryClient)
 	order.RegisterOrderServiceServer(server, orderServer)
 
 	// Включаем reflection для отладки
@@ -84,68 +135,53 @@ func main() {
 type OrderServiceServer struct {
 	order.UnimplementedOrderServiceServer
 	orderUC         *usecase.OrderUseCase
+	promotionUC     *usecase.PromotionUseCase
+	cartUC          *usecase.CartUseCase
+	slotUC          *usecase.DeliverySlotUseCase
+	zoneUC          *usecase.DeliveryZoneUseCase
+	deliveryUC      *usecase.DeliveryUseCase
 	inventoryClient inventory.InventoryServiceClient
 }
 
-func NewOrderServiceServer(orderUC *usecase.OrderUseCase, inventoryClient inventory.InventoryServiceClient) *OrderServiceServer {
+func NewOrderServiceServer(orderUC *usecase.OrderUseCase, promotionUC *usecase.PromotionUseCase, cartUC *usecase.CartUseCase,
+	slotUC *usecase.DeliverySlotUseCase, zoneUC *usecase.DeliveryZoneUseCase, deliveryUC *usecase.DeliveryUseCase,
+	inventoryClient inventory.InventoryServiceClient) *OrderServiceServer {
 	return &OrderServiceServer{
 		orderUC:         orderUC,
+		promotionUC:     promotionUC,
+		cartUC:          cartUC,
+		slotUC:          slotUC,
+		zoneUC:          zoneUC,
+		deliveryUC:      deliveryUC,
 		inventoryClient: inventoryClient,
 	}
 }
 
 // CreateOrder создает новый заказ
 func (s *OrderServiceServer) CreateOrder(ctx context.Context, req *order.CreateOrderRequest) (*order.CreateOrderResponse, error) {
-	// Проверяем наличие товаров через Inventory Service
-	var inventoryItems []*inventory.OrderItem
-	for _, item := range req.Items {
-		inventoryItems = append(inventoryItems, &inventory.OrderItem{
-			ProductId: item.ProductId,
-			Quantity:  item.Quantity,
-		})
-	}
-
-	checkResp, err := s.inventoryClient.CheckStock(ctx, &inventory.CheckStockRequest{
-		Items: inventoryItems,
-	})
-
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to check stock: %v", err)
-	}
-
-	if !checkResp.Available {
-		return nil, status.Errorf(codes.FailedPrecondition, "product %s is out of stock", checkResp.UnavailableProductId)
-	}
-
-	// Преобразуем запрос в домен
-	var orderItems []domain.OrderItemRequest
+	// Количество приходит в единицах товара (штуки, кг, литры); перевод в базовые
+	// единицы остатка и проверка наличия выполняются при создании заказа
+	var orderItems []domain.OrderLineRequest
 	for _, item := range req.Items {
-		orderItems = append(orderItems, domain.OrderItemRequest{
+		orderItems = append(orderItems, domain.OrderLineRequest{
 			ProductID: item.ProductId,
-			Quantity:  int(item.Quantity),
+			Quantity:  item.Quantity,
 		})
 	}
 
 	orderRequest := domain.OrderRequest{
-		UserID: req.UserId,
-		Items:  orderItems,
+		UserID:            req.UserId,
+		Items:             orderItems,
+		StoreID:           req.StoreId,
+		DeliveryAddress:   fromProtoAddress(req.DeliveryAddress),
+		CouponCodes:       req.CouponCodes,
+		SlotReservationID: req.SlotReservationId,
 	}
 
-	// Создаем заказ
+	// Создаем заказ; товары списываются по FEFO с выбранной точки через Inventory Service
 	orderID, err := s.orderUC.CreateOrder(orderRequest)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to create order: %v", err)
-	}
-
-	// Обновляем сток через Inventory Service
-	_, err = s.inventoryClient.UpdateStock(ctx, &inventory.UpdateStockRequest{
-		Items: inventoryItems,
-	})
-
-	if err != nil {
-		// В реальном приложении нужна компенсационная транзакция
-		log.Printf("Failed to update stock after order creation: %v", err)
-		return nil, status.Errorf(codes.Internal, "failed to update stock")
+		return nil, createOrderError(err)
 	}
 
 	return &order.CreateOrderResponse{
@@ -153,6 +189,30 @@ func (s *OrderServiceServer) CreateOrder(ctx context.Context, req *order.CreateO
 	}, nil
 }
 
+// createOrderError преобразует ошибки оформления заказа в gRPC статусы
+func createOrderError(err error) error {
+	if errors.Is(err, domain.ErrInvalidQuantity) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if errors.Is(err, domain.ErrInsufficientStock) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if errors.Is(err, usecase.ErrNoFulfillingLocation) || errors.Is(err, usecase.ErrProductArchived) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if errors.Is(err, domain.ErrInvalidCoupon) || errors.Is(err, domain.ErrCouponNotApplicable) ||
+		errors.Is(err, domain.ErrPromotionLimitReached) {
+		return promotionError(err)
+	}
+	if isSlotError(err) {
+		return slotError(err)
+	}
+	if errors.Is(err, domain.ErrAddressNotServed) || errors.Is(err, domain.ErrBelowMinimumOrder) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Errorf(codes.Internal, "failed to create order: %v", err)
+}
+
 // GetOrder возвращает информацию о заказе по ID
 func (s *OrderServiceServer) GetOrder(ctx context.Context, req *order.GetOrderRequest) (*order.GetOrderResponse, error) {
 	domainOrder, err := s.orderUC.GetOrderByID(req.Id)
@@ -176,20 +236,39 @@ func (s *OrderServiceServer) GetOrder(ctx context.Context, req *order.GetOrderRe
 			Id:        item.ID,
 			OrderId:   item.OrderID,
 			ProductId: item.ProductID,
-			Quantity:  int32(item.Quantity),
+			Quantity:  item.Quantity,
+			Unit:      item.Unit,
 			Price:     item.Price,
 			Product:   productInfo,
+			Discounts: toProtoDiscounts(item.Discounts),
+			Discount:  item.Discount,
+			TaxRate:   item.TaxRate,
+			TaxAmount: item.TaxAmount,
 		})
 	}
 
 	return &order.GetOrderResponse{
 		Order: &order.Order{
-			Id:         domainOrder.ID,
-			UserId:     domainOrder.UserID,
-			TotalPrice: domainOrder.TotalPrice,
-			Status:     domainOrder.Status,
-			CreatedAt:  timestamppb.New(domainOrder.CreatedAt),
-			Items:      orderItems,
+			Id:               domainOrder.ID,
+			UserId:           domainOrder.UserID,
+			TotalPrice:       domainOrder.TotalAmount,
+			Status:           domainOrder.Status,
+			CreatedAt:        timestamppb.New(domainOrder.CreatedAt),
+			Items:            orderItems,
+			LocationId:       domainOrder.LocationID,
+			DeliveryAddress:  toProtoAddress(domainOrder.DeliveryAddress),
+			Subtotal:         domainOrder.Subtotal,
+			DiscountTotal:    domainOrder.DiscountTotal,
+			DeliveryFee:      domainOrder.DeliveryFee,
+			Discounts:        toProtoDiscounts(domainOrder.Discounts),
+			TaxMode:          domainOrder.TaxMode,
+			TaxTotal:         domainOrder.TaxTotal,
+			DeliveryTax:      domainOrder.DeliveryTax,
+			TaxSummary:       toProtoTaxSummary(domainOrder.TaxSummary),
+			DeliverySlotId:   domainOrder.DeliverySlotID,
+			DeliveryStartsAt: formatTimestamp(domainOrder.DeliveryStartsAt),
+			DeliveryEndsAt:   formatTimestamp(domainOrder.DeliveryEndsAt),
+			DeliveryZone:     domainOrder.DeliveryZone,
 		},
 	}, nil
 }
@@ -197,7 +276,14 @@ func (s *OrderServiceServer) GetOrder(ctx context.Context, req *order.GetOrderRe
 // UpdateOrderStatus обновляет статус заказа
 func (s *OrderServiceServer) UpdateOrderStatus(ctx context.Context, req *order.UpdateOrderStatusRequest) (*order.UpdateOrderStatusResponse, error) {
 	err := s.orderUC.UpdateOrderStatus(req.Id, req.Status)
-	if err != nil {
+	switch {
+	case errors.Is(err, domain.ErrInvalidOrderStatus):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrOrderNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrInvalidOrderTransition):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
 		return nil, status.Errorf(codes.Internal, "failed to update order status: %v", err)
 	}
 
@@ -216,11 +302,23 @@ func (s *OrderServiceServer) GetUserOrders(ctx context.Context, req *order.GetUs
 	var orders []*order.Order
 	for _, o := range domainOrders {
 		orders = append(orders, &order.Order{
-			Id:         o.ID,
-			UserId:     o.UserID,
-			TotalPrice: o.TotalPrice,
-			Status:     o.Status,
-			CreatedAt:  timestamppb.New(o.CreatedAt),
+			Id:               o.ID,
+			UserId:           o.UserID,
+			TotalPrice:       o.TotalAmount,
+			Status:           o.Status,
+			CreatedAt:        timestamppb.New(o.CreatedAt),
+			LocationId:       o.LocationID,
+			DeliveryAddress:  toProtoAddress(o.DeliveryAddress),
+			Subtotal:         o.Subtotal,
+			DiscountTotal:    o.DiscountTotal,
+			DeliveryFee:      o.DeliveryFee,
+			TaxMode:          o.TaxMode,
+			TaxTotal:         o.TaxTotal,
+			DeliveryTax:      o.DeliveryTax,
+			DeliverySlotId:   o.DeliverySlotID,
+			DeliveryStartsAt: formatTimestamp(o.DeliveryStartsAt),
+			DeliveryEndsAt:   formatTimestamp(o.DeliveryEndsAt),
+			DeliveryZone:     o.DeliveryZone,
 		})
 	}
 
@@ -239,11 +337,23 @@ func (s *OrderServiceServer) GetAllOrders(ctx context.Context, req *order.GetAll
 	var orders []*order.Order
 	for _, o := range domainOrders {
 		orders = append(orders, &order.Order{
-			Id:         o.ID,
-			UserId:     o.UserID,
-			TotalPrice: o.TotalPrice,
-			Status:     o.Status,
-			CreatedAt:  timestamppb.New(o.CreatedAt),
+			Id:               o.ID,
+			UserId:           o.UserID,
+			TotalPrice:       o.TotalAmount,
+			Status:           o.Status,
+			CreatedAt:        timestamppb.New(o.CreatedAt),
+			LocationId:       o.LocationID,
+			DeliveryAddress:  toProtoAddress(o.DeliveryAddress),
+			Subtotal:         o.Subtotal,
+			DiscountTotal:    o.DiscountTotal,
+			DeliveryFee:      o.DeliveryFee,
+			TaxMode:          o.TaxMode,
+			TaxTotal:         o.TaxTotal,
+			DeliveryTax:      o.DeliveryTax,
+			DeliverySlotId:   o.DeliverySlotID,
+			DeliveryStartsAt: formatTimestamp(o.DeliveryStartsAt),
+			DeliveryEndsAt:   formatTimestamp(o.DeliveryEndsAt),
+			DeliveryZone:     o.DeliveryZone,
 		})
 	}
 
@@ -251,3 +361,26 @@ func (s *OrderServiceServer) GetAllOrders(ctx context.Context, req *order.GetAll
 		Orders: orders,
 	}, nil
 }
+
+func fromProtoAddress(a *order.Address) domain.Address {
+	if a == nil {
+		return domain.Address{}
+	}
+	return domain.Address{
+		Street:     a.Street,
+		City:       a.City,
+		PostalCode: a.PostalCode,
+		Latitude:   a.Latitude,
+		Longitude:  a.Longitude,
+	}
+}
+
+func toProtoAddress(a domain.Address) *order.Address {
+	return &order.Address{
+		Street:     a.Street,
+		City:       a.City,
+		PostalCode: a.PostalCode,
+		Latitude:   a.Latitude,
+		Longitude:  a.Longitude,
+	}
+}
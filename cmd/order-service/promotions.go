@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/proto/order"
+	"FoodStore-AdvProg2/utils"
+)
+
+// deliveryFee читает из ORDER_DELIVERY_FEE стоимость доставки, которая действует, пока не заведены зоны доставки;
+// по умолчанию доставка бесплатна
+func deliveryFee() float64 {
+	value := os.Getenv("ORDER_DELIVERY_FEE")
+	if value == "" {
+		return 0
+	}
+	fee, err := strconv.ParseFloat(value, 64)
+	if err != nil || fee < 0 {
+		log.Printf("Invalid ORDER_DELIVERY_FEE %q, delivery is free", value)
+		return 0
+	}
+	return fee
+}
+
+// promotionError преобразует ошибки акций и купонов в gRPC статусы
+func promotionError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidPromotion), errors.Is(err, domain.ErrInvalidCoupon):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrPromotionNotFound):
+		return status.Error(codes.NotFound, "promotion not found")
+	case errors.Is(err, domain.ErrDuplicateCoupon):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, domain.ErrCouponNotApplicable), errors.Is(err, domain.ErrPromotionLimitReached):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "promotion operation failed: %v", err)
+	}
+}
+
+func (s *OrderServiceServer) CreatePromotion(ctx context.Context, req *order.CreatePromotionRequest) (*order.Promotion, error) {
+	startsAt, err := parseTimestamp(req.StartsAt)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "starts_at must be an RFC 3339 timestamp")
+	}
+	endsAt, err := parseTimestamp(req.EndsAt)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "ends_at must be an RFC 3339 timestamp")
+	}
+
+	promotion, err := s.promotionUC.Create(domain.Promotion{
+		Name:         req.Name,
+		Type:         req.Type,
+		Value:        req.Value,
+		BuyQuantity:  int(req.BuyQuantity),
+		GetQuantity:  int(req.GetQuantity),
+		MinSubtotal:  req.MinSubtotal,
+		ProductIDs:   req.ProductIds,
+		Categories:   req.Categories,
+		CouponCode:   req.CouponCode,
+		UsageLimit:   int(req.UsageLimit),
+		PerUserLimit: int(req.PerUserLimit),
+		StartsAt:     startsAt,
+		EndsAt:       endsAt,
+		Stackable:    req.Stackable,
+		Priority:     int(req.Priority),
+	}, utils.ActorFromContext(ctx, "unknown"))
+	if err != nil {
+		return nil, promotionError(err)
+	}
+	return toProtoPromotion(promotion), nil
+}
+
+func (s *OrderServiceServer) ListPromotions(ctx context.Context, req *order.ListPromotionsRequest) (*order.ListPromotionsResponse, error) {
+	promotions, err := s.promotionUC.List(req.ActiveOnly)
+	if err != nil {
+		return nil, promotionError(err)
+	}
+
+	resp := &order.ListPromotionsResponse{}
+	for _, p := range promotions {
+		resp.Promotions = append(resp.Promotions, toProtoPromotion(p))
+	}
+	return resp, nil
+}
+
+func (s *OrderServiceServer) SetPromotionActive(ctx context.Context, req *order.SetPromotionActiveRequest) (*order.Promotion, error) {
+	promotion, err := s.promotionUC.SetActive(req.Id, req.Active)
+	if err != nil {
+		return nil, promotionError(err)
+	}
+	return toProtoPromotion(promotion), nil
+}
+
+// parseTimestamp разбирает время в RFC 3339; пустая строка означает отсутствие границы
+func parseTimestamp(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func formatTimestamp(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func toProtoPromotion(p domain.Promotion) *order.Promotion {
+	return &order.Promotion{
+		Id:           p.ID,
+		Name:         p.Name,
+		Type:         p.Type,
+		Value:        p.Value,
+		BuyQuantity:  int32(p.BuyQuantity),
+		GetQuantity:  int32(p.GetQuantity),
+		MinSubtotal:  p.MinSubtotal,
+		ProductIds:   p.ProductIDs,
+		Categories:   p.Categories,
+		CouponCode:   p.CouponCode,
+		UsageLimit:   int32(p.UsageLimit),
+		PerUserLimit: int32(p.PerUserLimit),
+		StartsAt:     formatTimestamp(p.StartsAt),
+		EndsAt:       formatTimestamp(p.EndsAt),
+		Stackable:    p.Stackable,
+		Priority:     int32(p.Priority),
+		Active:       p.Active,
+		TimesUsed:    int32(p.TimesUsed),
+		CreatedBy:    p.CreatedBy,
+		CreatedAt:    p.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toProtoDiscounts(discounts []domain.OrderDiscount) []*order.OrderDiscount {
+	var result []*order.OrderDiscount
+	for _, d := range discounts {
+		result = append(result, &order.OrderDiscount{
+			Id:          d.ID,
+			OrderItemId: d.OrderItemID,
+			PromotionId: d.PromotionID,
+			Name:        d.Name,
+			CouponCode:  d.CouponCode,
+			Amount:      d.Amount,
+		})
+	}
+	return result
+}
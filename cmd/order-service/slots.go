@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/proto/order"
+	"FoodStore-AdvProg2/usecase"
+)
+
+// defaultSlotExpiryInterval - период пометки истекших броней, если SLOT_EXPIRY_INTERVAL не задан
+const defaultSlotExpiryInterval = time.Minute
+
+// slotHoldTTL читает из SLOT_HOLD_TTL, сколько бронь интервала ждет заказ (например, 15m)
+func slotHoldTTL() time.Duration {
+	return durationFromEnv("SLOT_HOLD_TTL", usecase.DefaultSlotHoldTTL)
+}
+
+// slotExpiryInterval читает период пометки истекших броней из SLOT_EXPIRY_INTERVAL; 0 отключает ее
+func slotExpiryInterval() time.Duration {
+	return durationFromEnv("SLOT_EXPIRY_INTERVAL", defaultSlotExpiryInterval)
+}
+
+// runSlotExpiry периодически помечает истекшими брони без заказа до отмены ctx
+func runSlotExpiry(ctx context.Context, uc *usecase.DeliverySlotUseCase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		expired, err := uc.ExpireReservations()
+		if err != nil {
+			log.Printf("Slot reservation expiry failed: %v", err)
+		} else if expired > 0 {
+			log.Printf("Expired %d delivery slot reservation(s)", expired)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// slotError преобразует ошибки интервалов доставки в gRPC статусы
+func slotError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrDeliverySlotNotFound), errors.Is(err, domain.ErrSlotReservationNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrInvalidDeliverySlot):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrDuplicateDeliverySlot):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, domain.ErrDeliverySlotFull), errors.Is(err, domain.ErrSlotReservationExpired):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "delivery slot operation failed: %v", err)
+	}
+}
+
+// isSlotError сообщает, что ошибка оформления заказа связана с интервалом доставки
+func isSlotError(err error) bool {
+	return errors.Is(err, domain.ErrDeliverySlotNotFound) || errors.Is(err, domain.ErrSlotReservationNotFound) ||
+		errors.Is(err, domain.ErrInvalidDeliverySlot) || errors.Is(err, domain.ErrSlotReservationExpired)
+}
+
+func (s *OrderServiceServer) CreateDeliverySlot(ctx context.Context, req *order.CreateDeliverySlotRequest) (*order.DeliverySlot, error) {
+	startsAt, err := parseTimestamp(req.StartsAt)
+	if err != nil || startsAt == nil {
+		return nil, status.Error(codes.InvalidArgument, "starts_at must be an RFC 3339 timestamp")
+	}
+	endsAt, err := parseTimestamp(req.EndsAt)
+	if err != nil || endsAt == nil {
+		return nil, status.Error(codes.InvalidArgument, "ends_at must be an RFC 3339 timestamp")
+	}
+
+	slot, err := s.slotUC.Create(domain.DeliverySlot{
+		Zone:     req.Zone,
+		StartsAt: *startsAt,
+		EndsAt:   *endsAt,
+		Capacity: int(req.Capacity),
+	})
+	if err != nil {
+		return nil, slotError(err)
+	}
+	return toProtoDeliverySlot(slot), nil
+}
+
+func (s *OrderServiceServer) ListAvailableSlots(ctx context.Context, req *order.ListAvailableSlotsRequest) (*order.ListAvailableSlotsResponse, error) {
+	from, err := parseTimestamp(req.From)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "from must be an RFC 3339 timestamp")
+	}
+	to, err := parseTimestamp(req.To)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "to must be an RFC 3339 timestamp")
+	}
+	var fromTime, toTime time.Time
+	if from != nil {
+		fromTime = *from
+	}
+	if to != nil {
+		toTime = *to
+	}
+
+	slots, err := s.slotUC.ListAvailable(req.Zone, fromTime, toTime, req.IncludeFull)
+	if err != nil {
+		return nil, slotError(err)
+	}
+
+	resp := &order.ListAvailableSlotsResponse{}
+	for _, slot := range slots {
+		resp.Slots = append(resp.Slots, toProtoDeliverySlot(slot))
+	}
+	return resp, nil
+}
+
+func (s *OrderServiceServer) ReserveDeliverySlot(ctx context.Context, req *order.ReserveDeliverySlotRequest) (*order.SlotReservation, error) {
+	reservation, err := s.slotUC.Reserve(req.SlotId, req.UserId)
+	if err != nil {
+		return nil, slotError(err)
+	}
+	return toProtoSlotReservation(reservation), nil
+}
+
+func (s *OrderServiceServer) ReleaseSlotReservation(ctx context.Context, req *order.ReleaseSlotReservationRequest) (*order.SlotReservation, error) {
+	reservation, err := s.slotUC.Release(req.Id, req.UserId)
+	if err != nil {
+		return nil, slotError(err)
+	}
+	return toProtoSlotReservation(reservation), nil
+}
+
+func toProtoDeliverySlot(s domain.DeliverySlot) *order.DeliverySlot {
+	return &order.DeliverySlot{
+		Id:        s.ID,
+		Zone:      s.Zone,
+		Date:      s.Date(),
+		StartsAt:  s.StartsAt.Format(time.RFC3339),
+		EndsAt:    s.EndsAt.Format(time.RFC3339),
+		Capacity:  int32(s.Capacity),
+		Booked:    int32(s.Booked),
+		Available: int32(s.Available()),
+	}
+}
+
+func toProtoSlotReservation(r domain.SlotReservation) *order.SlotReservation {
+	return &order.SlotReservation{
+		Id:        r.ID,
+		SlotId:    r.SlotID,
+		UserId:    r.UserID,
+		OrderId:   r.OrderID,
+		Status:    r.Status,
+		ExpiresAt: r.ExpiresAt.Format(time.RFC3339),
+		CreatedAt: r.CreatedAt.Format(time.RFC3339),
+	}
+}
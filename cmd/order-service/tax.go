@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/proto/order"
+)
+
+// taxPolicy читает режим цен и ставки НДС:
+//   - ORDER_TAX_MODE - inclusive (по умолчанию, цены каталога включают НДС) или exclusive;
+//   - ORDER_VAT_RATE - стандартная ставка в процентах, по умолчанию domain.DefaultVATRate;
+//   - ORDER_VAT_CATEGORY_RATES - ставки категорий, например "dairy=5,bakery=5,produce=0".
+func taxPolicy() (domain.TaxPolicy, error) {
+	policy := domain.TaxPolicy{Mode: domain.TaxInclusive, StandardRate: domain.DefaultVATRate}
+	if mode := os.Getenv("ORDER_TAX_MODE"); mode != "" {
+		policy.Mode = mode
+	}
+	if value := os.Getenv("ORDER_VAT_RATE"); value != "" {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return policy, fmt.Errorf("%w: invalid ORDER_VAT_RATE %q", domain.ErrInvalidTaxPolicy, value)
+		}
+		policy.StandardRate = rate
+	}
+	rates, err := domain.ParseCategoryRates(os.Getenv("ORDER_VAT_CATEGORY_RATES"))
+	if err != nil {
+		return policy, err
+	}
+	policy.CategoryRates = rates
+	return policy, policy.Validate()
+}
+
+func toProtoTaxSummary(summary []domain.TaxSummaryLine) []*order.TaxSummaryLine {
+	var result []*order.TaxSummaryLine
+	for _, line := range summary {
+		result = append(result, &order.TaxSummaryLine{
+			Rate:      line.Rate,
+			NetAmount: line.NetAmount,
+			TaxAmount: line.TaxAmount,
+		})
+	}
+	return result
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/proto/order"
+)
+
+// orderEventSnapshot - тип первого события WatchOrder с текущим состоянием заказа
+const orderEventSnapshot = "snapshot"
+
+// WatchOrder отправляет текущее состояние заказа и затем каждое его изменение
+func (s *OrderServiceServer) WatchOrder(req *order.WatchOrderRequest, stream order.OrderService_WatchOrderServer) error {
+	if !req.AllUsers && req.UserId == "" {
+		return status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if s.orderUC.Updates == nil {
+		return status.Error(codes.Unimplemented, "order updates are disabled")
+	}
+	ctx := stream.Context()
+
+	// Подписка до чтения заказа: изменение между чтением и подпиской не потеряется
+	updates, cancel := s.orderUC.Updates.Subscribe(req.Id, "")
+	defer cancel()
+
+	snapshot, err := s.GetOrder(ctx, &order.GetOrderRequest{Id: req.Id})
+	if err != nil {
+		return err
+	}
+	// Чужой заказ не отличается от несуществующего
+	if !req.AllUsers && snapshot.Order.UserId != req.UserId {
+		return status.Error(codes.NotFound, "order not found")
+	}
+	err = stream.Send(&order.OrderEvent{
+		Type:       orderEventSnapshot,
+		Order:      snapshot.Order,
+		OccurredAt: time.Now().Format(time.RFC3339),
+	})
+	if err != nil || domain.IsFinalOrderStatus(snapshot.Order.Status) {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case update, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			event, err := s.orderEvent(ctx, update)
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+			if domain.IsFinalOrderStatus(event.Order.Status) {
+				return nil
+			}
+		}
+	}
+}
+
+// WatchUserOrders отправляет созданные заказы покупателя и изменения его заказов, пока клиент не отключится.
+// Пустой user_id не означает "все заказы": их нужно запросить явно через all_users.
+func (s *OrderServiceServer) WatchUserOrders(req *order.WatchUserOrdersRequest, stream order.OrderService_WatchUserOrdersServer) error {
+	userID := req.UserId
+	if req.AllUsers {
+		userID = ""
+	} else if userID == "" {
+		return status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if s.orderUC.Updates == nil {
+		return status.Error(codes.Unimplemented, "order updates are disabled")
+	}
+	ctx := stream.Context()
+
+	updates, cancel := s.orderUC.Updates.Subscribe("", userID)
+	defer cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case update, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			event, err := s.orderEvent(ctx, update)
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// orderEvent перечитывает заказ из изменения: подписчик получает заказ в том же виде, что и GetOrder
+func (s *OrderServiceServer) orderEvent(ctx context.Context, update domain.OrderUpdate) (*order.OrderEvent, error) {
+	resp, err := s.GetOrder(ctx, &order.GetOrderRequest{Id: update.OrderID})
+	if err != nil {
+		return nil, err
+	}
+	return &order.OrderEvent{
+		Type:       update.Type,
+		Order:      resp.Order,
+		OccurredAt: update.OccurredAt.Format(time.RFC3339),
+	}, nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/proto/order"
+)
+
+// zoneError преобразует ошибки зон доставки в gRPC статусы
+func zoneError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidDeliveryZone):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrDeliveryZoneNotFound), errors.Is(err, domain.ErrAddressNotServed):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrDuplicateDeliveryZone):
+		return status.Error(codes.AlreadyExists, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "delivery zone operation failed: %v", err)
+	}
+}
+
+func (s *OrderServiceServer) CreateDeliveryZone(ctx context.Context, req *order.CreateDeliveryZoneRequest) (*order.DeliveryZone, error) {
+	if req.Zone == nil {
+		return nil, status.Error(codes.InvalidArgument, "zone is required")
+	}
+	zone, err := s.zoneUC.Create(fromProtoDeliveryZone(req.Zone))
+	if err != nil {
+		return nil, zoneError(err)
+	}
+	return toProtoDeliveryZone(zone), nil
+}
+
+func (s *OrderServiceServer) UpdateDeliveryZone(ctx context.Context, req *order.UpdateDeliveryZoneRequest) (*order.DeliveryZone, error) {
+	if req.Zone == nil {
+		return nil, status.Error(codes.InvalidArgument, "zone is required")
+	}
+	zone, err := s.zoneUC.Update(fromProtoDeliveryZone(req.Zone))
+	if err != nil {
+		return nil, zoneError(err)
+	}
+	return toProtoDeliveryZone(zone), nil
+}
+
+func (s *OrderServiceServer) ListDeliveryZones(ctx context.Context, req *order.ListDeliveryZonesRequest) (*order.ListDeliveryZonesResponse, error) {
+	zones, err := s.zoneUC.List(req.ActiveOnly)
+	if err != nil {
+		return nil, zoneError(err)
+	}
+
+	resp := &order.ListDeliveryZonesResponse{}
+	for _, zone := range zones {
+		resp.Zones = append(resp.Zones, toProtoDeliveryZone(zone))
+	}
+	return resp, nil
+}
+
+func (s *OrderServiceServer) QuoteDelivery(ctx context.Context, req *order.QuoteDeliveryRequest) (*order.DeliveryQuote, error) {
+	zone, fee, err := s.zoneUC.Quote(fromProtoAddress(req.Address), req.Subtotal)
+	if err != nil {
+		return nil, zoneError(err)
+	}
+
+	quote := &order.DeliveryQuote{
+		Zone:            toProtoDeliveryZone(zone),
+		Fee:             fee,
+		AmountToMinimum: math.Max(0, math.Round((zone.MinOrderAmount-req.Subtotal)*100)/100),
+	}
+	if zone.FreeDeliveryThreshold > 0 {
+		quote.AmountToFreeDelivery = math.Max(0, math.Round((zone.FreeDeliveryThreshold-req.Subtotal)*100)/100)
+	}
+	return quote, nil
+}
+
+func fromProtoDeliveryZone(z *order.DeliveryZone) domain.DeliveryZone {
+	zone := domain.DeliveryZone{
+		ID:                    z.Id,
+		Code:                  z.Code,
+		Name:                  z.Name,
+		PostalCodes:           z.PostalCodes,
+		Fee:                   z.Fee,
+		FreeDeliveryThreshold: z.FreeDeliveryThreshold,
+		MinOrderAmount:        z.MinOrderAmount,
+		Priority:              int(z.Priority),
+		Active:                z.Active,
+	}
+	for _, p := range z.Polygon {
+		zone.Polygon = append(zone.Polygon, domain.GeoPoint{Latitude: p.Latitude, Longitude: p.Longitude})
+	}
+	return zone
+}
+
+func toProtoDeliveryZone(z domain.DeliveryZone) *order.DeliveryZone {
+	zone := &order.DeliveryZone{
+		Id:                    z.ID,
+		Code:                  z.Code,
+		Name:                  z.Name,
+		PostalCodes:           z.PostalCodes,
+		Fee:                   z.Fee,
+		FreeDeliveryThreshold: z.FreeDeliveryThreshold,
+		MinOrderAmount:        z.MinOrderAmount,
+		Priority:              int32(z.Priority),
+		Active:                z.Active,
+		CreatedAt:             z.CreatedAt.Format(time.RFC3339),
+	}
+	for _, p := range z.Polygon {
+		zone.Polygon = append(zone.Polygon, &order.GeoPoint{Latitude: p.Latitude, Longitude: p.Longitude})
+	}
+	return zone
+}
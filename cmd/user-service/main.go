@@ -5,6 +5,7 @@ import (
 	"log"
 	"net"
 	"os"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
@@ -55,7 +56,7 @@ func main() {
 	}
 
 	server := grpc.NewServer()
-	userServer := NewUserServiceServer(userUC)
+	userServer := NewUserServiceServer(userUC, adminUsernames(os.Getenv("ADMIN_USERNAMES")))
 	user.RegisterUserServiceServer(server, userServer)
 
 	// Включаем reflection для отладки
@@ -71,11 +72,42 @@ func main() {
 type UserServiceServer struct {
 	user.UnimplementedUserServiceServer
 	userUC *usecase.UserUseCase
+	admins map[string]bool
 }
 
-func NewUserServiceServer(userUC *usecase.UserUseCase) *UserServiceServer {
+func NewUserServiceServer(userUC *usecase.UserUseCase, admins map[string]bool) *UserServiceServer {
 	return &UserServiceServer{
 		userUC: userUC,
+		admins: admins,
+	}
+}
+
+// adminUsernames разбирает список администраторов из ADMIN_USERNAMES (через запятую)
+func adminUsernames(list string) map[string]bool {
+	admins := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			admins[name] = true
+		}
+	}
+	return admins
+}
+
+// toProtoUser переводит пользователя в ответ; роль admin дает запись в базе или ADMIN_USERNAMES
+func (s *UserServiceServer) toProtoUser(u domain.User) *user.User {
+	role := u.Role
+	if s.admins[u.Username] {
+		role = domain.UserRoleAdmin
+	}
+	if role == "" {
+		role = domain.UserRoleCustomer
+	}
+	return &user.User{
+		Id:       u.ID,
+		Username: u.Username,
+		Email:    u.Email,
+		FullName: u.FullName,
+		Role:     role,
 	}
 }
 
@@ -112,12 +144,7 @@ func (s *UserServiceServer) RegisterUser(ctx context.Context, req *user.UserRequ
 	}
 
 	return &user.UserResponse{
-		User: &user.User{
-			Id:       newUser.ID,
-			Username: newUser.Username,
-			Email:    newUser.Email,
-			FullName: newUser.FullName,
-		},
+		User: s.toProtoUser(newUser),
 	}, nil
 }
 
@@ -136,12 +163,7 @@ func (s *UserServiceServer) AuthenticateUser(ctx context.Context, req *user.Auth
 	}
 
 	return &user.AuthResponse{
-		User: &user.User{
-			Id:       userEntity.ID,
-			Username: userEntity.Username,
-			Email:    userEntity.Email,
-			FullName: userEntity.FullName,
-		},
+		User: s.toProtoUser(userEntity),
 	}, nil
 }
 
@@ -153,33 +175,6 @@ func (s *UserServiceServer) GetUserProfile(ctx context.Context, req *user.UserPr
 	}
 
 	return &user.UserProfile{
-		User: &user.User{
-			Id:       userEntity.ID,
-			Username: userEntity.Username,
-			Email:    userEntity.Email,
-			FullName: userEntity.FullName,
-		},
-	}, nil
-}
-
-// Login аутентифицирует пользователя
-func (s *UserServiceServer) Login(ctx context.Context, req *user.LoginRequest) (*user.LoginResponse, error) {
-	userData, err := s.userUC.GetByUsername(req.Username)
-	if err != nil {
-		return nil, status.Error(codes.NotFound, "user not found")
-	}
-
-	// Проверка пароля
-	if err := bcrypt.CompareHashAndPassword([]byte(userData.Password), []byte(req.Password)); err != nil {
-		return nil, status.Error(codes.Unauthenticated, "invalid password")
-	}
-
-	return &user.LoginResponse{
-		User: &user.User{
-			Id:       userData.ID,
-			Username: userData.Username,
-			Email:    userData.Email,
-			FullName: userData.FullName,
-		},
+		User: s.toProtoUser(userEntity),
 	}, nil
 }
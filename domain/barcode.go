@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidBarcode - штрихкод не EAN-13, EAN-8 или UPC-A либо не сходится контрольная цифра
+	ErrInvalidBarcode = errors.New("invalid barcode")
+	// ErrDuplicateBarcode - штрихкод уже привязан к другому товару
+	ErrDuplicateBarcode = errors.New("barcode is already used by another product")
+)
+
+// NormalizeBarcode проверяет штрихкод EAN-13, EAN-8 или UPC-A и возвращает его
+// каноническую запись. UPC-A (12 цифр) хранится как EAN-13 с ведущим нулем:
+// сканеры на кассе часто читают такие коды как EAN-13, и оба варианта должны находить товар.
+func NormalizeBarcode(code string) (string, error) {
+	code = strings.TrimSpace(code)
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("%w %q: only digits are allowed", ErrInvalidBarcode, code)
+		}
+	}
+
+	switch len(code) {
+	case 8, 13:
+	case 12:
+		code = "0" + code
+	default:
+		return "", fmt.Errorf("%w %q: expected 8, 12 or 13 digits", ErrInvalidBarcode, code)
+	}
+
+	if gtinCheckDigit(code[:len(code)-1]) != code[len(code)-1] {
+		return "", fmt.Errorf("%w %q: check digit mismatch", ErrInvalidBarcode, code)
+	}
+	return code, nil
+}
+
+// gtinCheckDigit считает контрольную цифру GTIN: цифры справа налево
+// умножаются попеременно на 3 и 1
+func gtinCheckDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// NormalizeBarcodes нормализует список штрихкодов и убирает повторы
+func NormalizeBarcodes(codes []string) ([]string, error) {
+	result := make([]string, 0, len(codes))
+	seen := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		normalized, err := NormalizeBarcode(code)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[normalized] {
+			seen[normalized] = true
+			result = append(result, normalized)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package domain
+
+import "errors"
+
+// ErrBundleStock - у набора нет собственного остатка: партии заводятся и списываются по компонентам
+var ErrBundleStock = errors.New("bundle stock is computed from its components")
+
+// Виды товаров. Набор (bundle) не имеет собственных партий: его остаток считается
+// по остаткам компонентов, а при заказе списываются компоненты.
+const (
+	ProductKindSimple = "simple"
+	ProductKindBundle = "bundle"
+)
+
+// IsValidProductKind проверяет вид товара
+func IsValidProductKind(kind string) bool {
+	return kind == ProductKindSimple || kind == ProductKindBundle
+}
+
+// BundleComponent - товар в составе набора. Quantity - количество в базовых единицах
+// компонента: 2 штуки, 500 г, 1000 мл.
+type BundleComponent struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+	// Название и единица компонента только для чтения
+	Name string `json:"name,omitempty"`
+	Unit string `json:"unit,omitempty"`
+}
+
+// IsBundle сообщает, что товар - набор из других товаров
+func (p Product) IsBundle() bool {
+	return p.Kind == ProductKindBundle
+}
+
+// IsVariant сообщает, что товар - вариант другого товара (например, упаковка 0.5 л)
+func (p Product) IsVariant() bool {
+	return p.ParentID != ""
+}
+
+// BundleAvailable считает, сколько наборов можно собрать из доступных остатков компонентов
+func BundleAvailable(components []BundleComponent, available map[string]int) int {
+	result := -1
+	for _, c := range components {
+		if c.Quantity <= 0 {
+			continue
+		}
+		n := available[c.ProductID] / c.Quantity
+		if result < 0 || n < result {
+			result = n
+		}
+	}
+	if result < 0 {
+		return 0
+	}
+	return result
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrCartNotFound - корзина не найдена или истек срок ее хранения
+	ErrCartNotFound = errors.New("cart not found")
+	// ErrInvalidCartItem - товар нельзя положить в корзину в таком количестве
+	ErrInvalidCartItem = errors.New("invalid cart item")
+	// ErrCartEmpty - в корзине нет товаров
+	ErrCartEmpty = errors.New("cart is empty")
+	// ErrCartOwner - гостевую корзину нужно сначала привязать к покупателю, либо корзина принадлежит другому покупателю
+	ErrCartOwner = errors.New("cart owner mismatch")
+	// ErrCartCheckoutInProgress - корзина уже оформляется в заказ
+	ErrCartCheckoutInProgress = errors.New("cart checkout is already in progress")
+)
+
+// Cart - корзина покупателя на сервере. Гостевая корзина (без UserID) доступна по ID,
+// при входе покупателя она сливается с его корзиной. У покупателя не больше одной корзины.
+type Cart struct {
+	ID     string     `json:"id"`
+	UserID string     `json:"user_id,omitempty"`
+	Items  []CartItem `json:"items"`
+	// Subtotal - стоимость доступных позиций по текущим ценам
+	Subtotal  float64   `json:"subtotal"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// ExpiresAt - после этого момента корзина удаляется; любое изменение продлевает срок
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// IsExpired сообщает, что срок хранения корзины истек к моменту at
+func (c Cart) IsExpired(at time.Time) bool {
+	return !at.Before(c.ExpiresAt)
+}
+
+// CartItem - позиция корзины. Price - цена, которую видел покупатель: она запоминается
+// при добавлении и обновляется при пересчете корзины; заказ всегда оформляется по текущей цене.
+type CartItem struct {
+	ProductID string  `json:"product_id"`
+	Name      string  `json:"name"`
+	Unit      string  `json:"unit"`
+	Quantity  float64 `json:"quantity"`
+	Price     float64 `json:"price"`
+	// CurrentPrice - цена товара сейчас; отличается от Price, если цена изменилась после добавления
+	CurrentPrice float64 `json:"current_price"`
+	LineTotal    float64 `json:"line_total"`
+	// Available - товар продается и его хватает на остатке
+	Available bool      `json:"available"`
+	AddedAt   time.Time `json:"added_at"`
+}
+
+// PriceChanged сообщает, что цена товара изменилась после того, как покупатель его увидел
+func (i CartItem) PriceChanged() bool {
+	return i.Price != i.CurrentPrice
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxSKULength - максимальная длина артикула
+const MaxSKULength = 64
+
+// NormalizeSKU убирает пробелы по краям артикула
+func NormalizeSKU(sku string) string {
+	return strings.TrimSpace(sku)
+}
+
+// MaxCategoryLength - максимальная длина кода категории
+const MaxCategoryLength = 50
+
+// NormalizeCategory приводит код категории товара (dairy, bakery, household) к нижнему регистру
+func NormalizeCategory(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
+
+// IsValidCategory проверяет код категории: латинские буквы, цифры, "_" и "-"; пусто - без категории
+func IsValidCategory(category string) bool {
+	if len(category) > MaxCategoryLength {
+		return false
+	}
+	for _, r := range category {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// Форматы файлов импорта и экспорта каталога
+const (
+	CatalogFormatCSV   = "csv"
+	CatalogFormatJSONL = "jsonl"
+)
+
+// IsValidCatalogFormat проверяет формат файла каталога
+func IsValidCatalogFormat(format string) bool {
+	return format == CatalogFormatCSV || format == CatalogFormatJSONL
+}
+
+// ProductImportRow - строка файла импорта. Товар ищется по SKU: найденный обновляется,
+// иначе создается новый. Description и Stock необязательны: nil оставляет поле как есть.
+type ProductImportRow struct {
+	Line        int // номер строки в файле, начиная с 1
+	SKU         string
+	Name        string
+	Description *string
+	Price       float64
+	Stock       *int // доступный остаток, к которому нужно привести товар
+}
+
+type ProductImportOptions struct {
+	Format string
+	// DryRun только проверяет файл и считает, что было бы создано и обновлено
+	DryRun bool
+	// Atomic - все или ничего: при ошибке хотя бы в одной строке не применяется ни одна
+	Atomic bool
+}
+
+// ProductImportRowError - ошибка в строке импорта; Field пуст, если ошибка не относится к полю
+type ProductImportRowError struct {
+	Line    int
+	SKU     string
+	Field   string
+	Message string
+}
+
+func (e ProductImportRowError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+	return fmt.Sprintf("line %d: %s: %s", e.Line, e.Field, e.Message)
+}
+
+// ProductImportResult - итог импорта. В режиме DryRun Created и Updated - сколько товаров
+// было бы создано и обновлено.
+type ProductImportResult struct {
+	Rows    int
+	Created int
+	Updated int
+	Failed  int // строки с ошибками
+	DryRun  bool
+	// Committed - изменения сохранены; без Atomic строки без ошибок сохраняются всегда
+	Committed bool
+	Errors    []ProductImportRowError
+}
@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrInvalidCourier - курьер заполнен неверно
+	ErrInvalidCourier = errors.New("invalid courier")
+	// ErrCourierNotFound - курьер не найден
+	ErrCourierNotFound = errors.New("courier not found")
+	// ErrDuplicateCourier - телефон уже занят другим курьером
+	ErrDuplicateCourier = errors.New("courier with this phone already exists")
+	// ErrCourierUnavailable - курьер не на смене или уже везет заказ
+	ErrCourierUnavailable = errors.New("courier is not available")
+	// ErrNoCourierAvailable - для заказа не нашлось свободного курьера
+	ErrNoCourierAvailable = errors.New("no courier is available")
+	// ErrInvalidDelivery - заказ нельзя передать в доставку или статус доставки неверный
+	ErrInvalidDelivery = errors.New("invalid delivery")
+	// ErrDeliveryNotFound - доставка не найдена
+	ErrDeliveryNotFound = errors.New("delivery not found")
+	// ErrDeliveryAlreadyAssigned - у заказа уже есть курьер
+	ErrDeliveryAlreadyAssigned = errors.New("order is already assigned to a courier")
+)
+
+// Статусы курьера: на смене и готов взять заказ или не работает
+const (
+	CourierStatusAvailable = "available"
+	CourierStatusOffline   = "offline"
+)
+
+// Courier - курьер. Курьер на смене везет один заказ за раз;
+// последняя присланная точка хранится вместе с курьером.
+type Courier struct {
+	ID string `json:"id"`
+	// UserID - учетная запись, под которой курьер входит в приложение
+	UserID string `json:"user_id,omitempty"`
+	Name   string `json:"name"`
+	Phone  string `json:"phone"`
+	// Zone - код зоны доставки, в которой работает курьер; пусто - любая зона
+	Zone       string     `json:"zone,omitempty"`
+	Status     string     `json:"status"`
+	Location   *GeoPoint  `json:"location,omitempty"`
+	LocationAt *time.Time `json:"location_at,omitempty"`
+	// Busy - у курьера есть назначенная или везущаяся доставка
+	Busy      bool      `json:"busy"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func IsValidCourierStatus(s string) bool {
+	return s == CourierStatusAvailable || s == CourierStatusOffline
+}
+
+// HasFreshLocation сообщает, что курьер присылал координаты не раньше maxAge до момента at
+func (c Courier) HasFreshLocation(at time.Time, maxAge time.Duration) bool {
+	return c.Location != nil && c.LocationAt != nil && at.Sub(*c.LocationAt) <= maxAge
+}
+
+// CourierLocation - точка маршрута курьера
+type CourierLocation struct {
+	CourierID  string    `json:"courier_id"`
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// Статусы доставки. Переходы: assigned -> out_for_delivery -> delivered или failed;
+// assigned и out_for_delivery отменяются вместе с заказом.
+const (
+	DeliveryStatusAssigned       = "assigned"
+	DeliveryStatusOutForDelivery = "out_for_delivery"
+	DeliveryStatusDelivered      = "delivered"
+	DeliveryStatusFailed         = "failed"
+	DeliveryStatusCancelled      = "cancelled"
+)
+
+// Delivery - передача заказа курьеру и ход доставки
+type Delivery struct {
+	ID        string `json:"id"`
+	OrderID   string `json:"order_id"`
+	CourierID string `json:"courier_id"`
+	Status    string `json:"status"`
+	// ProofNote и ProofPhotoURL подтверждают вручение: кому отдан заказ, фото у двери
+	ProofNote     string     `json:"proof_note,omitempty"`
+	ProofPhotoURL string     `json:"proof_photo_url,omitempty"`
+	AssignedAt    time.Time  `json:"assigned_at"`
+	PickedUpAt    *time.Time `json:"picked_up_at,omitempty"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// IsActive сообщает, что заказ еще у курьера
+func (d Delivery) IsActive() bool {
+	return d.Status == DeliveryStatusAssigned || d.Status == DeliveryStatusOutForDelivery
+}
+
+// CanTransition проверяет переход доставки в статус to
+func (d Delivery) CanTransition(to string) bool {
+	switch d.Status {
+	case DeliveryStatusAssigned:
+		return to == DeliveryStatusOutForDelivery || to == DeliveryStatusFailed
+	case DeliveryStatusOutForDelivery:
+		return to == DeliveryStatusDelivered || to == DeliveryStatusFailed
+	default:
+		return false
+	}
+}
+
+// OrderStatusFor возвращает статус заказа, который задает доставка в статусе s.
+// Неудачная доставка возвращает заказ в ожидание, чтобы назначить другого курьера.
+func OrderStatusFor(s string) string {
+	switch s {
+	case DeliveryStatusOutForDelivery:
+		return OrderStatusOutForDelivery
+	case DeliveryStatusDelivered:
+		return OrderStatusDelivered
+	default:
+		return OrderStatusPending
+	}
+}
+
+// OrderTracking - что покупатель видит о доставке своего заказа
+type OrderTracking struct {
+	OrderID     string
+	OrderStatus string
+	Delivery    *Delivery
+	CourierName string
+	// CourierLocation показывается, только пока заказ в пути
+	CourierLocation   *GeoPoint
+	CourierLocationAt *time.Time
+	// EtaMinutes - оценка по прямой до адреса; 0, если оценить нельзя
+	EtaMinutes       int
+	DeliveryStartsAt *time.Time
+	DeliveryEndsAt   *time.Time
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrInvalidDeliverySlot - интервал доставки заполнен неверно
+	ErrInvalidDeliverySlot = errors.New("invalid delivery slot")
+	// ErrDeliverySlotNotFound - интервал доставки не найден
+	ErrDeliverySlotNotFound = errors.New("delivery slot not found")
+	// ErrDuplicateDeliverySlot - в зоне уже есть интервал с тем же началом
+	ErrDuplicateDeliverySlot = errors.New("delivery slot already exists")
+	// ErrDeliverySlotFull - все места интервала заняты или он уже начался
+	ErrDeliverySlotFull = errors.New("delivery slot is not available")
+	// ErrSlotReservationNotFound - бронь интервала не найдена или принадлежит другому покупателю
+	ErrSlotReservationNotFound = errors.New("delivery slot reservation not found")
+	// ErrSlotReservationExpired - бронь истекла или уже использована, интервал нужно выбрать заново
+	ErrSlotReservationExpired = errors.New("delivery slot reservation expired")
+)
+
+// Статусы брони интервала доставки
+const (
+	// SlotReservationHeld - место удерживается за покупателем до ExpiresAt, пока он оформляет заказ
+	SlotReservationHeld = "held"
+	// SlotReservationConfirmed - место занято заказом
+	SlotReservationConfirmed = "confirmed"
+	// SlotReservationReleased - бронь отменена покупателем или заказ отменен
+	SlotReservationReleased = "released"
+	// SlotReservationExpired - заказ не оформили вовремя
+	SlotReservationExpired = "expired"
+)
+
+// DeliverySlot - интервал доставки в зоне. Место занимают подтвержденные брони
+// и неистекшие брони оформляемых заказов.
+type DeliverySlot struct {
+	ID   string `json:"id"`
+	Zone string `json:"zone"`
+	// StartsAt и EndsAt - окно доставки, например 2024-05-10 10:00-12:00
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Capacity  int       `json:"capacity"`
+	Booked    int       `json:"booked"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Available - число свободных мест
+func (s DeliverySlot) Available() int {
+	if s.Booked >= s.Capacity {
+		return 0
+	}
+	return s.Capacity - s.Booked
+}
+
+// Date - день доставки в формате 2006-01-02
+func (s DeliverySlot) Date() string {
+	return s.StartsAt.Format("2006-01-02")
+}
+
+// SlotReservation - место в интервале доставки, занятое покупателем
+type SlotReservation struct {
+	ID      string `json:"id"`
+	SlotID  string `json:"slot_id"`
+	UserID  string `json:"user_id"`
+	OrderID string `json:"order_id,omitempty"`
+	Status  string `json:"status"`
+	// ExpiresAt - до какого момента бронь ждет заказ; у подтвержденной брони не действует
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IsActive сообщает, что бронь занимает место в интервале в момент at
+func (r SlotReservation) IsActive(at time.Time) bool {
+	return r.Status == SlotReservationConfirmed || (r.Status == SlotReservationHeld && at.Before(r.ExpiresAt))
+}
@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidDeliveryZone - зона доставки заполнена неверно
+	ErrInvalidDeliveryZone = errors.New("invalid delivery zone")
+	// ErrDeliveryZoneNotFound - зона доставки не найдена
+	ErrDeliveryZoneNotFound = errors.New("delivery zone not found")
+	// ErrDuplicateDeliveryZone - код зоны уже занят
+	ErrDuplicateDeliveryZone = errors.New("delivery zone code already exists")
+	// ErrAddressNotServed - адрес не входит ни в одну активную зону доставки
+	ErrAddressNotServed = errors.New("delivery is not available at this address")
+	// ErrBelowMinimumOrder - сумма товаров меньше минимального заказа зоны
+	ErrBelowMinimumOrder = errors.New("order is below the minimum amount for delivery")
+)
+
+// GeoPoint - вершина многоугольника зоны
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// DeliveryZone - район доставки со своими условиями. Адрес попадает в зону по координатам,
+// если у зоны есть многоугольник, или по почтовому индексу из PostalCodes.
+type DeliveryZone struct {
+	ID string `json:"id"`
+	// Code связывает зону с интервалами доставки (DeliverySlot.Zone)
+	Code        string     `json:"code"`
+	Name        string     `json:"name"`
+	Polygon     []GeoPoint `json:"polygon,omitempty"`
+	PostalCodes []string   `json:"postal_codes,omitempty"`
+	// Fee - стоимость доставки; при сумме товаров от FreeDeliveryThreshold доставка бесплатна (0 - порога нет).
+	// Заказ на сумму меньше MinOrderAmount в зону не доставляется.
+	Fee                   float64 `json:"fee"`
+	FreeDeliveryThreshold float64 `json:"free_delivery_threshold,omitempty"`
+	MinOrderAmount        float64 `json:"min_order_amount,omitempty"`
+	// Priority - порядок проверки пересекающихся зон, большие первыми
+	Priority  int       `json:"priority,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Contains сообщает, что адрес входит в зону: точка внутри многоугольника или индекс из списка
+func (z DeliveryZone) Contains(a Address) bool {
+	if len(z.Polygon) >= 3 && a.HasCoordinates() &&
+		PointInPolygon(GeoPoint{Latitude: *a.Latitude, Longitude: *a.Longitude}, z.Polygon) {
+		return true
+	}
+	postalCode := NormalizePostalCode(a.PostalCode)
+	if postalCode == "" {
+		return false
+	}
+	for _, code := range z.PostalCodes {
+		if NormalizePostalCode(code) == postalCode {
+			return true
+		}
+	}
+	return false
+}
+
+// FeeFor возвращает стоимость доставки заказа с суммой товаров subtotal
+func (z DeliveryZone) FeeFor(subtotal float64) float64 {
+	if z.FreeDeliveryThreshold > 0 && subtotal >= z.FreeDeliveryThreshold {
+		return 0
+	}
+	return z.Fee
+}
+
+// NormalizePostalCode убирает пробелы из индекса и приводит его к верхнему регистру
+func NormalizePostalCode(code string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+}
+
+// PointInPolygon проверяет лучом, лежит ли точка внутри многоугольника. Координаты
+// считаются плоскими: для районов города искажение проекции несущественно.
+// Точка на границе считается внутри, чтобы адрес на линии зоны обслуживался.
+func PointInPolygon(p GeoPoint, polygon []GeoPoint) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if onSegment(p, a, b) {
+			return true
+		}
+		if (a.Latitude > p.Latitude) != (b.Latitude > p.Latitude) &&
+			p.Longitude < (b.Longitude-a.Longitude)*(p.Latitude-a.Latitude)/(b.Latitude-a.Latitude)+a.Longitude {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// geoEpsilon - допуск попадания точки на границу зоны в градусах, около сантиметра
+const geoEpsilon = 1e-7
+
+// onSegment сообщает, что точка p лежит на отрезке ab с точностью geoEpsilon
+func onSegment(p, a, b GeoPoint) bool {
+	dLat, dLon := b.Latitude-a.Latitude, b.Longitude-a.Longitude
+	length := math.Hypot(dLat, dLon)
+	if length == 0 {
+		return math.Hypot(p.Latitude-a.Latitude, p.Longitude-a.Longitude) <= geoEpsilon
+	}
+	// Расстояние от точки до прямой ab
+	if math.Abs(dLon*(p.Latitude-a.Latitude)-dLat*(p.Longitude-a.Longitude))/length > geoEpsilon {
+		return false
+	}
+	return p.Latitude >= math.Min(a.Latitude, b.Latitude)-geoEpsilon &&
+		p.Latitude <= math.Max(a.Latitude, b.Latitude)+geoEpsilon &&
+		p.Longitude >= math.Min(a.Longitude, b.Longitude)-geoEpsilon &&
+		p.Longitude <= math.Max(a.Longitude, b.Longitude)+geoEpsilon
+}
@@ -0,0 +1,90 @@
+package domain
+
+import "testing"
+
+func TestPointInPolygon(t *testing.T) {
+	square := []GeoPoint{{0, 0}, {0, 10}, {10, 10}, {10, 0}}
+	// Г-образная зона: квадрат без правого верхнего угла
+	lShape := []GeoPoint{{0, 0}, {0, 10}, {5, 10}, {5, 5}, {10, 5}, {10, 0}}
+
+	tests := []struct {
+		name    string
+		point   GeoPoint
+		polygon []GeoPoint
+		want    bool
+	}{
+		{"inside", GeoPoint{5, 5}, square, true},
+		{"outside", GeoPoint{11, 5}, square, false},
+		{"outside level with an edge", GeoPoint{10, 12}, square, false},
+		{"on the left edge", GeoPoint{5, 0}, square, true},
+		{"on the right edge", GeoPoint{5, 10}, square, true},
+		{"on the top edge", GeoPoint{10, 5}, square, true},
+		{"on the bottom edge", GeoPoint{0, 5}, square, true},
+		{"on a vertex", GeoPoint{10, 10}, square, true},
+		{"just outside an edge", GeoPoint{5, 10.0001}, square, false},
+		{"inside a concave zone", GeoPoint{2, 7}, lShape, true},
+		{"in the notch of a concave zone", GeoPoint{7, 7}, lShape, false},
+		{"on the inner edge of a concave zone", GeoPoint{7, 5}, lShape, true},
+		{"level with the inner vertex", GeoPoint{5, 2}, lShape, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PointInPolygon(tt.point, tt.polygon); got != tt.want {
+				t.Errorf("PointInPolygon(%v) = %v, want %v", tt.point, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeliveryZoneContains(t *testing.T) {
+	lat, lon := 5.0, 5.0
+	outLat, outLon := 20.0, 20.0
+	zone := DeliveryZone{
+		Polygon:     []GeoPoint{{0, 0}, {0, 10}, {10, 10}, {10, 0}},
+		PostalCodes: []string{"050000"},
+	}
+
+	tests := []struct {
+		name    string
+		address Address
+		want    bool
+	}{
+		{"coordinates inside the polygon", Address{Latitude: &lat, Longitude: &lon}, true},
+		{"coordinates outside, postal code matches", Address{Latitude: &outLat, Longitude: &outLon, PostalCode: "050 000"}, true},
+		{"coordinates outside, other postal code", Address{Latitude: &outLat, Longitude: &outLon, PostalCode: "010000"}, false},
+		{"postal code only", Address{PostalCode: " 050000 "}, true},
+		{"empty address", Address{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := zone.Contains(tt.address); got != tt.want {
+				t.Errorf("Contains = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeliveryZoneFeeFor(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold float64
+		subtotal  float64
+		want      float64
+	}{
+		{"below the free delivery threshold", 5000, 4999.99, 500},
+		{"at the threshold", 5000, 5000, 0},
+		{"above the threshold", 5000, 7500, 0},
+		{"no threshold", 0, 100000, 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone := DeliveryZone{Fee: 500, FreeDeliveryThreshold: tt.threshold}
+			if got := zone.FeeFor(tt.subtotal); got != tt.want {
+				t.Errorf("FeeFor(%v) = %v, want %v", tt.subtotal, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Типы доменных событий; в NATS событие публикуется в тему foodstore.events.<тип>
+const (
+	EventOrderCreated       = "order.created"
+	EventOrderStatusChanged = "order.status_changed"
+	EventStockChanged       = "stock.changed"
+	EventUserRegistered     = "user.registered"
+)
+
+// Event - доменное событие из outbox. ID один и тот же при повторных доставках,
+// по нему потребители отбрасывают дубликаты.
+type Event struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	AggregateID string          `json:"aggregate_id"`
+	Payload     json.RawMessage `json:"payload"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+}
+
+// NewEvent создает событие с сериализованным payload; ID назначается при записи в outbox
+func NewEvent(eventType, aggregateID string, payload interface{}) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{
+		Type:        eventType,
+		AggregateID: aggregateID,
+		Payload:     data,
+		OccurredAt:  time.Now(),
+	}, nil
+}
+
+// Decode читает payload события в v
+func (e Event) Decode(v interface{}) error {
+	return json.Unmarshal(e.Payload, v)
+}
+
+// OrderCreatedEvent - заказ оформлен
+type OrderCreatedEvent struct {
+	OrderID     string  `json:"order_id"`
+	UserID      string  `json:"user_id"`
+	Status      string  `json:"status"`
+	TotalAmount float64 `json:"total_amount"`
+	LocationID  string  `json:"location_id,omitempty"`
+}
+
+// OrderStatusChangedEvent - заказ перешел из PreviousStatus в Status
+type OrderStatusChangedEvent struct {
+	OrderID        string `json:"order_id"`
+	UserID         string `json:"user_id"`
+	PreviousStatus string `json:"previous_status"`
+	Status         string `json:"status"`
+}
+
+// StockChangedEvent - остаток товара изменился на Quantity и стал Stock
+type StockChangedEvent struct {
+	ProductID   string `json:"product_id"`
+	LocationID  string `json:"location_id,omitempty"`
+	Movement    string `json:"movement"`
+	Quantity    int    `json:"quantity"`
+	Stock       int    `json:"stock"`
+	ReferenceID string `json:"reference_id,omitempty"`
+}
+
+// UserRegisteredEvent - пользователь зарегистрировался
+type UserRegisteredEvent struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// Типы точек хранения
+const (
+	LocationWarehouse = "warehouse"
+	LocationStore     = "store"
+)
+
+// Address - адрес точки хранения или доставки. Координаты необязательны,
+// но без них точка выбирается только по городу и индексу.
+type Address struct {
+	Street     string   `json:"street"`
+	City       string   `json:"city"`
+	PostalCode string   `json:"postal_code"`
+	Latitude   *float64 `json:"latitude,omitempty"`
+	Longitude  *float64 `json:"longitude,omitempty"`
+}
+
+func (a Address) HasCoordinates() bool {
+	return a.Latitude != nil && a.Longitude != nil
+}
+
+func (a Address) IsEmpty() bool {
+	return strings.TrimSpace(a.Street) == "" && strings.TrimSpace(a.City) == "" &&
+		strings.TrimSpace(a.PostalCode) == "" && !a.HasCoordinates()
+}
+
+// DistanceKm возвращает расстояние по дуге большого круга между двумя адресами с координатами
+func (a Address) DistanceKm(b Address) float64 {
+	const earthRadiusKm = 6371.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	lat1, lat2 := toRad(*a.Latitude), toRad(*b.Latitude)
+	dLat := lat2 - lat1
+	dLng := toRad(*b.Longitude - *a.Longitude)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
+
+// Location - склад или магазин, на котором хранится товар. Остатки партий
+// привязаны к точке; склад по умолчанию получает остатки, заведенные без указания точки.
+type Location struct {
+	ID        string
+	Code      string
+	Name      string
+	Type      string
+	Address   Address
+	IsDefault bool
+	Active    bool // неактивная точка не участвует в выполнении заказов
+	CreatedAt time.Time
+}
+
+func IsValidLocationType(t string) bool {
+	return t == LocationWarehouse || t == LocationStore
+}
+
+// LocationStock - доступный (непросроченный) остаток товара на точке
+type LocationStock struct {
+	LocationID string
+	ProductID  string
+	Available  int
+}
+
+// StockTransfer - перемещение товара между точками. Партии на точке-получателе
+// сохраняют номер лота и срок годности исходных партий.
+type StockTransfer struct {
+	ID             string
+	ProductID      string
+	FromLocationID string
+	ToLocationID   string
+	Quantity       int
+	Allocations    []BatchAllocation // списания с партий точки-отправителя
+}
@@ -0,0 +1,168 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidDietInfo - пищевая ценность, аллергены или пищевые метки заполнены неверно
+var ErrInvalidDietInfo = errors.New("invalid nutrition or allergen info")
+
+// NutritionFacts - пищевая ценность на 100 г, для наливных товаров - на 100 мл.
+// Масса нутриентов указывается в граммах.
+type NutritionFacts struct {
+	EnergyKcal    float64 `json:"energy_kcal"`
+	Proteins      float64 `json:"proteins"`
+	Fats          float64 `json:"fats"`
+	SaturatedFats float64 `json:"saturated_fats"`
+	Carbohydrates float64 `json:"carbohydrates"`
+	Sugars        float64 `json:"sugars"`
+	Fiber         float64 `json:"fiber"`
+	Salt          float64 `json:"salt"`
+}
+
+// Validate проверяет, что значения неотрицательны и помещаются в 100 г продукта
+func (n NutritionFacts) Validate() error {
+	if n.EnergyKcal < 0 || n.EnergyKcal > 900 {
+		return fmt.Errorf("%w: energy_kcal must be between 0 and 900 per 100 g", ErrInvalidDietInfo)
+	}
+	grams := []struct {
+		name  string
+		value float64
+	}{
+		{"proteins", n.Proteins}, {"fats", n.Fats}, {"saturated_fats", n.SaturatedFats},
+		{"carbohydrates", n.Carbohydrates}, {"sugars", n.Sugars}, {"fiber", n.Fiber}, {"salt", n.Salt},
+	}
+	for _, g := range grams {
+		if g.value < 0 || g.value > 100 {
+			return fmt.Errorf("%w: %s must be between 0 and 100 g", ErrInvalidDietInfo, g.name)
+		}
+	}
+	if n.SaturatedFats > n.Fats {
+		return fmt.Errorf("%w: saturated_fats cannot exceed fats", ErrInvalidDietInfo)
+	}
+	if n.Sugars > n.Carbohydrates {
+		return fmt.Errorf("%w: sugars cannot exceed carbohydrates", ErrInvalidDietInfo)
+	}
+	// Допуск на округление значений на упаковке
+	if n.Proteins+n.Fats+n.Carbohydrates+n.Fiber+n.Salt > 101 {
+		return fmt.Errorf("%w: nutrients add up to more than 100 g", ErrInvalidDietInfo)
+	}
+	return nil
+}
+
+// Аллергены, которые производитель обязан указывать на упаковке (14 групп по регламенту ЕС 1169/2011)
+const (
+	AllergenGluten      = "gluten"
+	AllergenCrustaceans = "crustaceans"
+	AllergenEggs        = "eggs"
+	AllergenFish        = "fish"
+	AllergenPeanuts     = "peanuts"
+	AllergenSoy         = "soy"
+	AllergenMilk        = "milk"
+	AllergenNuts        = "nuts"
+	AllergenCelery      = "celery"
+	AllergenMustard     = "mustard"
+	AllergenSesame      = "sesame"
+	AllergenSulphites   = "sulphites"
+	AllergenLupin       = "lupin"
+	AllergenMolluscs    = "molluscs"
+)
+
+var allergens = map[string]bool{
+	AllergenGluten: true, AllergenCrustaceans: true, AllergenEggs: true, AllergenFish: true,
+	AllergenPeanuts: true, AllergenSoy: true, AllergenMilk: true, AllergenNuts: true,
+	AllergenCelery: true, AllergenMustard: true, AllergenSesame: true, AllergenSulphites: true,
+	AllergenLupin: true, AllergenMolluscs: true,
+}
+
+// IsValidAllergen проверяет код аллергена
+func IsValidAllergen(code string) bool {
+	return allergens[code]
+}
+
+// Пищевые метки товара
+const (
+	DietVegan       = "vegan"
+	DietVegetarian  = "vegetarian"
+	DietGlutenFree  = "gluten_free"
+	DietLactoseFree = "lactose_free"
+	DietHalal       = "halal"
+	DietKosher      = "kosher"
+)
+
+var dietaryTags = map[string]bool{
+	DietVegan: true, DietVegetarian: true, DietGlutenFree: true,
+	DietLactoseFree: true, DietHalal: true, DietKosher: true,
+}
+
+// IsValidDietaryTag проверяет пищевую метку
+func IsValidDietaryTag(tag string) bool {
+	return dietaryTags[tag]
+}
+
+// dietConflicts - аллергены, несовместимые с меткой: веганский товар не может содержать молоко
+var dietConflicts = map[string][]string{
+	DietVegan:       {AllergenMilk, AllergenEggs, AllergenFish, AllergenCrustaceans, AllergenMolluscs},
+	DietVegetarian:  {AllergenFish, AllergenCrustaceans, AllergenMolluscs},
+	DietGlutenFree:  {AllergenGluten},
+	DietLactoseFree: {AllergenMilk},
+}
+
+// NormalizeAllergens приводит коды аллергенов к нижнему регистру, проверяет их,
+// убирает повторы и сортирует
+func NormalizeAllergens(codes []string) ([]string, error) {
+	return normalizeCodes(codes, IsValidAllergen, "allergen")
+}
+
+// NormalizeDietaryTags приводит пищевые метки к нижнему регистру, проверяет их,
+// убирает повторы и сортирует
+func NormalizeDietaryTags(tags []string) ([]string, error) {
+	return normalizeCodes(tags, IsValidDietaryTag, "dietary tag")
+}
+
+func normalizeCodes(codes []string, valid func(string) bool, kind string) ([]string, error) {
+	result := make([]string, 0, len(codes))
+	seen := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		code = strings.ToLower(strings.TrimSpace(code))
+		if !valid(code) {
+			return nil, fmt.Errorf("%w: unknown %s %q", ErrInvalidDietInfo, kind, code)
+		}
+		if !seen[code] {
+			seen[code] = true
+			result = append(result, code)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// CheckDietConflicts не дает пометить товар меткой, которой противоречат его аллергены
+func CheckDietConflicts(allergenCodes, tags []string) error {
+	contains := make(map[string]bool, len(allergenCodes))
+	for _, code := range allergenCodes {
+		contains[code] = true
+	}
+	for _, tag := range tags {
+		for _, code := range dietConflicts[tag] {
+			if contains[code] {
+				return fmt.Errorf("%w: product tagged %s cannot contain %s", ErrInvalidDietInfo, tag, code)
+			}
+		}
+	}
+	return nil
+}
+
+// NormalizeIngredients убирает пустые строки и пробелы по краям, сохраняя порядок состава
+func NormalizeIngredients(ingredients []string) []string {
+	result := make([]string, 0, len(ingredients))
+	for _, ingredient := range ingredients {
+		if ingredient = strings.TrimSpace(ingredient); ingredient != "" {
+			result = append(result, ingredient)
+		}
+	}
+	return result
+}
@@ -1,13 +1,26 @@
 package domain
 
 import (
+	"errors"
 	"time"
 )
 
+var (
+	// ErrOrderNotFound - заказ не найден
+	ErrOrderNotFound = errors.New("order not found")
+	// ErrInvalidOrderStatus - неизвестный статус заказа
+	ErrInvalidOrderStatus = errors.New("invalid order status")
+	// ErrInvalidOrderTransition - заказ нельзя перевести из текущего статуса в запрошенный
+	ErrInvalidOrderTransition = errors.New("invalid order status transition")
+)
+
 const (
 	OrderStatusPending   = "pending"
 	OrderStatusCompleted = "completed"
 	OrderStatusCancelled = "cancelled"
+	// OrderStatusOutForDelivery и OrderStatusDelivered выставляет доставка, см. OrderStatusFor
+	OrderStatusOutForDelivery = "out_for_delivery"
+	OrderStatusDelivered      = "delivered"
 )
 
 // Order представляет заказ в системе
@@ -18,6 +31,30 @@ type Order struct {
 	Status      string      `json:"status"`
 	CreatedAt   time.Time   `json:"created_at"`
 	Items       []OrderItem `json:"items,omitempty"`
+	// Точка, с которой собирается заказ
+	LocationID      string  `json:"location_id,omitempty"`
+	DeliveryAddress Address `json:"delivery_address"`
+	// Subtotal - стоимость товаров до скидок. TotalAmount = Subtotal - DiscountTotal + DeliveryFee,
+	// а в режиме TaxExclusive к нему добавляется TaxTotal.
+	Subtotal      float64 `json:"subtotal"`
+	DiscountTotal float64 `json:"discount_total"`
+	DeliveryFee   float64 `json:"delivery_fee"`
+	// DeliveryZone - код зоны, по условиям которой посчитана доставка
+	DeliveryZone string `json:"delivery_zone,omitempty"`
+	// Discounts - скидки на заказ целиком (бесплатная доставка); скидки на товары - в позициях
+	Discounts []OrderDiscount `json:"discounts,omitempty"`
+	// TaxMode - режим цен на момент заказа; пусто у заказов, оформленных до учета НДС.
+	// TaxTotal - весь НДС заказа: налог позиций и DeliveryTax.
+	TaxMode     string           `json:"tax_mode,omitempty"`
+	TaxTotal    float64          `json:"tax_total"`
+	DeliveryTax float64          `json:"delivery_tax"`
+	TaxSummary  []TaxSummaryLine `json:"tax_summary,omitempty"`
+	// DeliverySlotID - интервал доставки; окно DeliveryStartsAt-DeliveryEndsAt копируется из него при оформлении
+	DeliverySlotID   string     `json:"delivery_slot_id,omitempty"`
+	DeliveryStartsAt *time.Time `json:"delivery_starts_at,omitempty"`
+	DeliveryEndsAt   *time.Time `json:"delivery_ends_at,omitempty"`
+	// SlotReservationID - бронь интервала, которую подтверждает сохранение заказа
+	SlotReservationID string `json:"-"`
 }
 
 type OrderItem struct {
@@ -25,15 +62,38 @@ type OrderItem struct {
 	OrderID   string   `json:"order_id"`
 	ProductID string   `json:"product_id"`
 	Product   *Product `json:"product,omitempty"`
-	Quantity  int      `json:"quantity"`
-	Price     float64  `json:"price"`
+	// Quantity - количество в единицах товара Unit, для весовых товаров дробное
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+	Price    float64 `json:"price"`
+	// Discount - сумма скидок позиции, Discounts - по каким акциям они получены
+	Discount  float64         `json:"discount"`
+	Discounts []OrderDiscount `json:"discounts,omitempty"`
+	// TaxRate - ставка НДС в процентах, TaxAmount - налог со стоимости позиции после скидок
+	TaxRate   float64 `json:"tax_rate"`
+	TaxAmount float64 `json:"tax_amount"`
 }
 
 type OrderRequest struct {
 	UserID string             `json:"user_id"`
-	Items  []OrderItemRequest `json:"items"`
+	Items  []OrderLineRequest `json:"items"`
+	// StoreID - точка, выбранная покупателем (самовывоз); если пусто,
+	// точка подбирается по адресу доставки
+	StoreID         string  `json:"store_id,omitempty"`
+	DeliveryAddress Address `json:"delivery_address"`
+	// CouponCodes - купоны, введенные покупателем
+	CouponCodes []string `json:"coupon_codes,omitempty"`
+	// SlotReservationID - бронь интервала доставки, сделанная покупателем перед оформлением
+	SlotReservationID string `json:"slot_reservation_id,omitempty"`
+}
+
+// OrderLineRequest - позиция заказа от покупателя; количество в единицах товара: 2 штуки, 0.75 кг
+type OrderLineRequest struct {
+	ProductID string  `json:"product_id"`
+	Quantity  float64 `json:"quantity"`
 }
 
+// OrderItemRequest - списание товара под заказ в базовых единицах остатка (штуки, граммы, миллилитры)
 type OrderItemRequest struct {
 	ProductID string `json:"product_id"`
 	Quantity  int    `json:"quantity"`
@@ -42,3 +102,66 @@ type OrderItemRequest struct {
 type OrderStatusUpdateRequest struct {
 	Status string `json:"status"`
 }
+
+// Типы изменений заказа для подписчиков WatchOrder и WatchUserOrders
+const (
+	OrderUpdateCreated         = "created"
+	OrderUpdateStatusChanged   = "status_changed"
+	OrderUpdateCourierAssigned = "courier_assigned"
+)
+
+// OrderUpdate - изменение заказа; подписчик перечитывает заказ целиком
+type OrderUpdate struct {
+	Type       string
+	OrderID    string
+	UserID     string
+	Status     string
+	OccurredAt time.Time
+}
+
+// IsFinalOrderStatus сообщает, что заказ больше не изменится
+func IsFinalOrderStatus(status string) bool {
+	return status == OrderStatusCompleted || status == OrderStatusCancelled || status == OrderStatusDelivered
+}
+
+// IsValidOrderStatus проверяет статус заказа
+func IsValidOrderStatus(status string) bool {
+	switch status {
+	case OrderStatusPending, OrderStatusCompleted, OrderStatusCancelled, OrderStatusOutForDelivery, OrderStatusDelivered:
+		return true
+	}
+	return false
+}
+
+// manualOrderTransitions - смены статуса, которые делает сотрудник (PATCH /orders/{id}).
+// Заказ, который курьер уже забрал, нельзя ни отменить, ни завершить вручную: товар у курьера,
+// и вернуть его на склад отменой нельзя. Финальные статусы не меняются.
+var manualOrderTransitions = map[string][]string{
+	OrderStatusPending: {OrderStatusCompleted, OrderStatusCancelled},
+}
+
+// deliveryOrderTransitions - смены статуса, которые задает доставка (см. OrderStatusFor):
+// курьер забрал заказ, вручил его или не смог доставить и заказ вернулся в ожидание
+var deliveryOrderTransitions = map[string][]string{
+	OrderStatusPending:        {OrderStatusOutForDelivery},
+	OrderStatusOutForDelivery: {OrderStatusDelivered, OrderStatusPending},
+}
+
+// CanChangeOrderStatus сообщает, что сотрудник может перевести заказ из from в to
+func CanChangeOrderStatus(from, to string) bool {
+	return hasTransition(manualOrderTransitions, from, to)
+}
+
+// CanDeliveryChangeOrderStatus сообщает, что доставка может перевести заказ из from в to
+func CanDeliveryChangeOrderStatus(from, to string) bool {
+	return hasTransition(deliveryOrderTransitions, from, to)
+}
+
+func hasTransition(transitions map[string][]string, from, to string) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package domain
+
+import "testing"
+
+func TestOrderStatusTransitions(t *testing.T) {
+	tests := []struct {
+		from, to   string
+		manual     bool
+		byDelivery bool
+	}{
+		{OrderStatusPending, OrderStatusCompleted, true, false},
+		{OrderStatusPending, OrderStatusCancelled, true, false},
+		{OrderStatusPending, OrderStatusOutForDelivery, false, true},
+		{OrderStatusPending, OrderStatusDelivered, false, false},
+		{OrderStatusOutForDelivery, OrderStatusCancelled, false, false},
+		{OrderStatusOutForDelivery, OrderStatusCompleted, false, false},
+		{OrderStatusOutForDelivery, OrderStatusPending, false, true},
+		{OrderStatusOutForDelivery, OrderStatusDelivered, false, true},
+		{OrderStatusDelivered, OrderStatusCancelled, false, false},
+		{OrderStatusDelivered, OrderStatusCompleted, false, false},
+		{OrderStatusDelivered, OrderStatusPending, false, false},
+		{OrderStatusCancelled, OrderStatusPending, false, false},
+		{OrderStatusCancelled, OrderStatusOutForDelivery, false, false},
+		{OrderStatusCompleted, OrderStatusCancelled, false, false},
+	}
+
+	for _, tt := range tests {
+		if got := CanChangeOrderStatus(tt.from, tt.to); got != tt.manual {
+			t.Errorf("CanChangeOrderStatus(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.manual)
+		}
+		if got := CanDeliveryChangeOrderStatus(tt.from, tt.to); got != tt.byDelivery {
+			t.Errorf("CanDeliveryChangeOrderStatus(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.byDelivery)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrPriceNotFound - на запрошенный момент у товара еще не было цены
+	ErrPriceNotFound = errors.New("no price recorded at that time")
+	// ErrInvalidPriceSchedule - запланированная цена заполнена неверно
+	ErrInvalidPriceSchedule = errors.New("invalid price schedule")
+	// ErrPriceScheduleOverlap - период пересекается с другой запланированной ценой товара
+	ErrPriceScheduleOverlap = errors.New("price schedule overlaps another schedule")
+	// ErrPriceScheduleNotFound - запланированная цена не найдена или уже не в нужном статусе
+	ErrPriceScheduleNotFound = errors.New("price schedule not found")
+	// ErrPriceScheduleClosed - запланированная цена уже завершена или отменена
+	ErrPriceScheduleClosed = errors.New("price schedule is already completed or cancelled")
+)
+
+// Источники изменения цены в истории
+const (
+	PriceSourceInitial     = "initial"      // цена при создании товара
+	PriceSourceManual      = "manual"       // изменение карточки товара
+	PriceSourceImport      = "import"       // импорт каталога
+	PriceSourceScheduled   = "scheduled"    // начало запланированной цены
+	PriceSourceScheduleEnd = "schedule_end" // возврат цены после окончания запланированной
+)
+
+// PriceChange - запись истории цены: с момента EffectiveAt товар продавался по Price.
+// EffectiveAt - время фактической смены цены, а не время, на которое она была запланирована.
+type PriceChange struct {
+	ID          int64     `json:"id"`
+	ProductID   string    `json:"product_id"`
+	Price       float64   `json:"price"`
+	EffectiveAt time.Time `json:"effective_at"`
+	Source      string    `json:"source"`
+	Actor       string    `json:"actor,omitempty"`
+	ScheduleID  string    `json:"schedule_id,omitempty"`
+}
+
+// Статусы запланированной цены
+const (
+	PriceSchedulePending   = "pending"   // ждет начала
+	PriceScheduleActive    = "active"    // действует и будет отменена в EndsAt
+	PriceScheduleCompleted = "completed" // применена и, если был конец периода, возвращена
+	PriceScheduleCancelled = "cancelled"
+)
+
+// IsValidPriceScheduleStatus проверяет статус запланированной цены
+func IsValidPriceScheduleStatus(status string) bool {
+	switch status {
+	case PriceSchedulePending, PriceScheduleActive, PriceScheduleCompleted, PriceScheduleCancelled:
+		return true
+	}
+	return false
+}
+
+// PriceSchedule - цена, которая начнет действовать в StartsAt. Если задан EndsAt, в этот момент
+// возвращается цена, действовавшая до начала (PreviousPrice), - так оформляются акции.
+// Без EndsAt это отложенное постоянное изменение цены.
+type PriceSchedule struct {
+	ID            string     `json:"id"`
+	ProductID     string     `json:"product_id"`
+	Price         float64    `json:"price"`
+	StartsAt      time.Time  `json:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at,omitempty"`
+	Status        string     `json:"status"`
+	PreviousPrice *float64   `json:"previous_price,omitempty"`
+	CreatedBy     string     `json:"created_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// IsTemporary сообщает, что после окончания периода цена вернется к прежней
+func (s PriceSchedule) IsTemporary() bool {
+	return s.EndsAt != nil
+}
@@ -1,19 +1,209 @@
 package domain
 
+import (
+	"errors"
+	"time"
+)
+
+// ErrProductVersionConflict - товар изменили после того, как клиент его прочитал
+var ErrProductVersionConflict = errors.New("product was modified by someone else")
+
+// ErrDuplicateSKU - артикул уже занят другим товаром
+var ErrDuplicateSKU = errors.New("sku is already used by another product")
+
 type Product struct {
-	ID    string
-	Name  string
-	Price float64
-	Stock int
+	ID          string
+	SKU         string // артикул, уникален среди всех товаров, включая архивные
+	Name        string
+	Description string
+	Price       float64 // цена за единицу Unit
+	// Category - код категории (dairy, bakery); по нему настраиваются акции
+	Category  string
+	Stock     int // доступный остаток в базовых единицах: штуках, граммах или миллилитрах
+	CreatedAt time.Time
+	Images    []ProductImage
+	// Unit - единица измерения (UnitPiece, UnitKilogram, UnitLitre); задается при создании товара
+	Unit string
+	// Barcodes - штрихкоды EAN/UPC в канонической записи, уникальны среди всех товаров
+	Barcodes []string
+	// Nutrition - пищевая ценность на 100 г или 100 мл; nil, если не указана
+	Nutrition *NutritionFacts
+	// Ingredients - состав в порядке, указанном на упаковке
+	Ingredients []string
+	// Allergens - коды аллергенов (AllergenMilk, ...), DietaryTags - пищевые метки (DietVegan, ...)
+	Allergens   []string
+	DietaryTags []string
+	// Kind - ProductKindSimple или ProductKindBundle; задается при создании товара
+	Kind string
+	// ParentID и VariantName - родительский товар и название варианта ("0.5 л", "1 л");
+	// пусто у самостоятельного товара
+	ParentID    string
+	VariantName string
+	// Components - состав набора; только у ProductKindBundle
+	Components []BundleComponent
+	// Точка заказа: при доступном остатке не выше ReorderPoint создается алерт
+	// и предложение пополнить товар минимум на ReorderQuantity. 0 - не отслеживать.
+	ReorderPoint    int
+	ReorderQuantity int
+	// Version увеличивается при каждом изменении карточки товара
+	Version int64
+	// ArchivedAt - время архивации; архивный товар скрыт из каталога и не продается,
+	// но остается в истории заказов
+	ArchivedAt *time.Time
+}
+
+// IsArchived сообщает, что товар в архиве
+func (p Product) IsArchived() bool {
+	return p.ArchivedAt != nil
+}
+
+// ProductReferences - записи, которые ссылаются на товар и не дают удалить его окончательно.
+// Учитывается любая партия и любое движение, а не только текущий остаток: журнал остатков
+// хранится вместе с товаром и не удаляется.
+type ProductReferences struct {
+	OrderItems         int
+	PurchaseOrderLines int
+	StockBatches       int
+	StockMovements     int
+	BundleComponents   int // наборы, в состав которых входит товар
+}
+
+// Any сообщает, что на товар есть хотя бы одна ссылка
+func (r ProductReferences) Any() bool {
+	return r.HasHistory() || r.BundleComponents > 0
+}
+
+// HasHistory сообщает, что товар уже продавали, закупали или приходовали
+func (r ProductReferences) HasHistory() bool {
+	return r.OrderItems+r.PurchaseOrderLines+r.StockBatches+r.StockMovements > 0
+}
+
+// Поля товара, которые можно обновить по маске
+const (
+	ProductFieldName        = "name"
+	ProductFieldDescription = "description"
+	ProductFieldPrice       = "price"
+	ProductFieldStock       = "stock"
+	ProductFieldSKU         = "sku"
+	ProductFieldBarcodes    = "barcodes"
+	ProductFieldNutrition   = "nutrition"
+	ProductFieldIngredients = "ingredients"
+	ProductFieldAllergens   = "allergens"
+	ProductFieldDietaryTags = "dietary_tags"
+	ProductFieldParentID    = "parent_id"
+	ProductFieldVariantName = "variant_name"
+	ProductFieldComponents  = "components"
+	ProductFieldCategory    = "category"
+)
+
+// IsValidProductField проверяет путь в маске обновления товара
+func IsValidProductField(field string) bool {
+	switch field {
+	case ProductFieldName, ProductFieldDescription, ProductFieldPrice, ProductFieldStock, ProductFieldSKU, ProductFieldBarcodes,
+		ProductFieldNutrition, ProductFieldIngredients, ProductFieldAllergens, ProductFieldDietaryTags,
+		ProductFieldParentID, ProductFieldVariantName, ProductFieldComponents, ProductFieldCategory:
+		return true
+	}
+	return false
+}
+
+// ProductUpdate - изменение карточки товара. Обновляются только поля из Fields,
+// пустой список означает все поля. Version - версия, которую видел клиент.
+type ProductUpdate struct {
+	ID          string
+	Version     int64
+	Fields      []string
+	Name        string
+	Description string
+	Price       float64
+	Stock       int // доступный остаток без просроченных партий; разница оформляется на складе по умолчанию
+	SKU         string
+	Barcodes    []string
+	Nutrition   *NutritionFacts // nil очищает пищевую ценность
+	Ingredients []string
+	Allergens   []string
+	DietaryTags []string
+	ParentID    string // пусто отвязывает вариант от родителя
+	VariantName string
+	Components  []BundleComponent // только для наборов
+	Category    string
+	Actor       string // автор изменения для истории цен
+}
+
+// Has сообщает, входит ли поле в обновление
+func (u ProductUpdate) Has(field string) bool {
+	if len(u.Fields) == 0 {
+		return true
+	}
+	for _, f := range u.Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
 }
 
 type FilterParams struct {
 	Name     string
+	Query    string // полнотекстовый поиск по названию и описанию
 	MinPrice float64
 	MaxPrice float64
+	Archived bool // только архивные товары вместо активных
+	// Фильтры по аллергенам и пищевым меткам: With* - товар содержит все перечисленные,
+	// Without* - ни одного из перечисленных. WithoutAllergens выбирает товары "без молока и орехов".
+	WithAllergens      []string
+	WithoutAllergens   []string
+	WithDietaryTags    []string
+	WithoutDietaryTags []string
+	// ParentID - только варианты указанного товара
+	ParentID string
 }
 
 type PaginationParams struct {
 	Page    int
 	PerPage int
+	Cursor  string // непрозрачный курсор; если задан, Page игнорируется
+}
+
+// Варианты сортировки списка товаров
+const (
+	ProductSortRelevance  = "relevance"
+	ProductSortPriceAsc   = "price_asc"
+	ProductSortPriceDesc  = "price_desc"
+	ProductSortName       = "name"
+	ProductSortNewest     = "newest"
+	ProductSortPopularity = "popularity"
+)
+
+// IsValidProductSort проверяет вариант сортировки; пустая строка - сортировка по умолчанию
+func IsValidProductSort(sort string) bool {
+	switch sort {
+	case "", ProductSortRelevance, ProductSortPriceAsc, ProductSortPriceDesc,
+		ProductSortName, ProductSortNewest, ProductSortPopularity:
+		return true
+	}
+	return false
+}
+
+// ProductPage - страница результатов поиска товаров
+type ProductPage struct {
+	Products   []Product
+	Total      int    // общее число товаров; только для постраничного режима
+	NextCursor string // курсор следующей страницы, пустой на последней странице
+	Sort       string // фактически примененная сортировка
+}
+
+// ProductImage - изображение товара в нескольких размерах
+type ProductImage struct {
+	ID           string
+	ProductID    string
+	URL          string // основное изображение, вписанное в 1024x1024
+	ThumbnailURL string // миниатюра для списков, вписанная в 256x256
+	OriginalURL  string
+	Width        int // размеры оригинала
+	Height       int
+	Position     int
+	CreatedAt    time.Time
+	// Ключи в BlobStore, нужны для удаления файлов
+	Keys []string
 }
@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidPromotion - акция заполнена неверно
+	ErrInvalidPromotion = errors.New("invalid promotion")
+	// ErrPromotionNotFound - акция не найдена
+	ErrPromotionNotFound = errors.New("promotion not found")
+	// ErrDuplicateCoupon - код купона уже занят другой акцией
+	ErrDuplicateCoupon = errors.New("coupon code already exists")
+	// ErrInvalidCoupon - купон не существует, выключен или вне периода действия
+	ErrInvalidCoupon = errors.New("invalid coupon")
+	// ErrCouponNotApplicable - условия купона не выполнены или он не дает скидки на этот заказ
+	ErrCouponNotApplicable = errors.New("coupon is not applicable to this order")
+	// ErrPromotionLimitReached - исчерпан общий лимит использований акции или лимит покупателя
+	ErrPromotionLimitReached = errors.New("promotion usage limit reached")
+)
+
+// Типы акций
+const (
+	// PromotionPercentage - скидка Value процентов на подходящие позиции
+	PromotionPercentage = "percentage"
+	// PromotionFixedAmount - скидка Value на сумму подходящих позиций, распределяется по ним пропорционально
+	PromotionFixedAmount = "fixed_amount"
+	// PromotionBOGO - "купи BuyQuantity, получи GetQuantity бесплатно" для штучных товаров
+	PromotionBOGO = "bogo"
+	// PromotionFreeDelivery - бесплатная доставка при сумме заказа от MinSubtotal
+	PromotionFreeDelivery = "free_delivery"
+)
+
+// IsValidPromotionType проверяет тип акции
+func IsValidPromotionType(t string) bool {
+	switch t {
+	case PromotionPercentage, PromotionFixedAmount, PromotionBOGO, PromotionFreeDelivery:
+		return true
+	}
+	return false
+}
+
+// Promotion - правило скидки. Без купона акция применяется к каждому подходящему заказу,
+// с купоном - только когда покупатель ввел код.
+type Promotion struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Value - процент для percentage или сумма для fixed_amount
+	Value       float64 `json:"value"`
+	BuyQuantity int     `json:"buy_quantity,omitempty"`
+	GetQuantity int     `json:"get_quantity,omitempty"`
+	// MinSubtotal - минимальная сумма товаров в заказе до скидок
+	MinSubtotal float64 `json:"min_subtotal,omitempty"`
+	// ProductIDs и Categories ограничивают позиции, на которые действует скидка;
+	// если оба пусты, акция действует на весь заказ
+	ProductIDs []string `json:"product_ids,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+	// CouponCode - код купона в верхнем регистре; пусто у автоматической акции
+	CouponCode string `json:"coupon_code,omitempty"`
+	// UsageLimit - сколько раз акцию можно применить всего, PerUserLimit - одному покупателю; 0 - без ограничений.
+	// Использования по отмененным заказам не считаются.
+	UsageLimit   int `json:"usage_limit,omitempty"`
+	PerUserLimit int `json:"per_user_limit,omitempty"`
+	// StartsAt и EndsAt - период действия; nil - без ограничения
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+	// Stackable - скидку можно сложить с другими суммируемыми акциями. Несуммируемая акция
+	// применяется одна, если она выгоднее покупателю, чем все суммируемые вместе.
+	Stackable bool `json:"stackable"`
+	// Priority - порядок применения суммируемых акций, большие первыми
+	Priority  int       `json:"priority,omitempty"`
+	Active    bool      `json:"active"`
+	TimesUsed int       `json:"times_used"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IsCoupon сообщает, что акция применяется только по коду купона
+func (p Promotion) IsCoupon() bool {
+	return p.CouponCode != ""
+}
+
+// ValidAt сообщает, что акция включена и действует в момент at
+func (p Promotion) ValidAt(at time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && at.Before(*p.StartsAt) {
+		return false
+	}
+	return p.EndsAt == nil || at.Before(*p.EndsAt)
+}
+
+// Applies сообщает, что скидка акции действует на товар
+func (p Promotion) Applies(productID, category string) bool {
+	if len(p.ProductIDs) == 0 && len(p.Categories) == 0 {
+		return true
+	}
+	for _, id := range p.ProductIDs {
+		if id == productID {
+			return true
+		}
+	}
+	for _, c := range p.Categories {
+		if category != "" && c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeCouponCode приводит код купона к верхнему регистру без пробелов по краям
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// PromotionUsage - число использований акции по неотмененным заказам
+type PromotionUsage struct {
+	Total  int
+	ByUser int
+}
+
+// OrderDiscount - скидка, примененная к заказу. Скидка на позицию ссылается на нее через OrderItemID,
+// скидка на доставку - без позиции.
+type OrderDiscount struct {
+	ID          string  `json:"id"`
+	OrderItemID string  `json:"order_item_id,omitempty"`
+	PromotionID string  `json:"promotion_id"`
+	Name        string  `json:"name"`
+	CouponCode  string  `json:"coupon_code,omitempty"`
+	Amount      float64 `json:"amount"`
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrPurchaseOrderNotReceivable возвращается при приемке заказа поставщику,
+// который еще не отправлен, уже принят полностью или отменен
+var ErrPurchaseOrderNotReceivable = errors.New("purchase order cannot be received in its current status")
+
+// ErrOverReceipt возвращается, если по строке принимают больше, чем осталось получить
+var ErrOverReceipt = errors.New("received quantity exceeds ordered quantity")
+
+// Статусы заказа поставщику
+const (
+	PurchaseOrderDraft             = "draft"
+	PurchaseOrderSent              = "sent"
+	PurchaseOrderPartiallyReceived = "partially_received"
+	PurchaseOrderReceived          = "received"
+	PurchaseOrderCancelled         = "cancelled"
+)
+
+func IsValidPurchaseOrderStatus(s string) bool {
+	switch s {
+	case PurchaseOrderDraft, PurchaseOrderSent, PurchaseOrderPartiallyReceived,
+		PurchaseOrderReceived, PurchaseOrderCancelled:
+		return true
+	}
+	return false
+}
+
+// CanTransitionPurchaseOrder проверяет ручную смену статуса. Статусы partially_received
+// и received выставляются только приемкой товара.
+func CanTransitionPurchaseOrder(from, to string) bool {
+	switch to {
+	case PurchaseOrderSent:
+		return from == PurchaseOrderDraft
+	case PurchaseOrderCancelled:
+		return from == PurchaseOrderDraft || from == PurchaseOrderSent
+	}
+	return false
+}
+
+type Supplier struct {
+	ID        string
+	Name      string
+	Email     string
+	Phone     string
+	Address   string
+	CreatedAt time.Time
+}
+
+// PurchaseOrder - заказ поставщику на пополнение остатков точки LocationID
+type PurchaseOrder struct {
+	ID         string
+	SupplierID string
+	LocationID string
+	Status     string
+	ExpectedAt *time.Time // ожидаемая дата поставки
+	Notes      string
+	CreatedBy  string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	Lines      []PurchaseOrderLine
+}
+
+// Total возвращает сумму заказа по закупочным ценам
+func (po PurchaseOrder) Total() float64 {
+	var total float64
+	for _, l := range po.Lines {
+		total += l.UnitCost * float64(l.QuantityOrdered)
+	}
+	return total
+}
+
+type PurchaseOrderLine struct {
+	ID               string
+	PurchaseOrderID  string
+	ProductID        string
+	QuantityOrdered  int
+	QuantityReceived int
+	UnitCost         float64
+}
+
+func (l PurchaseOrderLine) Remaining() int {
+	return l.QuantityOrdered - l.QuantityReceived
+}
+
+// PurchaseReceipt - принятое по строке заказа количество. Каждая приемка заводится отдельной партией.
+type PurchaseReceipt struct {
+	LineID    string
+	Quantity  int
+	LotNumber string
+	ExpiresAt *time.Time
+}
+
+type PurchaseOrderFilter struct {
+	Status     string
+	SupplierID string
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// Статусы предложений на пополнение
+const (
+	ReorderSuggestionDraft     = "draft"
+	ReorderSuggestionOrdered   = "ordered"
+	ReorderSuggestionDismissed = "dismissed"
+)
+
+// IsValidReorderSuggestionStatus проверяет статус предложения на пополнение
+func IsValidReorderSuggestionStatus(status string) bool {
+	switch status {
+	case ReorderSuggestionDraft, ReorderSuggestionOrdered, ReorderSuggestionDismissed:
+		return true
+	}
+	return false
+}
+
+// ReorderPolicy - точка заказа товара и минимальная партия пополнения
+type ReorderPolicy struct {
+	ProductID       string `json:"product_id"`
+	ReorderPoint    int    `json:"reorder_point"`
+	ReorderQuantity int    `json:"reorder_quantity"`
+}
+
+// ReorderStatus - доступный остаток товара с заданной точкой заказа
+// и признак того, что по нему уже есть открытый алерт
+type ReorderStatus struct {
+	ProductID   string
+	ProductName string
+	Available   int
+	Policy      ReorderPolicy
+	AlertOpen   bool
+}
+
+// BelowReorderPoint сообщает, что остаток опустился до точки заказа
+func (s ReorderStatus) BelowReorderPoint() bool {
+	return s.Policy.ReorderPoint > 0 && s.Available <= s.Policy.ReorderPoint
+}
+
+// LowStockAlert - алерт о низком остатке. Алерт открывается, когда доступный остаток
+// опускается до точки заказа, и закрывается, когда остаток снова ее превышает.
+type LowStockAlert struct {
+	ID           string     `json:"id"`
+	ProductID    string     `json:"product_id"`
+	ProductName  string     `json:"product_name"`
+	Available    int        `json:"available"`
+	ReorderPoint int        `json:"reorder_point"`
+	TriggeredAt  time.Time  `json:"triggered_at"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+}
+
+// ReorderSuggestion - черновик пополнения, рассчитанный по скорости продаж
+type ReorderSuggestion struct {
+	ID                string    `json:"id"`
+	AlertID           string    `json:"alert_id"`
+	ProductID         string    `json:"product_id"`
+	ProductName       string    `json:"product_name"`
+	Available         int       `json:"available"`
+	ReorderPoint      int       `json:"reorder_point"`
+	ReorderQuantity   int       `json:"reorder_quantity"`
+	UnitsSold         int       `json:"units_sold"`
+	SalesWindowDays   int       `json:"sales_window_days"`
+	DailySales        float64   `json:"daily_sales"`
+	SuggestedQuantity int       `json:"suggested_quantity"`
+	Status            string    `json:"status"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// SuggestReorderQuantity рассчитывает объем пополнения: остаток должен покрыть продажи
+// за coverDays и снова оказаться выше точки заказа, но не меньше минимальной партии.
+func SuggestReorderQuantity(available int, policy ReorderPolicy, dailySales float64, coverDays int) int {
+	demand := int(math.Ceil(dailySales * float64(coverDays)))
+	quantity := demand + policy.ReorderPoint - available
+	if quantity < policy.ReorderQuantity {
+		quantity = policy.ReorderQuantity
+	}
+	if quantity < 1 {
+		quantity = 1
+	}
+	return quantity
+}
+
+// Типы уведомлений
+const (
+	NotificationLowStock = "low_stock"
+)
+
+// Notification - уведомление для администраторов. Payload сериализуется в JSON для вебхуков.
+type Notification struct {
+	Type    string      `json:"type"`
+	Subject string      `json:"subject"`
+	Message string      `json:"message"`
+	Payload interface{} `json:"payload,omitempty"`
+}
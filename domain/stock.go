@@ -0,0 +1,192 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrInsufficientStock возвращается, если непросроченных партий не хватает на списание
+var ErrInsufficientStock = errors.New("not enough stock")
+
+// Лоты, которые создаются автоматически, а не при приемке товара
+const (
+	LotInitial    = "INITIAL"    // остаток, указанный при создании товара
+	LotAdjustment = "ADJUSTMENT" // ручное увеличение остатка в админке
+)
+
+// StockBatch - партия товара. Остаток товара складывается из остатков его партий,
+// просроченные партии в доступный остаток не входят.
+type StockBatch struct {
+	ID              string
+	ProductID       string
+	LocationID      string
+	LotNumber       string
+	ReceivedAt      time.Time
+	ExpiresAt       *time.Time // nil для товаров без срока годности
+	Quantity        int        // текущий остаток партии
+	InitialQuantity int
+}
+
+// IsExpired сообщает, просрочена ли партия на дату now. В день окончания срока товар еще продается.
+func (b StockBatch) IsExpired(now time.Time) bool {
+	if b.ExpiresAt == nil {
+		return false
+	}
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, b.ExpiresAt.Location())
+	return b.ExpiresAt.Before(today)
+}
+
+// BatchAllocation - сколько единиц товара списано с партии под заказ
+type BatchAllocation struct {
+	BatchID    string
+	ProductID  string
+	LocationID string
+	LotNumber  string
+	Quantity   int
+	ExpiresAt  *time.Time
+}
+
+// SortFEFO упорядочивает партии для списания: сначала ближайший срок годности, партии без срока -
+// последними, при равных сроках - раньше принятые
+func SortFEFO(batches []StockBatch) {
+	sort.SliceStable(batches, func(i, j int) bool {
+		a, b := batches[i], batches[j]
+		switch {
+		case a.ExpiresAt == nil && b.ExpiresAt != nil:
+			return false
+		case a.ExpiresAt != nil && b.ExpiresAt == nil:
+			return true
+		case a.ExpiresAt != nil && !a.ExpiresAt.Equal(*b.ExpiresAt):
+			return a.ExpiresAt.Before(*b.ExpiresAt)
+		case !a.ReceivedAt.Equal(b.ReceivedAt):
+			return a.ReceivedAt.Before(b.ReceivedAt)
+		}
+		return a.ID < b.ID
+	})
+}
+
+// PlanFEFO распределяет quantity единиц по партиям в порядке SortFEFO, пропуская пустые
+// и просроченные на now партии. Если их не хватает, возвращает ErrInsufficientStock.
+func PlanFEFO(batches []StockBatch, quantity int, now time.Time) ([]BatchAllocation, error) {
+	ordered := append([]StockBatch(nil), batches...)
+	SortFEFO(ordered)
+
+	var allocations []BatchAllocation
+	remaining := quantity
+	for _, b := range ordered {
+		if remaining == 0 {
+			break
+		}
+		if b.Quantity <= 0 || b.IsExpired(now) {
+			continue
+		}
+		take := b.Quantity
+		if take > remaining {
+			take = remaining
+		}
+		allocations = append(allocations, BatchAllocation{
+			BatchID:    b.ID,
+			ProductID:  b.ProductID,
+			LocationID: b.LocationID,
+			LotNumber:  b.LotNumber,
+			Quantity:   take,
+			ExpiresAt:  b.ExpiresAt,
+		})
+		remaining -= take
+	}
+
+	if remaining > 0 {
+		return nil, ErrInsufficientStock
+	}
+	return allocations, nil
+}
+
+// ReturnAllocations возвращает списания на те же партии, с которых они были сделаны, даже если
+// партия с тех пор просрочилась. Возвращает партии с новыми остатками в исходном порядке.
+func ReturnAllocations(batches []StockBatch, allocations []BatchAllocation) ([]StockBatch, error) {
+	result := append([]StockBatch(nil), batches...)
+	index := make(map[string]int, len(result))
+	for i, b := range result {
+		index[b.ID] = i
+	}
+	for _, a := range allocations {
+		i, ok := index[a.BatchID]
+		if !ok {
+			return nil, fmt.Errorf("allocation refers to unknown batch %s", a.BatchID)
+		}
+		result[i].Quantity += a.Quantity
+	}
+	return result, nil
+}
+
+// Типы движений товара в журнале stock_movements
+const (
+	MovementOpeningBalance = "opening_balance" // остаток на момент появления журнала
+	MovementReceipt        = "receipt"
+	MovementOrder          = "order"
+	MovementCancelRestock  = "cancel_restock"
+	MovementAdjustment     = "adjustment"
+	MovementWriteOff       = "write_off"
+	MovementTransferOut    = "transfer_out"
+	MovementTransferIn     = "transfer_in"
+)
+
+// IsValidMovementType проверяет тип движения; пустая строка - любой тип
+func IsValidMovementType(t string) bool {
+	switch t {
+	case "", MovementOpeningBalance, MovementReceipt, MovementOrder,
+		MovementCancelRestock, MovementAdjustment, MovementWriteOff,
+		MovementTransferOut, MovementTransferIn:
+		return true
+	}
+	return false
+}
+
+// StockChange описывает, кто и по какому поводу меняет остаток
+type StockChange struct {
+	Actor       string
+	ReferenceID string // например, ID заказа
+	Reason      string
+}
+
+// StockMovement - запись журнала движения товара. Журнал только дополняется,
+// сумма Quantity по товару равна products.stock.
+type StockMovement struct {
+	ID           string
+	ProductID    string
+	LocationID   string
+	BatchID      string
+	Type         string
+	Quantity     int // положительное - приход, отрицательное - расход
+	BalanceAfter int // products.stock после движения
+	ReferenceID  string
+	Actor        string
+	Reason       string
+	CreatedAt    time.Time
+}
+
+type StockMovementFilter struct {
+	ProductID   string
+	LocationID  string
+	BatchID     string
+	Type        string
+	ReferenceID string
+	Actor       string
+	From        *time.Time
+	To          *time.Time
+}
+
+// StockReconciliation - сверка остатка товара с журналом и партиями
+type StockReconciliation struct {
+	ProductID     string
+	RecordedStock int // products.stock
+	LedgerStock   int // сумма движений по журналу
+	BatchStock    int // сумма остатков партий
+}
+
+func (r StockReconciliation) Consistent() bool {
+	return r.RecordedStock == r.LedgerStock && r.RecordedStock == r.BatchStock
+}
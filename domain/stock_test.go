@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPlanFEFO(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+	day := func(d int) *time.Time {
+		t := time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+	received := func(d int) time.Time { return time.Date(2024, 2, d, 0, 0, 0, 0, time.UTC) }
+
+	batches := []StockBatch{
+		{ID: "no-expiry", ReceivedAt: received(1), Quantity: 10},
+		{ID: "late", ReceivedAt: received(1), ExpiresAt: day(20), Quantity: 5},
+		{ID: "expired", ReceivedAt: received(1), ExpiresAt: day(9), Quantity: 7},
+		{ID: "today-newer", ReceivedAt: received(5), ExpiresAt: day(10), Quantity: 2},
+		{ID: "today-older", ReceivedAt: received(2), ExpiresAt: day(10), Quantity: 3},
+		{ID: "empty", ReceivedAt: received(1), ExpiresAt: day(11), Quantity: 0},
+	}
+
+	tests := []struct {
+		name     string
+		quantity int
+		want     map[string]int
+		order    []string
+		err      error
+	}{
+		{
+			name:     "earliest expiry first, older receipt breaks ties",
+			quantity: 4,
+			want:     map[string]int{"today-older": 3, "today-newer": 1},
+			order:    []string{"today-older", "today-newer"},
+		},
+		{
+			name:     "partial allocation takes the rest of the last batch",
+			quantity: 7,
+			want:     map[string]int{"today-older": 3, "today-newer": 2, "late": 2},
+			order:    []string{"today-older", "today-newer", "late"},
+		},
+		{
+			name:     "batches without expiry go last",
+			quantity: 12,
+			want:     map[string]int{"today-older": 3, "today-newer": 2, "late": 5, "no-expiry": 2},
+			order:    []string{"today-older", "today-newer", "late", "no-expiry"},
+		},
+		{
+			name:     "expired batches are not available",
+			quantity: 21,
+			err:      ErrInsufficientStock,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocations, err := PlanFEFO(batches, tt.quantity, now)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+
+			got := make(map[string]int)
+			var order []string
+			for _, a := range allocations {
+				got[a.BatchID] = a.Quantity
+				order = append(order, a.BatchID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allocations = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(order, tt.order) {
+				t.Errorf("order = %v, want %v", order, tt.order)
+			}
+		})
+	}
+
+	if batches[0].ID != "no-expiry" || batches[3].Quantity != 2 {
+		t.Errorf("PlanFEFO modified its input: %+v", batches)
+	}
+}
+
+func TestReturnAllocations(t *testing.T) {
+	now := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	soon := now.AddDate(0, 0, 1)
+	later := now.AddDate(0, 0, 30)
+	batches := []StockBatch{
+		{ID: "a", ExpiresAt: &soon, Quantity: 2, InitialQuantity: 2},
+		{ID: "b", ExpiresAt: &later, Quantity: 6, InitialQuantity: 6},
+		{ID: "c", Quantity: 4, InitialQuantity: 4},
+	}
+
+	allocations, err := PlanFEFO(batches, 5, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := append([]StockBatch(nil), batches...)
+	for _, a := range allocations {
+		for i := range after {
+			if after[i].ID == a.BatchID {
+				after[i].Quantity -= a.Quantity
+			}
+		}
+	}
+
+	// К отмене партия a уже просрочена, но списанное все равно возвращается на нее
+	restocked, err := ReturnAllocations(after, allocations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, b := range restocked {
+		if b.ID != batches[i].ID || b.Quantity != batches[i].Quantity {
+			t.Errorf("batch %s: quantity = %d, want %d", b.ID, b.Quantity, batches[i].Quantity)
+		}
+	}
+
+	if _, err := ReturnAllocations(after, []BatchAllocation{{BatchID: "missing", Quantity: 1}}); err == nil {
+		t.Error("allocation of an unknown batch was accepted")
+	}
+}
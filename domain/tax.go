@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidTaxPolicy - неверный режим налога или ставка НДС
+var ErrInvalidTaxPolicy = errors.New("invalid tax policy")
+
+// Режимы цен
+const (
+	// TaxInclusive - цена товара уже включает НДС, налог выделяется из стоимости позиции
+	TaxInclusive = "inclusive"
+	// TaxExclusive - цена товара указана без НДС, налог добавляется к итогу заказа
+	TaxExclusive = "exclusive"
+)
+
+// DefaultVATRate - стандартная ставка НДС в процентах
+const DefaultVATRate = 12
+
+// IsValidTaxMode проверяет режим цен
+func IsValidTaxMode(mode string) bool {
+	return mode == TaxInclusive || mode == TaxExclusive
+}
+
+// TaxPolicy - режим цен и ставки НДС в процентах. Категории без своей ставки
+// (непродовольственные товары и товары без категории) облагаются по StandardRate.
+type TaxPolicy struct {
+	Mode          string
+	StandardRate  float64
+	CategoryRates map[string]float64
+}
+
+// RateFor возвращает ставку НДС для категории товара
+func (p TaxPolicy) RateFor(category string) float64 {
+	if rate, ok := p.CategoryRates[category]; ok {
+		return rate
+	}
+	return p.StandardRate
+}
+
+// Validate проверяет режим и ставки: от 0 до 100 процентов
+func (p TaxPolicy) Validate() error {
+	if !IsValidTaxMode(p.Mode) {
+		return fmt.Errorf("%w: unknown mode %q", ErrInvalidTaxPolicy, p.Mode)
+	}
+	if p.StandardRate < 0 || p.StandardRate > 100 {
+		return fmt.Errorf("%w: standard rate must be between 0 and 100", ErrInvalidTaxPolicy)
+	}
+	for category, rate := range p.CategoryRates {
+		if rate < 0 || rate > 100 {
+			return fmt.Errorf("%w: rate for %q must be between 0 and 100", ErrInvalidTaxPolicy, category)
+		}
+	}
+	return nil
+}
+
+// ParseCategoryRates разбирает ставки категорий вида "dairy=5,bakery=5,produce=0"
+func ParseCategoryRates(value string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		category, rate, ok := strings.Cut(pair, "=")
+		category = NormalizeCategory(category)
+		if !ok || category == "" || !IsValidCategory(category) {
+			return nil, fmt.Errorf("%w: expected category=rate, got %q", ErrInvalidTaxPolicy, pair)
+		}
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(rate), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid rate for %q", ErrInvalidTaxPolicy, category)
+		}
+		rates[category] = parsed
+	}
+	return rates, nil
+}
+
+// TaxSummaryLine - итог НДС заказа по одной ставке. NetAmount - стоимость без налога,
+// стоимость с налогом равна NetAmount + TaxAmount в любом режиме цен.
+type TaxSummaryLine struct {
+	Rate      float64 `json:"rate"`
+	NetAmount float64 `json:"net_amount"`
+	TaxAmount float64 `json:"tax_amount"`
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrInvalidQuantity - количество не подходит для единицы измерения товара
+var ErrInvalidQuantity = errors.New("invalid quantity")
+
+// Единицы измерения товаров. Цена указывается за единицу (штуку, килограмм, литр),
+// а остаток хранится в целых базовых единицах: штуках, граммах или миллилитрах.
+const (
+	UnitPiece    = "piece"
+	UnitKilogram = "kg"
+	UnitLitre    = "litre"
+)
+
+// IsValidUnit проверяет единицу измерения
+func IsValidUnit(unit string) bool {
+	return unit == UnitPiece || unit == UnitKilogram || unit == UnitLitre
+}
+
+// UnitScale - сколько базовых единиц остатка в одной единице товара
+func UnitScale(unit string) int {
+	switch unit {
+	case UnitKilogram, UnitLitre:
+		return 1000
+	}
+	return 1
+}
+
+// IsFractionalUnit сообщает, что товар продается дробным количеством (на вес или на разлив)
+func IsFractionalUnit(unit string) bool {
+	return UnitScale(unit) > 1
+}
+
+// ToBaseUnits переводит количество в единицах товара в базовые единицы остатка:
+// 0.75 кг -> 750 г. Штучный товар продается только целыми штуками,
+// весовой и наливной - с точностью до грамма и миллилитра.
+func ToBaseUnits(quantity float64, unit string) (int, error) {
+	if quantity <= 0 || math.IsNaN(quantity) || math.IsInf(quantity, 0) {
+		return 0, fmt.Errorf("%w: quantity must be greater than zero", ErrInvalidQuantity)
+	}
+
+	scaled := quantity * float64(UnitScale(unit))
+	base := math.Round(scaled)
+	// Допуск на погрешность двоичного представления: 0.3 * 1000 = 299.99999999999994
+	if math.Abs(scaled-base) > 1e-6 {
+		if IsFractionalUnit(unit) {
+			return 0, fmt.Errorf("%w: %g %s is more precise than 0.001", ErrInvalidQuantity, quantity, unit)
+		}
+		return 0, fmt.Errorf("%w: %g is not a whole number of pieces", ErrInvalidQuantity, quantity)
+	}
+	if base > math.MaxInt32 {
+		return 0, fmt.Errorf("%w: quantity is too large", ErrInvalidQuantity)
+	}
+	return int(base), nil
+}
+
+// FromBaseUnits переводит базовые единицы остатка в единицы товара: 750 г -> 0.75 кг
+func FromBaseUnits(base int, unit string) float64 {
+	return float64(base) / float64(UnitScale(unit))
+}
@@ -11,10 +11,17 @@ type User struct {
 	Email     string    `json:"email"`
 	FullName  string    `json:"full_name"`
 	Password  string    `json:"-"` // Не возвращаем пароль в JSON
+	Role      string    `json:"role"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// Роли пользователей
+const (
+	UserRoleCustomer = "customer"
+	UserRoleAdmin    = "admin"
+)
+
 // UserRepository представляет интерфейс репозитория для работы с пользователями
 type UserRepository interface {
 	Create(user User) error
@@ -4,12 +4,16 @@ import (
 	"FoodStore-AdvProg2/domain"
 	"FoodStore-AdvProg2/usecase"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
 )
 
+// legacyActor - инициатор изменений остатка в журнале для старого REST API без авторизации
+const legacyActor = "legacy-api"
+
 type ProductHandler struct {
 	UC *usecase.ProductUseCase
 }
@@ -31,7 +35,7 @@ func (h *ProductHandler) parseID(r *http.Request) string {
 func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var p domain.Product
 	_ = json.NewDecoder(r.Body).Decode(&p)
-	if err := h.UC.Create(p); err != nil {
+	if err := h.UC.Create(p, legacyActor); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -50,15 +54,42 @@ func (h *ProductHandler) Get(w http.ResponseWriter, r *http.Request) {
 func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
 	var p domain.Product
 	_ = json.NewDecoder(r.Body).Decode(&p)
-	if err := h.UC.Update(h.parseID(r), p); err != nil {
+	update := domain.ProductUpdate{
+		ID:          h.parseID(r),
+		Version:     p.Version,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       p.Price,
+		Stock:       p.Stock,
+		SKU:         p.SKU,
+	}
+	err := h.UC.Update(update, legacyActor)
+	switch {
+	case errors.Is(err, domain.ErrProductVersionConflict), errors.Is(err, domain.ErrDuplicateSKU):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, usecase.ErrInvalidProduct):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, usecase.ErrProductNotFound):
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	case err != nil:
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	h.respondJSON(w, p, http.StatusOK)
+
+	updated, err := h.UC.GetByID(update.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.respondJSON(w, updated, http.StatusOK)
 }
 
 func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	if err := h.UC.Delete(h.parseID(r)); err != nil {
+	// Товар архивируется: на него могут ссылаться заказы
+	if err := h.UC.Archive(h.parseID(r)); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
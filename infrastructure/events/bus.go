@@ -0,0 +1,35 @@
+package events
+
+import (
+	"fmt"
+
+	"FoodStore-AdvProg2/infrastructure/postgres"
+	"FoodStore-AdvProg2/repository"
+)
+
+// Брокеры доменных событий для Open
+const (
+	BusPostgres = "postgres"
+	BusNATS     = "nats"
+)
+
+// Open создает брокер kind: postgres (LISTEN/NOTIFY, по умолчанию) или nats по адресу natsURL;
+// natsCAFile - PEM с корневыми сертификатами для TLS, пусто - системные
+func Open(kind, natsURL, natsCAFile, clientName string) (repository.EventBus, error) {
+	switch kind {
+	case "", BusPostgres:
+		return postgres.NewPostgresEventBus(), nil
+	case BusNATS:
+		tlsConfig, err := NATSTLSConfig(natsCAFile)
+		if err != nil {
+			return nil, err
+		}
+		bus, err := NewNATSEventBus(natsURL, clientName, tlsConfig)
+		if err != nil {
+			return nil, err
+		}
+		return bus, nil
+	default:
+		return nil, fmt.Errorf("unknown event bus %q", kind)
+	}
+}
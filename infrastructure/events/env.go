@@ -0,0 +1,62 @@
+package events
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"FoodStore-AdvProg2/infrastructure/postgres"
+	"FoodStore-AdvProg2/repository"
+	"FoodStore-AdvProg2/usecase"
+)
+
+// Настройки событий сервисов по умолчанию
+const (
+	DefaultNATSURL = "nats://localhost:4222"
+	// DefaultOutboxRelayInterval - период повторной проверки outbox, если relay не разбудило уведомление
+	DefaultOutboxRelayInterval = 5 * time.Second
+)
+
+// OpenFromEnv открывает брокер доменных событий сервиса clientName из EVENT_BUS (postgres по умолчанию, nats)
+// и NATS_URL (NATS_CA_FILE - корневые сертификаты для TLS). EVENT_BUS=off отключает события в сервисе:
+// возвращается nil без ошибки.
+func OpenFromEnv(clientName string) (repository.EventBus, error) {
+	kind := os.Getenv("EVENT_BUS")
+	if kind == "off" {
+		return nil, nil
+	}
+	natsURL := os.Getenv("NATS_URL")
+	if natsURL == "" {
+		natsURL = DefaultNATSURL
+	}
+	return Open(kind, natsURL, os.Getenv("NATS_CA_FILE"), clientName)
+}
+
+// RunOutboxRelay отправляет события из outbox в bus до отмены ctx. Период проверки задает
+// OUTBOX_RELAY_INTERVAL (0 отключает relay), срок хранения отправленных событий - OUTBOX_RETENTION.
+func RunOutboxRelay(ctx context.Context, bus repository.EventBus) {
+	interval := DurationFromEnv("OUTBOX_RELAY_INTERVAL", DefaultOutboxRelayInterval)
+	if bus == nil || interval == 0 {
+		return
+	}
+
+	relay := usecase.NewOutboxRelay(postgres.NewOutboxPostgresRepo(), bus)
+	relay.Retention = DurationFromEnv("OUTBOX_RETENTION", usecase.DefaultOutboxRetention)
+	relay.Run(ctx, interval, postgres.ListenOutbox(ctx))
+}
+
+// DurationFromEnv читает период из переменной окружения name (например, 5s);
+// пустое, некорректное или отрицательное значение заменяется на fallback
+func DurationFromEnv(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		log.Printf("Invalid %s %q, using %s", name, value, fallback)
+		return fallback
+	}
+	return d
+}
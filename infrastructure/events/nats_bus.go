@@ -0,0 +1,524 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+)
+
+// natsSubjectPrefix - события публикуются в темы foodstore.events.<тип события>
+const natsSubjectPrefix = "foodstore.events."
+
+const (
+	natsDefaultPort  = "4222"
+	natsDialTimeout  = 5 * time.Second
+	natsFlushTimeout = 5 * time.Second
+	natsMaxBackoff   = 30 * time.Second
+)
+
+var (
+	errNATSNotConnected = errors.New("nats: not connected")
+	errNATSClosed       = errors.New("nats: connection closed")
+)
+
+// NATSEventBus - адаптер NATS на текстовом протоколе сервера (CONNECT, PUB, SUB, MSG, PING).
+// Publish ждет PONG на следующий за PUB PING: значит, сервер принял сообщение.
+// Подписчики одного потребителя входят в одну queue group, и событие получает один из них.
+// После обрыва соединения адаптер переподключается и восстанавливает подписки.
+// Соединение переводится на TLS, если сервер этого требует или адрес задан схемой tls://.
+type NATSEventBus struct {
+	addr string
+	host string
+	auth natsConnect
+	// secure - TLS обязателен, даже если сервер его только предлагает
+	secure    bool
+	tlsConfig *tls.Config
+
+	mu     sync.Mutex
+	conn   net.Conn
+	writer *bufio.Writer
+	// pongs - ожидающие PONG в порядке отправки PING; nil - публикация уже получила -ERR,
+	// но ее PONG еще придет и должен занять свое место в очереди
+	pongs   []chan error
+	subs    map[int]*natsSubscription
+	nextSID int
+	closed  bool
+}
+
+// natsSubscription - подписка с собственной очередью сообщений. readLoop только кладет
+// сообщения в очередь, а обрабатывает их горутина Subscribe: медленный обработчик не должен
+// останавливать чтение, иначе вместе с сообщениями встанут и PONG, которых ждут публикации.
+type natsSubscription struct {
+	subject string
+	queue   string
+
+	mu      sync.Mutex
+	pending [][]byte
+	// ready сигналит, что в pending появились сообщения
+	ready chan struct{}
+}
+
+// push ставит сообщение в очередь подписки, не дожидаясь обработчика
+func (s *natsSubscription) push(data []byte) {
+	s.mu.Lock()
+	s.pending = append(s.pending, data)
+	s.mu.Unlock()
+
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+// take забирает все сообщения из очереди
+func (s *natsSubscription) take() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msgs := s.pending
+	s.pending = nil
+	return msgs
+}
+
+// natsConnect - параметры команды CONNECT
+type natsConnect struct {
+	Verbose   bool   `json:"verbose"`
+	Pedantic  bool   `json:"pedantic"`
+	Lang      string `json:"lang"`
+	Version   string `json:"version"`
+	Protocol  int    `json:"protocol"`
+	Name      string `json:"name,omitempty"`
+	User      string `json:"user,omitempty"`
+	Pass      string `json:"pass,omitempty"`
+	AuthToken string `json:"auth_token,omitempty"`
+}
+
+// NewNATSEventBus подключается к серверу rawURL (nats://[user:pass@]host[:port]
+// или nats://token@host; tls:// вместо nats:// требует TLS); name - имя клиента в мониторинге NATS.
+// tlsConfig задает проверку сертификата сервера, nil - системные корневые сертификаты.
+func NewNATSEventBus(rawURL, name string, tlsConfig *tls.Config) (*NATSEventBus, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("nats: invalid url: %w", err)
+	}
+	if u.Scheme != "nats" && u.Scheme != "tls" {
+		return nil, fmt.Errorf("nats: unsupported scheme %q", u.Scheme)
+	}
+
+	b := &NATSEventBus{
+		addr:      u.Host,
+		host:      u.Hostname(),
+		auth:      natsConnect{Lang: "go", Version: "0", Protocol: 1, Name: name},
+		secure:    u.Scheme == "tls",
+		tlsConfig: tlsConfig,
+		subs:      make(map[int]*natsSubscription),
+	}
+	if u.Port() == "" {
+		b.addr = net.JoinHostPort(u.Hostname(), natsDefaultPort)
+	}
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			b.auth.User, b.auth.Pass = u.User.Username(), pass
+		} else {
+			b.auth.AuthToken = u.User.Username()
+		}
+	}
+
+	if err := b.connect(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// NATSTLSConfig возвращает настройки TLS с корневыми сертификатами из PEM-файла caFile;
+// пустой caFile - nil, то есть системные корневые сертификаты
+func NATSTLSConfig(caFile string) (*tls.Config, error) {
+	if caFile == "" {
+		return nil, nil
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("nats: read CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("nats: no certificates in %s", caFile)
+	}
+	return &tls.Config{RootCAs: pool}, nil
+}
+
+func (b *NATSEventBus) Publish(ctx context.Context, event domain.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	pong := make(chan error, 1)
+	b.mu.Lock()
+	if b.conn == nil {
+		b.mu.Unlock()
+		return errNATSNotConnected
+	}
+	conn := b.conn
+	fmt.Fprintf(b.writer, "PUB %s%s %d\r\n", natsSubjectPrefix, event.Type, len(data))
+	b.writer.Write(data)
+	b.writer.WriteString("\r\nPING\r\n")
+	err = b.writer.Flush()
+	if err == nil {
+		b.pongs = append(b.pongs, pong)
+	}
+	b.mu.Unlock()
+	if err != nil {
+		b.disconnected(conn, err)
+		return err
+	}
+
+	timer := time.NewTimer(natsFlushTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-pong:
+		return err
+	case <-timer.C:
+		return errors.New("nats: publish timed out")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Subscribe получает все события; подписчики с одним consumer делят поток между собой
+func (b *NATSEventBus) Subscribe(ctx context.Context, consumer string, handle func(domain.Event)) error {
+	sub := &natsSubscription{
+		subject: natsSubjectPrefix + ">",
+		queue:   consumer,
+		ready:   make(chan struct{}, 1),
+	}
+
+	b.mu.Lock()
+	b.nextSID++
+	sid := b.nextSID
+	b.subs[sid] = sub
+	if b.conn != nil {
+		// При ошибке записи подписка восстановится после переподключения
+		b.writeSub(sid, sub)
+		b.writer.Flush()
+	}
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		delete(b.subs, sid)
+		if b.conn != nil {
+			fmt.Fprintf(b.writer, "UNSUB %d\r\n", sid)
+			b.writer.Flush()
+		}
+		b.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-sub.ready:
+			for _, data := range sub.take() {
+				if ctx.Err() != nil {
+					return nil
+				}
+				var event domain.Event
+				if err := json.Unmarshal(data, &event); err != nil {
+					log.Printf("Invalid NATS event message: %v", err)
+					continue
+				}
+				handle(event)
+			}
+		}
+	}
+}
+
+func (b *NATSEventBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	b.failPongs(errNATSClosed)
+	if b.conn == nil {
+		return nil
+	}
+	b.writer.Flush()
+	err := b.conn.Close()
+	b.conn = nil
+	return err
+}
+
+// connect устанавливает соединение, восстанавливает подписки и запускает чтение
+func (b *NATSEventBus) connect() error {
+	raw, err := net.DialTimeout("tcp", b.addr, natsDialTimeout)
+	if err != nil {
+		return err
+	}
+
+	conn, reader, writer, err := b.handshake(raw)
+	if err != nil {
+		raw.Close()
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		conn.Close()
+		return errNATSClosed
+	}
+	b.conn, b.writer = conn, writer
+	for sid, sub := range b.subs {
+		b.writeSub(sid, sub)
+	}
+	if err := writer.Flush(); err != nil {
+		b.conn = nil
+		conn.Close()
+		return err
+	}
+
+	go b.readLoop(conn, reader)
+	return nil
+}
+
+// handshake читает INFO, при необходимости переводит соединение на TLS, отправляет CONNECT
+// и ждет PONG - сервер принял параметры. Возвращает соединение, через которое дальше идет обмен.
+func (b *NATSEventBus) handshake(raw net.Conn) (net.Conn, *bufio.Reader, *bufio.Writer, error) {
+	raw.SetDeadline(time.Now().Add(natsDialTimeout))
+	defer raw.SetDeadline(time.Time{})
+
+	conn := net.Conn(raw)
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+
+	line, err := readLine(reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return nil, nil, nil, fmt.Errorf("nats: unexpected greeting %q", line)
+	}
+	var info struct {
+		TLSRequired  bool `json:"tls_required"`
+		TLSAvailable bool `json:"tls_available"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "INFO ")), &info); err != nil {
+		return nil, nil, nil, fmt.Errorf("nats: malformed INFO: %w", err)
+	}
+
+	// Сервер шлет INFO открытым текстом, дальше обмен идет поверх TLS
+	if info.TLSRequired || b.secure {
+		if !info.TLSRequired && !info.TLSAvailable {
+			return nil, nil, nil, errors.New("nats: server does not support TLS")
+		}
+		tlsConn := tls.Client(raw, b.clientTLSConfig())
+		if err := tlsConn.Handshake(); err != nil {
+			return nil, nil, nil, fmt.Errorf("nats: TLS handshake: %w", err)
+		}
+		conn = tlsConn
+		reader = bufio.NewReader(conn)
+		writer = bufio.NewWriter(conn)
+	}
+
+	options, err := json.Marshal(b.auth)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	fmt.Fprintf(writer, "CONNECT %s\r\nPING\r\n", options)
+	if err := writer.Flush(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	for {
+		line, err := readLine(reader)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		switch {
+		case line == "PONG":
+			return conn, reader, writer, nil
+		case line == "PING":
+			writer.WriteString("PONG\r\n")
+			if err := writer.Flush(); err != nil {
+				return nil, nil, nil, err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return nil, nil, nil, fmt.Errorf("nats: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+// clientTLSConfig дополняет настройки TLS именем сервера из адреса
+func (b *NATSEventBus) clientTLSConfig() *tls.Config {
+	config := &tls.Config{}
+	if b.tlsConfig != nil {
+		config = b.tlsConfig.Clone()
+	}
+	if config.ServerName == "" {
+		config.ServerName = b.host
+	}
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+	return config
+}
+
+func (b *NATSEventBus) writeSub(sid int, sub *natsSubscription) {
+	fmt.Fprintf(b.writer, "SUB %s %s %d\r\n", sub.subject, sub.queue, sid)
+}
+
+// readLoop разбирает команды сервера, пока соединение живо
+func (b *NATSEventBus) readLoop(conn net.Conn, reader *bufio.Reader) {
+	for {
+		line, err := readLine(reader)
+		if err != nil {
+			b.disconnected(conn, err)
+			return
+		}
+
+		switch {
+		case strings.HasPrefix(line, "MSG "):
+			if err := b.deliver(line, reader); err != nil {
+				b.disconnected(conn, err)
+				return
+			}
+		case line == "PING":
+			b.mu.Lock()
+			if b.conn == conn {
+				b.writer.WriteString("PONG\r\n")
+				b.writer.Flush()
+			}
+			b.mu.Unlock()
+		case line == "PONG":
+			b.mu.Lock()
+			if len(b.pongs) > 0 {
+				if b.pongs[0] != nil {
+					b.pongs[0] <- nil
+				}
+				b.pongs = b.pongs[1:]
+			}
+			b.mu.Unlock()
+		case strings.HasPrefix(line, "-ERR"):
+			// Сервер отвечает -ERR на команды по порядку, поэтому ошибка относится к самой старой
+			// неподтвержденной публикации. PONG на ее PING все равно придет, поэтому место в очереди остается.
+			cause := fmt.Errorf("nats: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+			log.Printf("NATS error: %v", cause)
+			b.mu.Lock()
+			for i, pong := range b.pongs {
+				if pong != nil {
+					pong <- cause
+					b.pongs[i] = nil
+					break
+				}
+			}
+			b.mu.Unlock()
+		}
+	}
+}
+
+// deliver читает тело MSG <subject> <sid> [reply-to] <size> и ставит его в очередь подписки
+func (b *NATSEventBus) deliver(line string, reader *bufio.Reader) error {
+	fields := strings.Fields(line)
+	if len(fields) != 4 && len(fields) != 5 {
+		return fmt.Errorf("nats: malformed %q", line)
+	}
+	sid, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return fmt.Errorf("nats: malformed %q", line)
+	}
+	size, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil || size < 0 {
+		return fmt.Errorf("nats: malformed %q", line)
+	}
+
+	data := make([]byte, size+2)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	sub := b.subs[sid]
+	b.mu.Unlock()
+	if sub != nil {
+		sub.push(data[:size])
+	}
+	return nil
+}
+
+// disconnected закрывает оборвавшееся соединение, отменяет ожидание PONG и запускает переподключение
+func (b *NATSEventBus) disconnected(conn net.Conn, cause error) {
+	b.mu.Lock()
+	if b.conn != conn {
+		b.mu.Unlock()
+		return
+	}
+	conn.Close()
+	b.conn = nil
+	b.failPongs(errNATSNotConnected)
+	closed := b.closed
+	b.mu.Unlock()
+
+	if closed {
+		return
+	}
+	log.Printf("NATS connection lost: %v", cause)
+	go b.reconnect()
+}
+
+// failPongs завершает все ожидающие публикации ошибкой err. Вызывается под b.mu.
+func (b *NATSEventBus) failPongs(err error) {
+	for _, pong := range b.pongs {
+		if pong != nil {
+			pong <- err
+		}
+	}
+	b.pongs = nil
+}
+
+func (b *NATSEventBus) reconnect() {
+	backoff := time.Second
+	for {
+		time.Sleep(backoff)
+
+		b.mu.Lock()
+		closed := b.closed
+		b.mu.Unlock()
+		if closed {
+			return
+		}
+
+		err := b.connect()
+		if err == nil {
+			log.Printf("NATS connection restored")
+			return
+		}
+		log.Printf("NATS reconnect failed, retrying in %s: %v", backoff, err)
+		if backoff < natsMaxBackoff {
+			backoff *= 2
+		}
+	}
+}
+
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,429 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+)
+
+// fakeNATSServer - минимальный сервер NATS для тестов: INFO, PING/PONG, SUB, PUB и MSG.
+// Queue group не учитываются: сообщение получают все подписки.
+type fakeNATSServer struct {
+	listener net.Listener
+	info     string
+	// tlsConfig задан - после INFO соединение переводится на TLS
+	tlsConfig *tls.Config
+
+	mu    sync.Mutex
+	conns []*fakeNATSConn
+	// rejectSubject - публикации в эту тему отклоняются через -ERR, как при нехватке прав
+	rejectSubject string
+	// silent - сервер не отвечает на PING, публикации остаются неподтвержденными
+	silent bool
+}
+
+type fakeNATSConn struct {
+	conn net.Conn
+
+	mu     sync.Mutex
+	writer *bufio.Writer
+	sids   []string
+}
+
+func (c *fakeNATSConn) write(format string, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(c.writer, format, args...)
+	c.writer.Flush()
+}
+
+func startFakeNATS(t *testing.T, info string, tlsConfig *tls.Config) *fakeNATSServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeNATSServer{listener: listener, info: info, tlsConfig: tlsConfig}
+	t.Cleanup(func() {
+		listener.Close()
+		s.dropConnections()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeNATSServer) url(scheme string) string {
+	return scheme + "://" + s.listener.Addr().String()
+}
+
+func (s *fakeNATSServer) serve(raw net.Conn) {
+	defer raw.Close()
+	fmt.Fprintf(raw, "INFO %s\r\n", s.info)
+
+	conn := raw
+	if s.tlsConfig != nil {
+		tlsConn := tls.Server(raw, s.tlsConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			return
+		}
+		conn = tlsConn
+	}
+	c := &fakeNATSConn{conn: conn, writer: bufio.NewWriter(conn)}
+	s.mu.Lock()
+	s.conns = append(s.conns, c)
+	s.mu.Unlock()
+
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := readLine(reader)
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		s.mu.Lock()
+		rejectSubject, silent := s.rejectSubject, s.silent
+		s.mu.Unlock()
+
+		switch fields[0] {
+		case "PING":
+			if !silent {
+				c.write("PONG\r\n")
+			}
+		case "SUB":
+			c.mu.Lock()
+			c.sids = append(c.sids, fields[len(fields)-1])
+			c.mu.Unlock()
+		case "PUB":
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+			data := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, data); err != nil {
+				return
+			}
+			if fields[1] == rejectSubject {
+				// Настоящий сервер после такой ошибки не закрывает соединение и отвечает на следующий PING
+				c.write("-ERR 'Permissions Violation for Publish to %s'\r\n", fields[1])
+				continue
+			}
+			s.publish(fields[1], data[:size])
+		}
+	}
+}
+
+func (s *fakeNATSServer) publish(subject string, data []byte) {
+	s.mu.Lock()
+	conns := append([]*fakeNATSConn(nil), s.conns...)
+	s.mu.Unlock()
+
+	for _, c := range conns {
+		c.mu.Lock()
+		sids := append([]string(nil), c.sids...)
+		c.mu.Unlock()
+		for _, sid := range sids {
+			c.write("MSG %s %s %d\r\n%s\r\n", subject, sid, len(data), data)
+		}
+	}
+}
+
+// subscriptions возвращает число подписок на живых соединениях
+func (s *fakeNATSServer) subscriptions() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for _, c := range s.conns {
+		c.mu.Lock()
+		n += len(c.sids)
+		c.mu.Unlock()
+	}
+	return n
+}
+
+func (s *fakeNATSServer) waitSubscriptions(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for s.subscriptions() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("server has %d subscription(s), want %d", s.subscriptions(), n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// dropConnections обрывает все соединения, как при перезапуске сервера
+func (s *fakeNATSServer) dropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.conns {
+		c.conn.Close()
+	}
+	s.conns = nil
+}
+
+func publishEvent(t *testing.T, bus *NATSEventBus, id string) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := bus.Publish(ctx, domain.Event{ID: id, Type: "order.created"}); err != nil {
+		t.Fatalf("publish %s: %v", id, err)
+	}
+}
+
+func receiveEvent(t *testing.T, received <-chan domain.Event) domain.Event {
+	t.Helper()
+	select {
+	case event := <-received:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not delivered")
+		return domain.Event{}
+	}
+}
+
+func TestNATSEventBusSlowSubscriber(t *testing.T) {
+	server := startFakeNATS(t, `{"server_id":"test"}`, nil)
+	bus, err := NewNATSEventBus(server.url("nats"), "test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bus.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	release := make(chan struct{})
+	received := make(chan domain.Event, 1000)
+	go bus.Subscribe(ctx, "test", func(event domain.Event) {
+		<-release
+		received <- event
+	})
+	server.waitSubscriptions(t, 1)
+
+	// Обработчик стоит, а публикации все равно подтверждаются: чтение соединения не блокируется
+	const count = 500
+	for i := 0; i < count; i++ {
+		publishEvent(t, bus, strconv.Itoa(i))
+	}
+
+	close(release)
+	for i := 0; i < count; i++ {
+		if event := receiveEvent(t, received); event.ID != strconv.Itoa(i) {
+			t.Fatalf("event %d: got ID %s, events must keep their order", i, event.ID)
+		}
+	}
+}
+
+func TestNATSEventBusResubscribesAfterReconnect(t *testing.T) {
+	server := startFakeNATS(t, `{"server_id":"test"}`, nil)
+	bus, err := NewNATSEventBus(server.url("nats"), "test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bus.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	received := make(chan domain.Event, 10)
+	go bus.Subscribe(ctx, "test", func(event domain.Event) { received <- event })
+	server.waitSubscriptions(t, 1)
+
+	server.dropConnections()
+	server.waitSubscriptions(t, 1)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		err := bus.Publish(ctx, domain.Event{ID: "after-reconnect", Type: "order.created"})
+		cancel()
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("publish after reconnect: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if event := receiveEvent(t, received); event.ID != "after-reconnect" {
+		t.Errorf("got event %s, want after-reconnect", event.ID)
+	}
+}
+
+// selfSignedTLS возвращает настройки сервера с сертификатом для 127.0.0.1 и клиента, который ему доверяет
+func selfSignedTLS(t *testing.T) (server, client *tls.Config) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	server = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	return server, &tls.Config{RootCAs: pool}
+}
+
+func TestNATSEventBusTLS(t *testing.T) {
+	serverTLS, clientTLS := selfSignedTLS(t)
+
+	tests := []struct {
+		name      string
+		serverTLS bool
+		scheme    string
+		tlsConfig *tls.Config
+		wantErr   bool
+	}{
+		{"server requires TLS", true, "nats", clientTLS, false},
+		{"tls scheme", true, "tls", clientTLS, false},
+		{"untrusted certificate", true, "nats", nil, true},
+		{"tls scheme, server without TLS", false, "tls", clientTLS, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startFakeNATS(t, `{"server_id":"test"}`, nil)
+			if tt.serverTLS {
+				server = startFakeNATS(t, `{"server_id":"test","tls_required":true}`, serverTLS)
+			}
+			bus, err := NewNATSEventBus(server.url(tt.scheme), "test", tt.tlsConfig)
+			if tt.wantErr {
+				if err == nil {
+					bus.Close()
+					t.Fatal("connected, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer bus.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			received := make(chan domain.Event, 1)
+			go bus.Subscribe(ctx, "test", func(event domain.Event) { received <- event })
+			server.waitSubscriptions(t, 1)
+
+			publishEvent(t, bus, "secure")
+			if event := receiveEvent(t, received); event.ID != "secure" {
+				t.Errorf("got event %s, want secure", event.ID)
+			}
+		})
+	}
+}
+
+func TestNATSEventBusRejectedPublish(t *testing.T) {
+	server := startFakeNATS(t, `{"server_id":"test"}`, nil)
+	server.mu.Lock()
+	server.rejectSubject = natsSubjectPrefix + "order.forbidden"
+	server.mu.Unlock()
+	bus, err := NewNATSEventBus(server.url("nats"), "test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bus.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	received := make(chan domain.Event, 10)
+	go bus.Subscribe(ctx, "test", func(event domain.Event) { received <- event })
+	server.waitSubscriptions(t, 1)
+
+	publishCtx, publishCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer publishCancel()
+	err = bus.Publish(publishCtx, domain.Event{ID: "forbidden", Type: "order.forbidden"})
+	if err == nil || !strings.Contains(err.Error(), "Permissions Violation") {
+		t.Fatalf("publish to a forbidden subject: err = %v, want the server's -ERR", err)
+	}
+
+	// PONG отклоненной публикации не должен подтвердить следующую
+	publishEvent(t, bus, "allowed")
+	if event := receiveEvent(t, received); event.ID != "allowed" {
+		t.Errorf("got event %s, want allowed", event.ID)
+	}
+}
+
+func TestNATSEventBusCloseFailsPendingPublish(t *testing.T) {
+	server := startFakeNATS(t, `{"server_id":"test"}`, nil)
+	bus, err := NewNATSEventBus(server.url("nats"), "test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.mu.Lock()
+	server.silent = true
+	server.mu.Unlock()
+
+	result := make(chan error, 1)
+	go func() {
+		result <- bus.Publish(context.Background(), domain.Event{ID: "pending", Type: "order.created"})
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		bus.mu.Lock()
+		pending := len(bus.pongs)
+		bus.mu.Unlock()
+		if pending > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("publish did not wait for PONG")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	bus.Close()
+
+	// Без Close публикация ждала бы natsFlushTimeout
+	select {
+	case err := <-result:
+		if !errors.Is(err, errNATSClosed) {
+			t.Errorf("err = %v, want %v", err, errNATSClosed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("pending publish was not failed by Close")
+	}
+}
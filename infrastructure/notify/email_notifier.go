@@ -0,0 +1,50 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"FoodStore-AdvProg2/domain"
+)
+
+// EmailNotifier отправляет уведомление письмом через SMTP сервер
+type EmailNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+}
+
+// NewEmailNotifier создает уведомитель для SMTP сервера addr (host:port).
+// Без username письма отправляются без аутентификации.
+func NewEmailNotifier(addr, username, password, from string, to []string) *EmailNotifier {
+	var auth smtp.Auth
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &EmailNotifier{addr: addr, auth: auth, from: from, to: to}
+}
+
+func (n *EmailNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", n.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", notification.Subject)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(notification.Message)
+	msg.WriteString("\r\n")
+
+	if err := smtp.SendMail(n.addr, n.auth, n.from, n.to, []byte(msg.String())); err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	return nil
+}
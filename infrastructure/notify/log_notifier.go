@@ -0,0 +1,26 @@
+package notify
+
+import (
+	"context"
+	"log"
+
+	"FoodStore-AdvProg2/domain"
+)
+
+// LogNotifier пишет уведомления в лог сервиса
+type LogNotifier struct {
+	logger *log.Logger
+}
+
+// NewLogNotifier создает уведомитель; nil означает стандартный логгер
+func NewLogNotifier(logger *log.Logger) *LogNotifier {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	n.logger.Printf("[%s] %s: %s", notification.Type, notification.Subject, notification.Message)
+	return nil
+}
@@ -0,0 +1,29 @@
+package notify
+
+import (
+	"context"
+	"errors"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/repository"
+)
+
+// MultiNotifier рассылает уведомление по всем каналам. Ошибка одного канала
+// не мешает доставке в остальные; ошибки возвращаются вместе.
+type MultiNotifier struct {
+	notifiers []repository.Notifier
+}
+
+func NewMultiNotifier(notifiers ...repository.Notifier) *MultiNotifier {
+	return &MultiNotifier{notifiers: notifiers}
+}
+
+func (n *MultiNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	var errs []error
+	for _, notifier := range n.notifiers {
+		if err := notifier.Notify(ctx, notification); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,201 @@
+package postgres
+
+import (
+	"FoodStore-AdvProg2/domain"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type CartPostgresRepo struct{}
+
+func NewCartPostgresRepo() *CartPostgresRepo {
+	return &CartPostgresRepo{}
+}
+
+// Create удаляет истекшую корзину покупателя, чтобы вместо нее завести новую
+func (r *CartPostgresRepo) Create(cart domain.Cart) (string, error) {
+	ctx := context.Background()
+	if cart.UserID == "" {
+		_, err := DB.Exec(ctx, `
+            INSERT INTO carts (id, created_at, updated_at, expires_at) VALUES ($1, $2, $2, $3)`,
+			cart.ID, cart.CreatedAt, cart.ExpiresAt)
+		return cart.ID, err
+	}
+
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM carts WHERE user_id = $1 AND expires_at <= $2`, cart.UserID, cart.CreatedAt); err != nil {
+		return "", err
+	}
+	_, err = tx.Exec(ctx, `
+        INSERT INTO carts (id, user_id, created_at, updated_at, expires_at) VALUES ($1, $2, $3, $3, $4)
+        ON CONFLICT (user_id) DO NOTHING`,
+		cart.ID, cart.UserID, cart.CreatedAt, cart.ExpiresAt)
+	if err != nil {
+		return "", err
+	}
+	var id string
+	if err := tx.QueryRow(ctx, `SELECT id FROM carts WHERE user_id = $1`, cart.UserID).Scan(&id); err != nil {
+		return "", err
+	}
+	return id, tx.Commit(ctx)
+}
+
+func (r *CartPostgresRepo) FindByID(id string) (domain.Cart, error) {
+	return r.findCart(`id = $1`, id)
+}
+
+func (r *CartPostgresRepo) FindByUserID(userID string) (domain.Cart, error) {
+	return r.findCart(`user_id = $1`, userID)
+}
+
+func (r *CartPostgresRepo) findCart(condition string, arg string) (domain.Cart, error) {
+	var cart domain.Cart
+	err := DB.QueryRow(context.Background(), `
+        SELECT id, COALESCE(user_id, ''), created_at, updated_at, expires_at FROM carts
+        WHERE `+condition+` AND expires_at > now()`, arg).
+		Scan(&cart.ID, &cart.UserID, &cart.CreatedAt, &cart.UpdatedAt, &cart.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return cart, domain.ErrCartNotFound
+	}
+	if err != nil {
+		return cart, err
+	}
+
+	rows, err := DB.Query(context.Background(), `
+        SELECT product_id, quantity::float8, price, added_at FROM cart_items
+        WHERE cart_id = $1 ORDER BY added_at, product_id`, cart.ID)
+	if err != nil {
+		return cart, err
+	}
+	defer rows.Close()
+
+	cart.Items = []domain.CartItem{}
+	for rows.Next() {
+		var item domain.CartItem
+		if err := rows.Scan(&item.ProductID, &item.Quantity, &item.Price, &item.AddedAt); err != nil {
+			return cart, err
+		}
+		cart.Items = append(cart.Items, item)
+	}
+	return cart, rows.Err()
+}
+
+// touchCart продлевает срок корзины в транзакции tx; domain.ErrCartNotFound, если корзины нет или она истекла
+func touchCart(ctx context.Context, tx pgx.Tx, cartID string, expiresAt time.Time) error {
+	tag, err := tx.Exec(ctx, `
+        UPDATE carts SET updated_at = now(), expires_at = $2 WHERE id = $1 AND expires_at > now()`,
+		cartID, expiresAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrCartNotFound
+	}
+	return nil
+}
+
+func (r *CartPostgresRepo) SetItem(cartID string, item domain.CartItem, expiresAt time.Time) error {
+	ctx := context.Background()
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := touchCart(ctx, tx, cartID, expiresAt); err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+        INSERT INTO cart_items (cart_id, product_id, quantity, price, added_at) VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (cart_id, product_id) DO UPDATE SET quantity = EXCLUDED.quantity, price = EXCLUDED.price`,
+		cartID, item.ProductID, item.Quantity, item.Price, item.AddedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *CartPostgresRepo) RemoveItem(cartID, productID string, expiresAt time.Time) error {
+	ctx := context.Background()
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := touchCart(ctx, tx, cartID, expiresAt); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM cart_items WHERE cart_id = $1 AND product_id = $2`, cartID, productID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Merge оставляет у совпадающих товаров цену гостевой корзины: ее покупатель видел последней
+func (r *CartPostgresRepo) Merge(guestCartID, userCartID string, expiresAt time.Time) error {
+	ctx := context.Background()
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := touchCart(ctx, tx, userCartID, expiresAt); err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+        INSERT INTO cart_items (cart_id, product_id, quantity, price, added_at)
+        SELECT $2, product_id, quantity, price, added_at FROM cart_items WHERE cart_id = $1
+        ON CONFLICT (cart_id, product_id) DO UPDATE
+        SET quantity = cart_items.quantity + EXCLUDED.quantity, price = EXCLUDED.price`,
+		guestCartID, userCartID)
+	if err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx, `DELETE FROM carts WHERE id = $1 AND user_id IS NULL AND expires_at > now()`, guestCartID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrCartNotFound
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *CartPostgresRepo) BeginCheckout(cartID string, staleAfter time.Duration) (bool, error) {
+	tag, err := DB.Exec(context.Background(), `
+        UPDATE carts SET checkout_started_at = now()
+        WHERE id = $1 AND (checkout_started_at IS NULL OR checkout_started_at < now() - $2::interval)`,
+		cartID, staleAfter)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *CartPostgresRepo) EndCheckout(cartID string) error {
+	_, err := DB.Exec(context.Background(), `UPDATE carts SET checkout_started_at = NULL WHERE id = $1`, cartID)
+	return err
+}
+
+func (r *CartPostgresRepo) Delete(cartID string) error {
+	_, err := DB.Exec(context.Background(), `DELETE FROM carts WHERE id = $1`, cartID)
+	return err
+}
+
+func (r *CartPostgresRepo) DeleteExpired(now time.Time) (int, error) {
+	tag, err := DB.Exec(context.Background(), `DELETE FROM carts WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,251 @@
+package postgres
+
+import (
+	"FoodStore-AdvProg2/domain"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type DeliveryPostgresRepo struct{}
+
+func NewDeliveryPostgresRepo() *DeliveryPostgresRepo {
+	return &DeliveryPostgresRepo{}
+}
+
+// activeDeliveryStatuses - статусы доставки, пока заказ у курьера
+const activeDeliveryStatuses = `('assigned', 'out_for_delivery')`
+
+const courierColumns = `c.id, c.user_id, c.name, c.phone, c.zone, c.status, c.latitude, c.longitude, c.location_at,
+        EXISTS (SELECT 1 FROM deliveries d WHERE d.courier_id = c.id AND d.status IN ` + activeDeliveryStatuses + `),
+        c.created_at`
+
+func scanCourier(row pgx.Row) (domain.Courier, error) {
+	var c domain.Courier
+	var latitude, longitude *float64
+	err := row.Scan(&c.ID, &c.UserID, &c.Name, &c.Phone, &c.Zone, &c.Status, &latitude, &longitude, &c.LocationAt,
+		&c.Busy, &c.CreatedAt)
+	if err != nil {
+		return c, err
+	}
+	if latitude != nil && longitude != nil {
+		c.Location = &domain.GeoPoint{Latitude: *latitude, Longitude: *longitude}
+	}
+	return c, nil
+}
+
+const deliveryColumns = `id, order_id, courier_id, status, proof_note, proof_photo_url, assigned_at, picked_up_at,
+        delivered_at, updated_at`
+
+func scanDelivery(row pgx.Row) (domain.Delivery, error) {
+	var d domain.Delivery
+	err := row.Scan(&d.ID, &d.OrderID, &d.CourierID, &d.Status, &d.ProofNote, &d.ProofPhotoURL, &d.AssignedAt,
+		&d.PickedUpAt, &d.DeliveredAt, &d.UpdatedAt)
+	return d, err
+}
+
+func (r *DeliveryPostgresRepo) SaveCourier(c domain.Courier) error {
+	_, err := DB.Exec(context.Background(), `
+        INSERT INTO couriers (id, user_id, name, phone, zone, status, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		c.ID, c.UserID, c.Name, c.Phone, c.Zone, c.Status, c.CreatedAt)
+	return uniqueViolationError(err)
+}
+
+func (r *DeliveryPostgresRepo) FindCourierByID(id string) (domain.Courier, error) {
+	c, err := scanCourier(DB.QueryRow(context.Background(), `SELECT `+courierColumns+` FROM couriers c WHERE c.id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c, domain.ErrCourierNotFound
+	}
+	return c, err
+}
+
+func (r *DeliveryPostgresRepo) FindCourierByUserID(userID string) (domain.Courier, error) {
+	c, err := scanCourier(DB.QueryRow(context.Background(), `
+        SELECT `+courierColumns+` FROM couriers c
+        WHERE c.user_id = $1 AND c.user_id <> ''
+        ORDER BY c.created_at, c.id
+        LIMIT 1`, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c, domain.ErrCourierNotFound
+	}
+	return c, err
+}
+
+func (r *DeliveryPostgresRepo) FindCouriers(status, zone string) ([]domain.Courier, error) {
+	rows, err := DB.Query(context.Background(), `
+        SELECT `+courierColumns+` FROM couriers c
+        WHERE ($1 = '' OR c.status = $1) AND ($2 = '' OR c.zone = $2)
+        ORDER BY c.name, c.id`, status, zone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	couriers := []domain.Courier{}
+	for rows.Next() {
+		c, err := scanCourier(rows)
+		if err != nil {
+			return nil, err
+		}
+		couriers = append(couriers, c)
+	}
+	return couriers, rows.Err()
+}
+
+func (r *DeliveryPostgresRepo) UpdateCourierStatus(id, status string) error {
+	tag, err := DB.Exec(context.Background(), `UPDATE couriers SET status = $2 WHERE id = $1`, id, status)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrCourierNotFound
+	}
+	return nil
+}
+
+// RecordLocation не сдвигает текущее положение назад, если точки пришли не по порядку
+func (r *DeliveryPostgresRepo) RecordLocation(l domain.CourierLocation) error {
+	ctx := context.Background()
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+        UPDATE couriers SET latitude = $2, longitude = $3, location_at = $4
+        WHERE id = $1 AND (location_at IS NULL OR location_at <= $4)`,
+		l.CourierID, l.Latitude, l.Longitude, l.RecordedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		var exists bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM couriers WHERE id = $1)`, l.CourierID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return domain.ErrCourierNotFound
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO courier_locations (courier_id, latitude, longitude, recorded_at) VALUES ($1, $2, $3, $4)`,
+		l.CourierID, l.Latitude, l.Longitude, l.RecordedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Assign блокирует курьера, чтобы два диспетчера не отдали ему заказы одновременно
+func (r *DeliveryPostgresRepo) Assign(d domain.Delivery) error {
+	ctx := context.Background()
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx, `SELECT status FROM couriers WHERE id = $1 FOR UPDATE`, d.CourierID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrCourierNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if status != domain.CourierStatusAvailable {
+		return domain.ErrCourierUnavailable
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO deliveries (id, order_id, courier_id, status, assigned_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6)`,
+		d.ID, d.OrderID, d.CourierID, d.Status, d.AssignedAt, d.UpdatedAt)
+	if err != nil {
+		return uniqueViolationError(err)
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *DeliveryPostgresRepo) FindDeliveryByID(id string) (domain.Delivery, error) {
+	d, err := scanDelivery(DB.QueryRow(context.Background(), `SELECT `+deliveryColumns+` FROM deliveries WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return d, domain.ErrDeliveryNotFound
+	}
+	return d, err
+}
+
+func (r *DeliveryPostgresRepo) FindLatestByOrder(orderID string) (domain.Delivery, error) {
+	d, err := scanDelivery(DB.QueryRow(context.Background(), `
+        SELECT `+deliveryColumns+` FROM deliveries WHERE order_id = $1 ORDER BY assigned_at DESC LIMIT 1`, orderID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return d, domain.ErrDeliveryNotFound
+	}
+	return d, err
+}
+
+func (r *DeliveryPostgresRepo) FindDeliveries(courierID, status string) ([]domain.Delivery, error) {
+	rows, err := DB.Query(context.Background(), `
+        SELECT `+deliveryColumns+` FROM deliveries
+        WHERE ($1 = '' OR courier_id::text = $1) AND ($2 = '' OR status = $2)
+        ORDER BY assigned_at DESC`, courierID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []domain.Delivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// UpdateDelivery меняет доставку, только если она еще в прежнем активном статусе:
+// отмененный тем временем заказ курьер уже не вручит. Заказ блокируется раньше доставки,
+// в том же порядке, что и при отмене заказа.
+func (r *DeliveryPostgresRepo) UpdateDelivery(d domain.Delivery, orderStatus string) error {
+	ctx := context.Background()
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM orders WHERE id = $1 FOR UPDATE`, d.OrderID); err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, `
+        UPDATE deliveries SET status = $2, proof_note = $3, proof_photo_url = $4, picked_up_at = $5,
+            delivered_at = $6, updated_at = $7
+        WHERE id = $1 AND status IN `+activeDeliveryStatuses,
+		d.ID, d.Status, d.ProofNote, d.ProofPhotoURL, d.PickedUpAt, d.DeliveredAt, d.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvalidDelivery
+	}
+
+	if err := changeOrderStatus(ctx, tx, d.OrderID, orderStatus, domain.CanDeliveryChangeOrderStatus); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// cancelActiveDelivery снимает заказ с курьера в транзакции tx при отмене заказа
+func cancelActiveDelivery(ctx context.Context, tx pgx.Tx, orderID string) error {
+	_, err := tx.Exec(ctx, `
+        UPDATE deliveries SET status = 'cancelled', updated_at = $2
+        WHERE order_id = $1 AND status IN `+activeDeliveryStatuses, orderID, time.Now())
+	return err
+}
@@ -0,0 +1,170 @@
+package postgres
+
+import (
+	"FoodStore-AdvProg2/domain"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type DeliverySlotPostgresRepo struct{}
+
+func NewDeliverySlotPostgresRepo() *DeliverySlotPostgresRepo {
+	return &DeliverySlotPostgresRepo{}
+}
+
+// slotBookedExpr - число мест интервала s, занятых заказами и неистекшими бронями
+const slotBookedExpr = `(SELECT COUNT(*) FROM delivery_slot_reservations r
+        WHERE r.slot_id = s.id AND (r.status = 'confirmed' OR (r.status = 'held' AND r.expires_at > now())))`
+
+const slotColumns = `s.id, s.zone, s.starts_at, s.ends_at, s.capacity, ` + slotBookedExpr + `, s.created_at`
+
+func scanSlot(row pgx.Row) (domain.DeliverySlot, error) {
+	var slot domain.DeliverySlot
+	err := row.Scan(&slot.ID, &slot.Zone, &slot.StartsAt, &slot.EndsAt, &slot.Capacity, &slot.Booked, &slot.CreatedAt)
+	return slot, err
+}
+
+func (r *DeliverySlotPostgresRepo) SaveSlot(slot domain.DeliverySlot) error {
+	_, err := DB.Exec(context.Background(), `
+        INSERT INTO delivery_slots (id, zone, starts_at, ends_at, capacity, created_at) VALUES ($1, $2, $3, $4, $5, $6)`,
+		slot.ID, slot.Zone, slot.StartsAt, slot.EndsAt, slot.Capacity, slot.CreatedAt)
+	return uniqueViolationError(err)
+}
+
+func (r *DeliverySlotPostgresRepo) FindSlotByID(id string) (domain.DeliverySlot, error) {
+	slot, err := scanSlot(DB.QueryRow(context.Background(), `SELECT `+slotColumns+` FROM delivery_slots s WHERE s.id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return slot, domain.ErrDeliverySlotNotFound
+	}
+	return slot, err
+}
+
+func (r *DeliverySlotPostgresRepo) FindSlots(zone string, from, to time.Time) ([]domain.DeliverySlot, error) {
+	rows, err := DB.Query(context.Background(), `
+        SELECT `+slotColumns+` FROM delivery_slots s
+        WHERE ($1 = '' OR s.zone = $1) AND s.starts_at >= $2 AND s.starts_at < $3
+        ORDER BY s.starts_at, s.zone`, zone, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slots := []domain.DeliverySlot{}
+	for rows.Next() {
+		slot, err := scanSlot(rows)
+		if err != nil {
+			return nil, err
+		}
+		slots = append(slots, slot)
+	}
+	return slots, rows.Err()
+}
+
+// Reserve блокирует интервал, чтобы два покупателя не заняли последнее место одновременно
+func (r *DeliverySlotPostgresRepo) Reserve(reservation domain.SlotReservation) error {
+	ctx := context.Background()
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var capacity int
+	var startsAt time.Time
+	err = tx.QueryRow(ctx, `SELECT capacity, starts_at FROM delivery_slots WHERE id = $1 FOR UPDATE`, reservation.SlotID).
+		Scan(&capacity, &startsAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrDeliverySlotNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !startsAt.After(reservation.CreatedAt) {
+		return domain.ErrDeliverySlotFull
+	}
+
+	// Покупатель держит не больше одного места: новый выбор снимает прежний
+	_, err = tx.Exec(ctx, `
+        UPDATE delivery_slot_reservations SET status = 'released' WHERE user_id = $1 AND status = 'held'`,
+		reservation.UserID)
+	if err != nil {
+		return err
+	}
+
+	var booked int
+	err = tx.QueryRow(ctx, `
+        SELECT COUNT(*) FROM delivery_slot_reservations
+        WHERE slot_id = $1 AND (status = 'confirmed' OR (status = 'held' AND expires_at > now()))`,
+		reservation.SlotID).Scan(&booked)
+	if err != nil {
+		return err
+	}
+	if booked >= capacity {
+		return domain.ErrDeliverySlotFull
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO delivery_slot_reservations (id, slot_id, user_id, status, expires_at, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6)`,
+		reservation.ID, reservation.SlotID, reservation.UserID, reservation.Status, reservation.ExpiresAt, reservation.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *DeliverySlotPostgresRepo) FindReservation(id string) (domain.SlotReservation, error) {
+	var reservation domain.SlotReservation
+	err := DB.QueryRow(context.Background(), `
+        SELECT id, slot_id, user_id, COALESCE(order_id::text, ''), status, expires_at, created_at
+        FROM delivery_slot_reservations WHERE id = $1`, id).
+		Scan(&reservation.ID, &reservation.SlotID, &reservation.UserID, &reservation.OrderID,
+			&reservation.Status, &reservation.ExpiresAt, &reservation.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return reservation, domain.ErrSlotReservationNotFound
+	}
+	return reservation, err
+}
+
+func (r *DeliverySlotPostgresRepo) Release(id string) (bool, error) {
+	tag, err := DB.Exec(context.Background(), `
+        UPDATE delivery_slot_reservations SET status = 'released' WHERE id = $1 AND status = 'held' AND expires_at > now()`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *DeliverySlotPostgresRepo) ReleaseByOrder(orderID string) error {
+	_, err := DB.Exec(context.Background(), `
+        UPDATE delivery_slot_reservations SET status = 'released' WHERE order_id = $1 AND status = 'confirmed'`, orderID)
+	return err
+}
+
+func (r *DeliverySlotPostgresRepo) ExpireReservations(now time.Time) (int, error) {
+	tag, err := DB.Exec(context.Background(), `
+        UPDATE delivery_slot_reservations SET status = 'expired' WHERE status = 'held' AND expires_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// confirmSlotReservation закрепляет бронь покупателя за заказом в транзакции tx.
+// Истекшая бронь не подтверждается: ее место могли уже занять.
+func confirmSlotReservation(ctx context.Context, tx pgx.Tx, reservationID, orderID, userID string) error {
+	tag, err := tx.Exec(ctx, `
+        UPDATE delivery_slot_reservations SET status = 'confirmed', order_id = $2
+        WHERE id = $1 AND user_id = $3 AND status = 'held' AND expires_at > now()`,
+		reservationID, orderID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSlotReservationExpired
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"FoodStore-AdvProg2/domain"
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type DeliveryZonePostgresRepo struct{}
+
+func NewDeliveryZonePostgresRepo() *DeliveryZonePostgresRepo {
+	return &DeliveryZonePostgresRepo{}
+}
+
+const deliveryZoneColumns = `id, code, name, polygon, postal_codes, fee, free_delivery_threshold, min_order_amount,
+        priority, active, created_at`
+
+func scanDeliveryZone(row pgx.Row) (domain.DeliveryZone, error) {
+	var z domain.DeliveryZone
+	var polygon []byte
+	err := row.Scan(&z.ID, &z.Code, &z.Name, &polygon, &z.PostalCodes, &z.Fee, &z.FreeDeliveryThreshold,
+		&z.MinOrderAmount, &z.Priority, &z.Active, &z.CreatedAt)
+	if err != nil {
+		return z, err
+	}
+	if err := json.Unmarshal(polygon, &z.Polygon); err != nil {
+		return z, err
+	}
+	return z, nil
+}
+
+// marshalPolygon сохраняет вершины зоны в JSON; зона только с индексами хранит пустой массив
+func marshalPolygon(polygon []domain.GeoPoint) ([]byte, error) {
+	if polygon == nil {
+		polygon = []domain.GeoPoint{}
+	}
+	return json.Marshal(polygon)
+}
+
+func (r *DeliveryZonePostgresRepo) Save(z domain.DeliveryZone) error {
+	polygon, err := marshalPolygon(z.Polygon)
+	if err != nil {
+		return err
+	}
+	_, err = DB.Exec(context.Background(), `
+        INSERT INTO delivery_zones (id, code, name, polygon, postal_codes, fee, free_delivery_threshold,
+            min_order_amount, priority, active, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		z.ID, z.Code, z.Name, polygon, textArray(z.PostalCodes), z.Fee, z.FreeDeliveryThreshold,
+		z.MinOrderAmount, z.Priority, z.Active, z.CreatedAt)
+	return uniqueViolationError(err)
+}
+
+func (r *DeliveryZonePostgresRepo) Update(z domain.DeliveryZone) error {
+	polygon, err := marshalPolygon(z.Polygon)
+	if err != nil {
+		return err
+	}
+	tag, err := DB.Exec(context.Background(), `
+        UPDATE delivery_zones SET code = $2, name = $3, polygon = $4, postal_codes = $5, fee = $6,
+            free_delivery_threshold = $7, min_order_amount = $8, priority = $9, active = $10
+        WHERE id = $1`,
+		z.ID, z.Code, z.Name, polygon, textArray(z.PostalCodes), z.Fee, z.FreeDeliveryThreshold,
+		z.MinOrderAmount, z.Priority, z.Active)
+	if err != nil {
+		return uniqueViolationError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrDeliveryZoneNotFound
+	}
+	return nil
+}
+
+func (r *DeliveryZonePostgresRepo) FindByID(id string) (domain.DeliveryZone, error) {
+	z, err := scanDeliveryZone(DB.QueryRow(context.Background(),
+		`SELECT `+deliveryZoneColumns+` FROM delivery_zones WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return z, domain.ErrDeliveryZoneNotFound
+	}
+	return z, err
+}
+
+func (r *DeliveryZonePostgresRepo) FindAll(activeOnly bool) ([]domain.DeliveryZone, error) {
+	rows, err := DB.Query(context.Background(), `SELECT `+deliveryZoneColumns+` FROM delivery_zones
+        WHERE active OR NOT $1 ORDER BY priority DESC, code`, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	zones := []domain.DeliveryZone{}
+	for rows.Next() {
+		z, err := scanDeliveryZone(rows)
+		if err != nil {
+			return nil, err
+		}
+		zones = append(zones, z)
+	}
+	return zones, rows.Err()
+}
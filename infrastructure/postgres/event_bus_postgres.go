@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"FoodStore-AdvProg2/domain"
+	"context"
+	"encoding/json"
+	"log"
+)
+
+// eventChannel - канал NOTIFY доменных событий
+const eventChannel = "domain_events"
+
+// maxNotifyPayload - предел payload NOTIFY (8000 байт) с запасом
+const maxNotifyPayload = 7900
+
+// PostgresEventBus доставляет доменные события через LISTEN/NOTIFY той же базы,
+// поэтому работает локально без отдельного брокера. Уведомления не хранятся:
+// пропущенные во время отключения события потребитель добирает из outbox.
+type PostgresEventBus struct{}
+
+func NewPostgresEventBus() *PostgresEventBus {
+	return &PostgresEventBus{}
+}
+
+// Publish отправляет событие целиком; слишком большое - только ID, подписчик прочитает его из outbox
+func (b *PostgresEventBus) Publish(ctx context.Context, event domain.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if len(data) > maxNotifyPayload {
+		data, err = json.Marshal(domain.Event{ID: event.ID})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = DB.Exec(ctx, `SELECT pg_notify($1, $2)`, eventChannel, string(data))
+	return err
+}
+
+// Subscribe передает события всем подписчикам; consumer в Postgres не используется
+func (b *PostgresEventBus) Subscribe(ctx context.Context, consumer string, handle func(domain.Event)) error {
+	listen(ctx, eventChannel, func(payload string) {
+		var event domain.Event
+		if err := json.Unmarshal([]byte(payload), &event); err != nil {
+			log.Printf("Invalid event notification: %v", err)
+			return
+		}
+		if event.Type == "" {
+			loaded, err := scanEvent(DB.QueryRow(ctx, `SELECT `+eventColumns+` FROM outbox_events WHERE id = $1`, event.ID))
+			if err != nil {
+				log.Printf("Failed to load event %s: %v", event.ID, err)
+				return
+			}
+			event = loaded
+		}
+		handle(event)
+	})
+	return nil
+}
+
+func (b *PostgresEventBus) Close() error {
+	return nil
+}
@@ -2,27 +2,10 @@ package postgres
 
 import (
 	"context"
-	"database/sql"
 	"log"
-
-	_ "github.com/lib/pq"
 )
 
-var db *sql.DB
-
-// InitDB инициализирует соединение с базой данных
-func InitDB(dataSourceName string) {
-	var err error
-	db, err = sql.Open("postgres", dataSourceName)
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
-	}
-
-	if err := db.Ping(); err != nil {
-		log.Fatalf("Failed to ping database: %v", err)
-	}
-}
-
+// InitTables создает базовые таблицы и применяет schemaUpdates через пул DB из InitDB
 func InitTables() error {
 	createProductsTable := `
     CREATE TABLE IF NOT EXISTS products (
@@ -36,7 +19,7 @@ func InitTables() error {
     CREATE TABLE IF NOT EXISTS orders (
         id UUID PRIMARY KEY,
         user_id VARCHAR(255) NOT NULL,
-        total_price DECIMAL(10, 2) NOT NULL,
+        total_amount DECIMAL(10, 2) NOT NULL,
         status VARCHAR(50) NOT NULL,
         created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
     );`
@@ -61,30 +44,513 @@ func InitTables() error {
         updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
     );`
 
-	_, err := db.Exec(context.Background(), createProductsTable)
+	_, err := DB.Exec(context.Background(), createProductsTable)
 	if err != nil {
 		log.Printf("Error creating products: %v", err)
 		return err
 	}
 
-	_, err = db.Exec(context.Background(), createOrdersTable)
+	_, err = DB.Exec(context.Background(), createOrdersTable)
 	if err != nil {
 		log.Printf("Error creating orders: %v", err)
 		return err
 	}
 
-	_, err = db.Exec(context.Background(), createOrderItemsTable)
+	_, err = DB.Exec(context.Background(), createOrderItemsTable)
 	if err != nil {
 		log.Printf("Error creating order_items: %v", err)
 		return err
 	}
 
-	_, err = db.Exec(context.Background(), createUsersTable)
+	_, err = DB.Exec(context.Background(), createUsersTable)
 	if err != nil {
 		log.Printf("Error creating users: %v", err)
 		return err
 	}
 
+	for _, stmt := range schemaUpdates {
+		if _, err := DB.Exec(context.Background(), stmt); err != nil {
+			log.Printf("Error updating schema: %v\n%s", err, stmt)
+			return err
+		}
+	}
+
 	log.Println("Tables created successfully")
 	return nil
 }
+
+// schemaUpdates - изменения схемы поверх базовых таблиц.
+// Выполняются при каждом старте, поэтому должны быть идемпотентными.
+var schemaUpdates = []string{
+	// Базовая таблица заказов раньше создавала total_price, а запросы всегда читали total_amount
+	`DO $$ BEGIN
+        IF EXISTS (SELECT 1 FROM information_schema.columns
+            WHERE table_name = 'orders' AND column_name = 'total_price')
+            AND NOT EXISTS (SELECT 1 FROM information_schema.columns
+            WHERE table_name = 'orders' AND column_name = 'total_amount') THEN
+            ALTER TABLE orders RENAME COLUMN total_price TO total_amount;
+        END IF;
+    END $$`,
+	// Полнотекстовый поиск и сортировка товаров
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP`,
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+    ) STORED`,
+	`CREATE INDEX IF NOT EXISTS products_search_idx ON products USING GIN (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS products_price_idx ON products (price, id)`,
+	`CREATE INDEX IF NOT EXISTS products_name_idx ON products (name, id)`,
+	`CREATE INDEX IF NOT EXISTS products_created_at_idx ON products (created_at, id)`,
+	`CREATE INDEX IF NOT EXISTS order_items_product_idx ON order_items (product_id)`,
+
+	// Изображения товаров
+	`CREATE TABLE IF NOT EXISTS product_images (
+        id UUID PRIMARY KEY,
+        product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+        url TEXT NOT NULL,
+        thumbnail_url TEXT NOT NULL,
+        original_url TEXT NOT NULL,
+        width INT NOT NULL,
+        height INT NOT NULL,
+        position INT NOT NULL DEFAULT 0,
+        blob_keys TEXT[] NOT NULL DEFAULT '{}',
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+    )`,
+	`CREATE INDEX IF NOT EXISTS product_images_product_idx ON product_images (product_id, position)`,
+
+	// Партии товаров со сроками годности. products.stock хранит сумму остатков всех партий
+	`CREATE TABLE IF NOT EXISTS stock_batches (
+        id UUID PRIMARY KEY,
+        product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+        lot_number VARCHAR(100) NOT NULL,
+        received_at DATE NOT NULL DEFAULT CURRENT_DATE,
+        expires_at DATE,
+        quantity INT NOT NULL CHECK (quantity >= 0),
+        initial_quantity INT NOT NULL,
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+    )`,
+	`CREATE INDEX IF NOT EXISTS stock_batches_fefo_idx ON stock_batches (product_id, expires_at, received_at) WHERE quantity > 0`,
+	`CREATE INDEX IF NOT EXISTS stock_batches_expires_idx ON stock_batches (expires_at) WHERE quantity > 0`,
+	`CREATE TABLE IF NOT EXISTS stock_allocations (
+        id UUID PRIMARY KEY,
+        order_id UUID NOT NULL,
+        batch_id UUID NOT NULL REFERENCES stock_batches(id) ON DELETE CASCADE,
+        product_id UUID NOT NULL,
+        quantity INT NOT NULL,
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        released_at TIMESTAMP WITH TIME ZONE
+    )`,
+	`CREATE INDEX IF NOT EXISTS stock_allocations_order_idx ON stock_allocations (order_id) WHERE released_at IS NULL`,
+	// Остаток, заведенный до появления партий, переносим в бессрочную партию
+	`INSERT INTO stock_batches (id, product_id, lot_number, quantity, initial_quantity)
+        SELECT gen_random_uuid(), p.id, 'LEGACY', p.stock, p.stock FROM products p
+        WHERE p.stock > 0 AND NOT EXISTS (SELECT 1 FROM stock_batches b WHERE b.product_id = p.id)`,
+
+	// Журнал движения товаров. Сумма quantity по товару равна products.stock
+	`CREATE TABLE IF NOT EXISTS stock_movements (
+        id UUID PRIMARY KEY,
+        product_id UUID NOT NULL,
+        batch_id UUID,
+        type VARCHAR(32) NOT NULL,
+        quantity INT NOT NULL,
+        balance_after INT NOT NULL,
+        reference_id VARCHAR(255) NOT NULL DEFAULT '',
+        actor VARCHAR(255) NOT NULL DEFAULT '',
+        reason TEXT NOT NULL DEFAULT '',
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+    )`,
+	`CREATE INDEX IF NOT EXISTS stock_movements_product_idx ON stock_movements (product_id, created_at)`,
+	`CREATE INDEX IF NOT EXISTS stock_movements_reference_idx ON stock_movements (reference_id) WHERE reference_id <> ''`,
+	`CREATE INDEX IF NOT EXISTS stock_movements_created_idx ON stock_movements (created_at)`,
+	// Журнал только дополняется: изменение и удаление записей запрещены
+	`CREATE OR REPLACE FUNCTION stock_movements_append_only() RETURNS trigger AS $$
+    BEGIN
+        RAISE EXCEPTION 'stock_movements is append-only';
+    END;
+    $$ LANGUAGE plpgsql`,
+	`DO $$ BEGIN
+        IF NOT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname = 'stock_movements_append_only') THEN
+            CREATE TRIGGER stock_movements_append_only BEFORE UPDATE OR DELETE ON stock_movements
+                FOR EACH ROW EXECUTE FUNCTION stock_movements_append_only();
+        END IF;
+    END $$`,
+	// Остатки, заведенные до появления журнала, записываем как начальные
+	`INSERT INTO stock_movements (id, product_id, batch_id, type, quantity, balance_after, actor, reason)
+        SELECT gen_random_uuid(), b.product_id, b.id, 'opening_balance', b.quantity,
+               SUM(b.quantity) OVER (PARTITION BY b.product_id ORDER BY b.received_at, b.id),
+               'system', 'ledger introduced'
+        FROM stock_batches b
+        WHERE b.quantity > 0 AND NOT EXISTS (SELECT 1 FROM stock_movements m WHERE m.product_id = b.product_id)`,
+
+	// Склады и магазины. Склад по умолчанию получает остатки, заведенные без указания точки
+	`CREATE TABLE IF NOT EXISTS locations (
+        id UUID PRIMARY KEY,
+        code VARCHAR(50) NOT NULL UNIQUE,
+        name VARCHAR(255) NOT NULL,
+        type VARCHAR(20) NOT NULL DEFAULT 'warehouse',
+        street TEXT NOT NULL DEFAULT '',
+        city VARCHAR(255) NOT NULL DEFAULT '',
+        postal_code VARCHAR(20) NOT NULL DEFAULT '',
+        latitude DOUBLE PRECISION,
+        longitude DOUBLE PRECISION,
+        is_default BOOLEAN NOT NULL DEFAULT FALSE,
+        active BOOLEAN NOT NULL DEFAULT TRUE,
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+    )`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS locations_default_idx ON locations (is_default) WHERE is_default`,
+	`INSERT INTO locations (id, code, name, is_default)
+        SELECT gen_random_uuid(), 'MAIN', 'Main warehouse', TRUE
+        WHERE NOT EXISTS (SELECT 1 FROM locations WHERE is_default)`,
+
+	// Партии, заведенные до появления точек, относятся к складу по умолчанию
+	`ALTER TABLE stock_batches ADD COLUMN IF NOT EXISTS location_id UUID REFERENCES locations(id)`,
+	`UPDATE stock_batches SET location_id = (SELECT id FROM locations WHERE is_default) WHERE location_id IS NULL`,
+	`ALTER TABLE stock_batches ALTER COLUMN location_id SET NOT NULL`,
+	`CREATE INDEX IF NOT EXISTS stock_batches_location_idx ON stock_batches (location_id, product_id) WHERE quantity > 0`,
+	// Точка движения; пусто для записей, сделанных до появления точек
+	`ALTER TABLE stock_movements ADD COLUMN IF NOT EXISTS location_id UUID`,
+	`CREATE INDEX IF NOT EXISTS stock_movements_location_idx ON stock_movements (location_id, created_at)`,
+
+	// Точка выполнения и адрес доставки заказа
+	`ALTER TABLE orders ADD COLUMN IF NOT EXISTS location_id UUID REFERENCES locations(id)`,
+	`ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_street TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_city VARCHAR(255) NOT NULL DEFAULT ''`,
+	`ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_postal_code VARCHAR(20) NOT NULL DEFAULT ''`,
+	`ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_latitude DOUBLE PRECISION`,
+	`ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_longitude DOUBLE PRECISION`,
+
+	// Поставщики и заказы на пополнение остатков
+	`CREATE TABLE IF NOT EXISTS suppliers (
+        id UUID PRIMARY KEY,
+        name VARCHAR(255) NOT NULL,
+        email VARCHAR(255) NOT NULL DEFAULT '',
+        phone VARCHAR(50) NOT NULL DEFAULT '',
+        address TEXT NOT NULL DEFAULT '',
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+    )`,
+	`CREATE TABLE IF NOT EXISTS purchase_orders (
+        id UUID PRIMARY KEY,
+        supplier_id UUID NOT NULL REFERENCES suppliers(id),
+        location_id UUID NOT NULL REFERENCES locations(id),
+        status VARCHAR(32) NOT NULL,
+        expected_at DATE,
+        notes TEXT NOT NULL DEFAULT '',
+        created_by VARCHAR(255) NOT NULL DEFAULT '',
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+    )`,
+	`CREATE INDEX IF NOT EXISTS purchase_orders_status_idx ON purchase_orders (status, created_at)`,
+	`CREATE INDEX IF NOT EXISTS purchase_orders_supplier_idx ON purchase_orders (supplier_id, created_at)`,
+	`CREATE TABLE IF NOT EXISTS purchase_order_lines (
+        id UUID PRIMARY KEY,
+        purchase_order_id UUID NOT NULL REFERENCES purchase_orders(id) ON DELETE CASCADE,
+        product_id UUID NOT NULL REFERENCES products(id),
+        quantity_ordered INT NOT NULL CHECK (quantity_ordered > 0),
+        quantity_received INT NOT NULL DEFAULT 0 CHECK (quantity_received >= 0 AND quantity_received <= quantity_ordered),
+        unit_cost DECIMAL(10, 2) NOT NULL DEFAULT 0,
+        position INT NOT NULL DEFAULT 0
+    )`,
+	`CREATE INDEX IF NOT EXISTS purchase_order_lines_order_idx ON purchase_order_lines (purchase_order_id, position)`,
+	// Точки заказа, алерты о низком остатке и предложения на пополнение
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS reorder_point INT NOT NULL DEFAULT 0 CHECK (reorder_point >= 0)`,
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS reorder_quantity INT NOT NULL DEFAULT 0 CHECK (reorder_quantity >= 0)`,
+	`CREATE TABLE IF NOT EXISTS low_stock_alerts (
+        id UUID PRIMARY KEY,
+        product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+        available INT NOT NULL,
+        reorder_point INT NOT NULL,
+        triggered_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        resolved_at TIMESTAMP WITH TIME ZONE
+    )`,
+	// Не больше одного открытого алерта на товар, даже если проверку запустили несколько реплик
+	`CREATE UNIQUE INDEX IF NOT EXISTS low_stock_alerts_open_idx ON low_stock_alerts (product_id) WHERE resolved_at IS NULL`,
+	`CREATE INDEX IF NOT EXISTS low_stock_alerts_triggered_idx ON low_stock_alerts (triggered_at)`,
+	`CREATE TABLE IF NOT EXISTS reorder_suggestions (
+        id UUID PRIMARY KEY,
+        alert_id UUID REFERENCES low_stock_alerts(id) ON DELETE SET NULL,
+        product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+        available INT NOT NULL,
+        reorder_point INT NOT NULL,
+        reorder_quantity INT NOT NULL,
+        units_sold INT NOT NULL DEFAULT 0,
+        sales_window_days INT NOT NULL,
+        daily_sales DOUBLE PRECISION NOT NULL DEFAULT 0,
+        suggested_quantity INT NOT NULL CHECK (suggested_quantity > 0),
+        status VARCHAR(20) NOT NULL DEFAULT 'draft',
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+    )`,
+	`CREATE INDEX IF NOT EXISTS reorder_suggestions_status_idx ON reorder_suggestions (status, created_at)`,
+	// Версия карточки товара для оптимистичной блокировки
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1`,
+	// Архив товаров вместо удаления: на товары ссылаются заказы и журнал движений
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE`,
+	`CREATE INDEX IF NOT EXISTS products_archived_idx ON products (archived_at) WHERE archived_at IS NOT NULL`,
+	// Артикул для импорта каталога; NULL у товаров без артикула
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS sku VARCHAR(64)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS products_sku_idx ON products (sku)`,
+	// Единицы измерения: цена за штуку, килограмм или литр, остаток в штуках, граммах или миллилитрах
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS unit VARCHAR(10) NOT NULL DEFAULT 'piece'
+        CHECK (unit IN ('piece', 'kg', 'litre'))`,
+	// Штрихкоды EAN/UPC; первичный ключ не дает привязать один код к двум товарам
+	`CREATE TABLE IF NOT EXISTS product_barcodes (
+        barcode VARCHAR(13) PRIMARY KEY,
+        product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+        position INT NOT NULL DEFAULT 0
+    )`,
+	`CREATE INDEX IF NOT EXISTS product_barcodes_product_idx ON product_barcodes (product_id)`,
+	// Дробное количество в заказе для весовых и наливных товаров, в единицах товара
+	`DO $$ BEGIN
+        IF (SELECT data_type FROM information_schema.columns
+            WHERE table_name = 'order_items' AND column_name = 'quantity') = 'integer' THEN
+            ALTER TABLE order_items ALTER COLUMN quantity TYPE NUMERIC(12, 3);
+        END IF;
+    END $$`,
+	`ALTER TABLE order_items ADD COLUMN IF NOT EXISTS unit VARCHAR(10) NOT NULL DEFAULT 'piece'`,
+	// Пищевая ценность на 100 г, состав, аллергены и пищевые метки;
+	// GIN-индексы ускоряют фильтры каталога по аллергенам и меткам
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS nutrition JSONB`,
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS ingredients TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS allergens TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS dietary_tags TEXT[] NOT NULL DEFAULT '{}'`,
+	`CREATE INDEX IF NOT EXISTS products_allergens_idx ON products USING GIN (allergens)`,
+	`CREATE INDEX IF NOT EXISTS products_dietary_tags_idx ON products USING GIN (dietary_tags)`,
+	// Варианты товара (упаковки 0.5 л и 1 л) и наборы из других товаров
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS kind VARCHAR(10) NOT NULL DEFAULT 'simple'
+        CHECK (kind IN ('simple', 'bundle'))`,
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES products(id) ON DELETE SET NULL`,
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS variant_name VARCHAR(100) NOT NULL DEFAULT ''`,
+	`CREATE INDEX IF NOT EXISTS products_parent_idx ON products (parent_id) WHERE parent_id IS NOT NULL`,
+	// Компонент нельзя удалить, пока он входит в набор
+	`CREATE TABLE IF NOT EXISTS bundle_components (
+        bundle_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+        component_id UUID NOT NULL REFERENCES products(id),
+        quantity INT NOT NULL CHECK (quantity > 0),
+        position INT NOT NULL DEFAULT 0,
+        PRIMARY KEY (bundle_id, component_id)
+    )`,
+	`CREATE INDEX IF NOT EXISTS bundle_components_component_idx ON bundle_components (component_id)`,
+	// Категория товара (dairy, bakery); по ней настраиваются акции
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS category VARCHAR(50) NOT NULL DEFAULT ''`,
+	// Акции и купоны. Код купона хранится в верхнем регистре и уникален
+	`CREATE TABLE IF NOT EXISTS promotions (
+        id UUID PRIMARY KEY,
+        name VARCHAR(255) NOT NULL,
+        type VARCHAR(20) NOT NULL CHECK (type IN ('percentage', 'fixed_amount', 'bogo', 'free_delivery')),
+        value DECIMAL(10, 2) NOT NULL DEFAULT 0,
+        buy_quantity INT NOT NULL DEFAULT 0,
+        get_quantity INT NOT NULL DEFAULT 0,
+        min_subtotal DECIMAL(10, 2) NOT NULL DEFAULT 0,
+        product_ids TEXT[] NOT NULL DEFAULT '{}',
+        categories TEXT[] NOT NULL DEFAULT '{}',
+        coupon_code VARCHAR(50),
+        usage_limit INT NOT NULL DEFAULT 0,
+        per_user_limit INT NOT NULL DEFAULT 0,
+        starts_at TIMESTAMP WITH TIME ZONE,
+        ends_at TIMESTAMP WITH TIME ZONE,
+        stackable BOOLEAN NOT NULL DEFAULT TRUE,
+        priority INT NOT NULL DEFAULT 0,
+        active BOOLEAN NOT NULL DEFAULT TRUE,
+        created_by VARCHAR(255) NOT NULL DEFAULT '',
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+    )`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS promotions_coupon_idx ON promotions (coupon_code) WHERE coupon_code IS NOT NULL`,
+	// Использования акций; лимиты считаются по неотмененным заказам
+	`CREATE TABLE IF NOT EXISTS promotion_redemptions (
+        promotion_id UUID NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+        order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+        user_id VARCHAR(255) NOT NULL,
+        amount DECIMAL(10, 2) NOT NULL,
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (promotion_id, order_id)
+    )`,
+	`CREATE INDEX IF NOT EXISTS promotion_redemptions_user_idx ON promotion_redemptions (promotion_id, user_id)`,
+	// Скидки заказа: по позициям и на доставку (order_item_id пустой)
+	`CREATE TABLE IF NOT EXISTS order_discounts (
+        id UUID PRIMARY KEY,
+        order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+        order_item_id UUID REFERENCES order_items(id) ON DELETE CASCADE,
+        promotion_id UUID REFERENCES promotions(id) ON DELETE SET NULL,
+        name VARCHAR(255) NOT NULL,
+        coupon_code VARCHAR(50) NOT NULL DEFAULT '',
+        amount DECIMAL(10, 2) NOT NULL
+    )`,
+	`CREATE INDEX IF NOT EXISTS order_discounts_order_idx ON order_discounts (order_id)`,
+	// Стоимость товаров до скидок, сумма скидок и доставка; у старых заказов скидок и доставки не было
+	`ALTER TABLE orders ADD COLUMN IF NOT EXISTS subtotal DECIMAL(10, 2)`,
+	`ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount_total DECIMAL(10, 2) NOT NULL DEFAULT 0`,
+	`ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_fee DECIMAL(10, 2) NOT NULL DEFAULT 0`,
+	`UPDATE orders SET subtotal = total_amount WHERE subtotal IS NULL`,
+	// НДС: ставка и налог позиции, налог на доставку и итоги по ставкам. У старых заказов tax_mode пустой - налог не считался
+	`ALTER TABLE order_items ADD COLUMN IF NOT EXISTS tax_rate DECIMAL(5, 2) NOT NULL DEFAULT 0`,
+	`ALTER TABLE order_items ADD COLUMN IF NOT EXISTS tax_amount DECIMAL(10, 2) NOT NULL DEFAULT 0`,
+	`ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_mode VARCHAR(10) NOT NULL DEFAULT ''`,
+	`ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_total DECIMAL(10, 2) NOT NULL DEFAULT 0`,
+	`ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_tax DECIMAL(10, 2) NOT NULL DEFAULT 0`,
+	`CREATE TABLE IF NOT EXISTS order_tax_summary (
+        order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+        rate DECIMAL(5, 2) NOT NULL,
+        net_amount DECIMAL(10, 2) NOT NULL,
+        tax_amount DECIMAL(10, 2) NOT NULL,
+        PRIMARY KEY (order_id, rate)
+    )`,
+	// Корзины: гостевые без user_id, у покупателя не больше одной
+	`CREATE TABLE IF NOT EXISTS carts (
+        id UUID PRIMARY KEY,
+        user_id VARCHAR(255) UNIQUE,
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        checkout_started_at TIMESTAMP WITH TIME ZONE
+    )`,
+	`CREATE INDEX IF NOT EXISTS carts_expires_at_idx ON carts (expires_at)`,
+	`CREATE TABLE IF NOT EXISTS cart_items (
+        cart_id UUID NOT NULL REFERENCES carts(id) ON DELETE CASCADE,
+        product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+        quantity DECIMAL(12, 3) NOT NULL CHECK (quantity > 0),
+        price DECIMAL(10, 2) NOT NULL,
+        added_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (cart_id, product_id)
+    )`,
+	// Запланированные цены: акции с концом периода и отложенные изменения цены
+	`CREATE TABLE IF NOT EXISTS price_schedules (
+        id UUID PRIMARY KEY,
+        product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+        price DECIMAL(10, 2) NOT NULL CHECK (price >= 0),
+        starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        ends_at TIMESTAMP WITH TIME ZONE,
+        status VARCHAR(16) NOT NULL DEFAULT 'pending',
+        previous_price DECIMAL(10, 2),
+        created_by VARCHAR(255) NOT NULL DEFAULT '',
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        CHECK (ends_at IS NULL OR ends_at > starts_at)
+    )`,
+	`CREATE INDEX IF NOT EXISTS price_schedules_product_idx ON price_schedules (product_id, starts_at)`,
+	`CREATE INDEX IF NOT EXISTS price_schedules_open_idx ON price_schedules (status) WHERE status IN ('pending', 'active')`,
+	// История цен: каждая строка - цена, действовавшая с effective_at до следующей строки товара
+	`CREATE TABLE IF NOT EXISTS price_history (
+        id BIGSERIAL PRIMARY KEY,
+        product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+        price DECIMAL(10, 2) NOT NULL,
+        effective_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        source VARCHAR(16) NOT NULL,
+        actor VARCHAR(255) NOT NULL DEFAULT '',
+        schedule_id UUID REFERENCES price_schedules(id) ON DELETE SET NULL
+    )`,
+	`CREATE INDEX IF NOT EXISTS price_history_product_idx ON price_history (product_id, effective_at, id)`,
+	// Товары, созданные до истории цен, получают начальную запись с датой создания
+	`INSERT INTO price_history (product_id, price, effective_at, source)
+        SELECT p.id, p.price, p.created_at, 'initial' FROM products p
+        WHERE NOT EXISTS (SELECT 1 FROM price_history h WHERE h.product_id = p.id)`,
+	// Интервалы доставки по зонам; в зоне не бывает двух интервалов с одним началом
+	`CREATE TABLE IF NOT EXISTS delivery_slots (
+        id UUID PRIMARY KEY,
+        zone VARCHAR(100) NOT NULL,
+        starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        capacity INT NOT NULL CHECK (capacity > 0),
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        CHECK (ends_at > starts_at)
+    )`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS delivery_slots_zone_start_idx ON delivery_slots (zone, starts_at)`,
+	// Брони мест: held ждет заказ до expires_at, confirmed занята заказом
+	`CREATE TABLE IF NOT EXISTS delivery_slot_reservations (
+        id UUID PRIMARY KEY,
+        slot_id UUID NOT NULL REFERENCES delivery_slots(id),
+        user_id VARCHAR(255) NOT NULL,
+        order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+        status VARCHAR(16) NOT NULL,
+        expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+    )`,
+	`CREATE INDEX IF NOT EXISTS delivery_slot_reservations_slot_idx ON delivery_slot_reservations (slot_id)
+        WHERE status IN ('held', 'confirmed')`,
+	`CREATE INDEX IF NOT EXISTS delivery_slot_reservations_order_idx ON delivery_slot_reservations (order_id)`,
+	// Интервал доставки заказа; окно копируется в заказ на момент оформления
+	`ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_slot_id UUID REFERENCES delivery_slots(id)`,
+	`ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_starts_at TIMESTAMP WITH TIME ZONE`,
+	`ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_ends_at TIMESTAMP WITH TIME ZONE`,
+	// Зоны доставки: многоугольник в JSON ([{latitude, longitude}, ...]) и/или список индексов
+	`CREATE TABLE IF NOT EXISTS delivery_zones (
+        id UUID PRIMARY KEY,
+        code VARCHAR(100) NOT NULL,
+        name VARCHAR(255) NOT NULL,
+        polygon JSONB NOT NULL DEFAULT '[]',
+        postal_codes TEXT[] NOT NULL DEFAULT '{}',
+        fee DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (fee >= 0),
+        free_delivery_threshold DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (free_delivery_threshold >= 0),
+        min_order_amount DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (min_order_amount >= 0),
+        priority INT NOT NULL DEFAULT 0,
+        active BOOLEAN NOT NULL DEFAULT TRUE,
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+    )`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS delivery_zones_code_idx ON delivery_zones (code)`,
+	// Зона, по условиям которой посчитана доставка; пусто у самовывоза и заказов до появления зон
+	`ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_zone VARCHAR(100) NOT NULL DEFAULT ''`,
+	// Курьеры; последняя точка маршрута хранится в строке курьера для быстрого подбора ближайшего
+	`CREATE TABLE IF NOT EXISTS couriers (
+        id UUID PRIMARY KEY,
+        user_id VARCHAR(255) NOT NULL DEFAULT '',
+        name VARCHAR(255) NOT NULL,
+        phone VARCHAR(50) NOT NULL,
+        zone VARCHAR(100) NOT NULL DEFAULT '',
+        status VARCHAR(16) NOT NULL DEFAULT 'offline',
+        latitude DOUBLE PRECISION,
+        longitude DOUBLE PRECISION,
+        location_at TIMESTAMP WITH TIME ZONE,
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+    )`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS couriers_phone_idx ON couriers (phone)`,
+	`CREATE TABLE IF NOT EXISTS courier_locations (
+        id BIGSERIAL PRIMARY KEY,
+        courier_id UUID NOT NULL REFERENCES couriers(id) ON DELETE CASCADE,
+        latitude DOUBLE PRECISION NOT NULL,
+        longitude DOUBLE PRECISION NOT NULL,
+        recorded_at TIMESTAMP WITH TIME ZONE NOT NULL
+    )`,
+	`CREATE INDEX IF NOT EXISTS courier_locations_courier_idx ON courier_locations (courier_id, recorded_at)`,
+	// Доставки заказов; у заказа не больше одной незавершенной доставки, у курьера - тоже
+	`CREATE TABLE IF NOT EXISTS deliveries (
+        id UUID PRIMARY KEY,
+        order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+        courier_id UUID NOT NULL REFERENCES couriers(id),
+        status VARCHAR(32) NOT NULL,
+        proof_note TEXT NOT NULL DEFAULT '',
+        proof_photo_url TEXT NOT NULL DEFAULT '',
+        assigned_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        picked_up_at TIMESTAMP WITH TIME ZONE,
+        delivered_at TIMESTAMP WITH TIME ZONE,
+        updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+    )`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS deliveries_active_order_idx ON deliveries (order_id)
+        WHERE status IN ('assigned', 'out_for_delivery')`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS deliveries_active_courier_idx ON deliveries (courier_id)
+        WHERE status IN ('assigned', 'out_for_delivery')`,
+	`CREATE INDEX IF NOT EXISTS deliveries_order_idx ON deliveries (order_id, assigned_at)`,
+	// Outbox доменных событий: событие пишется в транзакции изменения, relay отправляет его брокеру
+	`CREATE TABLE IF NOT EXISTS outbox_events (
+        id UUID PRIMARY KEY,
+        seq BIGSERIAL NOT NULL,
+        type VARCHAR(100) NOT NULL,
+        aggregate_id VARCHAR(255) NOT NULL,
+        payload JSONB NOT NULL,
+        occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        published_at TIMESTAMP WITH TIME ZONE,
+        attempts INT NOT NULL DEFAULT 0,
+        last_error TEXT NOT NULL DEFAULT ''
+    )`,
+	`CREATE INDEX IF NOT EXISTS outbox_events_pending_idx ON outbox_events (seq) WHERE published_at IS NULL`,
+	`CREATE INDEX IF NOT EXISTS outbox_events_published_idx ON outbox_events (published_at)`,
+	// Обработанные потребителями события: повторная доставка того же события пропускается
+	`CREATE TABLE IF NOT EXISTS processed_events (
+        consumer VARCHAR(100) NOT NULL,
+        event_id UUID NOT NULL REFERENCES outbox_events(id) ON DELETE CASCADE,
+        processed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (consumer, event_id)
+    )`,
+	// Роль пользователя: администраторы получают доступ к управлению каталогом, складом и доставкой
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'customer'`,
+	// До какого момента событие закреплено за relay, который его отправляет
+	`ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMP WITH TIME ZONE`,
+}
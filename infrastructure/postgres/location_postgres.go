@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"FoodStore-AdvProg2/domain"
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type LocationPostgresRepo struct{}
+
+func NewLocationPostgresRepo() *LocationPostgresRepo {
+	return &LocationPostgresRepo{}
+}
+
+const locationColumns = `id, code, name, type, street, city, postal_code, latitude, longitude, is_default, active, created_at`
+
+func scanLocation(row pgx.Row) (domain.Location, error) {
+	var l domain.Location
+	err := row.Scan(&l.ID, &l.Code, &l.Name, &l.Type, &l.Address.Street, &l.Address.City, &l.Address.PostalCode,
+		&l.Address.Latitude, &l.Address.Longitude, &l.IsDefault, &l.Active, &l.CreatedAt)
+	return l, err
+}
+
+// Save создает точку; склад по умолчанию создается только миграцией
+func (r *LocationPostgresRepo) Save(location domain.Location) (domain.Location, error) {
+	location.ID = uuid.New().String()
+	query := `
+        INSERT INTO locations (id, code, name, type, street, city, postal_code, latitude, longitude, active)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        RETURNING created_at
+    `
+	err := DB.QueryRow(context.Background(), query,
+		location.ID, location.Code, location.Name, location.Type,
+		location.Address.Street, location.Address.City, location.Address.PostalCode,
+		location.Address.Latitude, location.Address.Longitude, location.Active,
+	).Scan(&location.CreatedAt)
+	return location, err
+}
+
+func (r *LocationPostgresRepo) Update(location domain.Location) error {
+	query := `
+        UPDATE locations
+        SET name = $2, type = $3, street = $4, city = $5, postal_code = $6, latitude = $7, longitude = $8, active = $9
+        WHERE id = $1
+    `
+	_, err := DB.Exec(context.Background(), query,
+		location.ID, location.Name, location.Type,
+		location.Address.Street, location.Address.City, location.Address.PostalCode,
+		location.Address.Latitude, location.Address.Longitude, location.Active,
+	)
+	return err
+}
+
+func (r *LocationPostgresRepo) FindByID(id string) (domain.Location, error) {
+	return scanLocation(DB.QueryRow(context.Background(), `SELECT `+locationColumns+` FROM locations WHERE id = $1`, id))
+}
+
+func (r *LocationPostgresRepo) FindDefault() (domain.Location, error) {
+	return scanLocation(DB.QueryRow(context.Background(), `SELECT `+locationColumns+` FROM locations WHERE is_default`))
+}
+
+func (r *LocationPostgresRepo) FindAll(includeInactive bool) ([]domain.Location, error) {
+	rows, err := DB.Query(context.Background(),
+		`SELECT `+locationColumns+` FROM locations WHERE active OR $1 ORDER BY code`, includeInactive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var locations []domain.Location
+	for rows.Next() {
+		l, err := scanLocation(rows)
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, l)
+	}
+	return locations, rows.Err()
+}
@@ -3,6 +3,10 @@ package postgres
 import (
 	"FoodStore-AdvProg2/domain"
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4"
@@ -10,11 +14,49 @@ import (
 
 type OrderPostgresRepo struct{}
 
+const orderColumns = `id, user_id, total_amount, status, created_at, COALESCE(location_id::text, ''),
+        delivery_street, delivery_city, delivery_postal_code, delivery_latitude, delivery_longitude,
+        COALESCE(subtotal, total_amount), discount_total, delivery_fee, tax_mode, tax_total, delivery_tax,
+        COALESCE(delivery_slot_id::text, ''), delivery_starts_at, delivery_ends_at, delivery_zone`
+
+func scanOrder(row pgx.Row) (domain.Order, error) {
+	var order domain.Order
+	err := row.Scan(
+		&order.ID,
+		&order.UserID,
+		&order.TotalAmount,
+		&order.Status,
+		&order.CreatedAt,
+		&order.LocationID,
+		&order.DeliveryAddress.Street,
+		&order.DeliveryAddress.City,
+		&order.DeliveryAddress.PostalCode,
+		&order.DeliveryAddress.Latitude,
+		&order.DeliveryAddress.Longitude,
+		&order.Subtotal,
+		&order.DiscountTotal,
+		&order.DeliveryFee,
+		&order.TaxMode,
+		&order.TaxTotal,
+		&order.DeliveryTax,
+		&order.DeliverySlotID,
+		&order.DeliveryStartsAt,
+		&order.DeliveryEndsAt,
+		&order.DeliveryZone,
+	)
+	return order, err
+}
+
+// orderItemBaseQuantityExpr - количество позиции заказа в базовых единицах остатка (domain.ToBaseUnits)
+const orderItemBaseQuantityExpr = `ROUND(oi.quantity * CASE oi.unit WHEN 'kg' THEN 1000 WHEN 'litre' THEN 1000 ELSE 1 END)`
+
 func NewOrderPostgresRepo() *OrderPostgresRepo {
 	return &OrderPostgresRepo{}
 }
 
-// Save сохраняет новый заказ в базу данных
+// Save сохраняет новый заказ в базу данных вместе с примененными скидками и использованиями акций.
+// Лимиты акций перепроверяются в транзакции; при превышении возвращается domain.ErrPromotionLimitReached.
+// Бронь интервала доставки подтверждается в той же транзакции.
 func (r *OrderPostgresRepo) Save(order domain.Order, items []domain.OrderItem) (string, error) {
 	tx, err := DB.BeginTx(context.Background(), pgx.TxOptions{})
 	if err != nil {
@@ -28,31 +70,102 @@ func (r *OrderPostgresRepo) Save(order domain.Order, items []domain.OrderItem) (
 		}
 	}()
 
-	// Генерируем ID заказа
-	orderID := uuid.New().String()
+	// ID заказа задает use case, если под него уже списаны товары
+	orderID := order.ID
+	if orderID == "" {
+		orderID = uuid.New().String()
+	}
 
 	// Сохраняем заказ
 	_, err = tx.Exec(context.Background(),
-		"INSERT INTO orders (id, user_id, status, total_amount, created_at) VALUES ($1, $2, $3, $4, $5)",
-		orderID, order.UserID, order.Status, order.TotalAmount, order.CreatedAt,
+		`INSERT INTO orders (id, user_id, status, total_amount, created_at, location_id,
+            delivery_street, delivery_city, delivery_postal_code, delivery_latitude, delivery_longitude,
+            subtotal, discount_total, delivery_fee, tax_mode, tax_total, delivery_tax,
+            delivery_slot_id, delivery_starts_at, delivery_ends_at, delivery_zone)
+        VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::uuid, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17,
+            NULLIF($18, '')::uuid, $19, $20, $21)`,
+		orderID, order.UserID, order.Status, order.TotalAmount, order.CreatedAt, order.LocationID,
+		order.DeliveryAddress.Street, order.DeliveryAddress.City, order.DeliveryAddress.PostalCode,
+		order.DeliveryAddress.Latitude, order.DeliveryAddress.Longitude,
+		order.Subtotal, order.DiscountTotal, order.DeliveryFee, order.TaxMode, order.TaxTotal, order.DeliveryTax,
+		order.DeliverySlotID, order.DeliveryStartsAt, order.DeliveryEndsAt, order.DeliveryZone,
 	)
 	if err != nil {
 		return "", err
 	}
 
-	// Сохраняем элементы заказа
+	// Бронь интервала доставки переходит к заказу; если она успела истечь, заказ не создается
+	if order.SlotReservationID != "" {
+		if err = confirmSlotReservation(context.Background(), tx, order.SlotReservationID, orderID, order.UserID); err != nil {
+			return "", err
+		}
+	}
+
+	// Сохраняем элементы заказа; ID позиции может быть задан заранее, чтобы привязать к ней скидки
+	discounts := order.Discounts
 	for _, item := range items {
+		itemID := item.ID
+		if itemID == "" {
+			itemID = uuid.New().String()
+		}
+		for _, d := range item.Discounts {
+			d.OrderItemID = itemID
+			discounts = append(discounts, d)
+		}
 		_, err = tx.Exec(context.Background(),
-			"INSERT INTO order_items (id, order_id, product_id, quantity, price) VALUES ($1, $2, $3, $4, $5)",
-			uuid.New().String(),
+			`INSERT INTO order_items (id, order_id, product_id, quantity, unit, price, tax_rate, tax_amount)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			itemID,
 			orderID,
 			item.ProductID,
 			item.Quantity,
+			item.Unit,
 			item.Price,
+			item.TaxRate,
+			item.TaxAmount,
+		)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// Сохраняем итоги НДС по ставкам
+	for _, line := range order.TaxSummary {
+		_, err = tx.Exec(context.Background(),
+			`INSERT INTO order_tax_summary (order_id, rate, net_amount, tax_amount) VALUES ($1, $2, $3, $4)`,
+			orderID, line.Rate, line.NetAmount, line.TaxAmount,
+		)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// Сохраняем скидки и использования акций
+	redeemed := make(map[string]float64)
+	for _, d := range discounts {
+		_, err = tx.Exec(context.Background(), `
+            INSERT INTO order_discounts (id, order_id, order_item_id, promotion_id, name, coupon_code, amount)
+            VALUES ($1, $2, NULLIF($3, '')::uuid, $4, $5, $6, $7)`,
+			uuid.New().String(), orderID, d.OrderItemID, d.PromotionID, d.Name, d.CouponCode, d.Amount,
 		)
 		if err != nil {
 			return "", err
 		}
+		redeemed[d.PromotionID] += d.Amount
+	}
+	if err = redeemPromotions(context.Background(), tx, orderID, order.UserID, redeemed); err != nil {
+		return "", err
+	}
+
+	err = insertEvent(context.Background(), tx, domain.EventOrderCreated, orderID, domain.OrderCreatedEvent{
+		OrderID:     orderID,
+		UserID:      order.UserID,
+		Status:      order.Status,
+		TotalAmount: order.TotalAmount,
+		LocationID:  order.LocationID,
+	})
+	if err != nil {
+		return "", err
 	}
 
 	if err := tx.Commit(context.Background()); err != nil {
@@ -64,27 +177,23 @@ func (r *OrderPostgresRepo) Save(order domain.Order, items []domain.OrderItem) (
 
 func (r *OrderPostgresRepo) FindByID(id string) (domain.Order, []domain.OrderItem, error) {
 	orderQuery := `
-        SELECT id, user_id, total_amount, status, created_at 
+        SELECT ` + orderColumns + `
         FROM orders 
         WHERE id = $1
     `
-	var order domain.Order
-	err := DB.QueryRow(context.Background(), orderQuery, id).Scan(
-		&order.ID,
-		&order.UserID,
-		&order.TotalAmount,
-		&order.Status,
-		&order.CreatedAt,
-	)
+	order, err := scanOrder(DB.QueryRow(context.Background(), orderQuery, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Order{}, nil, domain.ErrOrderNotFound
+	}
 	if err != nil {
 		return domain.Order{}, nil, err
 	}
 
 	itemsQuery := `
-        SELECT oi.id, oi.order_id, oi.product_id, oi.quantity, oi.price,
-               p.name, p.stock
+        SELECT oi.id, oi.order_id, oi.product_id, oi.quantity::float8, oi.unit, oi.price,
+               oi.tax_rate, oi.tax_amount, COALESCE(p.name, ''), COALESCE(p.stock, 0), p.archived_at
         FROM order_items oi
-        JOIN products p ON oi.product_id = p.id
+        LEFT JOIN products p ON oi.product_id = p.id
         WHERE oi.order_id = $1
     `
 	rows, err := DB.Query(context.Background(), itemsQuery, id)
@@ -98,42 +207,168 @@ func (r *OrderPostgresRepo) FindByID(id string) (domain.Order, []domain.OrderIte
 		var item domain.OrderItem
 		var productName string
 		var productStock int
+		var archivedAt *time.Time
 
 		err := rows.Scan(
 			&item.ID,
 			&item.OrderID,
 			&item.ProductID,
 			&item.Quantity,
+			&item.Unit,
 			&item.Price,
+			&item.TaxRate,
+			&item.TaxAmount,
 			&productName,
 			&productStock,
+			&archivedAt,
 		)
 		if err != nil {
 			return domain.Order{}, nil, err
 		}
 
+		// Архивный товар остается в истории заказов
 		item.Product = &domain.Product{
-			ID:    item.ProductID,
-			Name:  productName,
-			Price: item.Price,
-			Stock: productStock,
+			ID:         item.ProductID,
+			Name:       productName,
+			Price:      item.Price,
+			Stock:      productStock,
+			Unit:       item.Unit,
+			ArchivedAt: archivedAt,
 		}
 
 		items = append(items, item)
 	}
+	if err := rows.Err(); err != nil {
+		return domain.Order{}, nil, err
+	}
+
+	if err := r.attachDiscounts(&order, items); err != nil {
+		return domain.Order{}, nil, err
+	}
+	if order.TaxSummary, err = r.findTaxSummary(order.ID); err != nil {
+		return domain.Order{}, nil, err
+	}
 
 	return order, items, nil
 }
 
+// findTaxSummary возвращает итоги НДС заказа, большие ставки первыми
+func (r *OrderPostgresRepo) findTaxSummary(orderID string) ([]domain.TaxSummaryLine, error) {
+	rows, err := DB.Query(context.Background(), `
+        SELECT rate, net_amount, tax_amount FROM order_tax_summary
+        WHERE order_id = $1 ORDER BY rate DESC`,
+		orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summary []domain.TaxSummaryLine
+	for rows.Next() {
+		var line domain.TaxSummaryLine
+		if err := rows.Scan(&line.Rate, &line.NetAmount, &line.TaxAmount); err != nil {
+			return nil, err
+		}
+		summary = append(summary, line)
+	}
+	return summary, rows.Err()
+}
+
+// attachDiscounts раскладывает скидки заказа по позициям; скидки без позиции остаются на заказе
+func (r *OrderPostgresRepo) attachDiscounts(order *domain.Order, items []domain.OrderItem) error {
+	rows, err := DB.Query(context.Background(), `
+        SELECT id, COALESCE(order_item_id::text, ''), COALESCE(promotion_id::text, ''), name, coupon_code, amount
+        FROM order_discounts WHERE order_id = $1 ORDER BY name, id`,
+		order.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	byItem := make(map[string]int, len(items))
+	for i, item := range items {
+		byItem[item.ID] = i
+	}
+	for rows.Next() {
+		var d domain.OrderDiscount
+		if err := rows.Scan(&d.ID, &d.OrderItemID, &d.PromotionID, &d.Name, &d.CouponCode, &d.Amount); err != nil {
+			return err
+		}
+		if i, ok := byItem[d.OrderItemID]; ok {
+			items[i].Discounts = append(items[i].Discounts, d)
+			items[i].Discount = math.Round((items[i].Discount+d.Amount)*100) / 100
+			continue
+		}
+		order.Discounts = append(order.Discounts, d)
+	}
+	return rows.Err()
+}
+
+// UpdateStatus меняет статус заказа вручную по таблице domain.CanChangeOrderStatus, проверяя
+// текущий статус под блокировкой заказа. Отмена в той же транзакции снимает заказ с курьера;
+// завершить заказ, за которым закреплен курьер, нельзя.
 func (r *OrderPostgresRepo) UpdateStatus(id string, status string) error {
-	query := `UPDATE orders SET status = $1 WHERE id = $2`
-	_, err := DB.Exec(context.Background(), query, status, id)
-	return err
+	ctx := context.Background()
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := changeOrderStatus(ctx, tx, id, status, domain.CanChangeOrderStatus); err != nil {
+		return err
+	}
+	switch status {
+	case domain.OrderStatusCancelled:
+		if err := cancelActiveDelivery(ctx, tx, id); err != nil {
+			return err
+		}
+	case domain.OrderStatusCompleted:
+		var withCourier bool
+		err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM deliveries WHERE order_id = $1 AND status IN `+
+			activeDeliveryStatuses+`)`, id).Scan(&withCourier)
+		if err != nil {
+			return err
+		}
+		if withCourier {
+			return fmt.Errorf("%w: order is assigned to a courier", domain.ErrInvalidOrderTransition)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// changeOrderStatus переводит заказ в status в транзакции tx и пишет в outbox OrderStatusChanged,
+// если статус действительно изменился. Переход проверяется функцией allowed под блокировкой заказа.
+func changeOrderStatus(ctx context.Context, tx pgx.Tx, orderID, status string, allowed func(from, to string) bool) error {
+	var previous, userID string
+	err := tx.QueryRow(ctx, `SELECT status, user_id FROM orders WHERE id = $1 FOR UPDATE`, orderID).Scan(&previous, &userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrOrderNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if previous == status {
+		return nil
+	}
+	if !allowed(previous, status) {
+		return fmt.Errorf("%w: cannot change order from %s to %s", domain.ErrInvalidOrderTransition, previous, status)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE orders SET status = $2 WHERE id = $1`, orderID, status); err != nil {
+		return err
+	}
+	return insertEvent(ctx, tx, domain.EventOrderStatusChanged, orderID, domain.OrderStatusChangedEvent{
+		OrderID:        orderID,
+		UserID:         userID,
+		PreviousStatus: previous,
+		Status:         status,
+	})
 }
 
 func (r *OrderPostgresRepo) FindByUserID(userID string) ([]domain.Order, error) {
 	query := `
-        SELECT id, user_id, total_amount, status, created_at 
+        SELECT ` + orderColumns + `
         FROM orders 
         WHERE user_id = $1
         ORDER BY created_at DESC
@@ -146,14 +381,7 @@ func (r *OrderPostgresRepo) FindByUserID(userID string) ([]domain.Order, error)
 
 	var orders []domain.Order
 	for rows.Next() {
-		var order domain.Order
-		err := rows.Scan(
-			&order.ID,
-			&order.UserID,
-			&order.TotalAmount,
-			&order.Status,
-			&order.CreatedAt,
-		)
+		order, err := scanOrder(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -165,7 +393,7 @@ func (r *OrderPostgresRepo) FindByUserID(userID string) ([]domain.Order, error)
 
 func (r *OrderPostgresRepo) FindAll() ([]domain.Order, error) {
 	query := `
-        SELECT id, user_id, total_amount, status, created_at 
+        SELECT ` + orderColumns + `
         FROM orders 
         ORDER BY created_at DESC
     `
@@ -177,14 +405,7 @@ func (r *OrderPostgresRepo) FindAll() ([]domain.Order, error) {
 
 	var orders []domain.Order
 	for rows.Next() {
-		var order domain.Order
-		err := rows.Scan(
-			&order.ID,
-			&order.UserID,
-			&order.TotalAmount,
-			&order.Status,
-			&order.CreatedAt,
-		)
+		order, err := scanOrder(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,244 @@
+package postgres
+
+import (
+	"FoodStore-AdvProg2/domain"
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+// outboxChannel - канал NOTIFY, которым запись в outbox будит relay
+const outboxChannel = "outbox_events"
+
+type OutboxPostgresRepo struct{}
+
+func NewOutboxPostgresRepo() *OutboxPostgresRepo {
+	return &OutboxPostgresRepo{}
+}
+
+const eventColumns = `id, type, aggregate_id, payload, occurred_at`
+
+func scanEvent(row pgx.Row) (domain.Event, error) {
+	var e domain.Event
+	err := row.Scan(&e.ID, &e.Type, &e.AggregateID, &e.Payload, &e.OccurredAt)
+	return e, err
+}
+
+// insertEvent записывает событие в outbox в транзакции tx. Уведомление relay
+// уходит при фиксации транзакции, после отката события нет.
+func insertEvent(ctx context.Context, tx pgx.Tx, eventType, aggregateID string, payload interface{}) error {
+	event, err := domain.NewEvent(eventType, aggregateID, payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO outbox_events (id, type, aggregate_id, payload, occurred_at)
+        VALUES ($1, $2, $3, $4, $5)`,
+		uuid.New().String(), event.Type, event.AggregateID, []byte(event.Payload), event.OccurredAt,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `SELECT pg_notify($1, '')`, outboxChannel)
+	return err
+}
+
+// outboxClaimTTL - сколько событие остается за relay, который его взял. Если relay упал,
+// не отметив событие, после этого срока его отправит другой relay.
+const outboxClaimTTL = time.Minute
+
+// PublishPending сначала отдельным запросом закрепляет пачку событий за этим relay (claimed_until),
+// затем отправляет их без открытой транзакции и отмечает каждое отправленное коротким запросом.
+// Поэтому блокировки строк outbox не держатся, пока идет отправка по сети, а несколько relay
+// не отправят одно событие одновременно: закрепленные события другие relay пропускают.
+func (r *OutboxPostgresRepo) PublishPending(limit int, publish func(domain.Event) error) (int, error) {
+	ctx := context.Background()
+	events, err := claimEvents(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for i, event := range events {
+		if publishErr := publish(event); publishErr != nil {
+			_, err = DB.Exec(ctx, `UPDATE outbox_events SET attempts = attempts + 1, last_error = $2, claimed_until = NULL
+                WHERE id = $1`, event.ID, publishErr.Error())
+			if err != nil {
+				return published, err
+			}
+			// Остальные события пачки освобождаются, чтобы следующая попытка отправила их по порядку
+			if err := releaseEvents(ctx, events[i+1:]); err != nil {
+				log.Printf("Failed to release outbox events: %v", err)
+			}
+			return published, publishErr
+		}
+		_, err = DB.Exec(ctx, `UPDATE outbox_events SET published_at = $2, last_error = '', claimed_until = NULL
+            WHERE id = $1`, event.ID, time.Now())
+		if err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+
+// claimEvents закрепляет до limit неотправленных и никем не занятых событий на outboxClaimTTL
+// и возвращает их в порядке появления. Запрос выполняется в своей транзакции, которая сразу фиксируется.
+func claimEvents(ctx context.Context, limit int) ([]domain.Event, error) {
+	rows, err := DB.Query(ctx, `
+        WITH claimed AS (
+            UPDATE outbox_events SET claimed_until = now() + $2::interval
+            WHERE id IN (
+                SELECT id FROM outbox_events
+                WHERE published_at IS NULL AND (claimed_until IS NULL OR claimed_until < now())
+                ORDER BY seq
+                LIMIT $1
+                FOR UPDATE SKIP LOCKED)
+            RETURNING seq, `+eventColumns+`)
+        SELECT `+eventColumns+` FROM claimed ORDER BY seq`, limit, outboxClaimTTL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []domain.Event
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// releaseEvents снимает закрепление с неотправленных событий
+func releaseEvents(ctx context.Context, events []domain.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	_, err := DB.Exec(ctx, `UPDATE outbox_events SET claimed_until = NULL WHERE id = ANY($1::uuid[])`, ids)
+	return err
+}
+
+func (r *OutboxPostgresRepo) FindUnprocessed(consumer string, since time.Time, limit int) ([]domain.Event, error) {
+	rows, err := DB.Query(context.Background(), `
+        SELECT `+eventColumns+` FROM outbox_events e
+        WHERE e.published_at IS NOT NULL AND e.occurred_at >= $2
+            AND NOT EXISTS (SELECT 1 FROM processed_events p WHERE p.consumer = $1 AND p.event_id = e.id)
+        ORDER BY e.seq
+        LIMIT $3`, consumer, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []domain.Event
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func (r *OutboxPostgresRepo) IsProcessed(consumer, eventID string) (bool, error) {
+	var processed bool
+	err := DB.QueryRow(context.Background(),
+		`SELECT EXISTS (SELECT 1 FROM processed_events WHERE consumer = $1 AND event_id = $2)`,
+		consumer, eventID,
+	).Scan(&processed)
+	return processed, err
+}
+
+func (r *OutboxPostgresRepo) MarkProcessed(consumer, eventID string) error {
+	_, err := DB.Exec(context.Background(), `
+        INSERT INTO processed_events (consumer, event_id) VALUES ($1, $2)
+        ON CONFLICT DO NOTHING`, consumer, eventID)
+	return err
+}
+
+// DeletePublished удаляет отметки об обработке каскадом
+func (r *OutboxPostgresRepo) DeletePublished(before time.Time) (int64, error) {
+	tag, err := DB.Exec(context.Background(),
+		`DELETE FROM outbox_events WHERE published_at IS NOT NULL AND published_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ListenOutbox сигналит в канал, когда в outbox появляются события. Канал закрывается
+// при отмене ctx; после обрыва соединения подписка восстанавливается.
+func ListenOutbox(ctx context.Context) <-chan struct{} {
+	wake := make(chan struct{}, 1)
+	go func() {
+		defer close(wake)
+		listen(ctx, outboxChannel, func(string) {
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+		})
+	}()
+	return wake
+}
+
+// listen держит соединение из пула с LISTEN channel и передает handle payload
+// каждого уведомления, пока не отменен ctx. После ошибки переподключается с паузой.
+func listen(ctx context.Context, channel string, handle func(payload string)) {
+	backoff := time.Second
+	for {
+		started := time.Now()
+		err := listenOnce(ctx, channel, handle)
+		if ctx.Err() != nil {
+			return
+		}
+		// Долго работавшее соединение оборвалось случайно - переподключаемся с минимальной паузой
+		if time.Since(started) > time.Minute {
+			backoff = time.Second
+		}
+		log.Printf("LISTEN %s failed, reconnecting in %s: %v", channel, backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+func listenOnce(ctx context.Context, channel string, handle func(payload string)) error {
+	conn, err := DB.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return err
+	}
+	// Соединение вернется в пул с активным LISTEN, поэтому при выходе подписка снимается
+	defer conn.Exec(context.Background(), "UNLISTEN *")
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		handle(notification.Payload)
+	}
+}
@@ -0,0 +1,256 @@
+package postgres
+
+import (
+	"FoodStore-AdvProg2/domain"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type PricePostgresRepo struct{}
+
+func NewPricePostgresRepo() *PricePostgresRepo {
+	return &PricePostgresRepo{}
+}
+
+// recordPrice записывает текущую цену товара в историю, если она отличается от последней
+// записанной. Вызывается в транзакции, которая меняет цену, после изменения.
+func recordPrice(ctx context.Context, tx pgx.Tx, productID, source, actor, scheduleID string) error {
+	_, err := tx.Exec(ctx, `
+        INSERT INTO price_history (product_id, price, source, actor, schedule_id)
+        SELECT p.id, p.price, $2, $3, NULLIF($4, '')::uuid FROM products p
+        WHERE p.id = $1 AND p.price IS DISTINCT FROM (
+            SELECT h.price FROM price_history h WHERE h.product_id = p.id
+            ORDER BY h.effective_at DESC, h.id DESC LIMIT 1)`,
+		productID, source, actor, scheduleID)
+	return err
+}
+
+const priceChangeColumns = `id, product_id, price, effective_at, source, actor, COALESCE(schedule_id::text, '')`
+
+func scanPriceChange(row pgx.Row) (domain.PriceChange, error) {
+	var c domain.PriceChange
+	err := row.Scan(&c.ID, &c.ProductID, &c.Price, &c.EffectiveAt, &c.Source, &c.Actor, &c.ScheduleID)
+	return c, err
+}
+
+func (r *PricePostgresRepo) PriceAt(productID string, at time.Time) (domain.PriceChange, error) {
+	change, err := scanPriceChange(DB.QueryRow(context.Background(), `
+        SELECT `+priceChangeColumns+` FROM price_history
+        WHERE product_id = $1 AND effective_at <= $2
+        ORDER BY effective_at DESC, id DESC LIMIT 1`,
+		productID, at))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return change, domain.ErrPriceNotFound
+	}
+	return change, err
+}
+
+func (r *PricePostgresRepo) History(productID string, limit int) ([]domain.PriceChange, error) {
+	rows, err := DB.Query(context.Background(), `
+        SELECT `+priceChangeColumns+` FROM price_history
+        WHERE product_id = $1 ORDER BY effective_at DESC, id DESC LIMIT $2`,
+		productID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []domain.PriceChange
+	for rows.Next() {
+		change, err := scanPriceChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, change)
+	}
+	return history, rows.Err()
+}
+
+// priceScheduleOverlap ищет открытую запланированную цену товара $1, которая конфликтует с новой
+// на период [$2, $3). Две акции не могут пересекаться, постоянное изменение не может начаться
+// внутри акции, а два постоянных изменения - в один и тот же момент.
+const priceScheduleOverlap = `SELECT EXISTS (SELECT 1 FROM price_schedules
+    WHERE product_id = $1 AND status IN ('pending', 'active') AND (
+        (ends_at IS NOT NULL AND $3::timestamptz IS NOT NULL AND starts_at < $3 AND $2 < ends_at)
+        OR (ends_at IS NOT NULL AND $3::timestamptz IS NULL AND starts_at <= $2 AND $2 < ends_at)
+        OR (ends_at IS NULL AND $3::timestamptz IS NOT NULL AND $2 <= starts_at AND starts_at < $3)
+        OR (ends_at IS NULL AND $3::timestamptz IS NULL AND starts_at = $2)))`
+
+// SaveSchedule блокирует товар, чтобы две параллельные проверки пересечения не пропустили друг друга
+func (r *PricePostgresRepo) SaveSchedule(s domain.PriceSchedule) error {
+	ctx := context.Background()
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM products WHERE id = $1 FOR UPDATE`, s.ProductID); err != nil {
+		return err
+	}
+	var overlaps bool
+	if err := tx.QueryRow(ctx, priceScheduleOverlap, s.ProductID, s.StartsAt, s.EndsAt).Scan(&overlaps); err != nil {
+		return err
+	}
+	if overlaps {
+		return domain.ErrPriceScheduleOverlap
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO price_schedules (id, product_id, price, starts_at, ends_at, status, created_by, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		s.ID, s.ProductID, s.Price, s.StartsAt, s.EndsAt, s.Status, s.CreatedBy, s.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+const priceScheduleColumns = `id, product_id, price, starts_at, ends_at, status, previous_price, created_by, created_at`
+
+func scanPriceSchedule(row pgx.Row) (domain.PriceSchedule, error) {
+	var s domain.PriceSchedule
+	err := row.Scan(&s.ID, &s.ProductID, &s.Price, &s.StartsAt, &s.EndsAt, &s.Status,
+		&s.PreviousPrice, &s.CreatedBy, &s.CreatedAt)
+	return s, err
+}
+
+func (r *PricePostgresRepo) findSchedules(query string, args ...interface{}) ([]domain.PriceSchedule, error) {
+	rows, err := DB.Query(context.Background(), `SELECT `+priceScheduleColumns+` FROM price_schedules `+query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []domain.PriceSchedule
+	for rows.Next() {
+		s, err := scanPriceSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+	return schedules, rows.Err()
+}
+
+func (r *PricePostgresRepo) FindScheduleByID(id string) (domain.PriceSchedule, error) {
+	s, err := scanPriceSchedule(DB.QueryRow(context.Background(),
+		`SELECT `+priceScheduleColumns+` FROM price_schedules WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s, domain.ErrPriceScheduleNotFound
+	}
+	return s, err
+}
+
+func (r *PricePostgresRepo) FindSchedules(productID, status string) ([]domain.PriceSchedule, error) {
+	return r.findSchedules(`WHERE ($1 = '' OR product_id = NULLIF($1, '')::uuid) AND ($2 = '' OR status = $2)
+        ORDER BY starts_at, id`, productID, status)
+}
+
+func (r *PricePostgresRepo) DueSchedules(now time.Time) ([]domain.PriceSchedule, error) {
+	return r.findSchedules(`WHERE (status = 'pending' AND starts_at <= $1)
+        OR (status = 'active' AND ends_at <= $1)
+        ORDER BY starts_at, id`, now)
+}
+
+func (r *PricePostgresRepo) StartSchedule(id string) (bool, error) {
+	ctx := context.Background()
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var productID, createdBy string
+	var temporary, expired bool
+	err = tx.QueryRow(ctx, `
+        SELECT product_id, created_by, ends_at IS NOT NULL, ends_at <= CURRENT_TIMESTAMP
+        FROM price_schedules WHERE id = $1 AND status = 'pending' FOR UPDATE`, id).
+		Scan(&productID, &createdBy, &temporary, &expired)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, domain.ErrPriceScheduleNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// Сервис был остановлен на весь период акции: применять и сразу возвращать цену незачем
+	if temporary && expired {
+		if _, err := tx.Exec(ctx, `UPDATE price_schedules SET status = 'completed' WHERE id = $1`, id); err != nil {
+			return false, err
+		}
+		return false, tx.Commit(ctx)
+	}
+
+	var previous float64
+	if err := tx.QueryRow(ctx, `SELECT price FROM products WHERE id = $1 FOR UPDATE`, productID).Scan(&previous); err != nil {
+		return false, err
+	}
+	_, err = tx.Exec(ctx, `UPDATE products SET price = (SELECT price FROM price_schedules WHERE id = $1),
+        version = version + 1 WHERE id = $2`, id, productID)
+	if err != nil {
+		return false, err
+	}
+
+	status := domain.PriceScheduleCompleted
+	if temporary {
+		status = domain.PriceScheduleActive
+	}
+	_, err = tx.Exec(ctx, `UPDATE price_schedules SET status = $2, previous_price = $3 WHERE id = $1`, id, status, previous)
+	if err != nil {
+		return false, err
+	}
+	if err := recordPrice(ctx, tx, productID, domain.PriceSourceScheduled, createdBy, id); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
+
+func (r *PricePostgresRepo) EndSchedule(id, status, actor string) error {
+	ctx := context.Background()
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var productID string
+	err = tx.QueryRow(ctx, `SELECT product_id FROM price_schedules WHERE id = $1 AND status = 'active' FOR UPDATE`, id).
+		Scan(&productID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrPriceScheduleNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	// Цену возвращаем, только если она все еще акционная: ручное изменение во время акции главнее
+	tag, err := tx.Exec(ctx, `UPDATE products p SET price = s.previous_price, version = p.version + 1
+        FROM price_schedules s
+        WHERE s.id = $1 AND p.id = s.product_id AND p.price = s.price AND s.previous_price IS NOT NULL`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 1 {
+		if err := recordPrice(ctx, tx, productID, domain.PriceSourceScheduleEnd, actor, id); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE price_schedules SET status = $2 WHERE id = $1`, id, status); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *PricePostgresRepo) CancelSchedule(id string) (bool, error) {
+	tag, err := DB.Exec(context.Background(),
+		`UPDATE price_schedules SET status = 'cancelled' WHERE id = $1 AND status = 'pending'`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"FoodStore-AdvProg2/domain"
+	"context"
+)
+
+type ProductImagePostgresRepo struct{}
+
+func NewProductImagePostgresRepo() *ProductImagePostgresRepo {
+	return &ProductImagePostgresRepo{}
+}
+
+// Save сохраняет запись об изображении товара
+func (r *ProductImagePostgresRepo) Save(image domain.ProductImage) error {
+	query := `
+        INSERT INTO product_images (id, product_id, url, thumbnail_url, original_url, width, height, position, blob_keys)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    `
+	_, err := DB.Exec(context.Background(), query,
+		image.ID, image.ProductID, image.URL, image.ThumbnailURL, image.OriginalURL,
+		image.Width, image.Height, image.Position, image.Keys,
+	)
+	return err
+}
+
+func (r *ProductImagePostgresRepo) FindByID(id string) (domain.ProductImage, error) {
+	query := `
+        SELECT id, product_id, url, thumbnail_url, original_url, width, height, position, blob_keys, created_at
+        FROM product_images
+        WHERE id = $1
+    `
+	var img domain.ProductImage
+	err := DB.QueryRow(context.Background(), query, id).Scan(
+		&img.ID, &img.ProductID, &img.URL, &img.ThumbnailURL, &img.OriginalURL,
+		&img.Width, &img.Height, &img.Position, &img.Keys, &img.CreatedAt,
+	)
+	return img, err
+}
+
+// FindByProductIDs возвращает изображения нескольких товаров одним запросом, сгруппированные по товару
+func (r *ProductImagePostgresRepo) FindByProductIDs(productIDs []string) (map[string][]domain.ProductImage, error) {
+	query := `
+        SELECT id, product_id, url, thumbnail_url, original_url, width, height, position, blob_keys, created_at
+        FROM product_images
+        WHERE product_id = ANY($1::uuid[])
+        ORDER BY product_id, position, created_at
+    `
+	rows, err := DB.Query(context.Background(), query, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string][]domain.ProductImage)
+	for rows.Next() {
+		var img domain.ProductImage
+		if err := rows.Scan(
+			&img.ID, &img.ProductID, &img.URL, &img.ThumbnailURL, &img.OriginalURL,
+			&img.Width, &img.Height, &img.Position, &img.Keys, &img.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		result[img.ProductID] = append(result[img.ProductID], img)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *ProductImagePostgresRepo) Delete(id string) error {
+	_, err := DB.Exec(context.Background(), `DELETE FROM product_images WHERE id = $1`, id)
+	return err
+}
+
+// NextPosition возвращает позицию для нового изображения - в конец списка
+func (r *ProductImagePostgresRepo) NextPosition(productID string) (int, error) {
+	var pos int
+	err := DB.QueryRow(context.Background(),
+		`SELECT COALESCE(MAX(position) + 1, 0) FROM product_images WHERE product_id = $1`, productID,
+	).Scan(&pos)
+	return pos, err
+}
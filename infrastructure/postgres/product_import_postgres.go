@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"FoodStore-AdvProg2/domain"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type ProductImportPostgresRepo struct{}
+
+func NewProductImportPostgresRepo() *ProductImportPostgresRepo {
+	return &ProductImportPostgresRepo{}
+}
+
+func (r *ProductImportPostgresRepo) FindIDsBySKU(skus []string) (map[string]string, error) {
+	ids := make(map[string]string, len(skus))
+	if len(skus) == 0 {
+		return ids, nil
+	}
+
+	rows, err := DB.Query(context.Background(), `SELECT sku, id FROM products WHERE sku = ANY($1)`, skus)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sku, id string
+		if err := rows.Scan(&sku, &id); err != nil {
+			return nil, err
+		}
+		ids[sku] = id
+	}
+	return ids, rows.Err()
+}
+
+func (r *ProductImportPostgresRepo) Apply(rows []domain.ProductImportRow, change domain.StockChange) (int, int, error) {
+	ctx := context.Background()
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	created, updated := 0, 0
+	for _, row := range rows {
+		isNew, field, err := applyImportRow(ctx, tx, row, change)
+		if err != nil {
+			return 0, 0, domain.ProductImportRowError{Line: row.Line, SKU: row.SKU, Field: field, Message: uniqueViolationError(err).Error()}
+		}
+		if isNew {
+			created++
+		} else {
+			updated++
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, err
+	}
+	return created, updated, nil
+}
+
+// applyImportRow создает или обновляет товар строки и возвращает true для нового товара.
+// При ошибке остатка возвращает поле stock, чтобы показать его в ошибке строки.
+func applyImportRow(ctx context.Context, tx pgx.Tx, row domain.ProductImportRow, change domain.StockChange) (bool, string, error) {
+	var id, kind string
+	var available int
+	err := tx.QueryRow(ctx, `SELECT p.id, p.kind, `+availableStockExpr+` FROM products p WHERE p.sku = $1 FOR UPDATE`, row.SKU).
+		Scan(&id, &kind, &available)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		id = uuid.New().String()
+		description := ""
+		if row.Description != nil {
+			description = *row.Description
+		}
+		_, err = tx.Exec(ctx, `INSERT INTO products (id, sku, name, description, price, stock) VALUES ($1, $2, $3, $4, $5, 0)`,
+			id, row.SKU, row.Name, description, row.Price)
+		if err != nil {
+			return false, "", err
+		}
+		if err := recordPrice(ctx, tx, id, domain.PriceSourceInitial, change.Actor, ""); err != nil {
+			return false, "", err
+		}
+
+		if row.Stock != nil && *row.Stock > 0 {
+			initial := change
+			initial.Reason = "initial stock"
+			_, err = insertBatch(ctx, tx, domain.StockBatch{
+				ProductID:  id,
+				LotNumber:  domain.LotInitial,
+				ReceivedAt: time.Now(),
+				Quantity:   *row.Stock,
+			}, domain.MovementReceipt, initial)
+			if err != nil {
+				return false, domain.ProductFieldStock, err
+			}
+		}
+		return true, "", nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	// Остаток набора считается по компонентам, поэтому stock в его строке должен быть пустым
+	if kind == domain.ProductKindBundle && row.Stock != nil {
+		return false, domain.ProductFieldStock, domain.ErrBundleStock
+	}
+
+	// Статус архива не меняется: импорт обновляет архивный товар, но не возвращает его в каталог
+	_, err = tx.Exec(ctx, `UPDATE products SET name = $1, price = $2, description = COALESCE($3, description),
+        version = version + 1 WHERE id = $4`,
+		row.Name, row.Price, row.Description, id)
+	if err != nil {
+		return false, "", err
+	}
+	if err := recordPrice(ctx, tx, id, domain.PriceSourceImport, change.Actor, ""); err != nil {
+		return false, "", err
+	}
+
+	if row.Stock == nil || *row.Stock == available {
+		return false, "", nil
+	}
+	if err := adjustStock(ctx, tx, id, "", *row.Stock-available, change); err != nil {
+		return false, domain.ProductFieldStock, err
+	}
+	return false, "", nil
+}
@@ -2,100 +2,588 @@ package postgres
 
 import (
     "context"
+    "encoding/base64"
+    "encoding/json"
+    "errors"
     "fmt"
+    "strings"
+    "unicode"
+
     "FoodStore-AdvProg2/domain"
     "github.com/google/uuid"
+    "github.com/jackc/pgconn"
+    "github.com/jackc/pgx/v4"
 )
 
+// ErrInvalidCursor возвращается, если курсор поврежден или получен для другой сортировки
+var ErrInvalidCursor = errors.New("invalid cursor")
+
 type ProductPostgresRepo struct{}
 
 func NewProductPostgresRepo() *ProductPostgresRepo {
     return &ProductPostgresRepo{}
 }
 
-func (r *ProductPostgresRepo) Save(product domain.Product) error {
-    query := `INSERT INTO products (id, name, price, stock) VALUES ($1, $2, $3, $4)`
-    _, err := DB.Exec(context.Background(), query,
-        uuid.New().String(), product.Name, product.Price, product.Stock)
+// availableStockExpr - доступный остаток: все партии товара за вычетом просроченных
+const availableStockExpr = `p.stock - COALESCE((SELECT SUM(b.quantity) FROM stock_batches b
+        WHERE b.product_id = p.id AND b.expires_at < CURRENT_DATE), 0)`
+
+// productStockExpr - доступный остаток товара. Набор не имеет своих партий:
+// его остаток - сколько наборов можно собрать из доступных остатков компонентов.
+const productStockExpr = `CASE WHEN p.kind = 'bundle' THEN COALESCE((
+        SELECT MIN(GREATEST(c.stock - COALESCE((SELECT SUM(b.quantity) FROM stock_batches b
+            WHERE b.product_id = c.id AND b.expires_at < CURRENT_DATE), 0), 0) / bc.quantity)
+        FROM bundle_components bc JOIN products c ON c.id = bc.component_id WHERE bc.bundle_id = p.id), 0)
+    ELSE ` + availableStockExpr + ` END`
+
+// productComponentsExpr - состав набора в JSON в порядке добавления
+const productComponentsExpr = `(SELECT COALESCE(json_agg(json_build_object('product_id', bc.component_id,
+        'quantity', bc.quantity, 'name', c.name, 'unit', c.unit) ORDER BY bc.position), '[]')
+    FROM bundle_components bc JOIN products c ON c.id = bc.component_id WHERE bc.bundle_id = p.id)`
+
+// uniqueIndexErrors - уникальные индексы товара, акций, доставки и курьеров и ошибки, которыми они возвращаются.
+// Пустой артикул хранится как NULL и не уникален.
+var uniqueIndexErrors = map[string]error{
+    "products_sku_idx":              domain.ErrDuplicateSKU,
+    "product_barcodes_pkey":         domain.ErrDuplicateBarcode,
+    "promotions_coupon_idx":         domain.ErrDuplicateCoupon,
+    "delivery_slots_zone_start_idx": domain.ErrDuplicateDeliverySlot,
+    "delivery_zones_code_idx":       domain.ErrDuplicateDeliveryZone,
+    "couriers_phone_idx":            domain.ErrDuplicateCourier,
+    "deliveries_active_order_idx":   domain.ErrDeliveryAlreadyAssigned,
+    "deliveries_active_courier_idx": domain.ErrCourierUnavailable,
+}
+
+// uniqueViolationError превращает нарушение уникального индекса из uniqueIndexErrors в доменную ошибку
+func uniqueViolationError(err error) error {
+    var pgErr *pgconn.PgError
+    if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+        if domainErr, ok := uniqueIndexErrors[pgErr.ConstraintName]; ok {
+            return domainErr
+        }
+    }
     return err
 }
 
-func (r *ProductPostgresRepo) FindByID(id string) (domain.Product, error) {
-    query := `SELECT id, name, price, stock FROM products WHERE id = $1`
-    row := DB.QueryRow(context.Background(), query, id)
+// productBarcodesExpr - штрихкоды товара в порядке добавления
+const productBarcodesExpr = `ARRAY(SELECT pb.barcode FROM product_barcodes pb WHERE pb.product_id = p.id ORDER BY pb.position)`
+
+// productColumns - колонки товара в порядке scanProduct
+const productColumns = `p.id, COALESCE(p.sku, ''), p.name, p.description, p.price, ` + productStockExpr + `, p.unit,
+        ` + productBarcodesExpr + `, p.created_at, p.reorder_point, p.reorder_quantity, p.version, p.archived_at,
+        p.nutrition, p.ingredients, p.allergens, p.dietary_tags,
+        p.kind, COALESCE(p.parent_id::text, ''), p.variant_name, ` + productComponentsExpr + `, p.category`
 
+// scanProduct читает колонки productColumns и дополнительные значения extra после них
+func scanProduct(row pgx.Row, extra ...interface{}) (domain.Product, error) {
     var p domain.Product
-    err := row.Scan(&p.ID, &p.Name, &p.Price, &p.Stock)
-    return p, err
+    var nutrition, components []byte
+    dest := []interface{}{&p.ID, &p.SKU, &p.Name, &p.Description, &p.Price, &p.Stock, &p.Unit,
+        &p.Barcodes, &p.CreatedAt, &p.ReorderPoint, &p.ReorderQuantity, &p.Version, &p.ArchivedAt,
+        &nutrition, &p.Ingredients, &p.Allergens, &p.DietaryTags,
+        &p.Kind, &p.ParentID, &p.VariantName, &components, &p.Category}
+    if err := row.Scan(append(dest, extra...)...); err != nil {
+        return p, err
+    }
+    if p.IsBundle() {
+        if err := json.Unmarshal(components, &p.Components); err != nil {
+            return p, err
+        }
+    }
+    if nutrition != nil {
+        p.Nutrition = &domain.NutritionFacts{}
+        if err := json.Unmarshal(nutrition, p.Nutrition); err != nil {
+            return p, err
+        }
+    }
+    return p, nil
+}
+
+// nutritionJSON готовит пищевую ценность для колонки JSONB; nil сохраняется как NULL
+func nutritionJSON(n *domain.NutritionFacts) ([]byte, error) {
+    if n == nil {
+        return nil, nil
+    }
+    return json.Marshal(n)
+}
+
+// textArray заменяет nil на пустой массив: колонки массивов NOT NULL
+func textArray(values []string) []string {
+    if values == nil {
+        return []string{}
+    }
+    return values
+}
+
+// insertBarcodes привязывает штрихкоды к товару в транзакции tx
+func insertBarcodes(ctx context.Context, tx pgx.Tx, productID string, barcodes []string) error {
+    for i, barcode := range barcodes {
+        _, err := tx.Exec(ctx, `INSERT INTO product_barcodes (barcode, product_id, position) VALUES ($1, $2, $3)`,
+            barcode, productID, i)
+        if err != nil {
+            return uniqueViolationError(err)
+        }
+    }
+    return nil
+}
+
+// insertComponents сохраняет состав набора в транзакции tx
+func insertComponents(ctx context.Context, tx pgx.Tx, bundleID string, components []domain.BundleComponent) error {
+    for i, c := range components {
+        _, err := tx.Exec(ctx, `INSERT INTO bundle_components (bundle_id, component_id, quantity, position) VALUES ($1, $2, $3, $4)`,
+            bundleID, c.ProductID, c.Quantity, i)
+        if err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// Save сохраняет товар с нулевым остатком: остаток заводится партиями через StockBatchRepository
+func (r *ProductPostgresRepo) Save(product domain.Product) error {
+    if product.ID == "" {
+        product.ID = uuid.New().String()
+    }
+    if product.Unit == "" {
+        product.Unit = domain.UnitPiece
+    }
+    if product.Kind == "" {
+        product.Kind = domain.ProductKindSimple
+    }
+
+    ctx := context.Background()
+    tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+
+    nutrition, err := nutritionJSON(product.Nutrition)
+    if err != nil {
+        return err
+    }
+
+    query := `INSERT INTO products (id, sku, name, description, price, stock, unit, nutrition, ingredients, allergens, dietary_tags,
+        kind, parent_id, variant_name, category)
+        VALUES ($1, NULLIF($2, ''), $3, $4, $5, 0, $6, $7, $8, $9, $10, $11, NULLIF($12, '')::uuid, $13, $14)`
+    _, err = tx.Exec(ctx, query,
+        product.ID, product.SKU, product.Name, product.Description, product.Price, product.Unit,
+        nutrition, textArray(product.Ingredients), textArray(product.Allergens), textArray(product.DietaryTags),
+        product.Kind, product.ParentID, product.VariantName, product.Category)
+    if err != nil {
+        return uniqueViolationError(err)
+    }
+    if err := insertBarcodes(ctx, tx, product.ID, product.Barcodes); err != nil {
+        return err
+    }
+    if err := insertComponents(ctx, tx, product.ID, product.Components); err != nil {
+        return err
+    }
+    if err := recordPrice(ctx, tx, product.ID, domain.PriceSourceInitial, "", ""); err != nil {
+        return err
+    }
+    return tx.Commit(ctx)
+}
+
+func (r *ProductPostgresRepo) FindByID(id string) (domain.Product, error) {
+    query := `SELECT ` + productColumns + ` FROM products p WHERE p.id = $1`
+    return scanProduct(DB.QueryRow(context.Background(), query, id))
+}
+
+// FindByBarcode ищет товар, включая архивный, по штрихкоду в канонической записи
+func (r *ProductPostgresRepo) FindByBarcode(barcode string) (domain.Product, error) {
+    query := `SELECT ` + productColumns + ` FROM products p
+        JOIN product_barcodes b ON b.product_id = p.id WHERE b.barcode = $1`
+    return scanProduct(DB.QueryRow(context.Background(), query, barcode))
+}
+
+// Update обновляет карточку товара и увеличивает версию. Штрихкоды из маски заменяют
+// прежний список товара. Новая цена попадает в историю цен. Остаток складывается из партий:
+// разница между update.Stock и доступным остатком (тем же, что отдает API, без просроченных партий)
+// оформляется в той же транзакции партией-корректировкой или списанием по FEFO. Корректировка
+// всегда идет на складе по умолчанию: уменьшить остаток можно только на величину, лежащую там.
+// Остаток набора считается по компонентам и здесь не меняется.
+func (r *ProductPostgresRepo) Update(update domain.ProductUpdate) (int64, error) {
+    set := "version = version + 1"
+    args := []interface{}{}
+    if update.Has(domain.ProductFieldName) {
+        args = append(args, update.Name)
+        set += fmt.Sprintf(", name = $%d", len(args))
+    }
+    if update.Has(domain.ProductFieldDescription) {
+        args = append(args, update.Description)
+        set += fmt.Sprintf(", description = $%d", len(args))
+    }
+    if update.Has(domain.ProductFieldPrice) {
+        args = append(args, update.Price)
+        set += fmt.Sprintf(", price = $%d", len(args))
+    }
+    if update.Has(domain.ProductFieldSKU) {
+        args = append(args, update.SKU)
+        set += fmt.Sprintf(", sku = NULLIF($%d, '')", len(args))
+    }
+    if update.Has(domain.ProductFieldNutrition) {
+        nutrition, err := nutritionJSON(update.Nutrition)
+        if err != nil {
+            return 0, err
+        }
+        args = append(args, nutrition)
+        set += fmt.Sprintf(", nutrition = $%d", len(args))
+    }
+    if update.Has(domain.ProductFieldIngredients) {
+        args = append(args, textArray(update.Ingredients))
+        set += fmt.Sprintf(", ingredients = $%d", len(args))
+    }
+    if update.Has(domain.ProductFieldAllergens) {
+        args = append(args, textArray(update.Allergens))
+        set += fmt.Sprintf(", allergens = $%d", len(args))
+    }
+    if update.Has(domain.ProductFieldDietaryTags) {
+        args = append(args, textArray(update.DietaryTags))
+        set += fmt.Sprintf(", dietary_tags = $%d", len(args))
+    }
+    if update.Has(domain.ProductFieldParentID) {
+        args = append(args, update.ParentID)
+        set += fmt.Sprintf(", parent_id = NULLIF($%d, '')::uuid", len(args))
+    }
+    if update.Has(domain.ProductFieldVariantName) {
+        args = append(args, update.VariantName)
+        set += fmt.Sprintf(", variant_name = $%d", len(args))
+    }
+    if update.Has(domain.ProductFieldCategory) {
+        args = append(args, update.Category)
+        set += fmt.Sprintf(", category = $%d", len(args))
+    }
+    args = append(args, update.ID, update.Version)
+    query := fmt.Sprintf(`UPDATE products p SET %s WHERE p.id = $%d AND p.version = $%d
+        RETURNING p.version, `+availableStockExpr+`, p.kind`, set, len(args)-1, len(args))
+
+    ctx := context.Background()
+    tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+    if err != nil {
+        return 0, err
+    }
+    defer tx.Rollback(ctx)
+
+    // UPDATE блокирует строку товара, поэтому остаток не изменится до конца транзакции
+    var version int64
+    var available int
+    var kind string
+    err = tx.QueryRow(ctx, query, args...).Scan(&version, &available, &kind)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return 0, domain.ErrProductVersionConflict
+    }
+    if err != nil {
+        return 0, uniqueViolationError(err)
+    }
+
+    if update.Has(domain.ProductFieldStock) && kind != domain.ProductKindBundle && update.Stock != available {
+        change := domain.StockChange{Actor: update.Actor, Reason: "manual stock update"}
+        if err := adjustStock(ctx, tx, update.ID, "", update.Stock-available, change); err != nil {
+            return 0, err
+        }
+    }
+
+    if update.Has(domain.ProductFieldPrice) {
+        if err := recordPrice(ctx, tx, update.ID, domain.PriceSourceManual, update.Actor, ""); err != nil {
+            return 0, err
+        }
+    }
+    if update.Has(domain.ProductFieldBarcodes) {
+        if _, err := tx.Exec(ctx, `DELETE FROM product_barcodes WHERE product_id = $1`, update.ID); err != nil {
+            return 0, err
+        }
+        if err := insertBarcodes(ctx, tx, update.ID, update.Barcodes); err != nil {
+            return 0, err
+        }
+    }
+    // Состав меняется только у наборов; use case не пропускает компоненты для обычного товара
+    if update.Has(domain.ProductFieldComponents) && update.Components != nil {
+        if _, err := tx.Exec(ctx, `DELETE FROM bundle_components WHERE bundle_id = $1`, update.ID); err != nil {
+            return 0, err
+        }
+        if err := insertComponents(ctx, tx, update.ID, update.Components); err != nil {
+            return 0, err
+        }
+    }
+    return version, tx.Commit(ctx)
 }
 
-func (r *ProductPostgresRepo) Update(id string, product domain.Product) error {
-    query := `UPDATE products SET name=$1, price=$2, stock=$3 WHERE id=$4`
-    _, err := DB.Exec(context.Background(), query, product.Name, product.Price, product.Stock, id)
+func (r *ProductPostgresRepo) Archive(id string) error {
+    query := `UPDATE products SET archived_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $1 AND archived_at IS NULL`
+    _, err := DB.Exec(context.Background(), query, id)
     return err
 }
 
-func (r *ProductPostgresRepo) Delete(id string) error {
-    query := `DELETE FROM products WHERE id=$1`
+func (r *ProductPostgresRepo) Restore(id string) error {
+    query := `UPDATE products SET archived_at = NULL, version = version + 1 WHERE id = $1 AND archived_at IS NOT NULL`
     _, err := DB.Exec(context.Background(), query, id)
     return err
 }
 
-func (r *ProductPostgresRepo) FindAllWithFilter(filter domain.FilterParams, pagination domain.PaginationParams, offset int) ([]domain.Product, int, error) {
-    query := `SELECT id, name, price, stock FROM products WHERE 1=1`
-    countQuery := `SELECT COUNT(*) FROM products WHERE 1=1`
+// productReferenceCounts - число строк, которые ссылаются на товар и не удаляются вместе с ним.
+// Партии и движения считаются все, включая пустые и списанные: это история остатка товара.
+const productReferenceCounts = `
+    (SELECT COUNT(*) FROM order_items WHERE product_id = $1),
+    (SELECT COUNT(*) FROM purchase_order_lines WHERE product_id = $1),
+    (SELECT COUNT(*) FROM stock_batches WHERE product_id = $1),
+    (SELECT COUNT(*) FROM stock_movements WHERE product_id = $1),
+    (SELECT COUNT(*) FROM bundle_components WHERE component_id = $1)`
+
+func (r *ProductPostgresRepo) References(id string) (domain.ProductReferences, error) {
+    var refs domain.ProductReferences
+    err := DB.QueryRow(context.Background(), `SELECT `+productReferenceCounts, id).Scan(
+        &refs.OrderItems, &refs.PurchaseOrderLines, &refs.StockBatches, &refs.StockMovements, &refs.BundleComponents)
+    return refs, err
+}
+
+// Purge удаляет товар одним запросом с повторной проверкой ссылок, чтобы не удалить товар,
+// на который сослались после проверки в use case. Изображения, штрихкоды, состав набора,
+// алерты и предложения на пополнение удаляются каскадно, варианты отвязываются от товара.
+func (r *ProductPostgresRepo) Purge(id string) (bool, error) {
+    query := `DELETE FROM products p WHERE p.id = $1 AND p.archived_at IS NOT NULL
+        AND NOT EXISTS (SELECT 1 FROM order_items WHERE product_id = p.id)
+        AND NOT EXISTS (SELECT 1 FROM purchase_order_lines WHERE product_id = p.id)
+        AND NOT EXISTS (SELECT 1 FROM stock_batches WHERE product_id = p.id)
+        AND NOT EXISTS (SELECT 1 FROM stock_movements WHERE product_id = p.id)
+        AND NOT EXISTS (SELECT 1 FROM bundle_components WHERE component_id = p.id)`
+    tag, err := DB.Exec(context.Background(), query, id)
+    if err != nil {
+        return false, err
+    }
+    return tag.RowsAffected() == 1, nil
+}
+
+// productSortKey описывает ключ keyset-пагинации для варианта сортировки
+type productSortKey struct {
+    expr    string // SQL выражение ключа
+    sqlType string // тип для приведения значения из курсора
+    desc    bool
+}
+
+var productSortKeys = map[string]productSortKey{
+    domain.ProductSortRelevance:  {expr: "ts_rank(p.search_vector, to_tsquery('simple', $1))", sqlType: "real", desc: true},
+    domain.ProductSortPriceAsc:   {expr: "p.price", sqlType: "numeric"},
+    domain.ProductSortPriceDesc:  {expr: "p.price", sqlType: "numeric", desc: true},
+    domain.ProductSortName:       {expr: "p.name", sqlType: "text"},
+    domain.ProductSortNewest:     {expr: "p.created_at", sqlType: "timestamptz", desc: true},
+    domain.ProductSortPopularity: {expr: "COALESCE(s.sold, 0)", sqlType: "numeric", desc: true},
+}
+
+// productCursor - содержимое непрозрачного курсора: последняя строка предыдущей страницы
+type productCursor struct {
+    Sort string `json:"s"`
+    Key  string `json:"k"`
+    ID   string `json:"i"`
+}
+
+func encodeProductCursor(c productCursor) string {
+    raw, _ := json.Marshal(c)
+    return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeProductCursor(token string) (productCursor, error) {
+    var c productCursor
+    raw, err := base64.RawURLEncoding.DecodeString(token)
+    if err != nil {
+        return c, ErrInvalidCursor
+    }
+    if err := json.Unmarshal(raw, &c); err != nil || c.ID == "" {
+        return c, ErrInvalidCursor
+    }
+    return c, nil
+}
+
+// prefixTSQuery превращает пользовательский ввод в tsquery с поиском по префиксу:
+// "молоко сыр" -> "молоко:* & сыр:*"
+func prefixTSQuery(input string) string {
+    words := strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+    })
+    for i, w := range words {
+        words[i] = w + ":*"
+    }
+    return strings.Join(words, " & ")
+}
+
+func escapeLike(s string) string {
+    return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// FindAllWithFilter ищет товары с фильтрацией, сортировкой и пагинацией.
+// Если в pagination передан курсор, используется keyset-пагинация без подсчета total,
+// иначе - OFFSET с total из оконной функции в том же запросе.
+func (r *ProductPostgresRepo) FindAllWithFilter(filter domain.FilterParams, sort string, pagination domain.PaginationParams, offset int) (domain.ProductPage, error) {
+    tsQuery := prefixTSQuery(filter.Query)
+    if sort == "" || (sort == domain.ProductSortRelevance && tsQuery == "") {
+        if tsQuery != "" {
+            sort = domain.ProductSortRelevance
+        } else {
+            sort = domain.ProductSortNewest
+        }
+    }
+    key, ok := productSortKeys[sort]
+    if !ok {
+        return domain.ProductPage{}, fmt.Errorf("unknown sort %q", sort)
+    }
+
     args := []interface{}{}
     argCount := 1
+    where := ""
 
+    // tsquery всегда идет первым параметром: на $1 ссылается и выражение релевантности
+    if tsQuery != "" {
+        where += " AND p.search_vector @@ to_tsquery('simple', $1)"
+        args = append(args, tsQuery)
+        argCount++
+    }
+    if filter.Name != "" {
+        where += fmt.Sprintf(" AND p.name ILIKE '%%' || $%d || '%%'", argCount)
+        args = append(args, escapeLike(filter.Name))
+        argCount++
+    }
     if filter.MinPrice > 0 {
-        query += fmt.Sprintf(" AND price >= $%d", argCount)
-        countQuery += fmt.Sprintf(" AND price >= $%d", argCount)
+        where += fmt.Sprintf(" AND p.price >= $%d", argCount)
         args = append(args, filter.MinPrice)
         argCount++
     }
     if filter.MaxPrice > 0 {
-        query += fmt.Sprintf(" AND price <= $%d", argCount)
-        countQuery += fmt.Sprintf(" AND price <= $%d", argCount)
+        where += fmt.Sprintf(" AND p.price <= $%d", argCount)
         args = append(args, filter.MaxPrice)
         argCount++
     }
+    if filter.ParentID != "" {
+        where += fmt.Sprintf(" AND p.parent_id = $%d::uuid", argCount)
+        args = append(args, filter.ParentID)
+        argCount++
+    }
+    // @> - массив содержит все значения, && - пересекается хотя бы по одному
+    arrayFilters := []struct {
+        values []string
+        cond   string
+    }{
+        {filter.WithAllergens, "p.allergens @> $%d"},
+        {filter.WithoutAllergens, "NOT p.allergens && $%d"},
+        {filter.WithDietaryTags, "p.dietary_tags @> $%d"},
+        {filter.WithoutDietaryTags, "NOT p.dietary_tags && $%d"},
+    }
+    for _, f := range arrayFilters {
+        if len(f.values) > 0 {
+            where += " AND " + fmt.Sprintf(f.cond, argCount)
+            args = append(args, f.values)
+            argCount++
+        }
+    }
 
-    query += fmt.Sprintf(" ORDER BY id LIMIT $%d OFFSET $%d", argCount, argCount+1)
-    args = append(args, pagination.PerPage, offset)
+    // Архивные товары в каталоге не показываются, админка запрашивает их отдельно
+    if filter.Archived {
+        where += " AND p.archived_at IS NOT NULL"
+    } else {
+        where += " AND p.archived_at IS NULL"
+    }
 
-    var total int
-    if len(args) > 2 { 
-        err := DB.QueryRow(context.Background(), countQuery, args[:len(args)-2]...).Scan(&total)
-        if err != nil {
-            return nil, 0, err
+    useCursor := pagination.Cursor != ""
+    if useCursor {
+        cursor, err := decodeProductCursor(pagination.Cursor)
+        if err != nil || cursor.Sort != sort {
+            return domain.ProductPage{}, ErrInvalidCursor
         }
-    } else { 
-        err := DB.QueryRow(context.Background(), countQuery).Scan(&total)
-        if err != nil {
-            return nil, 0, err
+        op := ">"
+        if key.desc {
+            op = "<"
         }
+        where += fmt.Sprintf(" AND (%s, p.id) %s ($%d::%s, $%d::uuid)", key.expr, op, argCount, key.sqlType, argCount+1)
+        args = append(args, cursor.Key, cursor.ID)
+        argCount += 2
+    }
+
+    from := "products p"
+    if sort == domain.ProductSortPopularity {
+        // Отмененные заказы не считаются продажами
+        from += ` LEFT JOIN (
+            SELECT oi.product_id, SUM(oi.quantity) AS sold
+            FROM order_items oi JOIN orders o ON o.id = oi.order_id
+            WHERE o.status <> '` + domain.OrderStatusCancelled + `'
+            GROUP BY oi.product_id
+        ) s ON s.product_id = p.id`
+    }
+
+    totalExpr := "0"
+    if !useCursor {
+        totalExpr = "COUNT(*) OVER()"
+    }
+
+    direction := "ASC"
+    if key.desc {
+        direction = "DESC"
+    }
+
+    query := fmt.Sprintf(`SELECT %s, (%s)::text, %s
+        FROM %s WHERE 1=1%s
+        ORDER BY %s %s, p.id %s`,
+        productColumns, key.expr, totalExpr, from, where, key.expr, direction, direction)
+
+    // Берем на одну строку больше, чтобы понять, есть ли следующая страница
+    query += fmt.Sprintf(" LIMIT $%d", argCount)
+    args = append(args, pagination.PerPage+1)
+    argCount++
+    if !useCursor {
+        query += fmt.Sprintf(" OFFSET $%d", argCount)
+        args = append(args, offset)
     }
 
     rows, err := DB.Query(context.Background(), query, args...)
     if err != nil {
-        return nil, 0, err
+        return domain.ProductPage{}, err
     }
     defer rows.Close()
 
-    var products []domain.Product
+    page := domain.ProductPage{Sort: sort}
+    var lastKey string
     for rows.Next() {
-        var p domain.Product
-        err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Stock)
+        var sortKey string
+        p, err := scanProduct(rows, &sortKey, &page.Total)
         if err != nil {
-            return nil, 0, err
+            return domain.ProductPage{}, err
         }
-        products = append(products, p)
+        if len(page.Products) == pagination.PerPage {
+            page.NextCursor = encodeProductCursor(productCursor{Sort: sort, Key: lastKey, ID: page.Products[len(page.Products)-1].ID})
+            break
+        }
+        page.Products = append(page.Products, p)
+        lastKey = sortKey
+    }
+    if err := rows.Err(); err != nil {
+        return domain.ProductPage{}, err
     }
 
-    return products, total, nil
+    // Страница за пределами выборки: оконная функция ничего не вернула
+    if !useCursor && len(page.Products) == 0 && offset > 0 {
+        countQuery := "SELECT COUNT(*) FROM products p WHERE 1=1" + where
+        if err := DB.QueryRow(context.Background(), countQuery, args[:len(args)-2]...).Scan(&page.Total); err != nil {
+            return domain.ProductPage{}, err
+        }
+    }
+
+    return page, nil
 }
 
+// findAllPageSize - размер страницы, которыми FindAll читает каталог
+const findAllPageSize = 500
+
+// FindAll возвращает все неархивные товары, проходя каталог страницами по курсору
 func (r *ProductPostgresRepo) FindAll() ([]domain.Product, error) {
-    products, _, err := r.FindAllWithFilter(domain.FilterParams{}, domain.PaginationParams{PerPage: 1000}, 0)
-    return products, err
+    var products []domain.Product
+    pagination := domain.PaginationParams{PerPage: findAllPageSize}
+    for {
+        page, err := r.FindAllWithFilter(domain.FilterParams{}, domain.ProductSortNewest, pagination, 0)
+        if err != nil {
+            return nil, err
+        }
+        products = append(products, page.Products...)
+        if page.NextCursor == "" {
+            return products, nil
+        }
+        pagination.Cursor = page.NextCursor
+    }
 }
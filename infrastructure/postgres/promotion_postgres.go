@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"FoodStore-AdvProg2/domain"
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type PromotionPostgresRepo struct{}
+
+func NewPromotionPostgresRepo() *PromotionPostgresRepo {
+	return &PromotionPostgresRepo{}
+}
+
+// promotionTimesUsedExpr - число использований акции pm по неотмененным заказам
+const promotionTimesUsedExpr = `(SELECT COUNT(*) FROM promotion_redemptions pr
+        JOIN orders o ON o.id = pr.order_id
+        WHERE pr.promotion_id = pm.id AND o.status <> 'cancelled')`
+
+// promotionColumns - колонки акции в порядке scanPromotion
+const promotionColumns = `pm.id, pm.name, pm.type, pm.value, pm.buy_quantity, pm.get_quantity, pm.min_subtotal,
+        pm.product_ids, pm.categories, COALESCE(pm.coupon_code, ''), pm.usage_limit, pm.per_user_limit,
+        pm.starts_at, pm.ends_at, pm.stackable, pm.priority, pm.active, ` + promotionTimesUsedExpr + `,
+        pm.created_by, pm.created_at`
+
+func scanPromotion(row pgx.Row) (domain.Promotion, error) {
+	var p domain.Promotion
+	err := row.Scan(&p.ID, &p.Name, &p.Type, &p.Value, &p.BuyQuantity, &p.GetQuantity, &p.MinSubtotal,
+		&p.ProductIDs, &p.Categories, &p.CouponCode, &p.UsageLimit, &p.PerUserLimit,
+		&p.StartsAt, &p.EndsAt, &p.Stackable, &p.Priority, &p.Active, &p.TimesUsed,
+		&p.CreatedBy, &p.CreatedAt)
+	return p, err
+}
+
+func (r *PromotionPostgresRepo) queryPromotions(query string, args ...interface{}) ([]domain.Promotion, error) {
+	rows, err := DB.Query(context.Background(), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var promotions []domain.Promotion
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, p)
+	}
+	return promotions, rows.Err()
+}
+
+// Save сохраняет акцию; пустой код купона хранится как NULL и не уникален
+func (r *PromotionPostgresRepo) Save(p domain.Promotion) error {
+	_, err := DB.Exec(context.Background(), `
+        INSERT INTO promotions (id, name, type, value, buy_quantity, get_quantity, min_subtotal,
+            product_ids, categories, coupon_code, usage_limit, per_user_limit, starts_at, ends_at,
+            stackable, priority, active, created_by, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), $11, $12, $13, $14, $15, $16, $17, $18, $19)`,
+		p.ID, p.Name, p.Type, p.Value, p.BuyQuantity, p.GetQuantity, p.MinSubtotal,
+		textArray(p.ProductIDs), textArray(p.Categories), p.CouponCode, p.UsageLimit, p.PerUserLimit,
+		p.StartsAt, p.EndsAt, p.Stackable, p.Priority, p.Active, p.CreatedBy, p.CreatedAt)
+	return uniqueViolationError(err)
+}
+
+func (r *PromotionPostgresRepo) FindByID(id string) (domain.Promotion, error) {
+	p, err := scanPromotion(DB.QueryRow(context.Background(),
+		`SELECT `+promotionColumns+` FROM promotions pm WHERE pm.id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return p, domain.ErrPromotionNotFound
+	}
+	return p, err
+}
+
+func (r *PromotionPostgresRepo) FindAll(activeOnly bool) ([]domain.Promotion, error) {
+	return r.queryPromotions(`SELECT `+promotionColumns+` FROM promotions pm
+        WHERE pm.active OR NOT $1 ORDER BY pm.created_at DESC`, activeOnly)
+}
+
+func (r *PromotionPostgresRepo) SetActive(id string, active bool) error {
+	tag, err := DB.Exec(context.Background(), `UPDATE promotions SET active = $2 WHERE id = $1`, id, active)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPromotionNotFound
+	}
+	return nil
+}
+
+func (r *PromotionPostgresRepo) FindApplicable(couponCodes []string) ([]domain.Promotion, error) {
+	return r.queryPromotions(`SELECT `+promotionColumns+` FROM promotions pm
+        WHERE pm.active AND (pm.coupon_code IS NULL OR pm.coupon_code = ANY($1))`, textArray(couponCodes))
+}
+
+func (r *PromotionPostgresRepo) Usage(promotionIDs []string, userID string) (map[string]domain.PromotionUsage, error) {
+	usage := make(map[string]domain.PromotionUsage, len(promotionIDs))
+	if len(promotionIDs) == 0 {
+		return usage, nil
+	}
+
+	rows, err := DB.Query(context.Background(), `
+        SELECT pr.promotion_id, COUNT(*), COUNT(*) FILTER (WHERE pr.user_id = $2)
+        FROM promotion_redemptions pr
+        JOIN orders o ON o.id = pr.order_id
+        WHERE pr.promotion_id = ANY($1::uuid[]) AND o.status <> 'cancelled'
+        GROUP BY pr.promotion_id`,
+		promotionIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		var u domain.PromotionUsage
+		if err := rows.Scan(&id, &u.Total, &u.ByUser); err != nil {
+			return nil, err
+		}
+		usage[id] = u
+	}
+	return usage, rows.Err()
+}
+
+// redeemPromotions записывает использования акций заказа в транзакции tx. Строки акций блокируются,
+// чтобы два параллельных заказа не превысили лимит; при превышении возвращается domain.ErrPromotionLimitReached.
+func redeemPromotions(ctx context.Context, tx pgx.Tx, orderID, userID string, amounts map[string]float64) error {
+	for promotionID, amount := range amounts {
+		var usageLimit, perUserLimit, total, byUser int
+		err := tx.QueryRow(ctx, `SELECT usage_limit, per_user_limit FROM promotions WHERE id = $1 FOR UPDATE`,
+			promotionID).Scan(&usageLimit, &perUserLimit)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrPromotionNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		err = tx.QueryRow(ctx, `
+            SELECT COUNT(*), COUNT(*) FILTER (WHERE pr.user_id = $2)
+            FROM promotion_redemptions pr
+            JOIN orders o ON o.id = pr.order_id
+            WHERE pr.promotion_id = $1 AND o.status <> 'cancelled'`,
+			promotionID, userID).Scan(&total, &byUser)
+		if err != nil {
+			return err
+		}
+		if (usageLimit > 0 && total >= usageLimit) || (perUserLimit > 0 && byUser >= perUserLimit) {
+			return domain.ErrPromotionLimitReached
+		}
+
+		_, err = tx.Exec(ctx, `
+            INSERT INTO promotion_redemptions (promotion_id, order_id, user_id, amount)
+            VALUES ($1, $2, $3, $4)`,
+			promotionID, orderID, userID, amount)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
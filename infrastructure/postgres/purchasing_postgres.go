@@ -0,0 +1,295 @@
+package postgres
+
+import (
+	"FoodStore-AdvProg2/domain"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type SupplierPostgresRepo struct{}
+
+func NewSupplierPostgresRepo() *SupplierPostgresRepo {
+	return &SupplierPostgresRepo{}
+}
+
+const supplierColumns = `id, name, email, phone, address, created_at`
+
+func scanSupplier(row pgx.Row) (domain.Supplier, error) {
+	var s domain.Supplier
+	err := row.Scan(&s.ID, &s.Name, &s.Email, &s.Phone, &s.Address, &s.CreatedAt)
+	return s, err
+}
+
+func (r *SupplierPostgresRepo) Save(supplier domain.Supplier) (domain.Supplier, error) {
+	supplier.ID = uuid.New().String()
+	err := DB.QueryRow(context.Background(),
+		`INSERT INTO suppliers (id, name, email, phone, address) VALUES ($1, $2, $3, $4, $5) RETURNING created_at`,
+		supplier.ID, supplier.Name, supplier.Email, supplier.Phone, supplier.Address,
+	).Scan(&supplier.CreatedAt)
+	return supplier, err
+}
+
+func (r *SupplierPostgresRepo) FindByID(id string) (domain.Supplier, error) {
+	return scanSupplier(DB.QueryRow(context.Background(), `SELECT `+supplierColumns+` FROM suppliers WHERE id = $1`, id))
+}
+
+func (r *SupplierPostgresRepo) FindAll() ([]domain.Supplier, error) {
+	rows, err := DB.Query(context.Background(), `SELECT `+supplierColumns+` FROM suppliers ORDER BY name, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suppliers []domain.Supplier
+	for rows.Next() {
+		s, err := scanSupplier(rows)
+		if err != nil {
+			return nil, err
+		}
+		suppliers = append(suppliers, s)
+	}
+	return suppliers, rows.Err()
+}
+
+type PurchaseOrderPostgresRepo struct{}
+
+func NewPurchaseOrderPostgresRepo() *PurchaseOrderPostgresRepo {
+	return &PurchaseOrderPostgresRepo{}
+}
+
+const purchaseOrderColumns = `id, supplier_id, location_id, status, expected_at, notes, created_by, created_at, updated_at`
+
+const purchaseOrderLineColumns = `id, purchase_order_id, product_id, quantity_ordered, quantity_received, unit_cost`
+
+func scanPurchaseOrder(row pgx.Row) (domain.PurchaseOrder, error) {
+	var po domain.PurchaseOrder
+	err := row.Scan(&po.ID, &po.SupplierID, &po.LocationID, &po.Status, &po.ExpectedAt,
+		&po.Notes, &po.CreatedBy, &po.CreatedAt, &po.UpdatedAt)
+	return po, err
+}
+
+func scanPurchaseOrderLine(row pgx.Row) (domain.PurchaseOrderLine, error) {
+	var l domain.PurchaseOrderLine
+	err := row.Scan(&l.ID, &l.PurchaseOrderID, &l.ProductID, &l.QuantityOrdered, &l.QuantityReceived, &l.UnitCost)
+	return l, err
+}
+
+// Save сохраняет заказ поставщику со строками в одной транзакции
+func (r *PurchaseOrderPostgresRepo) Save(order domain.PurchaseOrder) (domain.PurchaseOrder, error) {
+	ctx := context.Background()
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	order.ID = uuid.New().String()
+	err = tx.QueryRow(ctx, `
+        INSERT INTO purchase_orders (id, supplier_id, location_id, status, expected_at, notes, created_by)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING created_at, updated_at`,
+		order.ID, order.SupplierID, order.LocationID, order.Status, order.ExpectedAt, order.Notes, order.CreatedBy,
+	).Scan(&order.CreatedAt, &order.UpdatedAt)
+	if err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+
+	for i := range order.Lines {
+		line := &order.Lines[i]
+		line.ID = uuid.New().String()
+		line.PurchaseOrderID = order.ID
+		_, err := tx.Exec(ctx,
+			`INSERT INTO purchase_order_lines (`+purchaseOrderLineColumns+`, position) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			line.ID, line.PurchaseOrderID, line.ProductID, line.QuantityOrdered, line.QuantityReceived, line.UnitCost, i,
+		)
+		if err != nil {
+			return domain.PurchaseOrder{}, err
+		}
+	}
+
+	return order, tx.Commit(ctx)
+}
+
+func (r *PurchaseOrderPostgresRepo) FindByID(id string) (domain.PurchaseOrder, error) {
+	order, err := scanPurchaseOrder(DB.QueryRow(context.Background(),
+		`SELECT `+purchaseOrderColumns+` FROM purchase_orders WHERE id = $1`, id))
+	if err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+
+	lines, err := r.findLines([]string{id})
+	if err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+	order.Lines = lines[id]
+	return order, nil
+}
+
+// FindAll возвращает заказы поставщикам по фильтру, новые первыми, вместе со строками
+func (r *PurchaseOrderPostgresRepo) FindAll(filter domain.PurchaseOrderFilter) ([]domain.PurchaseOrder, error) {
+	where := ""
+	args := []interface{}{}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if filter.SupplierID != "" {
+		args = append(args, filter.SupplierID)
+		where += fmt.Sprintf(" AND supplier_id = $%d", len(args))
+	}
+
+	rows, err := DB.Query(context.Background(),
+		`SELECT `+purchaseOrderColumns+` FROM purchase_orders WHERE 1=1`+where+` ORDER BY created_at DESC, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []domain.PurchaseOrder
+	var ids []string
+	for rows.Next() {
+		po, err := scanPurchaseOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, po)
+		ids = append(ids, po.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return orders, nil
+	}
+
+	lines, err := r.findLines(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range orders {
+		orders[i].Lines = lines[orders[i].ID]
+	}
+	return orders, nil
+}
+
+// findLines возвращает строки нескольких заказов одним запросом, сгруппированные по заказу
+func (r *PurchaseOrderPostgresRepo) findLines(orderIDs []string) (map[string][]domain.PurchaseOrderLine, error) {
+	rows, err := DB.Query(context.Background(),
+		`SELECT `+purchaseOrderLineColumns+` FROM purchase_order_lines
+        WHERE purchase_order_id = ANY($1::uuid[])
+        ORDER BY purchase_order_id, position`, orderIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string][]domain.PurchaseOrderLine)
+	for rows.Next() {
+		l, err := scanPurchaseOrderLine(rows)
+		if err != nil {
+			return nil, err
+		}
+		result[l.PurchaseOrderID] = append(result[l.PurchaseOrderID], l)
+	}
+	return result, rows.Err()
+}
+
+func (r *PurchaseOrderPostgresRepo) UpdateStatus(id, status string) error {
+	_, err := DB.Exec(context.Background(),
+		`UPDATE purchase_orders SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, status, id)
+	return err
+}
+
+func (r *PurchaseOrderPostgresRepo) Receive(orderID string, receipts []domain.PurchaseReceipt, change domain.StockChange) (domain.PurchaseOrder, []domain.StockBatch, error) {
+	ctx := context.Background()
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return domain.PurchaseOrder{}, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Блокируем заказ, чтобы параллельные приемки не превысили заказанное количество
+	order, err := scanPurchaseOrder(tx.QueryRow(ctx,
+		`SELECT `+purchaseOrderColumns+` FROM purchase_orders WHERE id = $1 FOR UPDATE`, orderID))
+	if err != nil {
+		return domain.PurchaseOrder{}, nil, err
+	}
+	if order.Status != domain.PurchaseOrderSent && order.Status != domain.PurchaseOrderPartiallyReceived {
+		return domain.PurchaseOrder{}, nil, domain.ErrPurchaseOrderNotReceivable
+	}
+
+	rows, err := tx.Query(ctx,
+		`SELECT `+purchaseOrderLineColumns+` FROM purchase_order_lines WHERE purchase_order_id = $1 ORDER BY position`, orderID)
+	if err != nil {
+		return domain.PurchaseOrder{}, nil, err
+	}
+	lineIndex := make(map[string]int)
+	for rows.Next() {
+		l, err := scanPurchaseOrderLine(rows)
+		if err != nil {
+			rows.Close()
+			return domain.PurchaseOrder{}, nil, err
+		}
+		lineIndex[l.ID] = len(order.Lines)
+		order.Lines = append(order.Lines, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return domain.PurchaseOrder{}, nil, err
+	}
+
+	change.ReferenceID = order.ID
+	receivedAt := time.Now()
+	var batches []domain.StockBatch
+	for _, receipt := range receipts {
+		i, ok := lineIndex[receipt.LineID]
+		if !ok {
+			return domain.PurchaseOrder{}, nil, fmt.Errorf("line %s does not belong to purchase order %s", receipt.LineID, order.ID)
+		}
+		line := &order.Lines[i]
+		if receipt.Quantity > line.Remaining() {
+			return domain.PurchaseOrder{}, nil, domain.ErrOverReceipt
+		}
+
+		batch, err := insertBatch(ctx, tx, domain.StockBatch{
+			ProductID:  line.ProductID,
+			LocationID: order.LocationID,
+			LotNumber:  receipt.LotNumber,
+			ReceivedAt: receivedAt,
+			ExpiresAt:  receipt.ExpiresAt,
+			Quantity:   receipt.Quantity,
+		}, domain.MovementReceipt, change)
+		if err != nil {
+			return domain.PurchaseOrder{}, nil, err
+		}
+		batches = append(batches, batch)
+
+		line.QuantityReceived += receipt.Quantity
+		_, err = tx.Exec(ctx,
+			`UPDATE purchase_order_lines SET quantity_received = $1 WHERE id = $2`, line.QuantityReceived, line.ID)
+		if err != nil {
+			return domain.PurchaseOrder{}, nil, err
+		}
+	}
+
+	order.Status = domain.PurchaseOrderReceived
+	for _, l := range order.Lines {
+		if l.Remaining() > 0 {
+			order.Status = domain.PurchaseOrderPartiallyReceived
+			break
+		}
+	}
+	err = tx.QueryRow(ctx,
+		`UPDATE purchase_orders SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 RETURNING updated_at`,
+		order.Status, order.ID,
+	).Scan(&order.UpdatedAt)
+	if err != nil {
+		return domain.PurchaseOrder{}, nil, err
+	}
+
+	return order, batches, tx.Commit(ctx)
+}
@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"FoodStore-AdvProg2/domain"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type ReplenishmentPostgresRepo struct{}
+
+func NewReplenishmentPostgresRepo() *ReplenishmentPostgresRepo {
+	return &ReplenishmentPostgresRepo{}
+}
+
+func (r *ReplenishmentPostgresRepo) SetPolicy(policy domain.ReorderPolicy) error {
+	_, err := DB.Exec(context.Background(),
+		`UPDATE products SET reorder_point = $1, reorder_quantity = $2 WHERE id = $3`,
+		policy.ReorderPoint, policy.ReorderQuantity, policy.ProductID)
+	return err
+}
+
+// ReorderStatuses возвращает товары с точкой заказа, а также товары с открытым алертом,
+// у которых точку заказа уже сняли или которые ушли в архив, - чтобы такой алерт можно было закрыть.
+// Архивные товары не отслеживаются: их точка заказа считается нулевой.
+func (r *ReplenishmentPostgresRepo) ReorderStatuses() ([]domain.ReorderStatus, error) {
+	query := `SELECT p.id, p.name, ` + availableStockExpr + `,
+            CASE WHEN p.archived_at IS NULL THEN p.reorder_point ELSE 0 END, p.reorder_quantity, a.id IS NOT NULL
+        FROM products p
+        LEFT JOIN low_stock_alerts a ON a.product_id = p.id AND a.resolved_at IS NULL
+        WHERE (p.reorder_point > 0 AND p.archived_at IS NULL) OR a.id IS NOT NULL
+        ORDER BY p.name, p.id`
+	rows, err := DB.Query(context.Background(), query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var statuses []domain.ReorderStatus
+	for rows.Next() {
+		var s domain.ReorderStatus
+		err := rows.Scan(&s.ProductID, &s.ProductName, &s.Available,
+			&s.Policy.ReorderPoint, &s.Policy.ReorderQuantity, &s.AlertOpen)
+		if err != nil {
+			return nil, err
+		}
+		s.Policy.ProductID = s.ProductID
+		statuses = append(statuses, s)
+	}
+	return statuses, rows.Err()
+}
+
+// UnitsSold считает продажи в базовых единицах остатка, чтобы сравнивать их с остатком
+func (r *ReplenishmentPostgresRepo) UnitsSold(productIDs []string, since time.Time) (map[string]int, error) {
+	sold := make(map[string]int, len(productIDs))
+	if len(productIDs) == 0 {
+		return sold, nil
+	}
+
+	rows, err := DB.Query(context.Background(), `
+        SELECT oi.product_id, SUM(`+orderItemBaseQuantityExpr+`)::bigint
+        FROM order_items oi
+        JOIN orders o ON o.id = oi.order_id
+        WHERE oi.product_id = ANY($1::uuid[]) AND o.created_at >= $2 AND o.status <> $3
+        GROUP BY oi.product_id`,
+		productIDs, since, domain.OrderStatusCancelled)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID string
+		var quantity int
+		if err := rows.Scan(&productID, &quantity); err != nil {
+			return nil, err
+		}
+		sold[productID] = quantity
+	}
+	return sold, rows.Err()
+}
+
+func (r *ReplenishmentPostgresRepo) OpenAlert(alert domain.LowStockAlert, suggestion domain.ReorderSuggestion) (domain.LowStockAlert, domain.ReorderSuggestion, bool, error) {
+	ctx := context.Background()
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return domain.LowStockAlert{}, domain.ReorderSuggestion{}, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Уникальный индекс по открытым алертам не дает открыть второй алерт на тот же товар
+	alert.ID = uuid.New().String()
+	err = tx.QueryRow(ctx, `
+        INSERT INTO low_stock_alerts (id, product_id, available, reorder_point)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (product_id) WHERE resolved_at IS NULL DO NOTHING
+        RETURNING triggered_at`,
+		alert.ID, alert.ProductID, alert.Available, alert.ReorderPoint,
+	).Scan(&alert.TriggeredAt)
+	if err == pgx.ErrNoRows {
+		return domain.LowStockAlert{}, domain.ReorderSuggestion{}, false, nil
+	}
+	if err != nil {
+		return domain.LowStockAlert{}, domain.ReorderSuggestion{}, false, err
+	}
+
+	suggestion.ID = uuid.New().String()
+	suggestion.AlertID = alert.ID
+	err = tx.QueryRow(ctx, `
+        INSERT INTO reorder_suggestions (id, alert_id, product_id, available, reorder_point, reorder_quantity,
+            units_sold, sales_window_days, daily_sales, suggested_quantity, status)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+        RETURNING created_at, updated_at`,
+		suggestion.ID, suggestion.AlertID, suggestion.ProductID, suggestion.Available, suggestion.ReorderPoint,
+		suggestion.ReorderQuantity, suggestion.UnitsSold, suggestion.SalesWindowDays, suggestion.DailySales,
+		suggestion.SuggestedQuantity, suggestion.Status,
+	).Scan(&suggestion.CreatedAt, &suggestion.UpdatedAt)
+	if err != nil {
+		return domain.LowStockAlert{}, domain.ReorderSuggestion{}, false, err
+	}
+
+	return alert, suggestion, true, tx.Commit(ctx)
+}
+
+func (r *ReplenishmentPostgresRepo) ResolveAlert(productID string) error {
+	_, err := DB.Exec(context.Background(),
+		`UPDATE low_stock_alerts SET resolved_at = CURRENT_TIMESTAMP WHERE product_id = $1 AND resolved_at IS NULL`,
+		productID)
+	return err
+}
+
+func (r *ReplenishmentPostgresRepo) FindAlerts(openOnly bool) ([]domain.LowStockAlert, error) {
+	query := `SELECT a.id, a.product_id, p.name, a.available, a.reorder_point, a.triggered_at, a.resolved_at
+        FROM low_stock_alerts a
+        JOIN products p ON p.id = a.product_id`
+	if openOnly {
+		query += ` WHERE a.resolved_at IS NULL`
+	}
+	query += ` ORDER BY a.triggered_at DESC, a.id`
+
+	rows, err := DB.Query(context.Background(), query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []domain.LowStockAlert
+	for rows.Next() {
+		var a domain.LowStockAlert
+		err := rows.Scan(&a.ID, &a.ProductID, &a.ProductName, &a.Available, &a.ReorderPoint, &a.TriggeredAt, &a.ResolvedAt)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, rows.Err()
+}
+
+const reorderSuggestionColumns = `s.id, COALESCE(s.alert_id::text, ''), s.product_id, p.name, s.available, s.reorder_point,
+        s.reorder_quantity, s.units_sold, s.sales_window_days, s.daily_sales, s.suggested_quantity, s.status,
+        s.created_at, s.updated_at`
+
+func scanReorderSuggestion(row pgx.Row) (domain.ReorderSuggestion, error) {
+	var s domain.ReorderSuggestion
+	err := row.Scan(&s.ID, &s.AlertID, &s.ProductID, &s.ProductName, &s.Available, &s.ReorderPoint,
+		&s.ReorderQuantity, &s.UnitsSold, &s.SalesWindowDays, &s.DailySales, &s.SuggestedQuantity, &s.Status,
+		&s.CreatedAt, &s.UpdatedAt)
+	return s, err
+}
+
+func (r *ReplenishmentPostgresRepo) FindSuggestions(status string) ([]domain.ReorderSuggestion, error) {
+	query := `SELECT ` + reorderSuggestionColumns + ` FROM reorder_suggestions s JOIN products p ON p.id = s.product_id`
+	args := []interface{}{}
+	if status != "" {
+		query += ` WHERE s.status = $1`
+		args = append(args, status)
+	}
+	query += ` ORDER BY s.created_at DESC, s.id`
+
+	rows, err := DB.Query(context.Background(), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suggestions []domain.ReorderSuggestion
+	for rows.Next() {
+		s, err := scanReorderSuggestion(rows)
+		if err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, s)
+	}
+	return suggestions, rows.Err()
+}
+
+func (r *ReplenishmentPostgresRepo) FindSuggestionByID(id string) (domain.ReorderSuggestion, error) {
+	return scanReorderSuggestion(DB.QueryRow(context.Background(),
+		`SELECT `+reorderSuggestionColumns+` FROM reorder_suggestions s JOIN products p ON p.id = s.product_id WHERE s.id = $1`, id))
+}
+
+func (r *ReplenishmentPostgresRepo) UpdateSuggestionStatus(id, status string) error {
+	_, err := DB.Exec(context.Background(),
+		`UPDATE reorder_suggestions SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, status, id)
+	return err
+}
@@ -0,0 +1,500 @@
+package postgres
+
+import (
+	"FoodStore-AdvProg2/domain"
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+type StockBatchPostgresRepo struct{}
+
+func NewStockBatchPostgresRepo() *StockBatchPostgresRepo {
+	return &StockBatchPostgresRepo{}
+}
+
+const batchColumns = `id, product_id, location_id, lot_number, received_at, expires_at, quantity, initial_quantity`
+
+func scanBatch(row pgx.Row) (domain.StockBatch, error) {
+	var b domain.StockBatch
+	err := row.Scan(&b.ID, &b.ProductID, &b.LocationID, &b.LotNumber, &b.ReceivedAt, &b.ExpiresAt, &b.Quantity, &b.InitialQuantity)
+	return b, err
+}
+
+// resolveLocation возвращает locationID или, если он пуст, ID склада по умолчанию
+func resolveLocation(ctx context.Context, tx pgx.Tx, locationID string) (string, error) {
+	if locationID != "" {
+		return locationID, nil
+	}
+	err := tx.QueryRow(ctx, `SELECT id FROM locations WHERE is_default`).Scan(&locationID)
+	return locationID, err
+}
+
+// recordMovement меняет products.stock на m.Quantity, добавляет запись в журнал
+// и событие StockChanged в outbox. Вызывается в транзакции вместе с изменением партии.
+func recordMovement(ctx context.Context, tx pgx.Tx, m domain.StockMovement) error {
+	err := tx.QueryRow(ctx,
+		`UPDATE products SET stock = stock + $1 WHERE id = $2 RETURNING stock`,
+		m.Quantity, m.ProductID,
+	).Scan(&m.BalanceAfter)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO stock_movements (id, product_id, location_id, batch_id, type, quantity, balance_after, reference_id, actor, reason)
+        VALUES ($1, $2, $3, NULLIF($4, '')::uuid, $5, $6, $7, $8, $9, $10)`,
+		uuid.New().String(), m.ProductID, m.LocationID, m.BatchID, m.Type, m.Quantity, m.BalanceAfter,
+		m.ReferenceID, m.Actor, m.Reason,
+	)
+	if err != nil {
+		return err
+	}
+
+	return insertEvent(ctx, tx, domain.EventStockChanged, m.ProductID, domain.StockChangedEvent{
+		ProductID:   m.ProductID,
+		LocationID:  m.LocationID,
+		Movement:    m.Type,
+		Quantity:    m.Quantity,
+		Stock:       m.BalanceAfter,
+		ReferenceID: m.ReferenceID,
+	})
+}
+
+// Receive сохраняет новую партию и увеличивает остаток товара
+func (r *StockBatchPostgresRepo) Receive(batch domain.StockBatch, change domain.StockChange) (domain.StockBatch, error) {
+	return r.receive(batch, domain.MovementReceipt, change)
+}
+
+func (r *StockBatchPostgresRepo) receive(batch domain.StockBatch, movementType string, change domain.StockChange) (domain.StockBatch, error) {
+	ctx := context.Background()
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return domain.StockBatch{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	batch, err = insertBatch(ctx, tx, batch, movementType, change)
+	if err != nil {
+		return domain.StockBatch{}, err
+	}
+
+	return batch, tx.Commit(ctx)
+}
+
+// insertBatch заводит партию и записывает приход в журнал в транзакции tx
+func insertBatch(ctx context.Context, tx pgx.Tx, batch domain.StockBatch, movementType string, change domain.StockChange) (domain.StockBatch, error) {
+	var err error
+	batch.ID = uuid.New().String()
+	batch.InitialQuantity = batch.Quantity
+	if batch.LocationID, err = resolveLocation(ctx, tx, batch.LocationID); err != nil {
+		return domain.StockBatch{}, err
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO stock_batches (`+batchColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		batch.ID, batch.ProductID, batch.LocationID, batch.LotNumber, batch.ReceivedAt, batch.ExpiresAt, batch.Quantity, batch.InitialQuantity,
+	)
+	if err != nil {
+		return domain.StockBatch{}, err
+	}
+
+	err = recordMovement(ctx, tx, domain.StockMovement{
+		ProductID:   batch.ProductID,
+		LocationID:  batch.LocationID,
+		BatchID:     batch.ID,
+		Type:        movementType,
+		Quantity:    batch.Quantity,
+		ReferenceID: change.ReferenceID,
+		Actor:       change.Actor,
+		Reason:      change.Reason,
+	})
+	if err != nil {
+		return domain.StockBatch{}, err
+	}
+
+	return batch, nil
+}
+
+func (r *StockBatchPostgresRepo) FindByID(id string) (domain.StockBatch, error) {
+	return scanBatch(DB.QueryRow(context.Background(), `SELECT `+batchColumns+` FROM stock_batches WHERE id = $1`, id))
+}
+
+// FindByProductID возвращает партии товара в порядке FEFO
+func (r *StockBatchPostgresRepo) FindByProductID(productID, locationID string, includeEmpty bool) ([]domain.StockBatch, error) {
+	query := `SELECT ` + batchColumns + ` FROM stock_batches WHERE product_id = $1 AND ($2 = '' OR location_id::text = $2)`
+	if !includeEmpty {
+		query += ` AND quantity > 0`
+	}
+	query += ` ORDER BY expires_at ASC NULLS LAST, received_at, id`
+
+	return r.query(query, productID, locationID)
+}
+
+func (r *StockBatchPostgresRepo) FindExpiring(before time.Time) ([]domain.StockBatch, error) {
+	query := `SELECT ` + batchColumns + ` FROM stock_batches
+        WHERE quantity > 0 AND expires_at <= $1
+        ORDER BY expires_at, product_id, id`
+
+	return r.query(query, before)
+}
+
+func (r *StockBatchPostgresRepo) query(query string, args ...interface{}) ([]domain.StockBatch, error) {
+	rows, err := DB.Query(context.Background(), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batches []domain.StockBatch
+	for rows.Next() {
+		b, err := scanBatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		batches = append(batches, b)
+	}
+	return batches, rows.Err()
+}
+
+// Allocate списывает все позиции заказа в одной транзакции
+func (r *StockBatchPostgresRepo) Allocate(orderID, locationID string, items []domain.OrderItemRequest, change domain.StockChange) ([]domain.BatchAllocation, error) {
+	// Объединяем повторяющиеся товары и блокируем партии в одном порядке, чтобы избежать взаимных блокировок
+	quantities := make(map[string]int)
+	var productIDs []string
+	for _, item := range items {
+		if _, ok := quantities[item.ProductID]; !ok {
+			productIDs = append(productIDs, item.ProductID)
+		}
+		quantities[item.ProductID] += item.Quantity
+	}
+	sort.Strings(productIDs)
+
+	ctx := context.Background()
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	change.ReferenceID = orderID
+	var allocations []domain.BatchAllocation
+	for _, productID := range productIDs {
+		allocated, err := allocateFEFO(ctx, tx, productID, locationID, quantities[productID], domain.MovementOrder, change)
+		if err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, allocated...)
+	}
+
+	return allocations, tx.Commit(ctx)
+}
+
+// allocateFEFO списывает quantity единиц товара с непросроченных партий точки, начиная с ближайшего срока годности.
+// Для движений типа order списания запоминаются, чтобы вернуть их при отмене заказа.
+func allocateFEFO(ctx context.Context, tx pgx.Tx, productID, locationID string, quantity int, movementType string, change domain.StockChange) ([]domain.BatchAllocation, error) {
+	rows, err := tx.Query(ctx, `SELECT `+batchColumns+` FROM stock_batches
+        WHERE product_id = $1 AND location_id = $2 AND quantity > 0 AND (expires_at IS NULL OR expires_at >= CURRENT_DATE)
+        ORDER BY expires_at ASC NULLS LAST, received_at, id
+        FOR UPDATE`, productID, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var batches []domain.StockBatch
+	for rows.Next() {
+		b, err := scanBatch(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		batches = append(batches, b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	allocations, err := domain.PlanFEFO(batches, quantity, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range allocations {
+		if _, err := tx.Exec(ctx, `UPDATE stock_batches SET quantity = quantity - $1 WHERE id = $2`, a.Quantity, a.BatchID); err != nil {
+			return nil, err
+		}
+		if movementType == domain.MovementOrder {
+			_, err := tx.Exec(ctx,
+				`INSERT INTO stock_allocations (id, order_id, batch_id, product_id, quantity) VALUES ($1, $2, $3, $4, $5)`,
+				uuid.New().String(), change.ReferenceID, a.BatchID, productID, a.Quantity,
+			)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err := recordMovement(ctx, tx, domain.StockMovement{
+			ProductID:   productID,
+			LocationID:  a.LocationID,
+			BatchID:     a.BatchID,
+			Type:        movementType,
+			Quantity:    -a.Quantity,
+			ReferenceID: change.ReferenceID,
+			Actor:       change.Actor,
+			Reason:      change.Reason,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return allocations, nil
+}
+
+// Release возвращает списанное под заказ на те же партии
+func (r *StockBatchPostgresRepo) Release(orderID string, change domain.StockChange) ([]domain.BatchAllocation, error) {
+	ctx := context.Background()
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+        UPDATE stock_allocations SET released_at = CURRENT_TIMESTAMP
+        WHERE order_id = $1 AND released_at IS NULL
+        RETURNING batch_id, product_id, quantity`, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var allocations []domain.BatchAllocation
+	for rows.Next() {
+		var a domain.BatchAllocation
+		if err := rows.Scan(&a.BatchID, &a.ProductID, &a.Quantity); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		allocations = append(allocations, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Блокируем партии и возвращаем списания на те же партии, даже если они уже просрочены
+	batchIDs := make([]string, len(allocations))
+	for i, a := range allocations {
+		batchIDs[i] = a.BatchID
+	}
+	rows, err = tx.Query(ctx, `SELECT `+batchColumns+` FROM stock_batches WHERE id = ANY($1::uuid[]) ORDER BY id FOR UPDATE`, batchIDs)
+	if err != nil {
+		return nil, err
+	}
+	var batches []domain.StockBatch
+	for rows.Next() {
+		b, err := scanBatch(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		batches = append(batches, b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	restocked, err := domain.ReturnAllocations(batches, allocations)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range restocked {
+		if _, err := tx.Exec(ctx, `UPDATE stock_batches SET quantity = $1 WHERE id = $2`, b.Quantity, b.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	byID := make(map[string]domain.StockBatch, len(batches))
+	for _, b := range batches {
+		byID[b.ID] = b
+	}
+	for i, a := range allocations {
+		b := byID[a.BatchID]
+		allocations[i].LocationID, allocations[i].LotNumber, allocations[i].ExpiresAt = b.LocationID, b.LotNumber, b.ExpiresAt
+
+		err = recordMovement(ctx, tx, domain.StockMovement{
+			ProductID:   a.ProductID,
+			LocationID:  b.LocationID,
+			BatchID:     a.BatchID,
+			Type:        domain.MovementCancelRestock,
+			Quantity:    a.Quantity,
+			ReferenceID: orderID,
+			Actor:       change.Actor,
+			Reason:      change.Reason,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return allocations, tx.Commit(ctx)
+}
+
+func (r *StockBatchPostgresRepo) Adjust(productID, locationID string, delta int, change domain.StockChange) error {
+	if delta == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := adjustStock(ctx, tx, productID, locationID, delta, change); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// adjustStock меняет остаток товара на точке на delta в транзакции tx: прибавка заводится
+// партией-корректировкой, недостача списывается по FEFO
+func adjustStock(ctx context.Context, tx pgx.Tx, productID, locationID string, delta int, change domain.StockChange) error {
+	if delta > 0 {
+		_, err := insertBatch(ctx, tx, domain.StockBatch{
+			ProductID:  productID,
+			LocationID: locationID,
+			LotNumber:  domain.LotAdjustment,
+			ReceivedAt: time.Now(),
+			Quantity:   delta,
+		}, domain.MovementAdjustment, change)
+		return err
+	}
+
+	locationID, err := resolveLocation(ctx, tx, locationID)
+	if err != nil {
+		return err
+	}
+	_, err = allocateFEFO(ctx, tx, productID, locationID, -delta, domain.MovementAdjustment, change)
+	return err
+}
+
+func (r *StockBatchPostgresRepo) WriteOff(batchID string, quantity int, change domain.StockChange) (domain.StockBatch, error) {
+	ctx := context.Background()
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return domain.StockBatch{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	batch, err := scanBatch(tx.QueryRow(ctx, `SELECT `+batchColumns+` FROM stock_batches WHERE id = $1 FOR UPDATE`, batchID))
+	if err != nil {
+		return domain.StockBatch{}, err
+	}
+	if batch.Quantity < quantity {
+		return domain.StockBatch{}, domain.ErrInsufficientStock
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE stock_batches SET quantity = quantity - $1 WHERE id = $2`, quantity, batchID); err != nil {
+		return domain.StockBatch{}, err
+	}
+	batch.Quantity -= quantity
+
+	err = recordMovement(ctx, tx, domain.StockMovement{
+		ProductID:   batch.ProductID,
+		LocationID:  batch.LocationID,
+		BatchID:     batch.ID,
+		Type:        domain.MovementWriteOff,
+		Quantity:    -quantity,
+		ReferenceID: change.ReferenceID,
+		Actor:       change.Actor,
+		Reason:      change.Reason,
+	})
+	if err != nil {
+		return domain.StockBatch{}, err
+	}
+
+	return batch, tx.Commit(ctx)
+}
+
+// Transfer списывает товар с партий точки-отправителя по FEFO и заводит на точке-получателе
+// партии с теми же лотами и сроками годности. Общий остаток товара не меняется.
+func (r *StockBatchPostgresRepo) Transfer(transfer domain.StockTransfer, change domain.StockChange) (domain.StockTransfer, error) {
+	ctx := context.Background()
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return domain.StockTransfer{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	transfer.ID = uuid.New().String()
+	change.ReferenceID = transfer.ID
+
+	allocations, err := allocateFEFO(ctx, tx, transfer.ProductID, transfer.FromLocationID, transfer.Quantity, domain.MovementTransferOut, change)
+	if err != nil {
+		return domain.StockTransfer{}, err
+	}
+
+	for _, a := range allocations {
+		batchID := uuid.New().String()
+		_, err := tx.Exec(ctx, `
+            INSERT INTO stock_batches (`+batchColumns+`)
+            SELECT $1, product_id, $2, lot_number, received_at, expires_at, $3, $3
+            FROM stock_batches WHERE id = $4`,
+			batchID, transfer.ToLocationID, a.Quantity, a.BatchID,
+		)
+		if err != nil {
+			return domain.StockTransfer{}, err
+		}
+
+		err = recordMovement(ctx, tx, domain.StockMovement{
+			ProductID:   transfer.ProductID,
+			LocationID:  transfer.ToLocationID,
+			BatchID:     batchID,
+			Type:        domain.MovementTransferIn,
+			Quantity:    a.Quantity,
+			ReferenceID: transfer.ID,
+			Actor:       change.Actor,
+			Reason:      change.Reason,
+		})
+		if err != nil {
+			return domain.StockTransfer{}, err
+		}
+	}
+
+	transfer.Allocations = allocations
+	return transfer, tx.Commit(ctx)
+}
+
+func (r *StockBatchPostgresRepo) StockLevels(productIDs []string) ([]domain.LocationStock, error) {
+	query := `
+        SELECT b.location_id, b.product_id, SUM(b.quantity)
+        FROM stock_batches b
+        JOIN locations l ON l.id = b.location_id AND l.active
+        WHERE b.product_id = ANY($1::uuid[]) AND b.quantity > 0
+          AND (b.expires_at IS NULL OR b.expires_at >= CURRENT_DATE)
+        GROUP BY b.location_id, b.product_id
+        ORDER BY b.location_id, b.product_id
+    `
+	rows, err := DB.Query(context.Background(), query, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var levels []domain.LocationStock
+	for rows.Next() {
+		var l domain.LocationStock
+		if err := rows.Scan(&l.LocationID, &l.ProductID, &l.Available); err != nil {
+			return nil, err
+		}
+		levels = append(levels, l)
+	}
+	return levels, rows.Err()
+}
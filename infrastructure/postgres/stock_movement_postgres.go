@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"FoodStore-AdvProg2/domain"
+	"context"
+	"fmt"
+)
+
+type StockMovementPostgresRepo struct{}
+
+func NewStockMovementPostgresRepo() *StockMovementPostgresRepo {
+	return &StockMovementPostgresRepo{}
+}
+
+// FindAll возвращает движения по фильтру, новые первыми, и общее число найденных движений
+func (r *StockMovementPostgresRepo) FindAll(filter domain.StockMovementFilter, limit, offset int) ([]domain.StockMovement, int, error) {
+	where := ""
+	args := []interface{}{}
+	add := func(cond string, value interface{}) {
+		args = append(args, value)
+		where += fmt.Sprintf(" AND "+cond, len(args))
+	}
+
+	if filter.ProductID != "" {
+		add("product_id = $%d", filter.ProductID)
+	}
+	if filter.LocationID != "" {
+		add("location_id = $%d", filter.LocationID)
+	}
+	if filter.BatchID != "" {
+		add("batch_id = $%d", filter.BatchID)
+	}
+	if filter.Type != "" {
+		add("type = $%d", filter.Type)
+	}
+	if filter.ReferenceID != "" {
+		add("reference_id = $%d", filter.ReferenceID)
+	}
+	if filter.Actor != "" {
+		add("actor = $%d", filter.Actor)
+	}
+	if filter.From != nil {
+		add("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("created_at < $%d", *filter.To)
+	}
+
+	query := fmt.Sprintf(`
+        SELECT id, product_id, COALESCE(location_id::text, ''), COALESCE(batch_id::text, ''), type, quantity, balance_after,
+               reference_id, actor, reason, created_at, COUNT(*) OVER()
+        FROM stock_movements
+        WHERE 1=1%s
+        ORDER BY created_at DESC, id DESC
+        LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := DB.Query(context.Background(), query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var movements []domain.StockMovement
+	total := 0
+	for rows.Next() {
+		var m domain.StockMovement
+		err := rows.Scan(&m.ID, &m.ProductID, &m.LocationID, &m.BatchID, &m.Type, &m.Quantity, &m.BalanceAfter,
+			&m.ReferenceID, &m.Actor, &m.Reason, &m.CreatedAt, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		movements = append(movements, m)
+	}
+
+	return movements, total, rows.Err()
+}
+
+func (r *StockMovementPostgresRepo) Reconcile(productID string) ([]domain.StockReconciliation, error) {
+	query := `
+        SELECT p.id, p.stock,
+               COALESCE((SELECT SUM(m.quantity) FROM stock_movements m WHERE m.product_id = p.id), 0),
+               COALESCE((SELECT SUM(b.quantity) FROM stock_batches b WHERE b.product_id = p.id), 0)
+        FROM products p
+        WHERE $1 = '' OR p.id::text = $1
+        ORDER BY p.id`
+
+	rows, err := DB.Query(context.Background(), query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []domain.StockReconciliation
+	for rows.Next() {
+		var rec domain.StockReconciliation
+		if err := rows.Scan(&rec.ProductID, &rec.RecordedStock, &rec.LedgerStock, &rec.BatchStock); err != nil {
+			return nil, err
+		}
+		result = append(result, rec)
+	}
+
+	return result, rows.Err()
+}
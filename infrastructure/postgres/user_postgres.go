@@ -11,120 +11,104 @@ import (
 	"FoodStore-AdvProg2/domain"
 )
 
+// UserPostgresRepo реализует domain.UserRepository для PostgreSQL
 type UserPostgresRepo struct{}
 
+// NewUserPostgresRepo создает новый экземпляр UserPostgresRepo
 func NewUserPostgresRepo() *UserPostgresRepo {
 	return &UserPostgresRepo{}
 }
 
-func (r *UserPostgresRepo) Save(user *domain.User) error {
-	if user.ID == "" {
-		user.ID = uuid.New().String()
-	}
-
-	now := time.Now()
-	user.CreatedAt = now
-	user.UpdatedAt = now
-
-	query := `INSERT INTO users (id, username, email, full_name, password, created_at, updated_at) 
-              VALUES ($1, $2, $3, $4, $5, $6, $7)`
+// errUserNotFound - пользователь не найден
+var errUserNotFound = errors.New("user not found")
 
-	_, err := DB.Exec(
-		context.Background(),
-		query,
-		user.ID,
-		user.Username,
-		user.Email,
-		user.FullName,
-		user.Password,
-		user.CreatedAt,
-		user.UpdatedAt,
-	)
-
-	return err
-}
-
-func (r *UserPostgresRepo) FindByID(id string) (*domain.User, error) {
-	query := `SELECT id, username, email, full_name, password, created_at, updated_at 
-              FROM users 
-              WHERE id = $1`
+const userColumns = `id, username, email, full_name, password, role, created_at, updated_at`
 
+func scanUser(row pgx.Row) (domain.User, error) {
 	var user domain.User
-	err := DB.QueryRow(context.Background(), query, id).Scan(
+	err := row.Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
 		&user.FullName,
 		&user.Password,
+		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
-
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, errors.New("user not found")
-		}
-		return nil, err
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.User{}, errUserNotFound
 	}
-
-	return &user, nil
+	return user, err
 }
 
-func (r *UserPostgresRepo) FindByUsername(username string) (*domain.User, error) {
-	query := `SELECT id, username, email, full_name, password, created_at, updated_at 
-              FROM users 
-              WHERE username = $1`
-
-	var user domain.User
-	err := DB.QueryRow(context.Background(), query, username).Scan(
-		&user.ID,
-		&user.Username,
-		&user.Email,
-		&user.FullName,
-		&user.Password,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+// Create добавляет пользователя и в той же транзакции записывает событие UserRegistered
+func (r *UserPostgresRepo) Create(user domain.User) error {
+	if user.ID == "" {
+		user.ID = uuid.New().String()
+	}
+	if user.Role == "" {
+		user.Role = domain.UserRoleCustomer
+	}
+	now := time.Now()
 
+	ctx := context.Background()
+	tx, err := DB.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, errors.New("user not found")
-		}
-		return nil, err
+		return err
 	}
+	defer tx.Rollback(ctx)
 
-	return &user, nil
-}
-
-func (r *UserPostgresRepo) FindByEmail(email string) (*domain.User, error) {
-	query := `SELECT id, username, email, full_name, password, created_at, updated_at 
-              FROM users 
-              WHERE email = $1`
+	query := `INSERT INTO users (id, username, email, full_name, password, role, created_at, updated_at) 
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
 
-	var user domain.User
-	err := DB.QueryRow(context.Background(), query, email).Scan(
-		&user.ID,
-		&user.Username,
-		&user.Email,
-		&user.FullName,
-		&user.Password,
-		&user.CreatedAt,
-		&user.UpdatedAt,
+	_, err = tx.Exec(
+		ctx,
+		query,
+		user.ID,
+		user.Username,
+		user.Email,
+		user.FullName,
+		user.Password,
+		user.Role,
+		now,
+		now,
 	)
+	if err != nil {
+		return err
+	}
 
+	err = insertEvent(ctx, tx, domain.EventUserRegistered, user.ID, domain.UserRegisteredEvent{
+		UserID:   user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+	})
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, errors.New("user not found")
-		}
-		return nil, err
+		return err
 	}
+	return tx.Commit(ctx)
+}
+
+// GetByID возвращает пользователя по ID
+func (r *UserPostgresRepo) GetByID(id string) (domain.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	return scanUser(DB.QueryRow(context.Background(), query, id))
+}
 
-	return &user, nil
+// GetByUsername возвращает пользователя по имени пользователя
+func (r *UserPostgresRepo) GetByUsername(username string) (domain.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = $1`
+	return scanUser(DB.QueryRow(context.Background(), query, username))
 }
 
-func (r *UserPostgresRepo) Update(user *domain.User) error {
-	user.UpdatedAt = time.Now()
+// GetByEmail возвращает пользователя по email
+func (r *UserPostgresRepo) GetByEmail(email string) (domain.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
+	return scanUser(DB.QueryRow(context.Background(), query, email))
+}
 
+// Update обновляет информацию о пользователе; роль меняется только напрямую в базе
+func (r *UserPostgresRepo) Update(id string, user domain.User) error {
 	query := `UPDATE users 
               SET username = $1, email = $2, full_name = $3, password = $4, updated_at = $5
               WHERE id = $6`
@@ -136,9 +120,14 @@ func (r *UserPostgresRepo) Update(user *domain.User) error {
 		user.Email,
 		user.FullName,
 		user.Password,
-		user.UpdatedAt,
-		user.ID,
+		time.Now(),
+		id,
 	)
+	return err
+}
 
+// Delete удаляет пользователя по ID
+func (r *UserPostgresRepo) Delete(id string) error {
+	_, err := DB.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, id)
 	return err
 }
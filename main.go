@@ -1,6 +1,7 @@
 package main
 
 import (
+	"FoodStore-AdvProg2/domain"
 	"FoodStore-AdvProg2/handler"
 	"FoodStore-AdvProg2/infrastructure/postgres"
 	"FoodStore-AdvProg2/usecase"
@@ -36,8 +37,13 @@ func main() {
 	productRepo := postgres.NewProductPostgresRepo()
 	orderRepo := postgres.NewOrderPostgresRepo()
 
-	productUC := usecase.NewProductUseCase(productRepo)
-	orderUC := usecase.NewOrderUseCase(orderRepo, productRepo)
+	batchRepo := postgres.NewStockBatchPostgresRepo()
+
+	productUC := usecase.NewProductUseCase(productRepo, batchRepo)
+	stockUC := usecase.NewStockUseCase(batchRepo, postgres.NewStockMovementPostgresRepo(), productRepo, postgres.NewLocationPostgresRepo())
+	orderUC := usecase.NewOrderUseCase(orderRepo, productRepo, stockUC, postgres.NewPromotionPostgresRepo(),
+		postgres.NewDeliverySlotPostgresRepo(), postgres.NewDeliveryZonePostgresRepo(), 0,
+		domain.TaxPolicy{Mode: domain.TaxInclusive, StandardRate: domain.DefaultVATRate})
 
 	productHandler := handler.NewProductHandler(productUC)
 	orderHandler := handler.NewOrderHandler(orderUC)
@@ -8,7 +8,7 @@ document
     const productStock = parseInt(document.querySelector('input[name="productStock"]').value);
 
     try {
-      const response = await fetch("/api/products", {
+      const response = await apiFetch("/api/v1/products", {
         method: "POST",
         headers: {
           "Content-Type": "application/json",
@@ -32,9 +32,50 @@ document
     }
   });
 
+document
+  .getElementById("importCatalog-form")
+  .addEventListener("submit", async function (event) {
+    event.preventDefault();
+
+    const form = event.target;
+    const formData = new FormData();
+    formData.append("file", form.catalogFile.files[0]);
+
+    const url = new URL("/api/v1/products/import", window.location.origin);
+    url.searchParams.append("dry_run", form.dryRun.checked);
+    url.searchParams.append("atomic", form.atomic.checked);
+
+    try {
+      const response = await apiFetch(url, { method: "POST", body: formData });
+      const result = await response.json();
+      if (!response.ok && !result.errors) {
+        alert(`Failed to import catalog: ${result.error}`);
+        return;
+      }
+
+      // Show the first row errors; the full report is in the response
+      const errors = (result.errors || [])
+        .slice(0, 10)
+        .map((e) => `line ${e.line}${e.field ? ` (${e.field})` : ""}: ${e.message}`);
+      const summary = result.dry_run ? "Dry run" : result.committed ? "Imported" : "Nothing imported";
+      alert(
+        `${summary}: ${result.created || 0} created, ${result.updated || 0} updated, ${result.failed || 0} failed` +
+          (errors.length ? `\n\n${errors.join("\n")}` : "")
+      );
+      if (result.committed) {
+        form.reset();
+        fetchProducts(1);
+      }
+    } catch (error) {
+      alert(`Failed to import catalog: ${error}`);
+    }
+  });
+
 // Pagination state
 let currentPage = 1;
 const perPage = 5; 
+// Values shown when editing started, including the product version
+const editedProducts = {};
 
 function updatePagination(total, currentPage, perPage) {
   const totalPages = Math.ceil(total / perPage);
@@ -76,7 +117,7 @@ function updatePagination(total, currentPage, perPage) {
 
 async function deleteProduct(productId) {
   try {
-    const response = await fetch(`/api/products/${productId}`, {
+    const response = await apiFetch(`/api/v1/products/${productId}`, {
       method: "DELETE",
     });
     if (response.ok) {
@@ -89,11 +130,36 @@ async function deleteProduct(productId) {
   }
 }
 
-function editProduct(id, name, price, stock) {
+async function uploadImage(productId, input) {
+  if (!input.files.length) return;
+
+  const formData = new FormData();
+  formData.append("image", input.files[0]);
+
+  try {
+    const response = await apiFetch(`/api/v1/products/${productId}/images`, {
+      method: "POST",
+      body: formData,
+    });
+    if (response.ok) {
+      fetchProducts(currentPage);
+    } else {
+      const error = await response.json();
+      alert(`Failed to upload image: ${error.error}`);
+    }
+  } catch (error) {
+    alert(`Failed to upload image: ${error}`);
+  }
+}
+
+function editProduct(id, name, price, stock, version) {
   const productItem = document
     .querySelector(`button[onclick*="editProduct('${id}'"]`)
     .closest(".main__products-item");
 
+  // Remember what the admin saw: only changed fields are sent
+  editedProducts[id] = { Name: name, Price: price, Stock: stock, Version: version };
+
   productItem.innerHTML = `
     <form class="main__products-item-update" onsubmit="submitUpdate(event, '${id}')">
       <input class="main__products-item-update-input" type="text" name="Name" value="${name}" required />
@@ -111,23 +177,39 @@ async function submitUpdate(event, id) {
   event.preventDefault();
 
   const form = event.target;
-  const updatedProduct = {
+  const original = editedProducts[id];
+  const edited = {
     Name: form.Name.value,
     Price: parseFloat(form.Price.value),
     Stock: parseInt(form.Stock.value),
   };
 
+  // PATCH only touches edited fields, so a price change leaves stock alone
+  const changes = { Version: original.Version };
+  for (const field of Object.keys(edited)) {
+    if (edited[field] !== original[field]) {
+      changes[field] = edited[field];
+    }
+  }
+  if (Object.keys(changes).length === 1) {
+    fetchProducts(currentPage);
+    return;
+  }
+
   try {
-    const response = await fetch(`/api/products/${id}`, {
-      method: "PUT",
+    const response = await apiFetch(`/api/v1/products/${id}`, {
+      method: "PATCH",
       headers: {
         "Content-Type": "application/json",
       },
-      body: JSON.stringify(updatedProduct),
+      body: JSON.stringify(changes),
     });
 
     if (response.ok) {
       fetchProducts(currentPage);
+    } else if (response.status === 409) {
+      alert("The product was changed by someone else. The list has been reloaded, please edit it again.");
+      fetchProducts(currentPage);
     } else {
       const error = await response.text();
       alert(`Failed to update product: ${error}`);
@@ -150,14 +232,14 @@ document
 
 async function fetchProducts(page = currentPage, filters = {}) {
   try {
-    const url = new URL("/api/products", window.location.origin);
+    const url = new URL("/api/v1/products", window.location.origin);
     url.searchParams.append("page", page);
     url.searchParams.append("per_page", perPage);
 
     if (filters.minPrice) url.searchParams.append("min_price", filters.minPrice);
     if (filters.maxPrice) url.searchParams.append("max_price", filters.maxPrice);
 
-    const response = await fetch(url);
+    const response = await apiFetch(url);
     if (!response.ok) {
       throw new Error(`Failed to fetch products: ${response.statusText}`);
     }
@@ -171,18 +253,26 @@ async function fetchProducts(page = currentPage, filters = {}) {
     data.products.forEach((product) => {
       const productItem = document.createElement("div");
       productItem.className = "main__products-item";
+      const thumbnail = product.images && product.images.length
+        ? `<img class="main__products-item-image" src="${product.images[0].thumbnail_url}" alt="${product.name}" />`
+        : "";
       productItem.innerHTML = `
+        ${thumbnail}
         <div class="main__products-item-wrap">
           <div class="main__products-item-name">${product.name}</div>
           <button class="main__products-item-edit" onclick="editProduct('${product.id}', '${product.name}', ${
             product.price
-          }, ${product.stock})">✏️</button>
+          }, ${product.stock || 0}, ${product.version})">✏️</button>
           <button class="main__products-item-delete" onclick="deleteProduct('${
             product.id
           }')">❌</button>
         </div>
         <div class="main__products-item-price">${product.price.toFixed(2)}₸</div>
         <div class="main__products-item-stock">Stock: ${product.stock}</div>
+        <label class="main__products-item-upload">
+          Upload image
+          <input type="file" accept="image/jpeg,image/png,image/gif" onchange="uploadImage('${product.id}', this)" hidden />
+        </label>
       `;
       productsList.appendChild(productItem);
     });
@@ -194,4 +284,65 @@ async function fetchProducts(page = currentPage, filters = {}) {
   }
 }
 
-window.onload = () => fetchProducts(1);
\ No newline at end of file
+window.onload = () => {
+  fetchProducts(1);
+  fetchOrders();
+  watchOrders();
+};
+
+// Orders of all buyers; the list is kept up to date by Server-Sent Events
+let orders = [];
+
+async function fetchOrders() {
+  try {
+    const response = await apiFetch("/api/v1/orders");
+    if (!response.ok) {
+      throw new Error(`Failed to fetch orders: ${response.statusText}`);
+    }
+    orders = (await response.json()) || [];
+    renderOrders();
+  } catch (error) {
+    console.error("Error fetching orders:", error);
+  }
+}
+
+function renderOrders(updatedId) {
+  const ordersList = document.getElementById("orders-list");
+  if (orders.length === 0) {
+    ordersList.innerHTML = '<div class="main__orders-empty">No orders yet</div>';
+    return;
+  }
+
+  ordersList.innerHTML = "";
+  orders.forEach((order) => {
+    const orderItem = document.createElement("div");
+    orderItem.className = "main__orders-item";
+    if (order.id === updatedId) {
+      orderItem.classList.add("main__orders-item-updated");
+    }
+    orderItem.innerHTML = `
+      <div class="main__orders-item-id">#${order.id.slice(0, 8)}</div>
+      <div class="main__orders-item-user">User: ${order.user_id}</div>
+      <div class="main__orders-item-total">${(order.total_price || 0).toFixed(2)}₸</div>
+      <div class="main__orders-item-status">${order.status}</div>
+    `;
+    ordersList.appendChild(orderItem);
+  });
+}
+
+// The admin stream carries the orders of every buyer
+function watchOrders() {
+  apiEventStream("/api/v1/orders/events/all", (type, event) => {
+    if (!["created", "status_changed", "courier_assigned"].includes(type)) return;
+    const order = event.order;
+    if (!order) return;
+
+    const index = orders.findIndex((o) => o.id === order.id);
+    if (index === -1) {
+      orders.unshift(order);
+    } else {
+      orders[index] = order;
+    }
+    renderOrders(order.id);
+  });
+}
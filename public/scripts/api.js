@@ -0,0 +1,80 @@
+// Shared helpers for pages that call the gateway API on behalf of the signed-in user
+
+// The login page stores the user and the JWT from POST /users/login in localStorage
+function currentUser() {
+  return JSON.parse(localStorage.getItem("user") || "{}");
+}
+
+// apiFetch works like fetch and adds the Authorization header when the user is signed in.
+// A 401 means the token is missing or expired, so the user is sent to the login page.
+async function apiFetch(url, options = {}) {
+  const { token } = currentUser();
+  const headers = new Headers(options.headers || {});
+  if (token) {
+    headers.set("Authorization", `Bearer ${token}`);
+  }
+
+  const response = await fetch(url, { ...options, headers });
+  if (response.status === 401) {
+    localStorage.removeItem("user");
+    window.location.href = `/login?next=${encodeURIComponent(window.location.pathname)}`;
+  }
+  return response;
+}
+
+// apiEventStream reads a Server-Sent Events stream with the Authorization header, which EventSource cannot send.
+// onEvent gets the event type and the parsed data. The stream reconnects after a drop until close() is called.
+function apiEventStream(url, onEvent) {
+  const controller = new AbortController();
+
+  const dispatch = (block) => {
+    let type = "message";
+    const data = [];
+    block.split("\n").forEach((line) => {
+      if (line.startsWith("event:")) {
+        type = line.slice(6).trim();
+      } else if (line.startsWith("data:")) {
+        data.push(line.slice(5).trimStart());
+      }
+    });
+    if (data.length > 0) {
+      onEvent(type, JSON.parse(data.join("\n")));
+    }
+  };
+
+  const connect = async () => {
+    const response = await apiFetch(url, {
+      headers: { Accept: "text/event-stream" },
+      signal: controller.signal,
+    });
+    if (!response.ok) {
+      throw new Error(`Event stream failed with status ${response.status}`);
+    }
+
+    const reader = response.body.pipeThrough(new TextDecoderStream()).getReader();
+    let buffer = "";
+    for (;;) {
+      const { value, done } = await reader.read();
+      if (done) return;
+      buffer += value.replace(/\r\n/g, "\n");
+      let end;
+      while ((end = buffer.indexOf("\n\n")) !== -1) {
+        dispatch(buffer.slice(0, end));
+        buffer = buffer.slice(end + 2);
+      }
+    }
+  };
+
+  const run = () => {
+    connect()
+      .catch((error) => {
+        if (!controller.signal.aborted) console.error("Event stream error:", error);
+      })
+      .finally(() => {
+        if (!controller.signal.aborted) setTimeout(run, 3000);
+      });
+  };
+  run();
+
+  return { close: () => controller.abort() };
+}
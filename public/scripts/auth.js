@@ -13,7 +13,7 @@ document.addEventListener('DOMContentLoaded', function() {
             const errorDiv = document.getElementById('login-error');
             
             try {
-                const response = await fetch('/api/users/login', {
+                const response = await fetch('/api/v1/users/login', {
                     method: 'POST',
                     headers: {
                         'Content-Type': 'application/json'
@@ -33,9 +33,29 @@ document.addEventListener('DOMContentLoaded', function() {
                     id: userData.id,
                     username: userData.username,
                     token: userData.token,
-                    fullName: userData.full_name
+                    fullName: userData.full_name,
+                    role: userData.role
                 }));
                 
+                // Гостевая корзина переходит к пользователю; ошибка слияния не мешает входу
+                const cartId = localStorage.getItem('cartId');
+                if (cartId) {
+                    try {
+                        const cartResponse = await fetch(`/api/v1/cart/${cartId}/merge`, {
+                            method: 'POST',
+                            headers: {
+                                'Authorization': `Bearer ${userData.token}`
+                            }
+                        });
+                        if (cartResponse.ok) {
+                            const cart = await cartResponse.json();
+                            localStorage.setItem('cartId', cart.id);
+                        }
+                    } catch (error) {
+                        console.error('Error merging cart:', error);
+                    }
+                }
+                
                 // Перенаправляем на страницу заказов
                 window.location.href = '/order';
             } catch (error) {
@@ -65,7 +85,7 @@ document.addEventListener('DOMContentLoaded', function() {
             }
             
             try {
-                const response = await fetch('/api/users/register', {
+                const response = await fetch('/api/v1/users/register', {
                     method: 'POST',
                     headers: {
                         'Content-Type': 'application/json'
@@ -90,7 +110,8 @@ document.addEventListener('DOMContentLoaded', function() {
                     id: userData.id,
                     username: userData.username,
                     token: userData.token,
-                    fullName: userData.full_name
+                    fullName: userData.full_name,
+                    role: userData.role
                 }));
                 
                 // Перенаправляем на страницу заказов
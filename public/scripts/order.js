@@ -1,16 +1,24 @@
 const state = {
     products: [],
+    // The cart lives in Order Service; the browser keeps only its ID
+    cartId: localStorage.getItem('cartId') || '',
     cart: [],
+    cartSubtotal: 0,
     currentPage: 1,
     perPage: 6,
     total: 0,
     filters: {
         name: '',
         minPrice: '',
-        maxPrice: ''
+        maxPrice: '',
+        sort: '',
+        freeFrom: [],
+        diet: []
     },
     userId: '',
-    orders: []
+    orders: [],
+    // Event stream with live updates for the orders currently shown
+    orderEvents: null
 };
 
 const DOM = {
@@ -24,10 +32,14 @@ const DOM = {
     cartIcon: document.getElementById('cart-icon'),
     checkoutButton: document.getElementById('checkout-button'),
     orderForm: document.getElementById('order-form'),
-    userIdInput: document.getElementById('user-id'),
+    couponCodesInput: document.getElementById('coupon-codes'),
+    deliverySlotSelect: document.getElementById('delivery-slot'),
     nameFilter: document.getElementById('name-filter'),
+    sortFilter: document.getElementById('sort-filter'),
     minPriceFilter: document.getElementById('min-price'),
     maxPriceFilter: document.getElementById('max-price'),
+    freeFromFilter: document.getElementById('free-from-filter'),
+    dietFilter: document.getElementById('diet-filter'),
     applyFiltersButton: document.getElementById('apply-filters'),
     resetFiltersButton: document.getElementById('reset-filters'),
     userIdFilter: document.getElementById('user-id-filter'),
@@ -42,7 +54,6 @@ document.addEventListener('DOMContentLoaded', () => {
     DOM.resetFiltersButton.addEventListener('click', resetFilters);
     DOM.orderForm.addEventListener('submit', placeOrder);
     DOM.viewOrdersButton.addEventListener('click', fetchOrders);
-    DOM.userIdInput.addEventListener('input', updateCheckoutButton);
     
     // Добавляем обработчик для кнопок в заказах
     DOM.ordersList.addEventListener('click', (e) => {
@@ -53,30 +64,28 @@ document.addEventListener('DOMContentLoaded', () => {
         }
     });
 
-    const savedCart = localStorage.getItem('cart');
-    if (savedCart) {
-        state.cart = JSON.parse(savedCart);
-        updateCart();
-    }
+    loadCart();
+    fetchDeliverySlots();
 
-    const savedUserId = localStorage.getItem('userId');
+    const savedUserId = currentUser().id || localStorage.getItem('userId');
     if (savedUserId) {
-        DOM.userIdInput.value = savedUserId;
         DOM.userIdFilter.value = savedUserId;
         state.userId = savedUserId;
-        updateCheckoutButton();
         fetchOrders();
     }
 });
 
 async function fetchProducts() {
     try {
-        const url = new URL('/api/products', window.location.origin);
+        const url = new URL('/api/v1/products', window.location.origin);
         url.searchParams.append('page', state.currentPage);
         url.searchParams.append('per_page', state.perPage);
 
         if (state.filters.name) {
-            url.searchParams.append('name', state.filters.name);
+            url.searchParams.append('q', state.filters.name);
+        }
+        if (state.filters.sort) {
+            url.searchParams.append('sort', state.filters.sort);
         }
         if (state.filters.minPrice) {
             url.searchParams.append('min_price', state.filters.minPrice);
@@ -84,6 +93,13 @@ async function fetchProducts() {
         if (state.filters.maxPrice) {
             url.searchParams.append('max_price', state.filters.maxPrice);
         }
+        // Allergens the customer must avoid and dietary tags every product must have
+        if (state.filters.freeFrom.length) {
+            url.searchParams.append('free_from', state.filters.freeFrom.join(','));
+        }
+        if (state.filters.diet.length) {
+            url.searchParams.append('diet', state.filters.diet.join(','));
+        }
 
         const response = await fetch(url);
         if (!response.ok) {
@@ -116,10 +132,17 @@ function renderProducts() {
         const isInCart = state.cart.some(item => item.id === product.id);
         const isOutOfStock = product.stock <= 0;
         
+        const thumbnail = product.images && product.images.length
+            ? `<img class="main__product-item-image" src="${product.images[0].thumbnail_url}" alt="${product.name}">`
+            : '';
+
         productElement.innerHTML = `
-            <div class="main__product-item-name">${product.name}</div>
+            ${thumbnail}
+            <div class="main__product-item-name">${product.name}${product.variant_name ? ` <span class="main__product-item-variant">${product.variant_name}</span>` : ''}</div>
+            ${renderBundleComponents(product)}
             <div class="main__product-item-price">${product.price.toFixed(2)} ₸</div>
             <div class="main__product-item-stock">Stock: ${product.stock}</div>
+            ${renderDietInfo(product)}
             <button class="main__product-item-button" 
                     data-id="${product.id}" 
                     data-name="${product.name}" 
@@ -178,30 +201,120 @@ function renderPagination() {
     }
 }
 
-function addToCart(event) {
+// Запросы к корзине идут с токеном: корзина покупателя доступна только ему
+async function cartRequest(path, options = {}) {
+    const response = await apiFetch(`/api/v1/cart${path}`, {
+        headers: {
+            'Content-Type': 'application/json'
+        },
+        ...options
+    });
+    const data = await response.json();
+    if (!response.ok) {
+        const error = new Error(data.error || 'Cart request failed');
+        error.status = response.status;
+        throw error;
+    }
+    return data;
+}
+
+function setCart(cart) {
+    state.cartId = cart.id;
+    localStorage.setItem('cartId', cart.id);
+    state.cart = (cart.items || []).map(item => ({
+        id: item.product_id,
+        name: item.name,
+        price: item.current_price || 0,
+        priceChanged: item.price_changed,
+        quantity: item.quantity,
+        available: item.available
+    }));
+    state.cartSubtotal = cart.subtotal || 0;
+    updateCart();
+}
+
+function forgetCart() {
+    state.cartId = '';
+    state.cart = [];
+    state.cartSubtotal = 0;
+    localStorage.removeItem('cartId');
+    updateCart();
+}
+
+// Load the saved cart, or the signed-in customer's cart from another device
+async function loadCart() {
+    try {
+        if (state.cartId) {
+            setCart(await cartRequest(`/${state.cartId}`));
+        } else if (currentUser().token) {
+            setCart(await cartRequest(''));
+        }
+    } catch (error) {
+        // The cart expired or the customer has none yet
+        if (error.status === 404) {
+            forgetCart();
+        } else {
+            console.error('Error loading cart:', error);
+        }
+    }
+    await migrateLocalCart();
+    // Кнопки товаров, уже лежащих в корзине
+    renderProducts();
+}
+
+// Carts saved in localStorage by older versions of the page are moved to the server once
+async function migrateLocalCart() {
+    const savedCart = JSON.parse(localStorage.getItem('cart') || '[]');
+    localStorage.removeItem('cart');
+    for (const item of savedCart) {
+        try {
+            await addItem(item.id, item.quantity);
+        } catch (error) {
+            console.error('Error moving cart item:', error);
+        }
+    }
+}
+
+async function ensureCart() {
+    if (!state.cartId) {
+        setCart(await cartRequest('', { method: 'POST' }));
+    }
+}
+
+async function addItem(productId, quantity) {
+    await ensureCart();
+    try {
+        setCart(await cartRequest(`/${state.cartId}/items`, {
+            method: 'POST',
+            body: JSON.stringify({ product_id: productId, quantity })
+        }));
+    } catch (error) {
+        // The cart expired: start a new one
+        if (error.status !== 404 || !state.cartId) throw error;
+        forgetCart();
+        await ensureCart();
+        setCart(await cartRequest(`/${state.cartId}/items`, {
+            method: 'POST',
+            body: JSON.stringify({ product_id: productId, quantity })
+        }));
+    }
+}
+
+async function addToCart(event) {
     const button = event.target;
     const id = button.dataset.id;
-    const name = button.dataset.name;
-    const price = parseFloat(button.dataset.price);
-    const stock = parseInt(button.dataset.stock);
-    
-    const existingProduct = state.cart.find(item => item.id === id);
-    if (existingProduct) return;
-    
-    state.cart.push({
-        id,
-        name,
-        price,
-        stock,
-        quantity: 1
-    });
-    
-    localStorage.setItem('cart', JSON.stringify(state.cart));
-    
+
+    if (state.cart.some(item => item.id === id)) return;
+
     button.disabled = true;
-    button.textContent = 'In cart';
-    
-    updateCart();
+    try {
+        await addItem(id, 1);
+        button.textContent = 'In cart';
+    } catch (error) {
+        console.error('Error adding to cart:', error);
+        button.disabled = false;
+        alert(error.message);
+    }
 }
 
 function updateCart() {
@@ -217,23 +330,20 @@ function updateCart() {
     
     DOM.cartItems.innerHTML = '';
     
-    let totalPrice = 0;
-    
     state.cart.forEach(item => {
-        const itemTotal = item.price * item.quantity;
-        totalPrice += itemTotal;
-        
         const cartItem = document.createElement('div');
         cartItem.className = 'main__cart-item';
         cartItem.innerHTML = `
             <div class="main__cart-item-details">
                 <div class="main__cart-item-name">${item.name}</div>
                 <div class="main__cart-item-price">${item.price.toFixed(2)} ₸ x ${item.quantity}</div>
+                ${item.priceChanged ? '<div class="main__cart-item-note">Price changed</div>' : ''}
+                ${!item.available ? '<div class="main__cart-item-note">Not available</div>' : ''}
             </div>
             <div class="main__cart-item-quantity">
                 <button class="decrease" data-id="${item.id}" ${item.quantity <= 1 ? 'disabled' : ''}>-</button>
                 <span>${item.quantity}</span>
-                <button class="increase" data-id="${item.id}" ${item.quantity >= item.stock ? 'disabled' : ''}>+</button>
+                <button class="increase" data-id="${item.id}" ${!item.available ? 'disabled' : ''}>+</button>
             </div>
             <button class="main__cart-item-remove" data-id="${item.id}">×</button>
         `;
@@ -242,58 +352,108 @@ function updateCart() {
         const increaseButton = cartItem.querySelector('.increase');
         const removeButton = cartItem.querySelector('.main__cart-item-remove');
         
-        decreaseButton.addEventListener('click', () => decreaseQuantity(item.id));
-        increaseButton.addEventListener('click', () => increaseQuantity(item.id));
+        decreaseButton.addEventListener('click', () => changeQuantity(item.id, item.quantity - 1));
+        increaseButton.addEventListener('click', () => changeQuantity(item.id, item.quantity + 1));
         removeButton.addEventListener('click', () => removeFromCart(item.id));
         
         DOM.cartItems.appendChild(cartItem);
     });
     
-    DOM.cartTotal.textContent = `${totalPrice.toFixed(2)} ₸`;
+    DOM.cartTotal.textContent = `${state.cartSubtotal.toFixed(2)} ₸`;
     
     updateCheckoutButton();
 }
 
-function decreaseQuantity(id) {
-    const item = state.cart.find(item => item.id === id);
-    if (!item) return;
-    
-    item.quantity -= 1;
-    
-    if (item.quantity <= 0) {
-        removeFromCart(id);
-    } else {
-        localStorage.setItem('cart', JSON.stringify(state.cart));
-        updateCart();
+async function changeQuantity(id, quantity) {
+    if (quantity <= 0) {
+        return removeFromCart(id);
+    }
+    try {
+        setCart(await cartRequest(`/${state.cartId}/items/${id}`, {
+            method: 'PATCH',
+            body: JSON.stringify({ quantity })
+        }));
+    } catch (error) {
+        console.error('Error updating cart:', error);
+        alert(error.message);
     }
 }
 
-function increaseQuantity(id) {
-    const item = state.cart.find(item => item.id === id);
-    if (!item || item.quantity >= item.stock) return;
-    
-    item.quantity += 1;
-    localStorage.setItem('cart', JSON.stringify(state.cart));
-    updateCart();
-}
-
-function removeFromCart(id) {
-    state.cart = state.cart.filter(item => item.id !== id);
-    localStorage.setItem('cart', JSON.stringify(state.cart));
+async function removeFromCart(id) {
+    try {
+        setCart(await cartRequest(`/${state.cartId}/items/${id}`, { method: 'DELETE' }));
+    } catch (error) {
+        console.error('Error removing from cart:', error);
+        alert(error.message);
+        return;
+    }
     
     const productButton = document.querySelector(`button[data-id="${id}"]`);
     if (productButton) {
         productButton.disabled = false;
         productButton.textContent = 'Add to cart';
     }
-    
-    updateCart();
+}
+
+// Bundle contents; stock of a bundle is computed from its components
+function renderBundleComponents(product) {
+    if (product.kind !== 'bundle' || !product.components || !product.components.length) {
+        return '';
+    }
+    const baseUnits = { kg: 'g', litre: 'ml' };
+    const parts = product.components.map(component => baseUnits[component.unit]
+        ? `${component.name} ${component.quantity} ${baseUnits[component.unit]}`
+        : `${component.name} × ${component.quantity}`);
+    return `<div class="main__product-item-bundle">Includes: ${parts.join(', ')}</div>`;
+}
+
+function formatCode(code) {
+    return code.replace(/_/g, '-');
+}
+
+// Dietary tags, allergens, ingredients and nutrition per 100 g of a product card
+function renderDietInfo(product) {
+    const tags = product.dietary_tags || [];
+    const allergens = product.allergens || [];
+    const ingredients = product.ingredients || [];
+    const nutrition = product.nutrition;
+
+    let html = '';
+    if (tags.length) {
+        html += `<div class="main__product-item-tags">${tags.map(tag =>
+            `<span class="main__product-item-tag">${formatCode(tag)}</span>`).join('')}</div>`;
+    }
+    if (allergens.length) {
+        html += `<div class="main__product-item-allergens">Contains: ${allergens.map(formatCode).join(', ')}</div>`;
+    }
+    if (ingredients.length || nutrition) {
+        html += '<details class="main__product-item-details"><summary>Ingredients &amp; nutrition</summary>';
+        if (ingredients.length) {
+            html += `<div>${ingredients.join(', ')}</div>`;
+        }
+        if (nutrition) {
+            const per = product.unit === 'litre' ? '100 ml' : '100 g';
+            html += `<div>Per ${per}: ${nutrition.energy_kcal || 0} kcal,
+                protein ${nutrition.proteins || 0} g, fat ${nutrition.fats || 0} g,
+                carbs ${nutrition.carbohydrates || 0} g, sugars ${nutrition.sugars || 0} g,
+                salt ${nutrition.salt || 0} g</div>`;
+        }
+        html += '</details>';
+    }
+    return html;
+}
+
+function selectedValues(select) {
+    return Array.from(select.selectedOptions).map(option => option.value);
 }
 
 function applyFilters() {
     state.filters.name = DOM.nameFilter.value;
     state.filters.minPrice = DOM.minPriceFilter.value;
     state.filters.maxPrice = DOM.maxPriceFilter.value;
+    state.filters.sort = DOM.sortFilter.value;
+    state.filters.freeFrom = selectedValues(DOM.freeFromFilter);
+    state.filters.diet = selectedValues(DOM.dietFilter);
     state.currentPage = 1;
     fetchProducts();
 }
@@ -302,24 +462,58 @@ function resetFilters() {
     DOM.nameFilter.value = '';
     DOM.minPriceFilter.value = '';
     DOM.maxPriceFilter.value = '';
-    state.filters = { name: '', minPrice: '', maxPrice: '' };
+    DOM.sortFilter.value = '';
+    DOM.freeFromFilter.selectedIndex = -1;
+    DOM.dietFilter.selectedIndex = -1;
+    state.filters = { name: '', minPrice: '', maxPrice: '', sort: '', freeFrom: [], diet: [] };
     state.currentPage = 1;
     fetchProducts();
 }
 
+// Оформить заказ можно с непустой корзиной; без входа placeOrder отправит на страницу входа
 function updateCheckoutButton() {
-    const userId = DOM.userIdInput.value.trim();
-    state.userId = userId;
-    
-    if (userId && state.cart.length > 0) {
-        DOM.checkoutButton.disabled = false;
-    } else {
-        DOM.checkoutButton.disabled = true;
+    DOM.checkoutButton.disabled = state.cart.length === 0;
+}
+
+// Интервалы доставки со свободными местами на ближайшую неделю
+async function fetchDeliverySlots() {
+    try {
+        const response = await fetch('/api/v1/delivery-slots');
+        if (!response.ok) {
+            throw new Error('Failed to load delivery slots');
+        }
+        const data = await response.json();
+        
+        DOM.deliverySlotSelect.innerHTML = '<option value="">Any delivery time</option>';
+        (data.slots || []).forEach(slot => {
+            const option = document.createElement('option');
+            option.value = slot.id;
+            option.textContent = `${formatDeliveryWindow(slot.starts_at, slot.ends_at)} (${slot.zone}, ${slot.available} left)`;
+            DOM.deliverySlotSelect.appendChild(option);
+        });
+    } catch (error) {
+        console.error('Error loading delivery slots:', error);
     }
-    
-    if (userId) {
-        localStorage.setItem('userId', userId);
+}
+
+function formatDeliveryWindow(startsAt, endsAt) {
+    const start = new Date(startsAt);
+    const end = new Date(endsAt);
+    const time = { hour: '2-digit', minute: '2-digit' };
+    return `${start.toLocaleDateString()} ${start.toLocaleTimeString([], time)}-${end.toLocaleTimeString([], time)}`;
+}
+
+// Место в интервале удерживается за покупателем из токена, пока оформляется заказ
+async function reserveDeliverySlot() {
+    const slotId = DOM.deliverySlotSelect.value;
+    if (!slotId) return '';
+    
+    const response = await apiFetch(`/api/v1/delivery-slots/${slotId}/reserve`, { method: 'POST' });
+    const data = await response.json();
+    if (!response.ok) {
+        throw new Error(data.error || 'Delivery slot is not available');
     }
+    return data.id;
 }
 
 async function placeOrder(event) {
@@ -327,43 +521,46 @@ async function placeOrder(event) {
     
     if (state.cart.length === 0) return;
     
-    const userId = DOM.userIdInput.value.trim();
-    if (!userId) {
-        alert('Please enter your ID');
+    // Корзина оформляется на покупателя из токена
+    const { token } = currentUser();
+    if (!token) {
+        window.location.href = `/login?next=${encodeURIComponent(window.location.pathname)}`;
         return;
     }
     
     try {
-        const items = state.cart.map(item => ({
-            product_id: item.id,
-            quantity: item.quantity
-        }));
+        // Купоны вводятся через запятую, регистр не важен
+        const couponCodes = DOM.couponCodesInput.value
+            .split(',')
+            .map(code => code.trim())
+            .filter(code => code !== '');
         
-        const response = await fetch('/api/orders', {
-            method: 'POST',
-            headers: {
-                'Content-Type': 'application/json'
-            },
-            body: JSON.stringify({
-                user_id: userId,
-                items: items
-            })
-        });
-        
-        if (!response.ok) {
-            const errorData = await response.json();
-            throw new Error(errorData.error || 'Error placing order');
+        // Гостевая корзина закрепляется за покупателем, затем оформляется по текущим ценам
+        setCart(await cartRequest(`/${state.cartId}/merge`, { method: 'POST' }));
+        const slotReservationId = await reserveDeliverySlot();
+        try {
+            await cartRequest(`/${state.cartId}/checkout`, {
+                method: 'POST',
+                body: JSON.stringify({
+                    coupon_codes: couponCodes,
+                    slot_reservation_id: slotReservationId
+                })
+            });
+        } catch (error) {
+            // Заказ не создан: место в интервале больше не нужно
+            if (slotReservationId) {
+                apiFetch(`/api/v1/slot-reservations/${slotReservationId}`, { method: 'DELETE' });
+            }
+            throw error;
         }
         
-        const result = await response.json();
+        // После оформления корзина удаляется на сервере
+        DOM.couponCodesInput.value = '';
+        forgetCart();
         
-        // Очищаем корзину
-        state.cart = [];
-        localStorage.setItem('cart', JSON.stringify(state.cart));
-        updateCart();
-        
-        // Обновляем список продуктов и заказов
+        // Обновляем список продуктов, интервалов и заказов
         fetchProducts();
+        fetchDeliverySlots();
         fetchOrders();
         
         alert('Order placed successfully!');
@@ -378,7 +575,7 @@ async function fetchOrders() {
     if (!userId) return;
     
     try {
-        const url = new URL('/api/orders', window.location.origin);
+        const url = new URL('/api/v1/orders', window.location.origin);
         url.searchParams.append('user_id', userId);
         
         const response = await fetch(url);
@@ -390,12 +587,46 @@ async function fetchOrders() {
         state.orders = orders;
         
         renderOrders();
+        watchOrders(userId);
     } catch (error) {
         console.error('Error fetching orders:', error);
         DOM.ordersList.innerHTML = '<div class="main__orders-error">Error fetching orders</div>';
     }
 }
 
+// watchOrders subscribes to the signed-in buyer's order updates, so statuses and new orders appear without reloading.
+// The stream is tied to the token, so it only runs while the buyer's own orders are shown.
+function watchOrders(userId) {
+    const user = currentUser();
+    if (state.orderEvents) {
+        if (state.orderEvents.userId === userId) return;
+        state.orderEvents.close();
+        state.orderEvents = null;
+    }
+    if (!user.token || user.id !== userId) return;
+
+    const source = apiEventStream('/api/v1/orders/events', (type, event) => {
+        if (['created', 'status_changed', 'courier_assigned'].includes(type)) {
+            applyOrderEvent(event);
+        }
+    });
+    source.userId = userId;
+    state.orderEvents = source;
+}
+
+function applyOrderEvent(event) {
+    const order = event.order;
+    if (!order) return;
+
+    const index = state.orders.findIndex(o => o.id === order.id);
+    if (index === -1) {
+        state.orders.unshift(order);
+    } else {
+        state.orders[index] = order;
+    }
+    renderOrders();
+}
+
 function renderOrders() {
     if (state.orders.length === 0) {
         DOM.ordersList.innerHTML = '<div class="main__orders-empty">No orders found</div>';
@@ -416,14 +647,46 @@ function renderOrders() {
             <div class="main__order-item-products">
                 ${renderOrderItems(order.items)}
             </div>
+            ${order.discount_total ? `
+                <div class="main__order-item-discount">
+                    <span>Discount:</span>
+                    <span>-${order.discount_total.toFixed(2)} ₸</span>
+                </div>
+            ` : ''}
+            ${order.delivery_zone || order.delivery_fee ? `
+                <div class="main__order-item-delivery">
+                    <span>Delivery fee${order.delivery_zone ? ` (${order.delivery_zone})` : ''}:</span>
+                    <span>${order.delivery_fee ? `${order.delivery_fee.toFixed(2)} ₸` : 'Free'}</span>
+                </div>
+            ` : ''}
             <div class="main__order-item-total">
                 <span>Total:</span>
                 <span>${order.total_amount.toFixed(2)} ₸</span>
             </div>
+            ${order.delivery_starts_at ? `
+                <div class="main__order-item-delivery">
+                    <span>Delivery:</span>
+                    <span>${formatDeliveryWindow(order.delivery_starts_at, order.delivery_ends_at)}</span>
+                </div>
+            ` : ''}
+            ${order.tax_total ? `
+                <div class="main__order-item-tax">
+                    <span>${order.tax_mode === 'exclusive' ? 'VAT added:' : 'Incl. VAT:'}</span>
+                    <span>${order.tax_total.toFixed(2)} ₸</span>
+                </div>
+            ` : ''}
+            ${order.status === 'out_for_delivery' || order.status === 'delivered' ? `
+                <div class="main__order-item-tracking"></div>
+            ` : ''}
             ${renderOrderActions(order)}
         `;
         
         DOM.ordersList.appendChild(orderItem);
+
+        const trackingElement = orderItem.querySelector('.main__order-item-tracking');
+        if (trackingElement) {
+            loadTracking(order, trackingElement);
+        }
         
         // Добавляем обработчики событий для кнопок
         const completeButton = orderItem.querySelector('.main__order-item-button.complete');
@@ -454,14 +717,51 @@ function renderOrderItems(items) {
                 <span>${item.quantity} x ${item.price.toFixed(2)} ₸</span>
             </div>
         `;
+        // Скидки позиции с названием акции, чтобы было видно, откуда взялась сумма
+        (item.discounts || []).forEach(discount => {
+            html += `
+                <div class="main__order-item-discount">
+                    <span>${discount.name}${discount.coupon_code ? ` (${discount.coupon_code})` : ''}</span>
+                    <span>-${discount.amount.toFixed(2)} ₸</span>
+                </div>
+            `;
+        });
     });
     
     html += '</div>';
     return html;
 }
 
+// loadTracking показывает курьера и ожидаемое время прибытия заказа в пути или подтверждение вручения.
+// Отслеживание доступно только покупателю заказа по его токену.
+async function loadTracking(order, element) {
+    if (currentUser().id !== order.user_id) return;
+    try {
+        const response = await apiFetch(`/api/v1/orders/${order.id}/tracking`);
+        if (!response.ok) {
+            throw new Error(`Error fetching tracking: ${response.statusText}`);
+        }
+        const tracking = await response.json();
+        const delivery = tracking.delivery || {};
+
+        if (delivery.status === 'delivered') {
+            element.innerHTML = `
+                <span>Delivered${delivery.delivered_at ? ` at ${new Date(delivery.delivered_at).toLocaleTimeString([], { hour: '2-digit', minute: '2-digit' })}` : ''}${delivery.proof_note ? `: ${delivery.proof_note}` : ''}</span>
+                ${delivery.proof_photo_url ? `<a href="${delivery.proof_photo_url}" target="_blank" rel="noopener">Photo</a>` : ''}
+            `;
+        } else if (tracking.courier_name) {
+            element.innerHTML = `
+                <span>${tracking.courier_name} is on the way</span>
+                <span>${tracking.eta_minutes ? `~${tracking.eta_minutes} min` : ''}</span>
+            `;
+        }
+    } catch (error) {
+        console.error('Error loading tracking:', error);
+    }
+}
+
 function renderOrderActions(order) {
-    if (order.status === 'completed' || order.status === 'cancelled') {
+    if (order.status !== 'pending') {
         return '';
     }
     
@@ -477,7 +777,7 @@ function renderOrderActions(order) {
 
 async function updateOrderStatus(orderId, status) {
     try {
-        const response = await fetch(`/api/orders/${orderId}`, {
+        const response = await fetch(`/api/v1/orders/${orderId}`, {
             method: 'PATCH',
             headers: {
                 'Content-Type': 'application/json'
@@ -503,6 +803,8 @@ async function updateOrderStatus(orderId, status) {
 function getStatusText(status) {
     switch (status) {
         case 'pending': return 'Pending';
+        case 'out_for_delivery': return 'Out for delivery';
+        case 'delivered': return 'Delivered';
         case 'completed': return 'Completed';
         case 'cancelled': return 'Cancelled';
         default: return status;
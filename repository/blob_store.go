@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"io"
+)
+
+// BlobStore хранит бинарные файлы (изображения товаров и т.п.).
+// Сейчас есть реализация на локальной файловой системе,
+// интерфейс рассчитан и на S3-совместимые хранилища.
+type BlobStore interface {
+	// Put сохраняет файл под ключом key и возвращает его публичный URL
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (string, error)
+	// Delete удаляет файл; отсутствие файла ошибкой не считается
+	Delete(ctx context.Context, key string) error
+}
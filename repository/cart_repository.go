@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+)
+
+// CartRepository хранит корзины покупателей. Методы, меняющие корзину, продлевают ее срок до expiresAt.
+type CartRepository interface {
+	// Create сохраняет пустую корзину; для покупателя, у которого корзина уже есть, возвращает ее ID
+	Create(cart domain.Cart) (string, error)
+	// FindByID возвращает корзину с позициями (Name, Unit, CurrentPrice и Available не заполнены)
+	// или domain.ErrCartNotFound, в том числе если срок корзины истек
+	FindByID(id string) (domain.Cart, error)
+	// FindByUserID возвращает корзину покупателя или domain.ErrCartNotFound
+	FindByUserID(userID string) (domain.Cart, error)
+	// SetItem кладет товар в корзину или меняет его количество и цену
+	SetItem(cartID string, item domain.CartItem, expiresAt time.Time) error
+	// RemoveItem убирает товар из корзины
+	RemoveItem(cartID, productID string, expiresAt time.Time) error
+	// Merge переносит позиции гостевой корзины в корзину покупателя, складывая количества
+	// одинаковых товаров, и удаляет гостевую корзину
+	Merge(guestCartID, userCartID string, expiresAt time.Time) error
+	// BeginCheckout помечает корзину как оформляемую; false, если ее уже оформляют.
+	// Пометка старше staleAfter считается брошенной.
+	BeginCheckout(cartID string, staleAfter time.Duration) (bool, error)
+	// EndCheckout снимает пометку оформления, если заказ создать не удалось
+	EndCheckout(cartID string) error
+	// Delete удаляет корзину
+	Delete(cartID string) error
+	// DeleteExpired удаляет корзины с истекшим сроком и возвращает их число
+	DeleteExpired(now time.Time) (int, error)
+}
@@ -0,0 +1,28 @@
+package repository
+
+import "FoodStore-AdvProg2/domain"
+
+// DeliveryRepository хранит курьеров, их маршруты и доставки заказов
+type DeliveryRepository interface {
+	SaveCourier(courier domain.Courier) error
+	FindCourierByID(id string) (domain.Courier, error)
+	// FindCourierByUserID возвращает курьера, привязанного к учетной записи, или domain.ErrCourierNotFound
+	FindCourierByUserID(userID string) (domain.Courier, error)
+	// FindCouriers возвращает курьеров по имени; пустые status и zone не фильтруют
+	FindCouriers(status, zone string) ([]domain.Courier, error)
+	UpdateCourierStatus(id, status string) error
+	// RecordLocation добавляет точку в маршрут и делает ее текущим положением курьера
+	RecordLocation(location domain.CourierLocation) error
+
+	// Assign передает заказ курьеру. domain.ErrCourierUnavailable, если курьер не на смене
+	// или уже везет заказ; domain.ErrDeliveryAlreadyAssigned, если заказ уже у курьера.
+	Assign(delivery domain.Delivery) error
+	FindDeliveryByID(id string) (domain.Delivery, error)
+	// FindLatestByOrder возвращает последнюю доставку заказа, в том числе завершенную
+	FindLatestByOrder(orderID string) (domain.Delivery, error)
+	// FindDeliveries возвращает доставки по убыванию времени назначения; пустые параметры не фильтруют
+	FindDeliveries(courierID, status string) ([]domain.Delivery, error)
+	// UpdateDelivery сохраняет статус и подтверждение доставки и в той же транзакции
+	// переводит заказ в orderStatus
+	UpdateDelivery(delivery domain.Delivery, orderStatus string) error
+}
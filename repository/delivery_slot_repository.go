@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"FoodStore-AdvProg2/domain"
+	"time"
+)
+
+// DeliverySlotRepository хранит интервалы доставки и брони мест в них
+type DeliverySlotRepository interface {
+	SaveSlot(slot domain.DeliverySlot) error
+	FindSlotByID(id string) (domain.DeliverySlot, error)
+	// FindSlots возвращает интервалы зоны (пустая зона - всех зон), начинающиеся в [from, to), с занятыми местами
+	FindSlots(zone string, from, to time.Time) ([]domain.DeliverySlot, error)
+	// Reserve занимает место в интервале, снимая другие неподтвержденные брони покупателя;
+	// domain.ErrDeliverySlotFull, если свободных мест нет
+	Reserve(reservation domain.SlotReservation) error
+	FindReservation(id string) (domain.SlotReservation, error)
+	// Release снимает неподтвержденную бронь; false, если она уже подтверждена, истекла или снята
+	Release(id string) (bool, error)
+	// ReleaseByOrder освобождает место отмененного заказа
+	ReleaseByOrder(orderID string) error
+	// ExpireReservations помечает истекшими брони, не подтвержденные к моменту now
+	ExpireReservations(now time.Time) (int, error)
+}
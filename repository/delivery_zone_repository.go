@@ -0,0 +1,12 @@
+package repository
+
+import "FoodStore-AdvProg2/domain"
+
+// DeliveryZoneRepository хранит зоны доставки
+type DeliveryZoneRepository interface {
+	Save(zone domain.DeliveryZone) error
+	Update(zone domain.DeliveryZone) error
+	FindByID(id string) (domain.DeliveryZone, error)
+	// FindAll возвращает зоны в порядке проверки: по убыванию приоритета, затем по коду
+	FindAll(activeOnly bool) ([]domain.DeliveryZone, error)
+}
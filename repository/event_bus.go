@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"FoodStore-AdvProg2/domain"
+)
+
+// EventPublisher отправляет доменные события брокеру. Publish возвращает nil, только когда
+// брокер принял событие: до этого relay не отмечает событие в outbox отправленным.
+type EventPublisher interface {
+	Publish(ctx context.Context, event domain.Event) error
+}
+
+// EventSubscriber передает handle события для потребителя consumer, пока не отменен ctx.
+// Событие может прийти повторно - дубликаты отбрасывает usecase.EventConsumer.
+type EventSubscriber interface {
+	Subscribe(ctx context.Context, consumer string, handle func(domain.Event)) error
+}
+
+// EventBus - брокер доменных событий.
+// Реализации: Postgres LISTEN/NOTIFY (infrastructure/postgres) и NATS (infrastructure/events).
+type EventBus interface {
+	EventPublisher
+	EventSubscriber
+	Close() error
+}
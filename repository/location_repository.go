@@ -0,0 +1,12 @@
+package repository
+
+import "FoodStore-AdvProg2/domain"
+
+type LocationRepository interface {
+	Save(location domain.Location) (domain.Location, error)
+	Update(location domain.Location) error
+	FindByID(id string) (domain.Location, error)
+	// FindAll возвращает точки по коду; includeInactive добавляет выключенные точки
+	FindAll(includeInactive bool) ([]domain.Location, error)
+	FindDefault() (domain.Location, error)
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"FoodStore-AdvProg2/domain"
+)
+
+// Notifier доставляет уведомления администраторам.
+// Реализации: лог, вебхук и email (infrastructure/notify).
+type Notifier interface {
+	Notify(ctx context.Context, notification domain.Notification) error
+}
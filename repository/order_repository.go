@@ -3,6 +3,7 @@ package repository
 import "FoodStore-AdvProg2/domain"
 
 type OrderRepository interface {
+	// Save сохраняет заказ с позициями и возвращает его ID; пустой order.ID генерируется
 	Save(order domain.Order, items []domain.OrderItem) (string, error)
 	FindByID(id string) (domain.Order, []domain.OrderItem, error)
 	UpdateStatus(id string, status string) error
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+)
+
+// OutboxRepository читает outbox доменных событий и отметки об их обработке.
+// События записываются в outbox репозиториями заказов, остатков и пользователей
+// в той же транзакции, что и изменение.
+type OutboxRepository interface {
+	// PublishPending передает publish до limit неотправленных событий в порядке появления
+	// и отмечает отправленные. На первой ошибке останавливается, чтобы не нарушить порядок.
+	// Пока идет отправка, транзакция не держится: события закрепляются за relay заранее.
+	PublishPending(limit int, publish func(domain.Event) error) (int, error)
+	// FindUnprocessed возвращает отправленные с момента since события, которые consumer еще не обработал
+	FindUnprocessed(consumer string, since time.Time, limit int) ([]domain.Event, error)
+	IsProcessed(consumer, eventID string) (bool, error)
+	MarkProcessed(consumer, eventID string) error
+	// DeletePublished удаляет отправленные до before события вместе с отметками об обработке
+	DeletePublished(before time.Time) (int64, error)
+}
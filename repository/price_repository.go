@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+)
+
+// PriceRepository хранит историю цен товаров и запланированные цены.
+// Записи истории добавляются в той же транзакции, что и изменение цены товара.
+type PriceRepository interface {
+	// PriceAt возвращает запись истории, действовавшую в момент at,
+	// или domain.ErrPriceNotFound, если товара тогда еще не было
+	PriceAt(productID string, at time.Time) (domain.PriceChange, error)
+	// History возвращает не больше limit последних изменений цены товара, новые первыми
+	History(productID string, limit int) ([]domain.PriceChange, error)
+	// SaveSchedule сохраняет запланированную цену или возвращает domain.ErrPriceScheduleOverlap
+	SaveSchedule(schedule domain.PriceSchedule) error
+	FindScheduleByID(id string) (domain.PriceSchedule, error)
+	// FindSchedules возвращает запланированные цены товара (всех товаров при пустом productID)
+	// с указанным статусом (любым при пустом status)
+	FindSchedules(productID, status string) ([]domain.PriceSchedule, error)
+	// DueSchedules возвращает ожидающие цены, которые пора применить, и действующие, которые пора вернуть
+	DueSchedules(now time.Time) ([]domain.PriceSchedule, error)
+	// StartSchedule применяет ожидающую цену. Если ее период уже закончился, цена не меняется,
+	// а запланированная цена закрывается; тогда возвращается false.
+	StartSchedule(id string) (bool, error)
+	// EndSchedule закрывает действующую цену и возвращает прежнюю, если цену товара
+	// за это время не меняли вручную; status - итоговый статус (completed или cancelled)
+	EndSchedule(id, status, actor string) error
+	// CancelSchedule отменяет ожидающую цену; false, если она уже не ожидает применения
+	CancelSchedule(id string) (bool, error)
+}
@@ -0,0 +1,11 @@
+package repository
+
+import "FoodStore-AdvProg2/domain"
+
+type ProductImageRepository interface {
+	Save(image domain.ProductImage) error
+	FindByID(id string) (domain.ProductImage, error)
+	FindByProductIDs(productIDs []string) (map[string][]domain.ProductImage, error)
+	Delete(id string) error
+	NextPosition(productID string) (int, error)
+}
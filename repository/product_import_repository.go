@@ -0,0 +1,13 @@
+package repository
+
+import "FoodStore-AdvProg2/domain"
+
+// ProductImportRepository применяет строки импорта каталога
+type ProductImportRepository interface {
+	// FindIDsBySKU возвращает ID товаров, включая архивные, по их артикулам
+	FindIDsBySKU(skus []string) (map[string]string, error)
+	// Apply в одной транзакции создает или обновляет товары по SKU и приводит их остаток
+	// на складе по умолчанию к Stock. Если строка не применилась, транзакция откатывается
+	// и возвращается domain.ProductImportRowError этой строки.
+	Apply(rows []domain.ProductImportRow, change domain.StockChange) (created, updated int, err error)
+}
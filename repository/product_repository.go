@@ -5,8 +5,19 @@ import "FoodStore-AdvProg2/domain"
 type ProductRepository interface {
     Save(product domain.Product) error
     FindByID(id string) (domain.Product, error)
-    Update(id string, product domain.Product) error
-    Delete(id string) error
+    // FindByBarcode ищет товар по штрихкоду в канонической записи (domain.NormalizeBarcode)
+    FindByBarcode(barcode string) (domain.Product, error)
+    // Update меняет поля карточки из update.Fields, если версия товара совпадает с update.Version,
+    // и возвращает новую версию. При несовпадении возвращает domain.ErrProductVersionConflict.
+    // Новый остаток оформляется корректировкой партий в той же транзакции.
+    Update(update domain.ProductUpdate) (int64, error)
+    // Archive и Restore переносят товар в архив и обратно, увеличивая версию
+    Archive(id string) error
+    Restore(id string) error
+    // References считает записи, которые ссылаются на товар
+    References(id string) (domain.ProductReferences, error)
+    // Purge окончательно удаляет архивный товар без ссылок; false, если товар не удален
+    Purge(id string) (bool, error)
     FindAll() ([]domain.Product, error)
-    FindAllWithFilter(filter domain.FilterParams, pagination domain.PaginationParams, offset int) ([]domain.Product, int, error)
+    FindAllWithFilter(filter domain.FilterParams, sort string, pagination domain.PaginationParams, offset int) (domain.ProductPage, error)
 }
\ No newline at end of file
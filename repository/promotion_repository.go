@@ -0,0 +1,20 @@
+package repository
+
+import "FoodStore-AdvProg2/domain"
+
+// PromotionRepository хранит акции и купоны. Использования акций записываются
+// вместе с заказом в OrderRepository.Save.
+type PromotionRepository interface {
+	// Save сохраняет акцию или возвращает domain.ErrDuplicateCoupon, если код купона занят
+	Save(promotion domain.Promotion) error
+	FindByID(id string) (domain.Promotion, error)
+	// FindAll возвращает акции, новые первыми; activeOnly - только включенные
+	FindAll(activeOnly bool) ([]domain.Promotion, error)
+	// SetActive включает или выключает акцию
+	SetActive(id string, active bool) error
+	// FindApplicable возвращает включенные автоматические акции и включенные акции
+	// с указанными кодами купонов; период действия проверяет вызывающий
+	FindApplicable(couponCodes []string) ([]domain.Promotion, error)
+	// Usage возвращает число использований акций всего и покупателем userID
+	Usage(promotionIDs []string, userID string) (map[string]domain.PromotionUsage, error)
+}
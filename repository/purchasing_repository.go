@@ -0,0 +1,22 @@
+package repository
+
+import "FoodStore-AdvProg2/domain"
+
+type SupplierRepository interface {
+	Save(supplier domain.Supplier) (domain.Supplier, error)
+	FindByID(id string) (domain.Supplier, error)
+	FindAll() ([]domain.Supplier, error)
+}
+
+// PurchaseOrderRepository хранит заказы поставщикам вместе со строками
+type PurchaseOrderRepository interface {
+	Save(order domain.PurchaseOrder) (domain.PurchaseOrder, error)
+	FindByID(id string) (domain.PurchaseOrder, error)
+	FindAll(filter domain.PurchaseOrderFilter) ([]domain.PurchaseOrder, error)
+	UpdateStatus(id, status string) error
+	// Receive в одной транзакции заводит партию на точке заказа по каждой приемке, увеличивает
+	// принятое количество строк и пересчитывает статус заказа. Приемка сверх заказанного
+	// отклоняется с domain.ErrOverReceipt, приемка неотправленного или закрытого заказа -
+	// с domain.ErrPurchaseOrderNotReceivable.
+	Receive(orderID string, receipts []domain.PurchaseReceipt, change domain.StockChange) (domain.PurchaseOrder, []domain.StockBatch, error)
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+)
+
+// ReplenishmentRepository хранит точки заказа товаров, алерты о низком остатке
+// и предложения на пополнение
+type ReplenishmentRepository interface {
+	SetPolicy(policy domain.ReorderPolicy) error
+	// ReorderStatuses возвращает доступный остаток всех товаров с заданной точкой заказа
+	ReorderStatuses() ([]domain.ReorderStatus, error)
+	// UnitsSold возвращает количество проданных с момента since штук по неотмененным заказам
+	UnitsSold(productIDs []string, since time.Time) (map[string]int, error)
+	// OpenAlert в одной транзакции создает алерт и черновик предложения. Если по товару
+	// уже есть открытый алерт, ничего не создается и возвращается false.
+	OpenAlert(alert domain.LowStockAlert, suggestion domain.ReorderSuggestion) (domain.LowStockAlert, domain.ReorderSuggestion, bool, error)
+	// ResolveAlert закрывает открытый алерт товара
+	ResolveAlert(productID string) error
+	FindAlerts(openOnly bool) ([]domain.LowStockAlert, error)
+	FindSuggestions(status string) ([]domain.ReorderSuggestion, error)
+	FindSuggestionByID(id string) (domain.ReorderSuggestion, error)
+	UpdateSuggestionStatus(id, status string) error
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+)
+
+// StockBatchRepository хранит партии товаров. Все методы, меняющие остатки партий,
+// в той же транзакции поддерживают products.stock равным сумме остатков партий
+// и записывают каждое изменение в журнал stock_movements.
+// Пустой ID точки в Receive и Adjust означает склад по умолчанию.
+type StockBatchRepository interface {
+	Receive(batch domain.StockBatch, change domain.StockChange) (domain.StockBatch, error)
+	FindByID(id string) (domain.StockBatch, error)
+	// FindByProductID возвращает партии товара; пустой locationID - на всех точках
+	FindByProductID(productID, locationID string, includeEmpty bool) ([]domain.StockBatch, error)
+	// FindExpiring возвращает непустые партии со сроком годности до даты before включительно
+	FindExpiring(before time.Time) ([]domain.StockBatch, error)
+	// Allocate списывает товары под заказ с партий точки locationID по FEFO: сначала партии с ближайшим сроком годности.
+	// Если хотя бы одной позиции не хватает, ничего не списывается и возвращается domain.ErrInsufficientStock.
+	Allocate(orderID, locationID string, items []domain.OrderItemRequest, change domain.StockChange) ([]domain.BatchAllocation, error)
+	// Release возвращает на партии все списания заказа, повторный вызов ничего не меняет
+	Release(orderID string, change domain.StockChange) ([]domain.BatchAllocation, error)
+	// Adjust меняет доступный остаток товара на delta: увеличение оформляется отдельной партией,
+	// уменьшение списывается по FEFO
+	Adjust(productID, locationID string, delta int, change domain.StockChange) error
+	// WriteOff списывает quantity единиц с конкретной партии, в том числе просроченной
+	WriteOff(batchID string, quantity int, change domain.StockChange) (domain.StockBatch, error)
+	// Transfer перемещает товар между точками по FEFO в одной транзакции
+	Transfer(transfer domain.StockTransfer, change domain.StockChange) (domain.StockTransfer, error)
+	// StockLevels возвращает доступные остатки товаров по активным точкам; точки без остатка не попадают в результат
+	StockLevels(productIDs []string) ([]domain.LocationStock, error)
+}
+
+// StockMovementRepository читает журнал движения товаров
+type StockMovementRepository interface {
+	FindAll(filter domain.StockMovementFilter, limit, offset int) ([]domain.StockMovement, int, error)
+	// Reconcile сверяет products.stock с журналом и партиями; пустой productID - все товары
+	Reconcile(productID string) ([]domain.StockReconciliation, error)
+}
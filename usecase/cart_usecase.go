@@ -0,0 +1,292 @@
+package usecase
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/repository"
+
+	"github.com/google/uuid"
+)
+
+// DefaultCartTTL - сколько хранится корзина без изменений
+const DefaultCartTTL = 30 * 24 * time.Hour
+
+// cartCheckoutTimeout - через сколько незавершенное оформление корзины считается брошенным
+const cartCheckoutTimeout = time.Minute
+
+// CartCheckout - параметры оформления корзины в заказ
+type CartCheckout struct {
+	StoreID         string
+	DeliveryAddress domain.Address
+	CouponCodes     []string
+	// SlotReservationID - бронь интервала доставки
+	SlotReservationID string
+}
+
+// CartUseCase ведет корзины покупателей на сервере. Цены и наличие в ответах всегда текущие,
+// а заказ из корзины оформляется через OrderUseCase.CreateOrder, как и любой другой.
+type CartUseCase struct {
+	Repo     repository.CartRepository
+	Products repository.ProductRepository
+	Orders   *OrderUseCase
+	TTL      time.Duration
+}
+
+func NewCartUseCase(repo repository.CartRepository, products repository.ProductRepository, orders *OrderUseCase, ttl time.Duration) *CartUseCase {
+	if ttl <= 0 {
+		ttl = DefaultCartTTL
+	}
+	return &CartUseCase{Repo: repo, Products: products, Orders: orders, TTL: ttl}
+}
+
+// Create заводит корзину; для покупателя с корзиной возвращает ее
+func (uc *CartUseCase) Create(userID string) (domain.Cart, error) {
+	now := time.Now()
+	id, err := uc.Repo.Create(domain.Cart{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(uc.TTL),
+	})
+	if err != nil {
+		return domain.Cart{}, err
+	}
+	return uc.Get(id, userID)
+}
+
+// Get возвращает корзину с текущими ценами и наличием товаров
+func (uc *CartUseCase) Get(id, userID string) (domain.Cart, error) {
+	cart, err := uc.find(id, userID)
+	if err != nil {
+		return domain.Cart{}, err
+	}
+	return uc.price(cart), nil
+}
+
+// find загружает корзину, доступную покупателю userID: гостевую - любому, знающему ее ID,
+// закрепленную за покупателем - только ему. Для остальных чужой корзины нет.
+func (uc *CartUseCase) find(id, userID string) (domain.Cart, error) {
+	cart, err := uc.Repo.FindByID(id)
+	if err != nil {
+		return domain.Cart{}, err
+	}
+	if cart.UserID != "" && cart.UserID != userID {
+		return domain.Cart{}, domain.ErrCartNotFound
+	}
+	return cart, nil
+}
+
+// GetByUser возвращает корзину покупателя
+func (uc *CartUseCase) GetByUser(userID string) (domain.Cart, error) {
+	cart, err := uc.Repo.FindByUserID(userID)
+	if err != nil {
+		return domain.Cart{}, err
+	}
+	return uc.price(cart), nil
+}
+
+// AddItem добавляет товар в корзину; если он уже есть, количество складывается
+func (uc *CartUseCase) AddItem(cartID, userID, productID string, quantity float64) (domain.Cart, error) {
+	if quantity <= 0 {
+		return domain.Cart{}, fmt.Errorf("%w: quantity must be greater than zero", domain.ErrInvalidCartItem)
+	}
+	cart, err := uc.find(cartID, userID)
+	if err != nil {
+		return domain.Cart{}, err
+	}
+	item := domain.CartItem{ProductID: productID, AddedAt: time.Now()}
+	for _, existing := range cart.Items {
+		if existing.ProductID == productID {
+			item = existing
+			break
+		}
+	}
+	return uc.setItem(cart, item, item.Quantity+quantity)
+}
+
+// UpdateItem задает количество товара в корзине; 0 убирает товар
+func (uc *CartUseCase) UpdateItem(cartID, userID, productID string, quantity float64) (domain.Cart, error) {
+	if quantity == 0 {
+		return uc.RemoveItem(cartID, userID, productID)
+	}
+	if quantity < 0 {
+		return domain.Cart{}, fmt.Errorf("%w: quantity cannot be negative", domain.ErrInvalidCartItem)
+	}
+	cart, err := uc.find(cartID, userID)
+	if err != nil {
+		return domain.Cart{}, err
+	}
+	for _, existing := range cart.Items {
+		if existing.ProductID == productID {
+			return uc.setItem(cart, existing, quantity)
+		}
+	}
+	return domain.Cart{}, fmt.Errorf("%w: product %s is not in the cart", domain.ErrInvalidCartItem, productID)
+}
+
+// setItem проверяет товар и количество и запоминает текущую цену товара
+func (uc *CartUseCase) setItem(cart domain.Cart, item domain.CartItem, quantity float64) (domain.Cart, error) {
+	product, err := uc.Products.FindByID(item.ProductID)
+	if err != nil {
+		return domain.Cart{}, ErrProductNotFound
+	}
+	if product.IsArchived() {
+		return domain.Cart{}, ErrProductArchived
+	}
+	if _, err := domain.ToBaseUnits(quantity, productUnit(product)); err != nil {
+		return domain.Cart{}, fmt.Errorf("%w: %v", domain.ErrInvalidCartItem, err)
+	}
+
+	item.Quantity = quantity
+	item.Price = product.Price
+	if err := uc.Repo.SetItem(cart.ID, item, time.Now().Add(uc.TTL)); err != nil {
+		return domain.Cart{}, err
+	}
+	return uc.Get(cart.ID, cart.UserID)
+}
+
+func (uc *CartUseCase) RemoveItem(cartID, userID, productID string) (domain.Cart, error) {
+	if _, err := uc.find(cartID, userID); err != nil {
+		return domain.Cart{}, err
+	}
+	if err := uc.Repo.RemoveItem(cartID, productID, time.Now().Add(uc.TTL)); err != nil {
+		return domain.Cart{}, err
+	}
+	return uc.Get(cartID, userID)
+}
+
+// Refresh запоминает текущие цены товаров как увиденные покупателем. Возвращает корзину
+// до обновления, чтобы клиент показал, какие цены изменились, и корзину после.
+func (uc *CartUseCase) Refresh(cartID, userID string) (before, after domain.Cart, err error) {
+	before, err = uc.Get(cartID, userID)
+	if err != nil {
+		return domain.Cart{}, domain.Cart{}, err
+	}
+	expiresAt := time.Now().Add(uc.TTL)
+	for _, item := range before.Items {
+		// Товар без названия удален из каталога, его цену обновлять не с чего
+		if item.Name == "" || !item.PriceChanged() {
+			continue
+		}
+		item.Price = item.CurrentPrice
+		if err := uc.Repo.SetItem(cartID, item, expiresAt); err != nil {
+			return domain.Cart{}, domain.Cart{}, err
+		}
+	}
+	after, err = uc.Get(cartID, userID)
+	return before, after, err
+}
+
+// Merge переносит гостевую корзину в корзину покупателя при входе. Если у покупателя
+// корзины нет, гостевая просто закрепляется за ним.
+func (uc *CartUseCase) Merge(guestCartID, userID string) (domain.Cart, error) {
+	if userID == "" {
+		return domain.Cart{}, fmt.Errorf("%w: user_id is required", domain.ErrCartOwner)
+	}
+	guest, err := uc.find(guestCartID, userID)
+	if err != nil {
+		return domain.Cart{}, err
+	}
+	if guest.UserID == userID {
+		return uc.price(guest), nil
+	}
+
+	userCart, err := uc.Create(userID)
+	if err != nil {
+		return domain.Cart{}, err
+	}
+	if err := uc.Repo.Merge(guestCartID, userCart.ID, time.Now().Add(uc.TTL)); err != nil {
+		return domain.Cart{}, err
+	}
+	return uc.Get(userCart.ID, userID)
+}
+
+// Checkout оформляет корзину покупателя userID в заказ по текущим ценам и удаляет ее.
+// Если заказ создать не удалось, корзина остается как была.
+func (uc *CartUseCase) Checkout(cartID, userID string, checkout CartCheckout) (string, error) {
+	if userID == "" {
+		return "", fmt.Errorf("%w: user_id is required", domain.ErrCartOwner)
+	}
+	cart, err := uc.find(cartID, userID)
+	if err != nil {
+		return "", err
+	}
+	if cart.UserID == "" {
+		return "", fmt.Errorf("%w: sign in and merge the guest cart before checkout", domain.ErrCartOwner)
+	}
+	if len(cart.Items) == 0 {
+		return "", domain.ErrCartEmpty
+	}
+
+	started, err := uc.Repo.BeginCheckout(cartID, cartCheckoutTimeout)
+	if err != nil {
+		return "", err
+	}
+	if !started {
+		return "", domain.ErrCartCheckoutInProgress
+	}
+
+	items := make([]domain.OrderLineRequest, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		items = append(items, domain.OrderLineRequest{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+	orderID, err := uc.Orders.CreateOrder(domain.OrderRequest{
+		UserID:            cart.UserID,
+		Items:             items,
+		StoreID:           checkout.StoreID,
+		DeliveryAddress:   checkout.DeliveryAddress,
+		CouponCodes:       checkout.CouponCodes,
+		SlotReservationID: checkout.SlotReservationID,
+	})
+	if err != nil {
+		if endErr := uc.Repo.EndCheckout(cartID); endErr != nil {
+			log.Printf("Failed to release cart %s after failed checkout: %v", cartID, endErr)
+		}
+		return "", err
+	}
+
+	// Заказ уже создан: ошибка удаления корзины не должна превращаться в ошибку оформления
+	if err := uc.Repo.Delete(cartID); err != nil {
+		log.Printf("Failed to delete cart %s after checkout: %v", cartID, err)
+	}
+	return orderID, nil
+}
+
+// DeleteExpired удаляет корзины с истекшим сроком
+func (uc *CartUseCase) DeleteExpired() (int, error) {
+	return uc.Repo.DeleteExpired(time.Now())
+}
+
+// price заполняет позиции текущими ценами, наличием и стоимостью
+func (uc *CartUseCase) price(cart domain.Cart) domain.Cart {
+	subtotal := 0.0
+	for i := range cart.Items {
+		item := &cart.Items[i]
+		product, err := uc.Products.FindByID(item.ProductID)
+		if err != nil {
+			continue
+		}
+		item.Name = product.Name
+		item.Unit = productUnit(product)
+		item.CurrentPrice = product.Price
+		item.LineTotal = roundMoney(product.Price * item.Quantity)
+
+		base, err := domain.ToBaseUnits(item.Quantity, item.Unit)
+		item.Available = err == nil && !product.IsArchived() && product.Stock >= base
+		if item.Available {
+			subtotal += item.LineTotal
+		}
+	}
+	cart.Subtotal = roundMoney(subtotal)
+	return cart
+}
+
+func productUnit(p domain.Product) string {
+	if p.Unit == "" {
+		return domain.UnitPiece
+	}
+	return p.Unit
+}
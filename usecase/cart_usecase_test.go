@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/repository"
+)
+
+// fakeCartRepo хранит корзины в памяти и помнит, менялись ли они
+type fakeCartRepo struct {
+	repository.CartRepository
+	carts   map[string]domain.Cart
+	changed []string
+}
+
+func (r *fakeCartRepo) FindByID(id string) (domain.Cart, error) {
+	cart, ok := r.carts[id]
+	if !ok {
+		return domain.Cart{}, domain.ErrCartNotFound
+	}
+	return cart, nil
+}
+
+func (r *fakeCartRepo) RemoveItem(cartID, productID string, expiresAt time.Time) error {
+	r.changed = append(r.changed, cartID)
+	return nil
+}
+
+func (r *fakeCartRepo) Merge(guestCartID, userCartID string, expiresAt time.Time) error {
+	r.changed = append(r.changed, guestCartID)
+	return nil
+}
+
+func TestCartAccess(t *testing.T) {
+	carts := map[string]domain.Cart{
+		"guest": {ID: "guest"},
+		"alice": {ID: "alice", UserID: "u-alice"},
+	}
+
+	tests := []struct {
+		name   string
+		cartID string
+		userID string
+		err    error
+	}{
+		{"guest cart without a token", "guest", "", nil},
+		{"guest cart with a token", "guest", "u-bob", nil},
+		{"own cart", "alice", "u-alice", nil},
+		{"another customer's cart", "alice", "u-bob", domain.ErrCartNotFound},
+		{"customer's cart without a token", "alice", "", domain.ErrCartNotFound},
+		{"missing cart", "missing", "u-alice", domain.ErrCartNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeCartRepo{carts: carts}
+			uc := NewCartUseCase(repo, nil, nil, 0)
+
+			if _, err := uc.Get(tt.cartID, tt.userID); !errors.Is(err, tt.err) {
+				t.Errorf("Get: err = %v, want %v", err, tt.err)
+			}
+			if _, _, err := uc.Refresh(tt.cartID, tt.userID); !errors.Is(err, tt.err) {
+				t.Errorf("Refresh: err = %v, want %v", err, tt.err)
+			}
+			if _, err := uc.RemoveItem(tt.cartID, tt.userID, "p1"); !errors.Is(err, tt.err) {
+				t.Errorf("RemoveItem: err = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil && len(repo.changed) > 0 {
+				t.Errorf("inaccessible cart was changed: %v", repo.changed)
+			}
+		})
+	}
+}
+
+func TestCartMergeAndCheckoutOwner(t *testing.T) {
+	repo := &fakeCartRepo{carts: map[string]domain.Cart{
+		"guest": {ID: "guest"},
+		"alice": {ID: "alice", UserID: "u-alice"},
+	}}
+	uc := NewCartUseCase(repo, nil, nil, 0)
+
+	if _, err := uc.Merge("alice", "u-bob"); !errors.Is(err, domain.ErrCartNotFound) {
+		t.Errorf("merge of another customer's cart: err = %v, want %v", err, domain.ErrCartNotFound)
+	}
+	if cart, err := uc.Merge("alice", "u-alice"); err != nil || cart.ID != "alice" {
+		t.Errorf("merge of own cart = %q, %v; want the cart unchanged", cart.ID, err)
+	}
+	if _, err := uc.Merge("guest", ""); !errors.Is(err, domain.ErrCartOwner) {
+		t.Errorf("merge without a customer: err = %v, want %v", err, domain.ErrCartOwner)
+	}
+	if len(repo.changed) > 0 {
+		t.Errorf("carts were merged: %v", repo.changed)
+	}
+
+	tests := []struct {
+		name   string
+		cartID string
+		userID string
+		err    error
+	}{
+		{"another customer's cart", "alice", "u-bob", domain.ErrCartNotFound},
+		{"without a customer", "alice", "", domain.ErrCartOwner},
+		{"guest cart", "guest", "u-bob", domain.ErrCartOwner},
+		// Проверка владельца пройдена, дальше корзина отклоняется как пустая
+		{"own cart", "alice", "u-alice", domain.ErrCartEmpty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := uc.Checkout(tt.cartID, tt.userID, CartCheckout{}); !errors.Is(err, tt.err) {
+				t.Errorf("err = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
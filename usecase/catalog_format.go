@@ -0,0 +1,314 @@
+package usecase
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"FoodStore-AdvProg2/domain"
+)
+
+// Колонки файла каталога. id выгружается для справки и при импорте игнорируется:
+// товары сопоставляются по sku.
+const (
+	catalogColumnID          = "id"
+	catalogColumnSKU         = "sku"
+	catalogColumnName        = "name"
+	catalogColumnDescription = "description"
+	catalogColumnPrice       = "price"
+	catalogColumnStock       = "stock"
+)
+
+var catalogColumns = []string{
+	catalogColumnID, catalogColumnSKU, catalogColumnName, catalogColumnDescription, catalogColumnPrice, catalogColumnStock,
+}
+
+// maxJSONLineSize - максимальная длина строки JSON Lines
+const maxJSONLineSize = 1 << 20
+
+// catalogRecord - товар в строке JSON Lines
+type catalogRecord struct {
+	ID          string   `json:"id,omitempty"`
+	SKU         string   `json:"sku"`
+	Name        string   `json:"name"`
+	Description *string  `json:"description,omitempty"`
+	Price       *float64 `json:"price"`
+	Stock       *int     `json:"stock,omitempty"`
+}
+
+// parseCatalog читает строки импорта. Ошибки отдельных строк возвращаются списком,
+// такие строки в результат не попадают; ошибка формата файла прерывает разбор.
+func parseCatalog(r io.Reader, format string) ([]domain.ProductImportRow, []domain.ProductImportRowError, error) {
+	switch format {
+	case domain.CatalogFormatCSV:
+		return parseCatalogCSV(r)
+	case domain.CatalogFormatJSONL:
+		return parseCatalogJSONL(r)
+	}
+	return nil, nil, fmt.Errorf("%w %q", ErrUnsupportedCatalogFormat, format)
+}
+
+func parseCatalogCSV(r io.Reader) ([]domain.ProductImportRow, []domain.ProductImportRowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("%w: file is empty", ErrInvalidCatalogFile)
+	}
+	if err != nil {
+		return nil, nil, csvError(err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !isCatalogColumn(name) {
+			return nil, nil, fmt.Errorf("%w: unknown column %q, expected %s", ErrInvalidCatalogFile, name, strings.Join(catalogColumns, ", "))
+		}
+		if _, ok := columns[name]; ok {
+			return nil, nil, fmt.Errorf("%w: duplicate column %q", ErrInvalidCatalogFile, name)
+		}
+		columns[name] = i
+	}
+	for _, required := range []string{catalogColumnSKU, catalogColumnName, catalogColumnPrice} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("%w: missing column %q", ErrInvalidCatalogFile, required)
+		}
+	}
+
+	var rows []domain.ProductImportRow
+	var rowErrors []domain.ProductImportRowError
+	count := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, csvError(err)
+		}
+		line, _ := reader.FieldPos(0)
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		if count++; count > MaxImportRows {
+			return nil, nil, fmt.Errorf("%w: more than %d rows", ErrInvalidCatalogFile, MaxImportRows)
+		}
+		if len(record) != len(header) {
+			rowErrors = append(rowErrors, domain.ProductImportRowError{
+				Line:    line,
+				Message: fmt.Sprintf("expected %d fields, got %d", len(header), len(record)),
+			})
+			continue
+		}
+
+		value := func(column string) (string, bool) {
+			i, ok := columns[column]
+			if !ok {
+				return "", false
+			}
+			return strings.TrimSpace(record[i]), true
+		}
+
+		row := domain.ProductImportRow{Line: line}
+		row.SKU, _ = value(catalogColumnSKU)
+		row.Name, _ = value(catalogColumnName)
+		if description, ok := value(catalogColumnDescription); ok {
+			row.Description = &description
+		}
+
+		var fieldErrors []domain.ProductImportRowError
+		fieldError := func(field, message string) {
+			fieldErrors = append(fieldErrors, domain.ProductImportRowError{Line: line, SKU: row.SKU, Field: field, Message: message})
+		}
+
+		price, _ := value(catalogColumnPrice)
+		if price == "" {
+			fieldError(catalogColumnPrice, "is required")
+		} else if row.Price, err = strconv.ParseFloat(price, 64); err != nil {
+			fieldError(catalogColumnPrice, fmt.Sprintf("%q is not a number", price))
+		}
+		// Пустой остаток оставляет остаток товара без изменений
+		if stock, ok := value(catalogColumnStock); ok && stock != "" {
+			quantity, err := strconv.Atoi(stock)
+			if err != nil {
+				fieldError(catalogColumnStock, fmt.Sprintf("%q is not an integer", stock))
+			} else {
+				row.Stock = &quantity
+			}
+		}
+
+		fieldErrors = append(fieldErrors, validateImportRow(row)...)
+		if len(fieldErrors) > 0 {
+			rowErrors = append(rowErrors, fieldErrors...)
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, rowErrors, nil
+}
+
+// csvError отделяет ошибки разбора CSV от ошибок чтения потока
+func csvError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return fmt.Errorf("%w: %v", ErrInvalidCatalogFile, parseErr)
+	}
+	return err
+}
+
+func isCatalogColumn(name string) bool {
+	for _, column := range catalogColumns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+func parseCatalogJSONL(r io.Reader) ([]domain.ProductImportRow, []domain.ProductImportRowError, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxJSONLineSize)
+
+	var rows []domain.ProductImportRow
+	var rowErrors []domain.ProductImportRowError
+	line, count := 0, 0
+	for scanner.Scan() {
+		line++
+		data := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if data == "" {
+			continue
+		}
+		if count++; count > MaxImportRows {
+			return nil, nil, fmt.Errorf("%w: more than %d rows", ErrInvalidCatalogFile, MaxImportRows)
+		}
+
+		var record catalogRecord
+		decoder := json.NewDecoder(strings.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&record); err != nil {
+			rowErrors = append(rowErrors, domain.ProductImportRowError{Line: line, Message: "invalid JSON: " + err.Error()})
+			continue
+		}
+
+		row := domain.ProductImportRow{
+			Line:        line,
+			SKU:         strings.TrimSpace(record.SKU),
+			Name:        strings.TrimSpace(record.Name),
+			Description: record.Description,
+			Stock:       record.Stock,
+		}
+		fieldErrors := validateImportRow(row)
+		if record.Price == nil {
+			fieldErrors = append(fieldErrors, domain.ProductImportRowError{Line: line, SKU: row.SKU, Field: catalogColumnPrice, Message: "is required"})
+		} else {
+			row.Price = *record.Price
+		}
+		if len(fieldErrors) > 0 {
+			rowErrors = append(rowErrors, fieldErrors...)
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, nil, fmt.Errorf("%w: line %d is longer than %d bytes", ErrInvalidCatalogFile, line+1, maxJSONLineSize)
+		}
+		return nil, nil, err
+	}
+	return rows, rowErrors, nil
+}
+
+// validateImportRow проверяет значения разобранной строки
+func validateImportRow(row domain.ProductImportRow) []domain.ProductImportRowError {
+	var rowErrors []domain.ProductImportRowError
+	fieldError := func(field, message string) {
+		rowErrors = append(rowErrors, domain.ProductImportRowError{Line: row.Line, SKU: row.SKU, Field: field, Message: message})
+	}
+
+	if row.SKU == "" {
+		fieldError(catalogColumnSKU, "is required")
+	} else if len(row.SKU) > domain.MaxSKULength {
+		fieldError(catalogColumnSKU, fmt.Sprintf("is longer than %d characters", domain.MaxSKULength))
+	}
+	if row.Name == "" {
+		fieldError(catalogColumnName, "is required")
+	}
+	if row.Price < 0 || math.IsNaN(row.Price) || math.IsInf(row.Price, 0) {
+		fieldError(catalogColumnPrice, "must be a non-negative number")
+	}
+	if row.Stock != nil && *row.Stock < 0 {
+		fieldError(catalogColumnStock, "cannot be negative")
+	}
+	return rowErrors
+}
+
+// catalogWriter пишет товары в файл каталога
+type catalogWriter interface {
+	Write(p domain.Product) error
+	Flush() error
+}
+
+func newCatalogWriter(w io.Writer, format string) (catalogWriter, error) {
+	switch format {
+	case domain.CatalogFormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(catalogColumns); err != nil {
+			return nil, err
+		}
+		return &csvCatalogWriter{writer: writer}, nil
+	case domain.CatalogFormatJSONL:
+		return &jsonlCatalogWriter{encoder: json.NewEncoder(w)}, nil
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnsupportedCatalogFormat, format)
+}
+
+type csvCatalogWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvCatalogWriter) Write(p domain.Product) error {
+	stock := ""
+	if !p.IsBundle() {
+		stock = strconv.Itoa(p.Stock)
+	}
+	return w.writer.Write([]string{
+		p.ID, p.SKU, p.Name, p.Description, strconv.FormatFloat(p.Price, 'f', -1, 64), stock,
+	})
+}
+
+func (w *csvCatalogWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type jsonlCatalogWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *jsonlCatalogWriter) Write(p domain.Product) error {
+	record := catalogRecord{
+		ID:          p.ID,
+		SKU:         p.SKU,
+		Name:        p.Name,
+		Description: &p.Description,
+		Price:       &p.Price,
+	}
+	// Остаток набора не выгружается: он считается по компонентам и не импортируется
+	if !p.IsBundle() {
+		record.Stock = &p.Stock
+	}
+	return w.encoder.Encode(record)
+}
+
+func (w *jsonlCatalogWriter) Flush() error {
+	return nil
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/repository"
+)
+
+var (
+	// ErrUnsupportedCatalogFormat - формат файла каталога не csv и не jsonl
+	ErrUnsupportedCatalogFormat = errors.New("unsupported catalog format")
+	// ErrInvalidCatalogFile - файл импорта не разобрать целиком: нет заголовка, битый CSV, слишком много строк
+	ErrInvalidCatalogFile = errors.New("invalid catalog file")
+)
+
+// MaxImportRows - максимальное число строк в одном файле импорта
+const MaxImportRows = 10000
+
+// exportPageSize - сколько товаров выгрузка читает из базы за раз
+const exportPageSize = 500
+
+// CatalogUseCase загружает каталог товаров из файлов и выгружает его в файлы
+type CatalogUseCase struct {
+	Products repository.ProductRepository
+	Importer repository.ProductImportRepository
+}
+
+func NewCatalogUseCase(products repository.ProductRepository, importer repository.ProductImportRepository) *CatalogUseCase {
+	return &CatalogUseCase{Products: products, Importer: importer}
+}
+
+// Import создает и обновляет товары из файла по SKU. Строки с ошибками возвращаются
+// в результате; без Atomic остальные строки применяются по одной, с Atomic при любой
+// ошибке не применяется ничего. В режиме DryRun база не меняется.
+func (uc *CatalogUseCase) Import(r io.Reader, opts domain.ProductImportOptions, actor string) (domain.ProductImportResult, error) {
+	if !domain.IsValidCatalogFormat(opts.Format) {
+		return domain.ProductImportResult{}, fmt.Errorf("%w %q", ErrUnsupportedCatalogFormat, opts.Format)
+	}
+
+	rows, rowErrors, err := parseCatalog(r, opts.Format)
+	if err != nil {
+		return domain.ProductImportResult{}, err
+	}
+
+	// Повтор артикула в файле - ошибка: непонятно, какая из строк должна победить
+	firstLine := make(map[string]int, len(rows))
+	unique := rows[:0]
+	for _, row := range rows {
+		if line, ok := firstLine[row.SKU]; ok {
+			rowErrors = append(rowErrors, domain.ProductImportRowError{
+				Line: row.Line, SKU: row.SKU, Field: catalogColumnSKU,
+				Message: fmt.Sprintf("duplicate sku, first used on line %d", line),
+			})
+			continue
+		}
+		firstLine[row.SKU] = row.Line
+		unique = append(unique, row)
+	}
+	rows = unique
+
+	result := domain.ProductImportResult{DryRun: opts.DryRun, Errors: rowErrors}
+	failedLines := failedImportLines(rowErrors)
+	result.Rows = len(rows) + len(failedLines)
+
+	switch {
+	case opts.DryRun:
+		skus := make([]string, 0, len(rows))
+		for _, row := range rows {
+			skus = append(skus, row.SKU)
+		}
+		existing, err := uc.Importer.FindIDsBySKU(skus)
+		if err != nil {
+			return domain.ProductImportResult{}, err
+		}
+		for _, row := range rows {
+			if _, ok := existing[row.SKU]; ok {
+				result.Updated++
+			} else {
+				result.Created++
+			}
+		}
+
+	case opts.Atomic:
+		if len(rowErrors) > 0 || len(rows) == 0 {
+			break
+		}
+		created, updated, err := uc.Importer.Apply(rows, importChange(actor))
+		var rowErr domain.ProductImportRowError
+		if errors.As(err, &rowErr) {
+			result.Errors = append(result.Errors, rowErr)
+			failedLines[rowErr.Line] = true
+			break
+		}
+		if err != nil {
+			return domain.ProductImportResult{}, err
+		}
+		result.Created, result.Updated = created, updated
+		result.Committed = true
+
+	default:
+		for _, row := range rows {
+			created, updated, err := uc.Importer.Apply([]domain.ProductImportRow{row}, importChange(actor))
+			var rowErr domain.ProductImportRowError
+			if errors.As(err, &rowErr) {
+				result.Errors = append(result.Errors, rowErr)
+				failedLines[rowErr.Line] = true
+				continue
+			}
+			if err != nil {
+				return domain.ProductImportResult{}, err
+			}
+			result.Created += created
+			result.Updated += updated
+		}
+		result.Committed = result.Created+result.Updated > 0
+	}
+
+	result.Failed = len(failedLines)
+	sort.SliceStable(result.Errors, func(i, j int) bool {
+		return result.Errors[i].Line < result.Errors[j].Line
+	})
+	return result, nil
+}
+
+func failedImportLines(rowErrors []domain.ProductImportRowError) map[int]bool {
+	lines := make(map[int]bool, len(rowErrors))
+	for _, e := range rowErrors {
+		lines[e.Line] = true
+	}
+	return lines
+}
+
+func importChange(actor string) domain.StockChange {
+	return domain.StockChange{Actor: actor, Reason: "catalog import"}
+}
+
+// Export выгружает товары каталога (или архива, если filter.Archived) по названию.
+// Товары читаются из базы страницами, поэтому размер каталога не ограничен памятью.
+func (uc *CatalogUseCase) Export(w io.Writer, format string, filter domain.FilterParams) error {
+	writer, err := newCatalogWriter(w, format)
+	if err != nil {
+		return err
+	}
+
+	pagination := domain.PaginationParams{PerPage: exportPageSize}
+	for {
+		page, err := uc.Products.FindAllWithFilter(filter, domain.ProductSortName, pagination, 0)
+		if err != nil {
+			return err
+		}
+		for _, product := range page.Products {
+			if err := writer.Write(product); err != nil {
+				return err
+			}
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		pagination.Cursor = page.NextCursor
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/repository"
+
+	"github.com/google/uuid"
+)
+
+// DefaultSlotHoldTTL - сколько бронь интервала ждет оформления заказа
+const DefaultSlotHoldTTL = 15 * time.Minute
+
+// defaultSlotListPeriod - за какой период показываются интервалы, если конец периода не задан
+const defaultSlotListPeriod = 7 * 24 * time.Hour
+
+// DeliverySlotUseCase ведет интервалы доставки и брони мест в них. Бронь делается при оформлении
+// заказа и подтверждается созданием заказа (OrderUseCase.CreateOrder); неподтвержденная бронь истекает через HoldTTL.
+type DeliverySlotUseCase struct {
+	Repo    repository.DeliverySlotRepository
+	HoldTTL time.Duration
+}
+
+func NewDeliverySlotUseCase(repo repository.DeliverySlotRepository, holdTTL time.Duration) *DeliverySlotUseCase {
+	if holdTTL <= 0 {
+		holdTTL = DefaultSlotHoldTTL
+	}
+	return &DeliverySlotUseCase{Repo: repo, HoldTTL: holdTTL}
+}
+
+func (uc *DeliverySlotUseCase) Create(slot domain.DeliverySlot) (domain.DeliverySlot, error) {
+	if slot.Zone == "" {
+		return domain.DeliverySlot{}, fmt.Errorf("%w: zone is required", domain.ErrInvalidDeliverySlot)
+	}
+	if slot.Capacity <= 0 {
+		return domain.DeliverySlot{}, fmt.Errorf("%w: capacity must be positive", domain.ErrInvalidDeliverySlot)
+	}
+	if slot.StartsAt.IsZero() || !slot.EndsAt.After(slot.StartsAt) {
+		return domain.DeliverySlot{}, fmt.Errorf("%w: ends_at must be after starts_at", domain.ErrInvalidDeliverySlot)
+	}
+	now := time.Now()
+	if !slot.StartsAt.After(now) {
+		return domain.DeliverySlot{}, fmt.Errorf("%w: starts_at is in the past", domain.ErrInvalidDeliverySlot)
+	}
+
+	slot.ID = uuid.New().String()
+	slot.Booked = 0
+	slot.CreatedAt = now
+	if err := uc.Repo.SaveSlot(slot); err != nil {
+		return domain.DeliverySlot{}, err
+	}
+	return slot, nil
+}
+
+// ListAvailable возвращает интервалы зоны, начинающиеся в [from, to), с учетом занятых мест.
+// Нулевой from - текущий момент, нулевой to - неделя от from. Полные и начавшиеся интервалы
+// пропускаются, если не задан includeFull.
+func (uc *DeliverySlotUseCase) ListAvailable(zone string, from, to time.Time, includeFull bool) ([]domain.DeliverySlot, error) {
+	now := time.Now()
+	if from.IsZero() {
+		from = now
+	}
+	if to.IsZero() {
+		to = from.Add(defaultSlotListPeriod)
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: to must be after from", domain.ErrInvalidDeliverySlot)
+	}
+
+	slots, err := uc.Repo.FindSlots(zone, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if includeFull {
+		return slots, nil
+	}
+
+	available := make([]domain.DeliverySlot, 0, len(slots))
+	for _, slot := range slots {
+		if slot.Available() > 0 && slot.StartsAt.After(now) {
+			available = append(available, slot)
+		}
+	}
+	return available, nil
+}
+
+// Reserve удерживает место в интервале за покупателем на HoldTTL. Прежняя неподтвержденная бронь
+// покупателя снимается: заказ оформляется на один интервал.
+func (uc *DeliverySlotUseCase) Reserve(slotID, userID string) (domain.SlotReservation, error) {
+	if userID == "" {
+		return domain.SlotReservation{}, fmt.Errorf("%w: user_id is required", domain.ErrInvalidDeliverySlot)
+	}
+
+	now := time.Now()
+	reservation := domain.SlotReservation{
+		ID:        uuid.New().String(),
+		SlotID:    slotID,
+		UserID:    userID,
+		Status:    domain.SlotReservationHeld,
+		ExpiresAt: now.Add(uc.HoldTTL),
+		CreatedAt: now,
+	}
+	if err := uc.Repo.Reserve(reservation); err != nil {
+		return domain.SlotReservation{}, err
+	}
+	return reservation, nil
+}
+
+// Release снимает неподтвержденную бронь покупателя. Место заказа освобождается только отменой заказа.
+func (uc *DeliverySlotUseCase) Release(reservationID, userID string) (domain.SlotReservation, error) {
+	reservation, err := uc.Repo.FindReservation(reservationID)
+	if err != nil {
+		return domain.SlotReservation{}, err
+	}
+	if reservation.UserID != userID {
+		return domain.SlotReservation{}, domain.ErrSlotReservationNotFound
+	}
+
+	released, err := uc.Repo.Release(reservationID)
+	if err != nil {
+		return domain.SlotReservation{}, err
+	}
+	if !released {
+		return domain.SlotReservation{}, domain.ErrSlotReservationExpired
+	}
+	return uc.Repo.FindReservation(reservationID)
+}
+
+// ExpireReservations помечает истекшими брони, по которым заказ так и не оформили.
+// Место истекшей брони свободно и до этого: занятость считается по сроку брони.
+func (uc *DeliverySlotUseCase) ExpireReservations() (int, error) {
+	return uc.Repo.ExpireReservations(time.Now())
+}
@@ -0,0 +1,372 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultCourierLocationMaxAge - сколько считается актуальной последняя точка курьера
+	DefaultCourierLocationMaxAge = 10 * time.Minute
+	// DefaultCourierSpeedKmh - средняя скорость курьера для оценки времени прибытия
+	DefaultCourierSpeedKmh = 20.0
+)
+
+// DeliveryUseCase ведет курьеров и доставку заказов: назначение курьера, точки маршрута,
+// подтверждение вручения. Статус доставки переводит и заказ (out_for_delivery -> delivered).
+type DeliveryUseCase struct {
+	Repo      repository.DeliveryRepository
+	Orders    repository.OrderRepository
+	Locations repository.LocationRepository
+	// Store хранит фото подтверждения вручения
+	Store          repository.BlobStore
+	LocationMaxAge time.Duration
+	SpeedKmh       float64
+	// Updates получает назначение курьера и смены статуса заказа; nil - без рассылки
+	Updates *OrderBroker
+}
+
+func NewDeliveryUseCase(repo repository.DeliveryRepository, orders repository.OrderRepository,
+	locations repository.LocationRepository, store repository.BlobStore) *DeliveryUseCase {
+	return &DeliveryUseCase{
+		Repo:           repo,
+		Orders:         orders,
+		Locations:      locations,
+		Store:          store,
+		LocationMaxAge: DefaultCourierLocationMaxAge,
+		SpeedKmh:       DefaultCourierSpeedKmh,
+	}
+}
+
+// RankCouriers упорядочивает свободных курьеров для заказа, который забирают в точке origin.
+// Первыми идут курьеры со свежими координатами по расстоянию до origin, за ними остальные:
+// если координат нет ни у кого, заказ все равно достанется свободному курьеру.
+func RankCouriers(couriers []domain.Courier, origin domain.Address, at time.Time, maxAge time.Duration) []domain.Courier {
+	ranked := make([]domain.Courier, 0, len(couriers))
+	for _, c := range couriers {
+		if c.Status == domain.CourierStatusAvailable && !c.Busy {
+			ranked = append(ranked, c)
+		}
+	}
+
+	distance := func(c domain.Courier) (float64, bool) {
+		if !origin.HasCoordinates() || !c.HasFreshLocation(at, maxAge) {
+			return 0, false
+		}
+		return origin.DistanceKm(geoAddress(*c.Location)), true
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		di, iOK := distance(ranked[i])
+		dj, jOK := distance(ranked[j])
+		if iOK != jOK {
+			return iOK
+		}
+		return iOK && di < dj
+	})
+	return ranked
+}
+
+// geoAddress - адрес из одних координат, чтобы считать расстояние через Address.DistanceKm
+func geoAddress(p domain.GeoPoint) domain.Address {
+	return domain.Address{Latitude: &p.Latitude, Longitude: &p.Longitude}
+}
+
+func (uc *DeliveryUseCase) CreateCourier(c domain.Courier) (domain.Courier, error) {
+	c.Name = strings.TrimSpace(c.Name)
+	c.Phone = strings.TrimSpace(c.Phone)
+	c.Zone = strings.TrimSpace(c.Zone)
+	if c.Name == "" || c.Phone == "" {
+		return domain.Courier{}, fmt.Errorf("%w: name and phone are required", domain.ErrInvalidCourier)
+	}
+	if c.Status == "" {
+		c.Status = domain.CourierStatusOffline
+	}
+	if !domain.IsValidCourierStatus(c.Status) {
+		return domain.Courier{}, fmt.Errorf("%w: unknown status %q", domain.ErrInvalidCourier, c.Status)
+	}
+
+	c.ID = uuid.New().String()
+	c.CreatedAt = time.Now()
+	c.Location, c.LocationAt, c.Busy = nil, nil, false
+	if err := uc.Repo.SaveCourier(c); err != nil {
+		return domain.Courier{}, err
+	}
+	return c, nil
+}
+
+func (uc *DeliveryUseCase) ListCouriers(status, zone string) ([]domain.Courier, error) {
+	if status != "" && !domain.IsValidCourierStatus(status) {
+		return nil, fmt.Errorf("%w: unknown status %q", domain.ErrInvalidCourier, status)
+	}
+	return uc.Repo.FindCouriers(status, zone)
+}
+
+// SetCourierStatus выводит курьера на смену или снимает с нее. Ушедший со смены курьер
+// довозит уже взятый заказ, но новых не получает.
+func (uc *DeliveryUseCase) SetCourierStatus(id, status string) (domain.Courier, error) {
+	if !domain.IsValidCourierStatus(status) {
+		return domain.Courier{}, fmt.Errorf("%w: unknown status %q", domain.ErrInvalidCourier, status)
+	}
+	if err := uc.Repo.UpdateCourierStatus(id, status); err != nil {
+		return domain.Courier{}, err
+	}
+	return uc.Repo.FindCourierByID(id)
+}
+
+// RecordLocation сохраняет точку маршрута курьера; пустое время - момент получения
+func (uc *DeliveryUseCase) RecordLocation(courierID string, point domain.GeoPoint, recordedAt time.Time) (domain.Courier, error) {
+	if point.Latitude < -90 || point.Latitude > 90 || point.Longitude < -180 || point.Longitude > 180 {
+		return domain.Courier{}, fmt.Errorf("%w: location out of range", domain.ErrInvalidCourier)
+	}
+	now := time.Now()
+	if recordedAt.IsZero() {
+		recordedAt = now
+	}
+	// Часы телефона могут спешить, но точка из будущего сделала бы курьера "свежим" надолго
+	if recordedAt.After(now.Add(time.Minute)) {
+		return domain.Courier{}, fmt.Errorf("%w: recorded_at is in the future", domain.ErrInvalidCourier)
+	}
+
+	err := uc.Repo.RecordLocation(domain.CourierLocation{
+		CourierID:  courierID,
+		Latitude:   point.Latitude,
+		Longitude:  point.Longitude,
+		RecordedAt: recordedAt,
+	})
+	if err != nil {
+		return domain.Courier{}, err
+	}
+	return uc.Repo.FindCourierByID(courierID)
+}
+
+// AssignCourier передает заказ курьеру courierID или, если он не указан, ближайшему свободному
+// курьеру зоны заказа. Расстояние считается до точки, с которой собирается заказ.
+func (uc *DeliveryUseCase) AssignCourier(orderID, courierID string) (domain.Delivery, error) {
+	order, _, err := uc.Orders.FindByID(orderID)
+	if err != nil {
+		return domain.Delivery{}, err
+	}
+	if order.Status != domain.OrderStatusPending {
+		return domain.Delivery{}, fmt.Errorf("%w: order is %s", domain.ErrInvalidDelivery, order.Status)
+	}
+	if order.DeliveryAddress.IsEmpty() {
+		return domain.Delivery{}, fmt.Errorf("%w: order has no delivery address", domain.ErrInvalidDelivery)
+	}
+
+	now := time.Now()
+	delivery := domain.Delivery{
+		ID:         uuid.New().String(),
+		OrderID:    orderID,
+		Status:     domain.DeliveryStatusAssigned,
+		AssignedAt: now,
+		UpdatedAt:  now,
+	}
+
+	if courierID != "" {
+		if _, err := uc.Repo.FindCourierByID(courierID); err != nil {
+			return domain.Delivery{}, err
+		}
+		delivery.CourierID = courierID
+		if err := uc.Repo.Assign(delivery); err != nil {
+			return domain.Delivery{}, err
+		}
+		uc.publish(order, domain.OrderUpdateCourierAssigned)
+		return delivery, nil
+	}
+
+	couriers, err := uc.Repo.FindCouriers(domain.CourierStatusAvailable, "")
+	if err != nil {
+		return domain.Delivery{}, err
+	}
+	inZone := couriers[:0]
+	for _, c := range couriers {
+		if c.Zone == "" || order.DeliveryZone == "" || c.Zone == order.DeliveryZone {
+			inZone = append(inZone, c)
+		}
+	}
+
+	// Другой диспетчер мог занять курьера между выборкой и назначением - берем следующего
+	for _, c := range RankCouriers(inZone, uc.pickupAddress(order), now, uc.LocationMaxAge) {
+		delivery.CourierID = c.ID
+		err := uc.Repo.Assign(delivery)
+		if errors.Is(err, domain.ErrCourierUnavailable) {
+			continue
+		}
+		if err != nil {
+			return domain.Delivery{}, err
+		}
+		uc.publish(order, domain.OrderUpdateCourierAssigned)
+		return delivery, nil
+	}
+	return domain.Delivery{}, domain.ErrNoCourierAvailable
+}
+
+// publish сообщает подписчикам об изменении заказа order
+func (uc *DeliveryUseCase) publish(order domain.Order, updateType string) {
+	uc.Updates.Publish(domain.OrderUpdate{
+		Type:       updateType,
+		OrderID:    order.ID,
+		UserID:     order.UserID,
+		Status:     order.Status,
+		OccurredAt: time.Now(),
+	})
+}
+
+// pickupAddress - точка, где курьер забирает заказ; без координат точки - адрес доставки
+func (uc *DeliveryUseCase) pickupAddress(order domain.Order) domain.Address {
+	if order.LocationID != "" && uc.Locations != nil {
+		if location, err := uc.Locations.FindByID(order.LocationID); err == nil && location.Address.HasCoordinates() {
+			return location.Address
+		}
+	}
+	return order.DeliveryAddress
+}
+
+// UpdateDeliveryStatus двигает доставку курьером courierID (пусто - диспетчер) и вместе с ней заказ.
+// Вручение требует подтверждения: заметки или фото; для неудачной доставки note - причина.
+func (uc *DeliveryUseCase) UpdateDeliveryStatus(id, courierID, status, note string) (domain.Delivery, error) {
+	d, err := uc.findDelivery(id, courierID)
+	if err != nil {
+		return domain.Delivery{}, err
+	}
+	if !d.CanTransition(status) {
+		return domain.Delivery{}, fmt.Errorf("%w: cannot change delivery from %s to %s", domain.ErrInvalidDelivery, d.Status, status)
+	}
+
+	now := time.Now()
+	if note = strings.TrimSpace(note); note != "" {
+		d.ProofNote = note
+	}
+	switch status {
+	case domain.DeliveryStatusOutForDelivery:
+		d.PickedUpAt = &now
+	case domain.DeliveryStatusDelivered:
+		if d.ProofNote == "" && d.ProofPhotoURL == "" {
+			return domain.Delivery{}, fmt.Errorf("%w: proof of delivery (note or photo) is required", domain.ErrInvalidDelivery)
+		}
+		d.DeliveredAt = &now
+	}
+	d.Status = status
+	d.UpdatedAt = now
+
+	if err := uc.Repo.UpdateDelivery(d, domain.OrderStatusFor(status)); err != nil {
+		return domain.Delivery{}, err
+	}
+	if order, _, err := uc.Orders.FindByID(d.OrderID); err == nil {
+		uc.publish(order, domain.OrderUpdateStatusChanged)
+	}
+	return d, nil
+}
+
+// AttachProofPhoto сохраняет фото вручения; загружается до перевода доставки в delivered
+func (uc *DeliveryUseCase) AttachProofPhoto(ctx context.Context, id, courierID string, data []byte) (domain.Delivery, error) {
+	d, err := uc.findDelivery(id, courierID)
+	if err != nil {
+		return domain.Delivery{}, err
+	}
+	if !d.IsActive() {
+		return domain.Delivery{}, fmt.Errorf("%w: delivery is %s", domain.ErrInvalidDelivery, d.Status)
+	}
+	if len(data) > MaxImageSize {
+		return domain.Delivery{}, ErrImageTooLarge
+	}
+	ext, ok := allowedImageTypes[http.DetectContentType(data)]
+	if !ok {
+		return domain.Delivery{}, ErrUnsupportedImage
+	}
+
+	key := fmt.Sprintf("deliveries/%s/%s.%s", d.ID, uuid.New().String(), ext)
+	url, err := uc.Store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), http.DetectContentType(data))
+	if err != nil {
+		return domain.Delivery{}, err
+	}
+
+	d.ProofPhotoURL = url
+	d.UpdatedAt = time.Now()
+	if err := uc.Repo.UpdateDelivery(d, domain.OrderStatusFor(d.Status)); err != nil {
+		uc.Store.Delete(ctx, key)
+		return domain.Delivery{}, err
+	}
+	return d, nil
+}
+
+func (uc *DeliveryUseCase) ListDeliveries(courierID, status string) ([]domain.Delivery, error) {
+	return uc.Repo.FindDeliveries(courierID, status)
+}
+
+// CourierByUser возвращает курьера, под учетной записью userID которого пришел запрос
+func (uc *DeliveryUseCase) CourierByUser(userID string) (domain.Courier, error) {
+	if userID == "" {
+		return domain.Courier{}, domain.ErrCourierNotFound
+	}
+	return uc.Repo.FindCourierByUserID(userID)
+}
+
+// findDelivery возвращает доставку; чужая доставка для курьера не существует
+func (uc *DeliveryUseCase) findDelivery(id, courierID string) (domain.Delivery, error) {
+	d, err := uc.Repo.FindDeliveryByID(id)
+	if err != nil {
+		return domain.Delivery{}, err
+	}
+	if courierID != "" && d.CourierID != courierID {
+		return domain.Delivery{}, domain.ErrDeliveryNotFound
+	}
+	return d, nil
+}
+
+// Track собирает для покупателя userID состояние доставки его заказа.
+// Положение курьера показывается, только пока заказ в пути.
+func (uc *DeliveryUseCase) Track(orderID, userID string) (domain.OrderTracking, error) {
+	order, _, err := uc.Orders.FindByID(orderID)
+	if err != nil {
+		return domain.OrderTracking{}, err
+	}
+	if order.UserID != userID {
+		return domain.OrderTracking{}, domain.ErrOrderNotFound
+	}
+
+	tracking := domain.OrderTracking{
+		OrderID:          order.ID,
+		OrderStatus:      order.Status,
+		DeliveryStartsAt: order.DeliveryStartsAt,
+		DeliveryEndsAt:   order.DeliveryEndsAt,
+	}
+
+	d, err := uc.Repo.FindLatestByOrder(orderID)
+	if errors.Is(err, domain.ErrDeliveryNotFound) {
+		return tracking, nil
+	}
+	if err != nil {
+		return domain.OrderTracking{}, err
+	}
+	tracking.Delivery = &d
+
+	courier, err := uc.Repo.FindCourierByID(d.CourierID)
+	if err != nil {
+		return domain.OrderTracking{}, err
+	}
+	tracking.CourierName = courier.Name
+	if d.Status != domain.DeliveryStatusOutForDelivery || courier.Location == nil {
+		return tracking, nil
+	}
+
+	tracking.CourierLocation = courier.Location
+	tracking.CourierLocationAt = courier.LocationAt
+	if order.DeliveryAddress.HasCoordinates() && uc.SpeedKmh > 0 {
+		km := order.DeliveryAddress.DistanceKm(geoAddress(*courier.Location))
+		tracking.EtaMinutes = int(math.Max(1, math.Ceil(km/uc.SpeedKmh*60)))
+	}
+	return tracking, nil
+}
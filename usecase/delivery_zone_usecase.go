@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/repository"
+
+	"github.com/google/uuid"
+)
+
+// DeliveryZoneUseCase ведет зоны доставки: где доставляем, сколько стоит доставка
+// и от какой суммы принимается заказ
+type DeliveryZoneUseCase struct {
+	Repo repository.DeliveryZoneRepository
+}
+
+func NewDeliveryZoneUseCase(repo repository.DeliveryZoneRepository) *DeliveryZoneUseCase {
+	return &DeliveryZoneUseCase{Repo: repo}
+}
+
+// MatchDeliveryZone возвращает активную зону с наибольшим приоритетом, в которую входит адрес.
+// Из пересекающихся зон с равным приоритетом выбирается первая в списке.
+func MatchDeliveryZone(zones []domain.DeliveryZone, address domain.Address) (domain.DeliveryZone, bool) {
+	var match domain.DeliveryZone
+	found := false
+	for _, z := range zones {
+		if !z.Active || !z.Contains(address) {
+			continue
+		}
+		if !found || z.Priority > match.Priority {
+			match, found = z, true
+		}
+	}
+	return match, found
+}
+
+func (uc *DeliveryZoneUseCase) Create(z domain.DeliveryZone) (domain.DeliveryZone, error) {
+	z, err := normalizeDeliveryZone(z)
+	if err != nil {
+		return domain.DeliveryZone{}, err
+	}
+	z.ID = uuid.New().String()
+	z.CreatedAt = time.Now()
+	if err := uc.Repo.Save(z); err != nil {
+		return domain.DeliveryZone{}, err
+	}
+	return z, nil
+}
+
+// Update заменяет условия зоны целиком
+func (uc *DeliveryZoneUseCase) Update(z domain.DeliveryZone) (domain.DeliveryZone, error) {
+	current, err := uc.Repo.FindByID(z.ID)
+	if err != nil {
+		return domain.DeliveryZone{}, err
+	}
+	z, err = normalizeDeliveryZone(z)
+	if err != nil {
+		return domain.DeliveryZone{}, err
+	}
+	z.CreatedAt = current.CreatedAt
+	if err := uc.Repo.Update(z); err != nil {
+		return domain.DeliveryZone{}, err
+	}
+	return z, nil
+}
+
+func (uc *DeliveryZoneUseCase) List(activeOnly bool) ([]domain.DeliveryZone, error) {
+	return uc.Repo.FindAll(activeOnly)
+}
+
+// Quote подбирает зону для адреса и считает стоимость доставки заказа с суммой товаров subtotal.
+// Сумма меньше минимального заказа зоны не ошибка: покупатель видит, сколько еще добрать.
+func (uc *DeliveryZoneUseCase) Quote(address domain.Address, subtotal float64) (domain.DeliveryZone, float64, error) {
+	zones, err := uc.Repo.FindAll(true)
+	if err != nil {
+		return domain.DeliveryZone{}, 0, err
+	}
+	zone, ok := MatchDeliveryZone(zones, address)
+	if !ok {
+		return domain.DeliveryZone{}, 0, domain.ErrAddressNotServed
+	}
+	return zone, zone.FeeFor(subtotal), nil
+}
+
+// normalizeDeliveryZone проверяет зону и приводит код и индексы к единому виду
+func normalizeDeliveryZone(z domain.DeliveryZone) (domain.DeliveryZone, error) {
+	z.Code = strings.TrimSpace(z.Code)
+	if z.Code == "" {
+		return z, fmt.Errorf("%w: code is required", domain.ErrInvalidDeliveryZone)
+	}
+	z.Name = strings.TrimSpace(z.Name)
+	if z.Name == "" {
+		z.Name = z.Code
+	}
+
+	if len(z.Polygon) > 0 && len(z.Polygon) < 3 {
+		return z, fmt.Errorf("%w: polygon needs at least 3 points", domain.ErrInvalidDeliveryZone)
+	}
+	for _, p := range z.Polygon {
+		if p.Latitude < -90 || p.Latitude > 90 || p.Longitude < -180 || p.Longitude > 180 {
+			return z, fmt.Errorf("%w: polygon point out of range", domain.ErrInvalidDeliveryZone)
+		}
+	}
+
+	codes := make([]string, 0, len(z.PostalCodes))
+	seen := make(map[string]bool, len(z.PostalCodes))
+	for _, code := range z.PostalCodes {
+		code = domain.NormalizePostalCode(code)
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		codes = append(codes, code)
+	}
+	z.PostalCodes = codes
+	if len(z.Polygon) == 0 && len(z.PostalCodes) == 0 {
+		return z, fmt.Errorf("%w: polygon or postal_codes is required", domain.ErrInvalidDeliveryZone)
+	}
+
+	if z.Fee < 0 || z.FreeDeliveryThreshold < 0 || z.MinOrderAmount < 0 {
+		return z, fmt.Errorf("%w: fee and amounts cannot be negative", domain.ErrInvalidDeliveryZone)
+	}
+	return z, nil
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/repository"
+)
+
+func square(fromLat, fromLon, toLat, toLon float64) []domain.GeoPoint {
+	corner := func(lat, lon float64) domain.GeoPoint { return domain.GeoPoint{Latitude: lat, Longitude: lon} }
+	return []domain.GeoPoint{corner(fromLat, fromLon), corner(fromLat, toLon), corner(toLat, toLon), corner(toLat, fromLon)}
+}
+
+func point(lat, lon float64) domain.Address {
+	return domain.Address{Latitude: &lat, Longitude: &lon}
+}
+
+func TestMatchDeliveryZoneOverlapping(t *testing.T) {
+	city := domain.DeliveryZone{Code: "CITY", Polygon: square(0, 0, 10, 10), Active: true}
+	center := domain.DeliveryZone{Code: "CENTER", Polygon: square(4, 4, 6, 6), Priority: 10, Active: true}
+	closed := domain.DeliveryZone{Code: "CLOSED", Polygon: square(0, 0, 10, 10), Priority: 100}
+	east := domain.DeliveryZone{Code: "EAST", Polygon: square(0, 8, 10, 12), Active: true}
+
+	tests := []struct {
+		name    string
+		zones   []domain.DeliveryZone
+		address domain.Address
+		want    string
+	}{
+		{"higher priority wins where zones overlap", []domain.DeliveryZone{city, center}, point(5, 5), "CENTER"},
+		{"priority does not depend on order", []domain.DeliveryZone{center, city}, point(5, 5), "CENTER"},
+		{"outer zone outside the inner one", []domain.DeliveryZone{center, city}, point(1, 1), "CITY"},
+		{"inactive zone is skipped", []domain.DeliveryZone{closed, city}, point(5, 5), "CITY"},
+		{"equal priority keeps list order", []domain.DeliveryZone{east, city}, point(5, 9), "EAST"},
+		{"shared edge belongs to both zones", []domain.DeliveryZone{city, east}, point(5, 10), "CITY"},
+		{"address outside all zones", []domain.DeliveryZone{city, center}, point(20, 20), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone, ok := MatchDeliveryZone(tt.zones, tt.address)
+			if ok != (tt.want != "") || zone.Code != tt.want {
+				t.Errorf("zone = %q (found %v), want %q", zone.Code, ok, tt.want)
+			}
+		})
+	}
+}
+
+// fakeDeliveryZoneRepo отдает заданные зоны
+type fakeDeliveryZoneRepo struct {
+	repository.DeliveryZoneRepository
+	zones []domain.DeliveryZone
+}
+
+func (r *fakeDeliveryZoneRepo) FindAll(activeOnly bool) ([]domain.DeliveryZone, error) {
+	return r.zones, nil
+}
+
+func TestDeliveryZoneQuote(t *testing.T) {
+	uc := NewDeliveryZoneUseCase(&fakeDeliveryZoneRepo{zones: []domain.DeliveryZone{
+		{Code: "CITY", Polygon: square(0, 0, 10, 10), Fee: 500, FreeDeliveryThreshold: 5000, Active: true},
+		{Code: "SUBURB", Polygon: square(0, 10, 10, 20), Fee: 900, Active: true},
+	}})
+
+	tests := []struct {
+		name     string
+		address  domain.Address
+		subtotal float64
+		zone     string
+		fee      float64
+		err      error
+	}{
+		{"below the free delivery threshold", point(5, 5), 4999.99, "CITY", 500, nil},
+		{"free delivery at the threshold", point(5, 5), 5000, "CITY", 0, nil},
+		{"zone without a threshold", point(5, 15), 100000, "SUBURB", 900, nil},
+		{"address not served", point(20, 20), 100, "", 0, domain.ErrAddressNotServed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone, fee, err := uc.Quote(tt.address, tt.subtotal)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if zone.Code != tt.zone || fee != tt.fee {
+				t.Errorf("got zone %q fee %v, want %q %v", zone.Code, fee, tt.zone, tt.fee)
+			}
+		})
+	}
+}
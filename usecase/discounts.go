@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"math"
+	"sort"
+
+	"FoodStore-AdvProg2/domain"
+)
+
+// DiscountLine - позиция заказа для расчета скидок
+type DiscountLine struct {
+	ItemID    string
+	ProductID string
+	Category  string
+	Unit      string
+	Quantity  float64
+	// Amount - стоимость позиции до скидок, округленная до копеек
+	Amount float64
+}
+
+// roundMoney округляет сумму до копеек
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// CalculateDiscounts применяет акции к позициям заказа и возвращает скидки по позициям
+// и скидку на доставку. Суммируемые акции применяются по приоритету, каждая - к стоимости позиций
+// после предыдущих скидок. Несуммируемая акция заменяет их все, если дает покупателю большую скидку.
+// Порог MinSubtotal скидки на товары сравнивается с суммой подходящих позиций до скидок,
+// порог бесплатной доставки - с суммой товаров после скидок; бесплатная доставка
+// сочетается с любыми скидками на товары.
+func CalculateDiscounts(lines []DiscountLine, promotions []domain.Promotion, deliveryFee float64) []domain.OrderDiscount {
+	sorted := make([]domain.Promotion, len(promotions))
+	copy(sorted, promotions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Priority != sorted[j].Priority {
+			return sorted[i].Priority > sorted[j].Priority
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	var stackable, exclusive, delivery []domain.Promotion
+	for _, p := range sorted {
+		switch {
+		case p.Type == domain.PromotionFreeDelivery:
+			delivery = append(delivery, p)
+		case p.Stackable:
+			stackable = append(stackable, p)
+		default:
+			exclusive = append(exclusive, p)
+		}
+	}
+
+	best := applyLinePromotions(lines, stackable)
+	for _, p := range exclusive {
+		if candidate := applyLinePromotions(lines, []domain.Promotion{p}); discountSum(candidate) > discountSum(best) {
+			best = candidate
+		}
+	}
+
+	if deliveryFee > 0 {
+		goods := 0.0
+		for _, l := range lines {
+			goods += l.Amount
+		}
+		goods -= discountSum(best)
+		for _, p := range delivery {
+			if goods >= p.MinSubtotal {
+				best = append(best, newDiscount(p, "", roundMoney(deliveryFee)))
+				break
+			}
+		}
+	}
+	return best
+}
+
+// applyLinePromotions применяет акции к позициям по очереди
+func applyLinePromotions(lines []DiscountLine, promotions []domain.Promotion) []domain.OrderDiscount {
+	remaining := make([]float64, len(lines))
+	for i, l := range lines {
+		remaining[i] = l.Amount
+	}
+
+	var discounts []domain.OrderDiscount
+	for _, p := range promotions {
+		var eligible []int
+		base := 0.0
+		for i, l := range lines {
+			if p.Applies(l.ProductID, l.Category) {
+				eligible = append(eligible, i)
+				base += l.Amount
+			}
+		}
+		if len(eligible) == 0 || base < p.MinSubtotal {
+			continue
+		}
+
+		amounts := promotionAmounts(p, lines, remaining, eligible)
+		for _, i := range eligible {
+			amount := math.Min(amounts[i], remaining[i])
+			if amount <= 0 {
+				continue
+			}
+			remaining[i] = roundMoney(remaining[i] - amount)
+			discounts = append(discounts, newDiscount(p, lines[i].ItemID, amount))
+		}
+	}
+	return discounts
+}
+
+// promotionAmounts считает скидку акции по каждой подходящей позиции от ее оставшейся стоимости
+func promotionAmounts(p domain.Promotion, lines []DiscountLine, remaining []float64, eligible []int) map[int]float64 {
+	amounts := make(map[int]float64, len(eligible))
+	switch p.Type {
+	case domain.PromotionPercentage:
+		for _, i := range eligible {
+			amounts[i] = roundMoney(remaining[i] * p.Value / 100)
+		}
+
+	case domain.PromotionFixedAmount:
+		// Сумма скидки делится пропорционально стоимости позиций; остаток от округления
+		// достается последней позиции, чтобы сумма скидок совпала со скидкой акции
+		total := 0.0
+		for _, i := range eligible {
+			total += remaining[i]
+		}
+		if total <= 0 {
+			return amounts
+		}
+		amount := roundMoney(math.Min(p.Value, total))
+		allocated := 0.0
+		last := -1
+		for _, i := range eligible {
+			if remaining[i] > 0 {
+				last = i
+			}
+		}
+		for _, i := range eligible {
+			if remaining[i] <= 0 {
+				continue
+			}
+			if i == last {
+				amounts[i] = roundMoney(amount - allocated)
+				break
+			}
+			amounts[i] = roundMoney(amount * remaining[i] / total)
+			allocated += amounts[i]
+		}
+
+	case domain.PromotionBOGO:
+		// Из каждой группы BuyQuantity+GetQuantity штук бесплатны GetQuantity.
+		// Скидка считается от оставшейся стоимости, чтобы не превысить ее после других акций.
+		group := p.BuyQuantity + p.GetQuantity
+		for _, i := range eligible {
+			l := lines[i]
+			quantity := int(l.Quantity)
+			if l.Unit != domain.UnitPiece || group <= 0 || float64(quantity) != l.Quantity || quantity == 0 {
+				continue
+			}
+			free := quantity / group * p.GetQuantity
+			amounts[i] = roundMoney(remaining[i] * float64(free) / float64(quantity))
+		}
+	}
+	return amounts
+}
+
+func newDiscount(p domain.Promotion, itemID string, amount float64) domain.OrderDiscount {
+	return domain.OrderDiscount{
+		OrderItemID: itemID,
+		PromotionID: p.ID,
+		Name:        p.Name,
+		CouponCode:  p.CouponCode,
+		Amount:      amount,
+	}
+}
+
+func discountSum(discounts []domain.OrderDiscount) float64 {
+	sum := 0.0
+	for _, d := range discounts {
+		sum += d.Amount
+	}
+	return roundMoney(sum)
+}
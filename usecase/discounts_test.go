@@ -0,0 +1,287 @@
+package usecase
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/repository"
+)
+
+// applied - скидка в виде, удобном для сравнения: акция, позиция и сумма
+type applied struct {
+	promotion string
+	item      string
+	amount    float64
+}
+
+func appliedDiscounts(discounts []domain.OrderDiscount) []applied {
+	var got []applied
+	for _, d := range discounts {
+		got = append(got, applied{d.PromotionID, d.OrderItemID, d.Amount})
+	}
+	return got
+}
+
+func pieceLine(id string, amount float64) DiscountLine {
+	return DiscountLine{ItemID: id, ProductID: "p-" + id, Unit: domain.UnitPiece, Quantity: 1, Amount: amount}
+}
+
+func TestCalculateDiscounts(t *testing.T) {
+	percent := func(id string, value float64, priority int, stackable bool) domain.Promotion {
+		return domain.Promotion{ID: id, Type: domain.PromotionPercentage, Value: value, Priority: priority, Stackable: stackable}
+	}
+	fixed := func(id string, value float64, priority int, stackable bool) domain.Promotion {
+		return domain.Promotion{ID: id, Type: domain.PromotionFixedAmount, Value: value, Priority: priority, Stackable: stackable}
+	}
+	freeDelivery := func(minSubtotal float64) domain.Promotion {
+		return domain.Promotion{ID: "free", Type: domain.PromotionFreeDelivery, MinSubtotal: minSubtotal}
+	}
+	dairy := pieceLine("a", 40)
+	dairy.Category = "dairy"
+	weighed := DiscountLine{ItemID: "b", ProductID: "p-b", Unit: domain.UnitKilogram, Quantity: 3, Amount: 30}
+	sevenPieces := DiscountLine{ItemID: "a", ProductID: "p-a", Unit: domain.UnitPiece, Quantity: 7, Amount: 70}
+
+	tests := []struct {
+		name        string
+		lines       []DiscountLine
+		promotions  []domain.Promotion
+		deliveryFee float64
+		want        []applied
+	}{
+		{
+			name:       "stackable promotions apply by priority",
+			lines:      []DiscountLine{pieceLine("a", 100)},
+			promotions: []domain.Promotion{fixed("fix", 5, 1, true), percent("pct", 10, 2, true)},
+			want:       []applied{{"pct", "a", 10}, {"fix", "a", 5}},
+		},
+		{
+			name:       "later promotion applies to what is left",
+			lines:      []DiscountLine{pieceLine("a", 100)},
+			promotions: []domain.Promotion{percent("pct", 10, 1, true), fixed("fix", 5, 2, true)},
+			want:       []applied{{"fix", "a", 5}, {"pct", "a", 9.5}},
+		},
+		{
+			name:  "exclusive promotion replaces smaller stack",
+			lines: []DiscountLine{pieceLine("a", 100)},
+			promotions: []domain.Promotion{
+				percent("pct", 10, 2, true), fixed("fix", 5, 1, true), percent("exclusive", 20, 0, false),
+			},
+			want: []applied{{"exclusive", "a", 20}},
+		},
+		{
+			name:  "stack kept when exclusive promotion is smaller",
+			lines: []DiscountLine{pieceLine("a", 100)},
+			promotions: []domain.Promotion{
+				percent("pct", 10, 2, true), fixed("fix", 5, 1, true), fixed("exclusive", 12, 0, false),
+			},
+			want: []applied{{"pct", "a", 10}, {"fix", "a", 5}},
+		},
+		{
+			name:       "fixed amount capped at eligible total",
+			lines:      []DiscountLine{pieceLine("a", 30)},
+			promotions: []domain.Promotion{fixed("fix", 50, 0, true)},
+			want:       []applied{{"fix", "a", 30}},
+		},
+		{
+			name:       "fixed amount split with rounding remainder on the last line",
+			lines:      []DiscountLine{pieceLine("a", 10), pieceLine("b", 10), pieceLine("c", 10)},
+			promotions: []domain.Promotion{fixed("fix", 10, 0, true)},
+			want:       []applied{{"fix", "a", 3.33}, {"fix", "b", 3.33}, {"fix", "c", 3.34}},
+		},
+		{
+			name:       "stacked discounts never exceed the line amount",
+			lines:      []DiscountLine{pieceLine("a", 100)},
+			promotions: []domain.Promotion{percent("pct", 60, 2, true), fixed("fix", 100, 1, true)},
+			want:       []applied{{"pct", "a", 60}, {"fix", "a", 40}},
+		},
+		{
+			name:  "min subtotal counts eligible lines only",
+			lines: []DiscountLine{dairy, pieceLine("b", 100)},
+			promotions: []domain.Promotion{{
+				ID: "dairy", Type: domain.PromotionPercentage, Value: 10, Categories: []string{"dairy"}, MinSubtotal: 50,
+			}},
+		},
+		{
+			name:  "category promotion skips other lines",
+			lines: []DiscountLine{dairy, pieceLine("b", 100)},
+			promotions: []domain.Promotion{{
+				ID: "dairy", Type: domain.PromotionPercentage, Value: 10, Categories: []string{"dairy"},
+			}},
+			want: []applied{{"dairy", "a", 4}},
+		},
+		{
+			name:  "bogo counts whole groups of pieces",
+			lines: []DiscountLine{sevenPieces, weighed},
+			promotions: []domain.Promotion{{
+				ID: "bogo", Type: domain.PromotionBOGO, BuyQuantity: 2, GetQuantity: 1,
+			}},
+			want: []applied{{"bogo", "a", 20}},
+		},
+		{
+			name:        "free delivery threshold uses goods after discounts",
+			lines:       []DiscountLine{pieceLine("a", 100)},
+			promotions:  []domain.Promotion{percent("pct", 10, 0, true), freeDelivery(95)},
+			deliveryFee: 5,
+			want:        []applied{{"pct", "a", 10}},
+		},
+		{
+			name:        "free delivery at the threshold",
+			lines:       []DiscountLine{pieceLine("a", 100)},
+			promotions:  []domain.Promotion{percent("pct", 10, 0, true), freeDelivery(90)},
+			deliveryFee: 5,
+			want:        []applied{{"pct", "a", 10}, {"free", "", 5}},
+		},
+		{
+			name:       "free delivery without a fee",
+			lines:      []DiscountLine{pieceLine("a", 100)},
+			promotions: []domain.Promotion{freeDelivery(0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := appliedDiscounts(CalculateDiscounts(tt.lines, tt.promotions, tt.deliveryFee))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("discounts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakePromotionRepo отдает заданные акции и счетчики использований
+type fakePromotionRepo struct {
+	repository.PromotionRepository
+	promotions []domain.Promotion
+	usage      map[string]domain.PromotionUsage
+}
+
+func (r *fakePromotionRepo) FindApplicable(couponCodes []string) ([]domain.Promotion, error) {
+	var found []domain.Promotion
+	for _, p := range r.promotions {
+		if !p.IsCoupon() {
+			found = append(found, p)
+			continue
+		}
+		for _, code := range couponCodes {
+			if code == p.CouponCode {
+				found = append(found, p)
+			}
+		}
+	}
+	return found, nil
+}
+
+func (r *fakePromotionRepo) Usage(promotionIDs []string, userID string) (map[string]domain.PromotionUsage, error) {
+	return r.usage, nil
+}
+
+func TestApplyPromotions(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	auto := domain.Promotion{ID: "auto", Type: domain.PromotionPercentage, Value: 10, Active: true}
+	coupon := domain.Promotion{ID: "coupon", Type: domain.PromotionFixedAmount, Value: 5, CouponCode: "SAVE5", Active: true}
+	with := func(p domain.Promotion, change func(*domain.Promotion)) domain.Promotion {
+		change(&p)
+		return p
+	}
+
+	tests := []struct {
+		name       string
+		promotions []domain.Promotion
+		usage      map[string]domain.PromotionUsage
+		coupons    []string
+		want       []applied
+		err        error
+	}{
+		{
+			name:       "coupon beaten by a larger exclusive promotion",
+			promotions: []domain.Promotion{auto, with(coupon, func(p *domain.Promotion) { p.Stackable = true })},
+			coupons:    []string{"SAVE5"},
+			err:        domain.ErrCouponNotApplicable,
+		},
+		{
+			name: "stacked coupon",
+			promotions: []domain.Promotion{
+				with(auto, func(p *domain.Promotion) { p.Stackable = true; p.Priority = 1 }),
+				with(coupon, func(p *domain.Promotion) { p.Stackable = true }),
+			},
+			coupons: []string{" save5 "},
+			want:    []applied{{"auto", "a", 10}, {"coupon", "a", 5}},
+		},
+		{
+			name:       "expired automatic promotion is ignored",
+			promotions: []domain.Promotion{with(auto, func(p *domain.Promotion) { p.EndsAt = &past })},
+		},
+		{
+			name:       "promotion that has not started is ignored",
+			promotions: []domain.Promotion{with(auto, func(p *domain.Promotion) { p.StartsAt = &future })},
+		},
+		{
+			name:       "disabled promotion is ignored",
+			promotions: []domain.Promotion{with(auto, func(p *domain.Promotion) { p.Active = false })},
+		},
+		{
+			name:       "expired coupon",
+			promotions: []domain.Promotion{with(coupon, func(p *domain.Promotion) { p.EndsAt = &past })},
+			coupons:    []string{"SAVE5"},
+			err:        domain.ErrInvalidCoupon,
+		},
+		{
+			name:    "unknown coupon",
+			coupons: []string{"NOPE"},
+			err:     domain.ErrInvalidCoupon,
+		},
+		{
+			name:       "automatic promotion below its usage limit",
+			promotions: []domain.Promotion{with(auto, func(p *domain.Promotion) { p.UsageLimit = 5 })},
+			usage:      map[string]domain.PromotionUsage{"auto": {Total: 4}},
+			want:       []applied{{"auto", "a", 10}},
+		},
+		{
+			name:       "automatic promotion with exhausted usage limit is skipped",
+			promotions: []domain.Promotion{with(auto, func(p *domain.Promotion) { p.UsageLimit = 5 })},
+			usage:      map[string]domain.PromotionUsage{"auto": {Total: 5}},
+		},
+		{
+			name:       "coupon with exhausted usage limit",
+			promotions: []domain.Promotion{with(coupon, func(p *domain.Promotion) { p.UsageLimit = 5 })},
+			usage:      map[string]domain.PromotionUsage{"coupon": {Total: 5, ByUser: 0}},
+			coupons:    []string{"SAVE5"},
+			err:        domain.ErrPromotionLimitReached,
+		},
+		{
+			name:       "coupon with exhausted per-user limit",
+			promotions: []domain.Promotion{with(coupon, func(p *domain.Promotion) { p.PerUserLimit = 1 })},
+			usage:      map[string]domain.PromotionUsage{"coupon": {Total: 1, ByUser: 1}},
+			coupons:    []string{"SAVE5"},
+			err:        domain.ErrPromotionLimitReached,
+		},
+		{
+			name:       "coupon below its minimum subtotal",
+			promotions: []domain.Promotion{with(coupon, func(p *domain.Promotion) { p.MinSubtotal = 500 })},
+			coupons:    []string{"SAVE5"},
+			err:        domain.ErrCouponNotApplicable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &OrderUseCase{Promotions: &fakePromotionRepo{promotions: tt.promotions, usage: tt.usage}}
+			req := domain.OrderRequest{UserID: "u1", CouponCodes: tt.coupons}
+
+			discounts, err := uc.applyPromotions(req, []DiscountLine{pieceLine("a", 100)}, 0)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+			if got := appliedDiscounts(discounts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("discounts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
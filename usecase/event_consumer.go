@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/repository"
+)
+
+// Параметры потребителя событий по умолчанию
+const (
+	DefaultEventCatchUpInterval = time.Minute
+	// DefaultEventReplayWindow - за какой срок потребитель дочитывает пропущенные события;
+	// не больше срока хранения событий в outbox
+	DefaultEventReplayWindow = 24 * time.Hour
+)
+
+// EventHandler обрабатывает событие; при ошибке событие будет доставлено повторно
+type EventHandler func(ctx context.Context, event domain.Event) error
+
+// EventConsumer получает события из брокера и отбрасывает уже обработанные по ID события.
+// Брокер не хранит события для отключенных подписчиков, поэтому потребитель периодически
+// дочитывает из outbox отправленные, но не обработанные им события.
+type EventConsumer struct {
+	// Name - имя потребителя: отметки об обработке и группа подписчиков NATS
+	Name            string
+	Repo            repository.OutboxRepository
+	CatchUpInterval time.Duration
+	ReplayWindow    time.Duration
+
+	handlers map[string]EventHandler
+	// mu упорядочивает обработку событий из брокера и из outbox
+	mu sync.Mutex
+}
+
+func NewEventConsumer(name string, repo repository.OutboxRepository) *EventConsumer {
+	return &EventConsumer{
+		Name:            name,
+		Repo:            repo,
+		CatchUpInterval: DefaultEventCatchUpInterval,
+		ReplayWindow:    DefaultEventReplayWindow,
+		handlers:        make(map[string]EventHandler),
+	}
+}
+
+// On задает обработчик событий типа eventType; события других типов только отмечаются обработанными
+func (c *EventConsumer) On(eventType string, handler EventHandler) *EventConsumer {
+	c.handlers[eventType] = handler
+	return c
+}
+
+// Handle обрабатывает событие не больше одного раза. Отметка ставится после обработчика:
+// если процесс упадет между ними, событие обработается повторно, но не потеряется.
+func (c *EventConsumer) Handle(ctx context.Context, event domain.Event) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	processed, err := c.Repo.IsProcessed(c.Name, event.ID)
+	if err != nil || processed {
+		return err
+	}
+
+	if handler, ok := c.handlers[event.Type]; ok {
+		if err := handler(ctx, event); err != nil {
+			return err
+		}
+	}
+	return c.Repo.MarkProcessed(c.Name, event.ID)
+}
+
+// Run подписывается на события и дочитывает пропущенные, пока не отменен ctx
+func (c *EventConsumer) Run(ctx context.Context, subscriber repository.EventSubscriber) {
+	go func() {
+		err := subscriber.Subscribe(ctx, c.Name, func(event domain.Event) {
+			if err := c.Handle(ctx, event); err != nil {
+				log.Printf("Event consumer %s: event %s (%s): %v", c.Name, event.ID, event.Type, err)
+			}
+		})
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Event consumer %s: subscription stopped: %v", c.Name, err)
+		}
+	}()
+
+	ticker := time.NewTicker(c.CatchUpInterval)
+	defer ticker.Stop()
+	for {
+		if err := c.CatchUp(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Event consumer %s: catch-up failed: %v", c.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CatchUp обрабатывает отправленные за ReplayWindow события, которых потребитель не видел.
+// Событие с ошибкой обработчика пропускается до следующего раза.
+func (c *EventConsumer) CatchUp(ctx context.Context) error {
+	events, err := c.Repo.FindUnprocessed(c.Name, time.Now().Add(-c.ReplayWindow), DefaultOutboxBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := c.Handle(ctx, event); err != nil {
+			log.Printf("Event consumer %s: event %s (%s): %v", c.Name, event.ID, event.Type, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"FoodStore-AdvProg2/domain"
+)
+
+// ErrNoFulfillingLocation возвращается, если ни одна точка не может собрать заказ целиком
+var ErrNoFulfillingLocation = errors.New("no location can fulfil the order")
+
+// ChooseFulfillmentLocation выбирает точку, с которой собирается заказ, среди точек,
+// где в наличии все позиции. Выбранный покупателем магазин (storeID) используется,
+// только если он может собрать заказ. Иначе предпочтение отдается ближайшей точке
+// к адресу доставки, затем точке с тем же индексом, тем же городом и складу по умолчанию.
+func ChooseFulfillmentLocation(candidates []domain.Location, storeID string, address domain.Address) (domain.Location, error) {
+	if storeID != "" {
+		for _, l := range candidates {
+			if l.ID == storeID {
+				return l, nil
+			}
+		}
+		return domain.Location{}, ErrNoFulfillingLocation
+	}
+	if len(candidates) == 0 {
+		return domain.Location{}, ErrNoFulfillingLocation
+	}
+
+	ranked := append([]domain.Location(nil), candidates...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+
+		if address.HasCoordinates() {
+			aHas, bHas := a.Address.HasCoordinates(), b.Address.HasCoordinates()
+			if aHas && bHas {
+				da, db := address.DistanceKm(a.Address), address.DistanceKm(b.Address)
+				if da != db {
+					return da < db
+				}
+			} else if aHas != bHas {
+				return aHas
+			}
+		}
+
+		if ra, rb := addressMatch(address, a.Address), addressMatch(address, b.Address); ra != rb {
+			return ra > rb
+		}
+		if a.IsDefault != b.IsDefault {
+			return a.IsDefault
+		}
+		return a.Code < b.Code
+	})
+
+	return ranked[0], nil
+}
+
+// addressMatch оценивает близость адресов без координат: 2 - совпал индекс, 1 - город
+func addressMatch(delivery, location domain.Address) int {
+	postal := strings.TrimSpace(delivery.PostalCode)
+	if postal != "" && strings.EqualFold(postal, strings.TrimSpace(location.PostalCode)) {
+		return 2
+	}
+	city := strings.TrimSpace(delivery.City)
+	if city != "" && strings.EqualFold(city, strings.TrimSpace(location.City)) {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"FoodStore-AdvProg2/domain"
+)
+
+func TestChooseFulfillmentLocation(t *testing.T) {
+	coords := func(lat, lon float64) domain.Address {
+		return domain.Address{Latitude: &lat, Longitude: &lon}
+	}
+	withCity := func(a domain.Address, city, postal string) domain.Address {
+		a.City, a.PostalCode = city, postal
+		return a
+	}
+
+	warehouse := domain.Location{ID: "main", Code: "MAIN", IsDefault: true, Address: withCity(coords(43.238, 76.945), "Almaty", "050000")}
+	north := domain.Location{ID: "north", Code: "NORTH", Address: withCity(coords(43.300, 76.950), "Almaty", "050010")}
+	astana := domain.Location{ID: "astana", Code: "ASTANA", Address: withCity(domain.Address{}, "Astana", "010000")}
+	noAddress := domain.Location{ID: "b", Code: "B"}
+	alsoNoAddress := domain.Location{ID: "a", Code: "A"}
+
+	tests := []struct {
+		name       string
+		candidates []domain.Location
+		storeID    string
+		address    domain.Address
+		want       string
+		err        error
+	}{
+		{"chosen store that can fulfil", []domain.Location{warehouse, north}, "north", coords(43.238, 76.945), "north", nil},
+		{"chosen store without stock", []domain.Location{warehouse}, "north", domain.Address{}, "", ErrNoFulfillingLocation},
+		{"no candidates", nil, "", coords(43.3, 76.95), "", ErrNoFulfillingLocation},
+		{"nearest by coordinates", []domain.Location{warehouse, north}, "", coords(43.299, 76.951), "north", nil},
+		{"locations with coordinates before those without", []domain.Location{astana, warehouse}, "", withCity(coords(51.17, 71.45), "Astana", ""), "main", nil},
+		{"postal code beats city", []domain.Location{warehouse, north}, "", withCity(domain.Address{}, "Almaty", "050010"), "north", nil},
+		{"city match", []domain.Location{warehouse, astana}, "", withCity(domain.Address{}, "astana ", ""), "astana", nil},
+		{"default warehouse when nothing matches", []domain.Location{north, warehouse}, "", withCity(domain.Address{}, "Shymkent", ""), "main", nil},
+		{"code breaks remaining ties", []domain.Location{noAddress, alsoNoAddress}, "", domain.Address{}, "a", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ChooseFulfillmentLocation(tt.candidates, tt.storeID, tt.address)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if got.ID != tt.want {
+				t.Errorf("location = %q, want %q", got.ID, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/repository"
+)
+
+var ErrLocationNotFound = errors.New("location not found")
+
+// ErrInvalidLocation - ошибка валидации точки; сервер возвращает ее клиенту как InvalidArgument
+var ErrInvalidLocation = errors.New("invalid location")
+
+// LocationUseCase управляет складами и магазинами
+type LocationUseCase struct {
+	Repo repository.LocationRepository
+}
+
+func NewLocationUseCase(repo repository.LocationRepository) *LocationUseCase {
+	return &LocationUseCase{Repo: repo}
+}
+
+func (uc *LocationUseCase) Create(location domain.Location) (domain.Location, error) {
+	location.Code = strings.ToUpper(strings.TrimSpace(location.Code))
+	if location.Code == "" {
+		return domain.Location{}, fmt.Errorf("%w: code is required", ErrInvalidLocation)
+	}
+	if err := validateLocation(&location); err != nil {
+		return domain.Location{}, err
+	}
+	return uc.Repo.Save(location)
+}
+
+// Update меняет название, тип, адрес и активность точки; код точки не меняется
+func (uc *LocationUseCase) Update(location domain.Location) (domain.Location, error) {
+	current, err := uc.Repo.FindByID(location.ID)
+	if err != nil {
+		return domain.Location{}, ErrLocationNotFound
+	}
+	if err := validateLocation(&location); err != nil {
+		return domain.Location{}, err
+	}
+	if current.IsDefault && !location.Active {
+		return domain.Location{}, fmt.Errorf("%w: default location cannot be deactivated", ErrInvalidLocation)
+	}
+
+	location.Code = current.Code
+	location.IsDefault = current.IsDefault
+	location.CreatedAt = current.CreatedAt
+	return location, uc.Repo.Update(location)
+}
+
+func (uc *LocationUseCase) GetByID(id string) (domain.Location, error) {
+	location, err := uc.Repo.FindByID(id)
+	if err != nil {
+		return domain.Location{}, ErrLocationNotFound
+	}
+	return location, nil
+}
+
+func (uc *LocationUseCase) List(includeInactive bool) ([]domain.Location, error) {
+	return uc.Repo.FindAll(includeInactive)
+}
+
+func validateLocation(location *domain.Location) error {
+	location.Name = strings.TrimSpace(location.Name)
+	if location.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidLocation)
+	}
+	if location.Type == "" {
+		location.Type = domain.LocationWarehouse
+	}
+	if !domain.IsValidLocationType(location.Type) {
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidLocation, location.Type)
+	}
+
+	a := location.Address
+	if (a.Latitude == nil) != (a.Longitude == nil) {
+		return fmt.Errorf("%w: latitude and longitude must be set together", ErrInvalidLocation)
+	}
+	if a.HasCoordinates() && (*a.Latitude < -90 || *a.Latitude > 90 || *a.Longitude < -180 || *a.Longitude > 180) {
+		return fmt.Errorf("%w: coordinates out of range", ErrInvalidLocation)
+	}
+	return nil
+}
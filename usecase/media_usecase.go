@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/repository"
+	"FoodStore-AdvProg2/utils"
+)
+
+const (
+	// MaxImageSize - максимальный размер загружаемого файла
+	MaxImageSize = 8 << 20
+	// maxImagePixels защищает от "декомпрессионных бомб"
+	maxImagePixels = 40_000_000
+
+	imageLargeSize = 1024
+	imageThumbSize = 256
+)
+
+var (
+	ErrImageTooLarge    = errors.New("image is too large")
+	ErrUnsupportedImage = errors.New("unsupported image format, use JPEG, PNG or GIF")
+	ErrImageNotFound    = errors.New("image not found")
+	ErrProductNotFound  = errors.New("product not found")
+)
+
+var allowedImageTypes = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+}
+
+// MediaUseCase управляет изображениями товаров
+type MediaUseCase struct {
+	Images   repository.ProductImageRepository
+	Products repository.ProductRepository
+	Store    repository.BlobStore
+}
+
+func NewMediaUseCase(images repository.ProductImageRepository, products repository.ProductRepository, store repository.BlobStore) *MediaUseCase {
+	return &MediaUseCase{
+		Images:   images,
+		Products: products,
+		Store:    store,
+	}
+}
+
+// UploadProductImage проверяет изображение, сохраняет оригинал и уменьшенные копии
+func (uc *MediaUseCase) UploadProductImage(ctx context.Context, productID string, data []byte) (domain.ProductImage, error) {
+	if len(data) > MaxImageSize {
+		return domain.ProductImage{}, ErrImageTooLarge
+	}
+
+	// Тип определяем по содержимому, а не по имени файла или заголовку клиента
+	contentType := http.DetectContentType(data)
+	ext, ok := allowedImageTypes[contentType]
+	if !ok {
+		return domain.ProductImage{}, ErrUnsupportedImage
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return domain.ProductImage{}, ErrUnsupportedImage
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return domain.ProductImage{}, ErrImageTooLarge
+	}
+
+	if _, err := uc.Products.FindByID(productID); err != nil {
+		return domain.ProductImage{}, ErrProductNotFound
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return domain.ProductImage{}, ErrUnsupportedImage
+	}
+
+	img := domain.ProductImage{
+		ID:        uuid.New().String(),
+		ProductID: productID,
+		Width:     cfg.Width,
+		Height:    cfg.Height,
+	}
+	prefix := fmt.Sprintf("products/%s/%s", productID, img.ID)
+
+	originalKey := prefix + "." + ext
+	img.OriginalURL, err = uc.Store.Put(ctx, originalKey, bytes.NewReader(data), int64(len(data)), contentType)
+	if err != nil {
+		return domain.ProductImage{}, fmt.Errorf("store original: %w", err)
+	}
+	img.Keys = append(img.Keys, originalKey)
+
+	variants := []struct {
+		suffix string
+		size   int
+		url    *string
+	}{
+		{"large", imageLargeSize, &img.URL},
+		{"thumb", imageThumbSize, &img.ThumbnailURL},
+	}
+	for _, v := range variants {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, utils.ResizeToFit(src, v.size, v.size), &jpeg.Options{Quality: 85}); err != nil {
+			uc.deleteBlobs(ctx, img.Keys)
+			return domain.ProductImage{}, fmt.Errorf("encode %s: %w", v.suffix, err)
+		}
+
+		key := fmt.Sprintf("%s_%s.jpg", prefix, v.suffix)
+		url, err := uc.Store.Put(ctx, key, &buf, int64(buf.Len()), "image/jpeg")
+		if err != nil {
+			uc.deleteBlobs(ctx, img.Keys)
+			return domain.ProductImage{}, fmt.Errorf("store %s: %w", v.suffix, err)
+		}
+		*v.url = url
+		img.Keys = append(img.Keys, key)
+	}
+
+	img.Position, err = uc.Images.NextPosition(productID)
+	if err != nil {
+		uc.deleteBlobs(ctx, img.Keys)
+		return domain.ProductImage{}, err
+	}
+
+	if err := uc.Images.Save(img); err != nil {
+		uc.deleteBlobs(ctx, img.Keys)
+		return domain.ProductImage{}, err
+	}
+
+	return img, nil
+}
+
+// DeleteProductImage удаляет запись об изображении и его файлы
+func (uc *MediaUseCase) DeleteProductImage(ctx context.Context, productID, imageID string) error {
+	img, err := uc.Images.FindByID(imageID)
+	if err != nil || img.ProductID != productID {
+		return ErrImageNotFound
+	}
+
+	if err := uc.Images.Delete(imageID); err != nil {
+		return err
+	}
+
+	uc.deleteBlobs(ctx, img.Keys)
+	return nil
+}
+
+// DeleteProductMedia удаляет все изображения товара перед удалением самого товара
+func (uc *MediaUseCase) DeleteProductMedia(ctx context.Context, productID string) error {
+	images, err := uc.Images.FindByProductIDs([]string{productID})
+	if err != nil {
+		return err
+	}
+
+	for _, img := range images[productID] {
+		if err := uc.Images.Delete(img.ID); err != nil {
+			return err
+		}
+		uc.deleteBlobs(ctx, img.Keys)
+	}
+	return nil
+}
+
+// AttachImages заполняет Images у списка товаров одним запросом
+func (uc *MediaUseCase) AttachImages(products []domain.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+
+	images, err := uc.Images.FindByProductIDs(ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range products {
+		products[i].Images = images[products[i].ID]
+	}
+	return nil
+}
+
+// deleteBlobs удаляет файлы; ошибки только логируются, так как запись в БД уже неактуальна
+func (uc *MediaUseCase) deleteBlobs(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := uc.Store.Delete(ctx, key); err != nil {
+			log.Printf("Failed to delete blob %s: %v", key, err)
+		}
+	}
+}
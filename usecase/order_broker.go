@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"sync"
+
+	"FoodStore-AdvProg2/domain"
+)
+
+// orderUpdateBuffer - сколько изменений ждет медленного подписчика
+const orderUpdateBuffer = 16
+
+// OrderBroker рассылает изменения заказов подписчикам внутри сервиса. Подписчик, который
+// не успевает читать, теряет самые старые изменения, но не последнее: по нему он
+// перечитает актуальное состояние заказа.
+type OrderBroker struct {
+	mu   sync.Mutex
+	subs map[*orderSubscription]struct{}
+}
+
+// orderSubscription - подписка на заказ orderID или на заказы покупателя userID;
+// пустые оба поля - все заказы
+type orderSubscription struct {
+	orderID string
+	userID  string
+	ch      chan domain.OrderUpdate
+}
+
+func NewOrderBroker() *OrderBroker {
+	return &OrderBroker{subs: make(map[*orderSubscription]struct{})}
+}
+
+// Subscribe подписывает на изменения; cancel отписывает и закрывает канал
+func (b *OrderBroker) Subscribe(orderID, userID string) (<-chan domain.OrderUpdate, func()) {
+	sub := &orderSubscription{orderID: orderID, userID: userID, ch: make(chan domain.OrderUpdate, orderUpdateBuffer)}
+
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, sub)
+			b.mu.Unlock()
+			close(sub.ch)
+		})
+	}
+	return sub.ch, cancel
+}
+
+// Publish не блокируется: для переполненного подписчика вытесняется самое старое изменение
+func (b *OrderBroker) Publish(update domain.OrderUpdate) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subs {
+		if sub.orderID != "" && sub.orderID != update.OrderID {
+			continue
+		}
+		if sub.userID != "" && sub.userID != update.UserID {
+			continue
+		}
+		select {
+		case sub.ch <- update:
+		default:
+			// Отправляет только брокер под мьютексом, поэтому после вытеснения место точно есть
+			select {
+			case <-sub.ch:
+			default:
+			}
+			sub.ch <- update
+		}
+	}
+}
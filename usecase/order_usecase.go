@@ -4,17 +4,54 @@ import (
 	"FoodStore-AdvProg2/domain"
 	"FoodStore-AdvProg2/repository"
 	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
 )
 
+// StockAllocator подбирает точки, способные собрать заказ, списывает товары под заказ и возвращает их при отмене.
+// Order Service реализует его через Inventory Service, монолит - через StockUseCase.
+type StockAllocator interface {
+	FulfillingLocations(items []domain.OrderItemRequest) ([]domain.Location, error)
+	Allocate(orderID, locationID string, items []domain.OrderItemRequest, change domain.StockChange) ([]domain.BatchAllocation, error)
+	Release(orderID string, change domain.StockChange) ([]domain.BatchAllocation, error)
+}
+
+// ErrProductArchived - в заказе есть товар, снятый с продажи
+var ErrProductArchived = errors.New("product is archived")
+
 type OrderUseCase struct {
 	OrderRepo   repository.OrderRepository
 	ProductRepo repository.ProductRepository
+	Stock       StockAllocator
+	Promotions  repository.PromotionRepository
+	// Slots - интервалы доставки; бронь из заказа подтверждается при его сохранении
+	Slots repository.DeliverySlotRepository
+	// Zones - зоны доставки со своими тарифами; адрес вне всех зон не обслуживается
+	Zones repository.DeliveryZoneRepository
+	// DeliveryFee - стоимость доставки, пока не заведено ни одной зоны; заказ с самовывозом доставку не оплачивает
+	DeliveryFee float64
+	// Tax - режим цен и ставки НДС по категориям товаров
+	Tax domain.TaxPolicy
+	// Updates получает созданные заказы и смены статуса для подписчиков; nil - без рассылки
+	Updates *OrderBroker
 }
 
-func NewOrderUseCase(orderRepo repository.OrderRepository, productRepo repository.ProductRepository) *OrderUseCase {
+func NewOrderUseCase(orderRepo repository.OrderRepository, productRepo repository.ProductRepository, stock StockAllocator,
+	promotions repository.PromotionRepository, slots repository.DeliverySlotRepository, zones repository.DeliveryZoneRepository,
+	deliveryFee float64, tax domain.TaxPolicy) *OrderUseCase {
 	return &OrderUseCase{
 		OrderRepo:   orderRepo,
 		ProductRepo: productRepo,
+		Stock:       stock,
+		Promotions:  promotions,
+		Slots:       slots,
+		Zones:       zones,
+		DeliveryFee: deliveryFee,
+		Tax:         tax,
 	}
 }
 
@@ -24,51 +61,325 @@ func (uc *OrderUseCase) CreateOrder(orderReq domain.OrderRequest) (string, error
 	}
 
 	// Подготовка заказа
-	var totalPrice float64
+	var subtotal float64
 	var orderItems []domain.OrderItem
+	var discountLines []DiscountLine
+	// Списание под заказ идет в базовых единицах остатка: 0.75 кг -> 750 г
+	var stockItems []domain.OrderItemRequest
 
 	for _, item := range orderReq.Items {
-		if item.Quantity <= 0 {
-			return "", errors.New("order item quantity must be greater than zero")
-		}
-
 		product, err := uc.ProductRepo.FindByID(item.ProductID)
 		if err != nil {
 			return "", errors.New("product not found")
 		}
+		// Архивный товар виден в старых заказах, но больше не продается
+		if product.IsArchived() {
+			return "", ErrProductArchived
+		}
 
-		if product.Stock < item.Quantity {
-			return "", errors.New("not enough stock for product")
+		unit := product.Unit
+		if unit == "" {
+			unit = domain.UnitPiece
+		}
+		base, err := domain.ToBaseUnits(item.Quantity, unit)
+		if err != nil {
+			return "", fmt.Errorf("product %s: %w", product.ID, err)
+		}
+
+		if product.Stock < base {
+			return "", fmt.Errorf("%w for product %s", domain.ErrInsufficientStock, product.ID)
 		}
 
 		orderItem := domain.OrderItem{
+			ID:        uuid.New().String(),
 			ProductID: product.ID,
 			Quantity:  item.Quantity,
+			Unit:      unit,
 			Price:     product.Price,
 		}
 		orderItems = append(orderItems, orderItem)
+		stockItems = append(stockItems, domain.OrderItemRequest{ProductID: product.ID, Quantity: base})
+
+		// Цена весового товара указана за килограмм или литр, стоимость позиции округляется до копеек
+		amount := math.Round(product.Price*item.Quantity*100) / 100
+		subtotal += amount
+		discountLines = append(discountLines, DiscountLine{
+			ItemID:    orderItem.ID,
+			ProductID: product.ID,
+			Category:  product.Category,
+			Unit:      unit,
+			Quantity:  item.Quantity,
+			Amount:    amount,
+		})
+	}
+	subtotal = roundMoney(subtotal)
+
+	// Доставку оплачивает только заказ с доставкой на адрес, по тарифу его зоны
+	deliveryFee, zone, err := uc.deliveryTerms(orderReq, subtotal)
+	if err != nil {
+		return "", err
+	}
+	discounts, err := uc.applyPromotions(orderReq, discountLines, deliveryFee)
+	if err != nil {
+		return "", err
+	}
+	discountTotal := discountSum(discounts)
+
+	// Скидки на позиции остаются в позициях, скидка на доставку - на заказе
+	var orderDiscounts []domain.OrderDiscount
+	itemIndex := make(map[string]int, len(orderItems))
+	for i, item := range orderItems {
+		itemIndex[item.ID] = i
+	}
+	for _, d := range discounts {
+		i, ok := itemIndex[d.OrderItemID]
+		if !ok {
+			orderDiscounts = append(orderDiscounts, d)
+			continue
+		}
+		orderItems[i].Discounts = append(orderItems[i].Discounts, d)
+		orderItems[i].Discount = roundMoney(orderItems[i].Discount + d.Amount)
+	}
+
+	// НДС считается со стоимости позиций после скидок; доставка облагается по стандартной ставке
+	taxLines := make([]TaxLine, 0, len(discountLines)+1)
+	for i, l := range discountLines {
+		taxLines = append(taxLines, TaxLine{ItemID: l.ItemID, Category: l.Category, Amount: roundMoney(l.Amount - orderItems[i].Discount)})
+	}
+	deliveryCharge := roundMoney(deliveryFee - discountSum(orderDiscounts))
+	if deliveryCharge > 0 {
+		taxLines = append(taxLines, TaxLine{Amount: deliveryCharge})
+	}
+	lineTaxes, taxSummary := CalculateTax(uc.Tax, taxLines)
+	var deliveryTax float64
+	for _, t := range lineTaxes {
+		i, ok := itemIndex[t.ItemID]
+		if !ok {
+			deliveryTax = t.TaxAmount
+			continue
+		}
+		orderItems[i].TaxRate = t.Rate
+		orderItems[i].TaxAmount = t.TaxAmount
+	}
+	taxTotal := TaxTotal(taxSummary)
 
-		totalPrice += product.Price * float64(item.Quantity)
+	totalPrice := roundMoney(subtotal - discountTotal + deliveryFee)
+	// Цены без НДС: налог добавляется к итогу, с НДС - уже входит в него
+	if uc.Tax.Mode == domain.TaxExclusive {
+		totalPrice = roundMoney(totalPrice + taxTotal)
+	}
+
+	slot, err := uc.deliverySlot(orderReq)
+	if err != nil {
+		return "", err
+	}
+	if slot != nil && zone != nil && slot.Zone != zone.Code {
+		return "", fmt.Errorf("%w: slot is for zone %s, address is in zone %s", domain.ErrInvalidDeliverySlot, slot.Zone, zone.Code)
+	}
+
+	// Заказ собирается целиком с одной точки: выбранного магазина или ближайшей к адресу доставки
+	candidates, err := uc.Stock.FulfillingLocations(stockItems)
+	if err != nil {
+		return "", err
+	}
+	location, err := ChooseFulfillmentLocation(candidates, orderReq.StoreID, orderReq.DeliveryAddress)
+	if err != nil {
+		return "", err
 	}
 
 	order := domain.Order{
-		UserID:     orderReq.UserID,
-		TotalPrice: totalPrice,
-		Status:     domain.OrderStatusPending,
+		UserID:          orderReq.UserID,
+		TotalAmount:     totalPrice,
+		Status:          domain.OrderStatusPending,
+		CreatedAt:       time.Now(),
+		LocationID:      location.ID,
+		DeliveryAddress: orderReq.DeliveryAddress,
+		Subtotal:        subtotal,
+		DiscountTotal:   discountTotal,
+		DeliveryFee:     deliveryFee,
+		Discounts:       orderDiscounts,
+		TaxMode:         uc.Tax.Mode,
+		TaxTotal:        taxTotal,
+		DeliveryTax:     deliveryTax,
+		TaxSummary:      taxSummary,
+	}
+	if zone != nil {
+		order.DeliveryZone = zone.Code
+	}
+	if slot != nil {
+		order.DeliverySlotID = slot.ID
+		order.DeliveryStartsAt = &slot.StartsAt
+		order.DeliveryEndsAt = &slot.EndsAt
+		order.SlotReservationID = orderReq.SlotReservationID
+	}
+
+	// Сначала списываем товары по FEFO под заранее выбранный ID: если остаток успели выкупить,
+	// заказ не создается. Если заказ не удалось сохранить, списанное возвращается на те же партии.
+	order.ID = uuid.New().String()
+	if _, err := uc.Stock.Allocate(order.ID, location.ID, stockItems, domain.StockChange{Actor: "user:" + orderReq.UserID}); err != nil {
+		return "", err
 	}
 
 	orderID, err := uc.OrderRepo.Save(order, orderItems)
 	if err != nil {
+		if _, releaseErr := uc.Stock.Release(order.ID, domain.StockChange{Reason: "order not saved"}); releaseErr != nil {
+			log.Printf("Failed to release stock of unsaved order %s: %v", order.ID, releaseErr)
+		}
 		return "", err
 	}
 
-	for _, item := range orderReq.Items {
-		product, _ := uc.ProductRepo.FindByID(item.ProductID)
-		product.Stock -= item.Quantity
-		uc.ProductRepo.Update(product.ID, product)
+	uc.Updates.Publish(domain.OrderUpdate{
+		Type:       domain.OrderUpdateCreated,
+		OrderID:    orderID,
+		UserID:     order.UserID,
+		Status:     order.Status,
+		OccurredAt: order.CreatedAt,
+	})
+	return orderID, nil
+}
+
+// deliveryTerms возвращает стоимость доставки заказа и его зону. Пока зон нет, действует единый тариф DeliveryFee;
+// когда они заведены, адрес вне зон не обслуживается, а минимальный заказ и порог бесплатной доставки
+// сравниваются с суммой товаров до скидок.
+func (uc *OrderUseCase) deliveryTerms(orderReq domain.OrderRequest, subtotal float64) (float64, *domain.DeliveryZone, error) {
+	if orderReq.StoreID != "" {
+		return 0, nil, nil
+	}
+	if uc.Zones == nil {
+		return uc.DeliveryFee, nil, nil
+	}
+	zones, err := uc.Zones.FindAll(true)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(zones) == 0 {
+		return uc.DeliveryFee, nil, nil
 	}
 
-	return orderID, nil
+	zone, ok := MatchDeliveryZone(zones, orderReq.DeliveryAddress)
+	if !ok {
+		return 0, nil, domain.ErrAddressNotServed
+	}
+	if subtotal < zone.MinOrderAmount {
+		return 0, nil, fmt.Errorf("%w: zone %s accepts orders from %.2f", domain.ErrBelowMinimumOrder, zone.Code, zone.MinOrderAmount)
+	}
+	return zone.FeeFor(subtotal), &zone, nil
+}
+
+// deliverySlot проверяет бронь интервала доставки из заказа и возвращает интервал; nil, если бронь не указана.
+// Место окончательно закрепляется за заказом при сохранении, поэтому бронь, истекшая позже, заказ тоже отклонит.
+func (uc *OrderUseCase) deliverySlot(orderReq domain.OrderRequest) (*domain.DeliverySlot, error) {
+	if orderReq.SlotReservationID == "" {
+		return nil, nil
+	}
+	if uc.Slots == nil {
+		return nil, fmt.Errorf("%w: delivery slots are not supported", domain.ErrInvalidDeliverySlot)
+	}
+	if orderReq.StoreID != "" {
+		return nil, fmt.Errorf("%w: pickup orders have no delivery slot", domain.ErrInvalidDeliverySlot)
+	}
+
+	reservation, err := uc.Slots.FindReservation(orderReq.SlotReservationID)
+	if err != nil {
+		return nil, err
+	}
+	if reservation.UserID != orderReq.UserID {
+		return nil, domain.ErrSlotReservationNotFound
+	}
+	if reservation.Status != domain.SlotReservationHeld || !reservation.IsActive(time.Now()) {
+		return nil, domain.ErrSlotReservationExpired
+	}
+
+	slot, err := uc.Slots.FindSlotByID(reservation.SlotID)
+	if err != nil {
+		return nil, err
+	}
+	return &slot, nil
+}
+
+// releaseSlot освобождает интервал доставки отмененного заказа
+func (uc *OrderUseCase) releaseSlot(orderID string) {
+	if uc.Slots == nil {
+		return
+	}
+	if err := uc.Slots.ReleaseByOrder(orderID); err != nil {
+		log.Printf("Failed to release delivery slot of order %s: %v", orderID, err)
+	}
+}
+
+// applyPromotions подбирает действующие акции и купоны покупателя и считает скидки заказа.
+// Автоматическая акция с исчерпанным лимитом просто не применяется, а для купона это ошибка:
+// покупатель должен узнать, почему скидки нет.
+func (uc *OrderUseCase) applyPromotions(orderReq domain.OrderRequest, lines []DiscountLine, deliveryFee float64) ([]domain.OrderDiscount, error) {
+	if uc.Promotions == nil {
+		return nil, nil
+	}
+
+	coupons := make(map[string]bool, len(orderReq.CouponCodes))
+	var codes []string
+	for _, code := range orderReq.CouponCodes {
+		code = domain.NormalizeCouponCode(code)
+		if code == "" || coupons[code] {
+			continue
+		}
+		coupons[code] = true
+		codes = append(codes, code)
+	}
+
+	candidates, err := uc.Promotions.FindApplicable(codes)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var valid []domain.Promotion
+	var ids []string
+	found := make(map[string]bool, len(codes))
+	for _, p := range candidates {
+		if !p.ValidAt(now) {
+			continue
+		}
+		if p.IsCoupon() {
+			found[p.CouponCode] = true
+		}
+		valid = append(valid, p)
+		ids = append(ids, p.ID)
+	}
+	for _, code := range codes {
+		if !found[code] {
+			return nil, fmt.Errorf("%w: %s", domain.ErrInvalidCoupon, code)
+		}
+	}
+
+	usage, err := uc.Promotions.Usage(ids, orderReq.UserID)
+	if err != nil {
+		return nil, err
+	}
+	var promotions []domain.Promotion
+	for _, p := range valid {
+		u := usage[p.ID]
+		if (p.UsageLimit > 0 && u.Total >= p.UsageLimit) || (p.PerUserLimit > 0 && u.ByUser >= p.PerUserLimit) {
+			if p.IsCoupon() {
+				return nil, fmt.Errorf("%w: %s", domain.ErrPromotionLimitReached, p.CouponCode)
+			}
+			continue
+		}
+		promotions = append(promotions, p)
+	}
+
+	discounts := CalculateDiscounts(lines, promotions, deliveryFee)
+	applied := make(map[string]bool, len(discounts))
+	for _, d := range discounts {
+		if d.CouponCode != "" {
+			applied[d.CouponCode] = true
+		}
+	}
+	for _, code := range codes {
+		if !applied[code] {
+			return nil, fmt.Errorf("%w: %s", domain.ErrCouponNotApplicable, code)
+		}
+	}
+	return discounts, nil
 }
 
 func (uc *OrderUseCase) GetOrderByID(id string) (domain.Order, error) {
@@ -81,28 +392,44 @@ func (uc *OrderUseCase) GetOrderByID(id string) (domain.Order, error) {
 	return order, nil
 }
 
+// UpdateOrderStatus меняет статус заказа вручную по таблице domain.CanChangeOrderStatus.
+// Статус меняется раньше возврата товара: если курьер тем временем забрал заказ, отмена
+// не пройдет и товар не вернется на склад. Возврат идемпотентен, поэтому повторная отмена
+// довозвращает товар, если в первый раз возврат не удался.
 func (uc *OrderUseCase) UpdateOrderStatus(id string, status string) error {
-	if status != domain.OrderStatusPending &&
-		status != domain.OrderStatusCompleted &&
-		status != domain.OrderStatusCancelled {
-		return errors.New("неверный статус заказа")
+	if !domain.IsValidOrderStatus(status) {
+		return fmt.Errorf("%w: %q", domain.ErrInvalidOrderStatus, status)
 	}
 
 	order, _, err := uc.OrderRepo.FindByID(id)
 	if err != nil {
 		return err
 	}
+	if status != order.Status && !domain.CanChangeOrderStatus(order.Status, status) {
+		return fmt.Errorf("%w: cannot change order from %s to %s", domain.ErrInvalidOrderTransition, order.Status, status)
+	}
 
-	if status == domain.OrderStatusCancelled && order.Status != domain.OrderStatusCancelled {
-		_, items, _ := uc.OrderRepo.FindByID(id)
-		for _, item := range items {
-			product, _ := uc.ProductRepo.FindByID(item.ProductID)
-			product.Stock += item.Quantity
-			uc.ProductRepo.Update(product.ID, product)
-		}
+	if err := uc.OrderRepo.UpdateStatus(id, status); err != nil {
+		return err
+	}
+	if status != order.Status {
+		uc.Updates.Publish(domain.OrderUpdate{
+			Type:       domain.OrderUpdateStatusChanged,
+			OrderID:    id,
+			UserID:     order.UserID,
+			Status:     status,
+			OccurredAt: time.Now(),
+		})
 	}
 
-	return uc.OrderRepo.UpdateStatus(id, status)
+	// Товары отмененного заказа возвращаются в те же партии, а место в интервале доставки снова доступно
+	if status == domain.OrderStatusCancelled {
+		if _, err := uc.Stock.Release(id, domain.StockChange{Reason: "order cancelled"}); err != nil {
+			return err
+		}
+		uc.releaseSlot(id)
+	}
+	return nil
 }
 
 func (uc *OrderUseCase) GetOrdersByUserID(userID string) ([]domain.Order, error) {
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/repository"
+)
+
+// fakeOrderRepo хранит статус одного заказа и записывает вызовы в общий журнал
+type fakeOrderRepo struct {
+	repository.OrderRepository
+	order     domain.Order
+	updateErr error
+	calls     *[]string
+}
+
+func (r *fakeOrderRepo) FindByID(id string) (domain.Order, []domain.OrderItem, error) {
+	if id != r.order.ID {
+		return domain.Order{}, nil, domain.ErrOrderNotFound
+	}
+	return r.order, nil, nil
+}
+
+func (r *fakeOrderRepo) UpdateStatus(id string, status string) error {
+	*r.calls = append(*r.calls, "status "+status)
+	if r.updateErr != nil {
+		return r.updateErr
+	}
+	r.order.Status = status
+	return nil
+}
+
+// fakeStock записывает возвраты товара в общий журнал
+type fakeStock struct {
+	StockAllocator
+	calls *[]string
+}
+
+func (s *fakeStock) Release(orderID string, change domain.StockChange) ([]domain.BatchAllocation, error) {
+	*s.calls = append(*s.calls, "release "+orderID)
+	return nil, nil
+}
+
+func TestUpdateOrderStatus(t *testing.T) {
+	// Курьер забрал заказ между чтением и сменой статуса: репозиторий отклоняет переход под блокировкой
+	raced := fmt.Errorf("%w: cannot change order from out_for_delivery to cancelled", domain.ErrInvalidOrderTransition)
+
+	tests := []struct {
+		name      string
+		current   string
+		status    string
+		updateErr error
+		calls     []string
+		err       error
+	}{
+		{"cancel pending order", domain.OrderStatusPending, domain.OrderStatusCancelled, nil,
+			[]string{"status cancelled", "release o1"}, nil},
+		{"complete pending order", domain.OrderStatusPending, domain.OrderStatusCompleted, nil,
+			[]string{"status completed"}, nil},
+		{"repeated cancel releases again", domain.OrderStatusCancelled, domain.OrderStatusCancelled, nil,
+			[]string{"status cancelled", "release o1"}, nil},
+		{"cancel order out for delivery", domain.OrderStatusOutForDelivery, domain.OrderStatusCancelled, nil,
+			nil, domain.ErrInvalidOrderTransition},
+		{"complete order out for delivery", domain.OrderStatusOutForDelivery, domain.OrderStatusCompleted, nil,
+			nil, domain.ErrInvalidOrderTransition},
+		{"cancel delivered order", domain.OrderStatusDelivered, domain.OrderStatusCancelled, nil,
+			nil, domain.ErrInvalidOrderTransition},
+		{"reopen cancelled order", domain.OrderStatusCancelled, domain.OrderStatusPending, nil,
+			nil, domain.ErrInvalidOrderTransition},
+		{"set delivery status by hand", domain.OrderStatusPending, domain.OrderStatusOutForDelivery, nil,
+			nil, domain.ErrInvalidOrderTransition},
+		{"unknown status", domain.OrderStatusPending, "shipped", nil,
+			nil, domain.ErrInvalidOrderStatus},
+		{"courier picked up during cancel", domain.OrderStatusPending, domain.OrderStatusCancelled, raced,
+			[]string{"status cancelled"}, domain.ErrInvalidOrderTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			uc := &OrderUseCase{
+				OrderRepo: &fakeOrderRepo{order: domain.Order{ID: "o1", Status: tt.current}, updateErr: tt.updateErr, calls: &calls},
+				Stock:     &fakeStock{calls: &calls},
+			}
+
+			err := uc.UpdateOrderStatus("o1", tt.status)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(calls, tt.calls) {
+				t.Errorf("calls = %v, want %v", calls, tt.calls)
+			}
+		})
+	}
+}
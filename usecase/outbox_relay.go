@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/repository"
+)
+
+// Параметры relay по умолчанию
+const (
+	DefaultOutboxBatchSize = 100
+	// DefaultOutboxRetention - сколько хранятся отправленные события: за это время
+	// отключенные потребители успевают их дочитать
+	DefaultOutboxRetention = 7 * 24 * time.Hour
+)
+
+// OutboxRelay отправляет события из outbox брокеру. Событие отмечается отправленным только
+// после того, как брокер его принял, поэтому после сбоя оно уйдет повторно (at-least-once).
+type OutboxRelay struct {
+	Repo      repository.OutboxRepository
+	Publisher repository.EventPublisher
+	BatchSize int
+	Retention time.Duration
+}
+
+func NewOutboxRelay(repo repository.OutboxRepository, publisher repository.EventPublisher) *OutboxRelay {
+	return &OutboxRelay{
+		Repo:      repo,
+		Publisher: publisher,
+		BatchSize: DefaultOutboxBatchSize,
+		Retention: DefaultOutboxRetention,
+	}
+}
+
+// PublishPending отправляет накопившиеся события пачками, пока они не кончатся
+func (r *OutboxRelay) PublishPending(ctx context.Context) (int, error) {
+	total := 0
+	for ctx.Err() == nil {
+		n, err := r.Repo.PublishPending(r.BatchSize, func(event domain.Event) error {
+			return r.Publisher.Publish(ctx, event)
+		})
+		total += n
+		if err != nil || n < r.BatchSize {
+			return total, err
+		}
+	}
+	return total, ctx.Err()
+}
+
+// Run отправляет события, как только wake сообщает о новых, и раз в interval на случай
+// пропущенного сигнала или недоступного брокера; раз в час удаляет старые отправленные события
+func (r *OutboxRelay) Run(ctx context.Context, interval time.Duration, wake <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	cleanup := time.NewTicker(time.Hour)
+	defer cleanup.Stop()
+
+	for {
+		if _, err := r.PublishPending(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Outbox relay: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+		case <-ticker.C:
+		case <-cleanup.C:
+			deleted, err := r.Repo.DeletePublished(time.Now().Add(-r.Retention))
+			if err != nil {
+				log.Printf("Outbox cleanup failed: %v", err)
+			} else if deleted > 0 {
+				log.Printf("Outbox cleanup removed %d event(s)", deleted)
+			}
+		}
+	}
+}
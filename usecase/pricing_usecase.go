@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/repository"
+
+	"github.com/google/uuid"
+)
+
+// Ограничения выборки истории цен
+const (
+	DefaultPriceHistoryLimit = 100
+	MaxPriceHistoryLimit     = 1000
+)
+
+// PricingUseCase отвечает на вопрос "сколько стоил товар в момент T" по истории цен
+// и применяет запланированные цены: акции с концом периода и отложенные изменения
+type PricingUseCase struct {
+	Repo     repository.PriceRepository
+	Products repository.ProductRepository
+}
+
+func NewPricingUseCase(repo repository.PriceRepository, products repository.ProductRepository) *PricingUseCase {
+	return &PricingUseCase{Repo: repo, Products: products}
+}
+
+// PriceAt возвращает цену товара, действовавшую в момент at; нулевой at - текущий момент
+func (uc *PricingUseCase) PriceAt(productID string, at time.Time) (domain.PriceChange, error) {
+	if _, err := uc.Products.FindByID(productID); err != nil {
+		return domain.PriceChange{}, ErrProductNotFound
+	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+	return uc.Repo.PriceAt(productID, at)
+}
+
+// History возвращает последние изменения цены товара, новые первыми
+func (uc *PricingUseCase) History(productID string, limit int) ([]domain.PriceChange, error) {
+	if _, err := uc.Products.FindByID(productID); err != nil {
+		return nil, ErrProductNotFound
+	}
+	if limit <= 0 {
+		limit = DefaultPriceHistoryLimit
+	}
+	if limit > MaxPriceHistoryLimit {
+		limit = MaxPriceHistoryLimit
+	}
+	return uc.Repo.History(productID, limit)
+}
+
+// Schedule планирует цену товара. Начало в прошлом допустимо: цена применится
+// при ближайшем запуске планировщика.
+func (uc *PricingUseCase) Schedule(s domain.PriceSchedule, actor string) (domain.PriceSchedule, error) {
+	if s.Price < 0 {
+		return domain.PriceSchedule{}, fmt.Errorf("%w: price cannot be negative", domain.ErrInvalidPriceSchedule)
+	}
+	if s.StartsAt.IsZero() {
+		return domain.PriceSchedule{}, fmt.Errorf("%w: starts_at is required", domain.ErrInvalidPriceSchedule)
+	}
+	now := time.Now()
+	if s.EndsAt != nil {
+		if !s.EndsAt.After(s.StartsAt) {
+			return domain.PriceSchedule{}, fmt.Errorf("%w: ends_at must be after starts_at", domain.ErrInvalidPriceSchedule)
+		}
+		if !s.EndsAt.After(now) {
+			return domain.PriceSchedule{}, fmt.Errorf("%w: ends_at is in the past", domain.ErrInvalidPriceSchedule)
+		}
+	}
+	if _, err := uc.Products.FindByID(s.ProductID); err != nil {
+		return domain.PriceSchedule{}, ErrProductNotFound
+	}
+
+	s.ID = uuid.New().String()
+	s.Status = domain.PriceSchedulePending
+	s.PreviousPrice = nil
+	s.CreatedBy = actor
+	s.CreatedAt = now
+	if err := uc.Repo.SaveSchedule(s); err != nil {
+		return domain.PriceSchedule{}, err
+	}
+	return s, nil
+}
+
+func (uc *PricingUseCase) ListSchedules(productID, status string) ([]domain.PriceSchedule, error) {
+	if status != "" && !domain.IsValidPriceScheduleStatus(status) {
+		return nil, fmt.Errorf("%w: unknown status %q", domain.ErrInvalidPriceSchedule, status)
+	}
+	if productID != "" {
+		if _, err := uc.Products.FindByID(productID); err != nil {
+			return nil, ErrProductNotFound
+		}
+	}
+	return uc.Repo.FindSchedules(productID, status)
+}
+
+// Cancel отменяет ожидающую цену. Действующая акция завершается досрочно,
+// и товару возвращается прежняя цена.
+func (uc *PricingUseCase) Cancel(id, actor string) (domain.PriceSchedule, error) {
+	s, err := uc.Repo.FindScheduleByID(id)
+	if err != nil {
+		return domain.PriceSchedule{}, err
+	}
+
+	switch s.Status {
+	case domain.PriceSchedulePending:
+		cancelled, err := uc.Repo.CancelSchedule(id)
+		if err != nil {
+			return domain.PriceSchedule{}, err
+		}
+		if cancelled {
+			break
+		}
+		// Планировщик успел применить цену: завершаем ее как действующую
+		err = uc.Repo.EndSchedule(id, domain.PriceScheduleCancelled, actor)
+		if errors.Is(err, domain.ErrPriceScheduleNotFound) {
+			return domain.PriceSchedule{}, domain.ErrPriceScheduleClosed
+		}
+		if err != nil {
+			return domain.PriceSchedule{}, err
+		}
+	case domain.PriceScheduleActive:
+		err = uc.Repo.EndSchedule(id, domain.PriceScheduleCancelled, actor)
+		if errors.Is(err, domain.ErrPriceScheduleNotFound) {
+			return domain.PriceSchedule{}, domain.ErrPriceScheduleClosed
+		}
+		if err != nil {
+			return domain.PriceSchedule{}, err
+		}
+	default:
+		return domain.PriceSchedule{}, domain.ErrPriceScheduleClosed
+	}
+	return uc.Repo.FindScheduleByID(id)
+}
+
+// priceEvent - начало или конец запланированной цены
+type priceEvent struct {
+	at       time.Time
+	end      bool
+	schedule domain.PriceSchedule
+}
+
+// ApplyDue применяет наступившие запланированные цены и возвращает прежние цены по окончании акций.
+// События обрабатываются по времени; в один момент конец акции идет раньше начала следующей,
+// чтобы следующая запомнила обычную цену, а не акционную. Возвращает число изменений цены.
+func (uc *PricingUseCase) ApplyDue(ctx context.Context) (int, error) {
+	due, err := uc.Repo.DueSchedules(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	events := make([]priceEvent, 0, len(due))
+	for _, s := range due {
+		if s.Status == domain.PriceScheduleActive {
+			events = append(events, priceEvent{at: *s.EndsAt, end: true, schedule: s})
+		} else {
+			events = append(events, priceEvent{at: s.StartsAt, schedule: s})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].at.Equal(events[j].at) {
+			return events[i].at.Before(events[j].at)
+		}
+		return events[i].end && !events[j].end
+	})
+
+	applied := 0
+	for _, e := range events {
+		if err := ctx.Err(); err != nil {
+			return applied, err
+		}
+
+		changed := true
+		if e.end {
+			err = uc.Repo.EndSchedule(e.schedule.ID, domain.PriceScheduleCompleted, e.schedule.CreatedBy)
+		} else {
+			changed, err = uc.Repo.StartSchedule(e.schedule.ID)
+		}
+		// Цену отменили, пока шла проверка
+		if errors.Is(err, domain.ErrPriceScheduleNotFound) {
+			continue
+		}
+		// Ошибка одного товара не должна задерживать цены остальных
+		if err != nil {
+			log.Printf("Failed to apply price schedule %s: %v", e.schedule.ID, err)
+			continue
+		}
+		if changed {
+			applied++
+		}
+	}
+	return applied, nil
+}
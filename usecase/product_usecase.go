@@ -1,43 +1,440 @@
 package usecase
 
 import (
+    "errors"
+    "fmt"
+    "strings"
+    "time"
+
     "FoodStore-AdvProg2/domain"
     "FoodStore-AdvProg2/repository"
+    "github.com/google/uuid"
+)
+
+// ErrInvalidProduct - ошибка валидации карточки товара; сервер возвращает ее клиенту как InvalidArgument
+var ErrInvalidProduct = errors.New("invalid product")
+
+const (
+	// MaxVariantNameLength - максимальная длина названия варианта
+	MaxVariantNameLength = 100
+	// MaxBundleComponents - максимальное число разных товаров в наборе
+	MaxBundleComponents = 50
+)
+
+var (
+	// ErrProductNotArchived - окончательно удалить можно только архивный товар
+	ErrProductNotArchived = errors.New("product is not archived")
+	// ErrProductInUse - на товар ссылаются заказы, закупки, партии, журнал движений или наборы
+	ErrProductInUse = errors.New("product is referenced")
 )
 
 type ProductUseCase struct {
-	Repo repository.ProductRepository
+	Repo    repository.ProductRepository
+	Batches repository.StockBatchRepository
 }
 
-func NewProductUseCase(repo repository.ProductRepository) *ProductUseCase {
-	return &ProductUseCase{Repo: repo}
+func NewProductUseCase(repo repository.ProductRepository, batches repository.StockBatchRepository) *ProductUseCase {
+	return &ProductUseCase{Repo: repo, Batches: batches}
 }
 
-func (uc *ProductUseCase) Create(p domain.Product) error {
-	return uc.Repo.Save(p)
+// Create сохраняет товар; начальный остаток заводится бессрочной партией на складе по умолчанию.
+// actor попадает в журнал движения товаров.
+func (uc *ProductUseCase) Create(p domain.Product, actor string) error {
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+	p.SKU = domain.NormalizeSKU(p.SKU)
+	if len(p.SKU) > domain.MaxSKULength {
+		return fmt.Errorf("%w: sku is longer than %d characters", ErrInvalidProduct, domain.MaxSKULength)
+	}
+	p.Category = domain.NormalizeCategory(p.Category)
+	if !domain.IsValidCategory(p.Category) {
+		return fmt.Errorf("%w: category must be up to %d latin letters, digits, '_' or '-'", ErrInvalidProduct, domain.MaxCategoryLength)
+	}
+	if p.Unit == "" {
+		p.Unit = domain.UnitPiece
+	}
+	if !domain.IsValidUnit(p.Unit) {
+		return fmt.Errorf("%w: unit must be piece, kg or litre", ErrInvalidProduct)
+	}
+	barcodes, err := domain.NormalizeBarcodes(p.Barcodes)
+	if err != nil {
+		return err
+	}
+	p.Barcodes = barcodes
+	if err := normalizeDietInfo(&p); err != nil {
+		return err
+	}
+	if p.Kind == "" {
+		p.Kind = domain.ProductKindSimple
+	}
+	if !domain.IsValidProductKind(p.Kind) {
+		return fmt.Errorf("%w: kind must be simple or bundle", ErrInvalidProduct)
+	}
+	if p.IsBundle() && p.Stock > 0 {
+		return fmt.Errorf("%w: %v", ErrInvalidProduct, domain.ErrBundleStock)
+	}
+	if err := uc.checkVariant(&p); err != nil {
+		return err
+	}
+	if p.Components, err = uc.checkComponents(p.ID, p.Kind, p.Components); err != nil {
+		return err
+	}
+	if err := uc.Repo.Save(p); err != nil {
+		return err
+	}
+	if p.Stock <= 0 {
+		return nil
+	}
+
+	_, err = uc.Batches.Receive(domain.StockBatch{
+		ProductID:  p.ID,
+		LotNumber:  domain.LotInitial,
+		ReceivedAt: time.Now(),
+		Quantity:   p.Stock,
+	}, domain.StockChange{Actor: actor, Reason: "initial stock"})
+	return err
 }
 
 func (uc *ProductUseCase) GetByID(id string) (domain.Product, error) {
 	return uc.Repo.FindByID(id)
 }
 
-func (uc *ProductUseCase) Update(id string, p domain.Product) error {
-	return uc.Repo.Update(id, p)
+// GetByBarcode ищет товар по штрихкоду; UPC-A находит товар, сохраненный как EAN-13
+func (uc *ProductUseCase) GetByBarcode(barcode string) (domain.Product, error) {
+	normalized, err := domain.NormalizeBarcode(barcode)
+	if err != nil {
+		return domain.Product{}, err
+	}
+	product, err := uc.Repo.FindByBarcode(normalized)
+	if err != nil {
+		return domain.Product{}, ErrProductNotFound
+	}
+	return product, nil
 }
 
-func (uc *ProductUseCase) Delete(id string) error {
-	return uc.Repo.Delete(id)
+// Update обновляет поля карточки товара из маски, если клиент видел актуальную версию товара.
+// Если в маске есть остаток и он изменился, разница оформляется партией-корректировкой
+// или списывается по FEFO на складе по умолчанию. actor попадает в журнал движения
+// товаров и в историю цен.
+func (uc *ProductUseCase) Update(update domain.ProductUpdate, actor string) error {
+	if update.Version <= 0 {
+		return fmt.Errorf("%w: version is required", ErrInvalidProduct)
+	}
+	for _, field := range update.Fields {
+		if !domain.IsValidProductField(field) {
+			return fmt.Errorf("%w: unknown field %q", ErrInvalidProduct, field)
+		}
+	}
+	if update.Has(domain.ProductFieldName) && strings.TrimSpace(update.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidProduct)
+	}
+	if update.Has(domain.ProductFieldPrice) && update.Price < 0 {
+		return fmt.Errorf("%w: price cannot be negative", ErrInvalidProduct)
+	}
+	if update.Has(domain.ProductFieldStock) && update.Stock < 0 {
+		return fmt.Errorf("%w: stock cannot be negative", ErrInvalidProduct)
+	}
+	update.SKU = domain.NormalizeSKU(update.SKU)
+	if update.Has(domain.ProductFieldSKU) && len(update.SKU) > domain.MaxSKULength {
+		return fmt.Errorf("%w: sku is longer than %d characters", ErrInvalidProduct, domain.MaxSKULength)
+	}
+	update.Category = domain.NormalizeCategory(update.Category)
+	if update.Has(domain.ProductFieldCategory) && !domain.IsValidCategory(update.Category) {
+		return fmt.Errorf("%w: category must be up to %d latin letters, digits, '_' or '-'", ErrInvalidProduct, domain.MaxCategoryLength)
+	}
+	if update.Has(domain.ProductFieldBarcodes) {
+		barcodes, err := domain.NormalizeBarcodes(update.Barcodes)
+		if err != nil {
+			return err
+		}
+		update.Barcodes = barcodes
+	}
+
+	current, err := uc.Repo.FindByID(update.ID)
+	if err != nil {
+		return ErrProductNotFound
+	}
+	if current.Version != update.Version {
+		return domain.ErrProductVersionConflict
+	}
+
+	// Метки проверяются вместе с аллергенами, даже если в маске только одно из полей
+	merged := current
+	if update.Has(domain.ProductFieldNutrition) {
+		merged.Nutrition = update.Nutrition
+	}
+	if update.Has(domain.ProductFieldIngredients) {
+		merged.Ingredients = update.Ingredients
+	}
+	if update.Has(domain.ProductFieldAllergens) {
+		merged.Allergens = update.Allergens
+	}
+	if update.Has(domain.ProductFieldDietaryTags) {
+		merged.DietaryTags = update.DietaryTags
+	}
+	if err := normalizeDietInfo(&merged); err != nil {
+		return err
+	}
+	update.Nutrition, update.Ingredients = merged.Nutrition, merged.Ingredients
+	update.Allergens, update.DietaryTags = merged.Allergens, merged.DietaryTags
+
+	if update.Has(domain.ProductFieldParentID) || update.Has(domain.ProductFieldVariantName) {
+		if update.Has(domain.ProductFieldParentID) {
+			merged.ParentID = update.ParentID
+		}
+		if update.Has(domain.ProductFieldVariantName) {
+			merged.VariantName = update.VariantName
+		}
+		if err := uc.checkVariant(&merged); err != nil {
+			return err
+		}
+		update.ParentID, update.VariantName = merged.ParentID, merged.VariantName
+		if update.Has(domain.ProductFieldParentID) {
+			update.Fields = appendField(update.Fields, domain.ProductFieldVariantName)
+		}
+	}
+
+	// Остаток набора считается по компонентам: полное обновление его не трогает,
+	// а явное изменение остатка набора по маске - ошибка
+	explicit := len(update.Fields) > 0
+	if current.IsBundle() && explicit && update.Has(domain.ProductFieldStock) {
+		return fmt.Errorf("%w: %v", ErrInvalidProduct, domain.ErrBundleStock)
+	}
+	if update.Has(domain.ProductFieldComponents) {
+		if !current.IsBundle() && explicit {
+			return fmt.Errorf("%w: only bundles have components", ErrInvalidProduct)
+		}
+		if current.IsBundle() {
+			if update.Components, err = uc.checkComponents(current.ID, current.Kind, update.Components); err != nil {
+				return err
+			}
+		} else {
+			update.Components = nil
+		}
+	}
+
+	// Карточка и корректировка остатка сохраняются в одной транзакции репозитория
+	update.Actor = actor
+	_, err = uc.Repo.Update(update)
+	return err
+}
+
+// appendField добавляет поле в непустую маску обновления; пустая маска и так обновляет все поля
+func appendField(fields []string, field string) []string {
+	if len(fields) == 0 {
+		return fields
+	}
+	for _, f := range fields {
+		if f == field {
+			return fields
+		}
+	}
+	return append(fields, field)
+}
+
+// checkVariant проверяет привязку варианта к родителю: родитель существует, сам не вариант
+// и не набор, а у товара-варианта нет своих вариантов. Вложенность - один уровень.
+func (uc *ProductUseCase) checkVariant(p *domain.Product) error {
+	p.VariantName = strings.TrimSpace(p.VariantName)
+	if p.ParentID == "" {
+		p.VariantName = ""
+		return nil
+	}
+	if p.ParentID == p.ID {
+		return fmt.Errorf("%w: product cannot be a variant of itself", ErrInvalidProduct)
+	}
+	if p.VariantName == "" {
+		return fmt.Errorf("%w: variant_name is required for a variant", ErrInvalidProduct)
+	}
+	if len(p.VariantName) > MaxVariantNameLength {
+		return fmt.Errorf("%w: variant_name is longer than %d characters", ErrInvalidProduct, MaxVariantNameLength)
+	}
+
+	parent, err := uc.Repo.FindByID(p.ParentID)
+	if err != nil {
+		return fmt.Errorf("%w: parent product not found", ErrInvalidProduct)
+	}
+	if parent.IsVariant() {
+		return fmt.Errorf("%w: parent product is itself a variant", ErrInvalidProduct)
+	}
+	if parent.IsBundle() {
+		return fmt.Errorf("%w: bundles cannot have variants", ErrInvalidProduct)
+	}
+
+	variants, err := uc.Repo.FindAllWithFilter(domain.FilterParams{ParentID: p.ID}, "", domain.PaginationParams{PerPage: 1}, 0)
+	if err != nil {
+		return err
+	}
+	if variants.Total > 0 {
+		return fmt.Errorf("%w: product with variants cannot become a variant", ErrInvalidProduct)
+	}
+	return nil
+}
+
+// checkComponents проверяет состав набора и объединяет повторы одного компонента.
+// Компонент - существующий товар, который сам не является набором.
+func (uc *ProductUseCase) checkComponents(productID, kind string, components []domain.BundleComponent) ([]domain.BundleComponent, error) {
+	if kind != domain.ProductKindBundle {
+		if len(components) > 0 {
+			return nil, fmt.Errorf("%w: only bundles have components", ErrInvalidProduct)
+		}
+		return nil, nil
+	}
+	if len(components) == 0 {
+		return nil, fmt.Errorf("%w: bundle needs at least one component", ErrInvalidProduct)
+	}
+	if len(components) > MaxBundleComponents {
+		return nil, fmt.Errorf("%w: bundle has more than %d components", ErrInvalidProduct, MaxBundleComponents)
+	}
+
+	var result []domain.BundleComponent
+	index := make(map[string]int)
+	for _, c := range components {
+		if c.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: component quantity must be greater than zero", ErrInvalidProduct)
+		}
+		if i, ok := index[c.ProductID]; ok {
+			result[i].Quantity += c.Quantity
+			continue
+		}
+		if c.ProductID == productID {
+			return nil, fmt.Errorf("%w: bundle cannot contain itself", ErrInvalidProduct)
+		}
+		component, err := uc.Repo.FindByID(c.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: component %s not found", ErrInvalidProduct, c.ProductID)
+		}
+		if component.IsBundle() {
+			return nil, fmt.Errorf("%w: bundle cannot contain another bundle", ErrInvalidProduct)
+		}
+		index[c.ProductID] = len(result)
+		result = append(result, domain.BundleComponent{ProductID: c.ProductID, Quantity: c.Quantity})
+	}
+	return result, nil
+}
+
+// normalizeDietInfo проверяет пищевую ценность, нормализует состав, аллергены и метки
+// и не дает пометить товар меткой, которой противоречат его аллергены
+func normalizeDietInfo(p *domain.Product) error {
+	if p.Nutrition != nil {
+		if err := p.Nutrition.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+		}
+	}
+	p.Ingredients = domain.NormalizeIngredients(p.Ingredients)
+
+	allergens, err := domain.NormalizeAllergens(p.Allergens)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+	}
+	tags, err := domain.NormalizeDietaryTags(p.DietaryTags)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+	}
+	if err := domain.CheckDietConflicts(allergens, tags); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+	}
+	p.Allergens, p.DietaryTags = allergens, tags
+	return nil
+}
+
+// Archive убирает товар из каталога и продажи. Товар остается в истории заказов,
+// журнале движений и закупках и может быть восстановлен.
+func (uc *ProductUseCase) Archive(id string) error {
+	if _, err := uc.Repo.FindByID(id); err != nil {
+		return ErrProductNotFound
+	}
+	return uc.Repo.Archive(id)
+}
+
+// Restore возвращает архивный товар в каталог
+func (uc *ProductUseCase) Restore(id string) error {
+	if _, err := uc.Repo.FindByID(id); err != nil {
+		return ErrProductNotFound
+	}
+	return uc.Repo.Restore(id)
+}
+
+// CheckPurge проверяет, что товар можно удалить окончательно: он в архиве
+// и на него не ссылаются заказы, закупки, партии, журнал движений и наборы.
+// Строки заказов и закупок, партии (в том числе пустые) и движения - история товара,
+// которая вместе с ним не удаляется, поэтому удалить окончательно можно только товар,
+// который ни разу не приходовали, не заказывали и не закупали, например заведенный по ошибке.
+// Остальные товары остаются в архиве.
+func (uc *ProductUseCase) CheckPurge(id string) error {
+	product, err := uc.Repo.FindByID(id)
+	if err != nil {
+		return ErrProductNotFound
+	}
+	if !product.IsArchived() {
+		return ErrProductNotArchived
+	}
+
+	refs, err := uc.Repo.References(id)
+	if err != nil {
+		return err
+	}
+	if refs.HasHistory() {
+		return fmt.Errorf("%w: purge is only for products that were never stocked, ordered or purchased, "+
+			"keep this one archived (%d order items, %d purchase order lines, %d batches, %d stock movements)",
+			ErrProductInUse, refs.OrderItems, refs.PurchaseOrderLines, refs.StockBatches, refs.StockMovements)
+	}
+	if refs.BundleComponents > 0 {
+		return fmt.Errorf("%w: product is a component of %d bundle(s), remove it from them first",
+			ErrProductInUse, refs.BundleComponents)
+	}
+	return nil
+}
+
+// Purge окончательно удаляет архивный товар без ссылок. Изображения
+// удаляются до вызова (MediaUseCase.DeleteProductMedia).
+func (uc *ProductUseCase) Purge(id string) error {
+	if err := uc.CheckPurge(id); err != nil {
+		return err
+	}
+
+	deleted, err := uc.Repo.Purge(id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		// На товар сослались или его восстановили между проверкой и удалением
+		return fmt.Errorf("%w: product changed during purge", ErrProductInUse)
+	}
+	return nil
 }
 
 func (uc *ProductUseCase) List(filter domain.FilterParams, pagination domain.PaginationParams) ([]domain.Product, int, error) {
+    page, err := uc.Search(filter, "", pagination)
+    return page.Products, page.Total, err
+}
+
+// Search ищет товары с сортировкой; поддерживает как номер страницы, так и курсор
+func (uc *ProductUseCase) Search(filter domain.FilterParams, sort string, pagination domain.PaginationParams) (domain.ProductPage, error) {
     if pagination.Page < 1 {
         pagination.Page = 1
     }
     if pagination.PerPage < 1 {
         pagination.PerPage = 10 
     }
+    if pagination.PerPage > 100 {
+        pagination.PerPage = 100
+    }
     offset := (pagination.Page - 1) * pagination.PerPage
-    
-    products, total, err := uc.Repo.FindAllWithFilter(filter, pagination, offset)
-    return products, total, err
+
+    // Неизвестный аллерген в фильтре - ошибка клиента, а не пустой результат
+    var err error
+    for _, list := range []*[]string{&filter.WithAllergens, &filter.WithoutAllergens} {
+        if *list, err = domain.NormalizeAllergens(*list); err != nil {
+            return domain.ProductPage{}, err
+        }
+    }
+    for _, list := range []*[]string{&filter.WithDietaryTags, &filter.WithoutDietaryTags} {
+        if *list, err = domain.NormalizeDietaryTags(*list); err != nil {
+            return domain.ProductPage{}, err
+        }
+    }
+
+    return uc.Repo.FindAllWithFilter(filter, sort, pagination, offset)
 }
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/repository"
+
+	"github.com/google/uuid"
+)
+
+// couponCodePattern - допустимый код купона после NormalizeCouponCode
+var couponCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,50}$`)
+
+// PromotionUseCase управляет акциями и купонами; скидки применяются в OrderUseCase.CreateOrder
+type PromotionUseCase struct {
+	Repo repository.PromotionRepository
+}
+
+func NewPromotionUseCase(repo repository.PromotionRepository) *PromotionUseCase {
+	return &PromotionUseCase{Repo: repo}
+}
+
+// Create проверяет и сохраняет акцию. Новая акция включена.
+func (uc *PromotionUseCase) Create(p domain.Promotion, actor string) (domain.Promotion, error) {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return domain.Promotion{}, fmt.Errorf("%w: name is required", domain.ErrInvalidPromotion)
+	}
+	if !domain.IsValidPromotionType(p.Type) {
+		return domain.Promotion{}, fmt.Errorf("%w: unknown type %q", domain.ErrInvalidPromotion, p.Type)
+	}
+
+	switch p.Type {
+	case domain.PromotionPercentage:
+		if p.Value <= 0 || p.Value > 100 {
+			return domain.Promotion{}, fmt.Errorf("%w: percentage must be in (0, 100]", domain.ErrInvalidPromotion)
+		}
+	case domain.PromotionFixedAmount:
+		if p.Value <= 0 {
+			return domain.Promotion{}, fmt.Errorf("%w: fixed amount must be positive", domain.ErrInvalidPromotion)
+		}
+	case domain.PromotionBOGO:
+		if p.BuyQuantity < 1 || p.GetQuantity < 1 {
+			return domain.Promotion{}, fmt.Errorf("%w: buy_quantity and get_quantity must be at least 1", domain.ErrInvalidPromotion)
+		}
+	case domain.PromotionFreeDelivery:
+		if len(p.ProductIDs) > 0 || len(p.Categories) > 0 {
+			return domain.Promotion{}, fmt.Errorf("%w: free delivery applies to the whole order", domain.ErrInvalidPromotion)
+		}
+	}
+	if p.Type != domain.PromotionBOGO {
+		p.BuyQuantity, p.GetQuantity = 0, 0
+	}
+	if p.Type == domain.PromotionBOGO || p.Type == domain.PromotionFreeDelivery {
+		p.Value = 0
+	}
+	if p.MinSubtotal < 0 {
+		return domain.Promotion{}, fmt.Errorf("%w: min_subtotal cannot be negative", domain.ErrInvalidPromotion)
+	}
+	if p.UsageLimit < 0 || p.PerUserLimit < 0 {
+		return domain.Promotion{}, fmt.Errorf("%w: usage limits cannot be negative", domain.ErrInvalidPromotion)
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return domain.Promotion{}, fmt.Errorf("%w: ends_at must be after starts_at", domain.ErrInvalidPromotion)
+	}
+
+	for _, id := range p.ProductIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return domain.Promotion{}, fmt.Errorf("%w: invalid product id %q", domain.ErrInvalidPromotion, id)
+		}
+	}
+	categories := make([]string, 0, len(p.Categories))
+	for _, c := range p.Categories {
+		c = domain.NormalizeCategory(c)
+		if c == "" || !domain.IsValidCategory(c) {
+			return domain.Promotion{}, fmt.Errorf("%w: invalid category %q", domain.ErrInvalidPromotion, c)
+		}
+		categories = append(categories, c)
+	}
+	p.Categories = categories
+
+	p.CouponCode = domain.NormalizeCouponCode(p.CouponCode)
+	if p.CouponCode != "" && !couponCodePattern.MatchString(p.CouponCode) {
+		return domain.Promotion{}, fmt.Errorf("%w: coupon code must be 3-50 letters, digits, '-' or '_'", domain.ErrInvalidPromotion)
+	}
+
+	p.ID = uuid.New().String()
+	p.Active = true
+	p.TimesUsed = 0
+	p.CreatedBy = actor
+	p.CreatedAt = time.Now()
+	if err := uc.Repo.Save(p); err != nil {
+		return domain.Promotion{}, err
+	}
+	return p, nil
+}
+
+func (uc *PromotionUseCase) List(activeOnly bool) ([]domain.Promotion, error) {
+	return uc.Repo.FindAll(activeOnly)
+}
+
+// SetActive включает или выключает акцию; заказы, уже получившие скидку, не меняются
+func (uc *PromotionUseCase) SetActive(id string, active bool) (domain.Promotion, error) {
+	if err := uc.Repo.SetActive(id, active); err != nil {
+		return domain.Promotion{}, err
+	}
+	return uc.Repo.FindByID(id)
+}
@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/repository"
+)
+
+// ErrInvalidPurchaseOrder - ошибка валидации поставщика или заказа поставщику;
+// сервер возвращает ее клиенту как InvalidArgument
+var ErrInvalidPurchaseOrder = errors.New("invalid purchase order")
+
+var ErrSupplierNotFound = errors.New("supplier not found")
+
+var ErrPurchaseOrderNotFound = errors.New("purchase order not found")
+
+// PurchasingUseCase управляет поставщиками, заказами на пополнение и приемкой товара по ним
+type PurchasingUseCase struct {
+	Suppliers repository.SupplierRepository
+	Orders    repository.PurchaseOrderRepository
+	Products  repository.ProductRepository
+	Locations repository.LocationRepository
+}
+
+func NewPurchasingUseCase(suppliers repository.SupplierRepository, orders repository.PurchaseOrderRepository, products repository.ProductRepository, locations repository.LocationRepository) *PurchasingUseCase {
+	return &PurchasingUseCase{
+		Suppliers: suppliers,
+		Orders:    orders,
+		Products:  products,
+		Locations: locations,
+	}
+}
+
+func (uc *PurchasingUseCase) CreateSupplier(supplier domain.Supplier) (domain.Supplier, error) {
+	supplier.Name = strings.TrimSpace(supplier.Name)
+	if supplier.Name == "" {
+		return domain.Supplier{}, fmt.Errorf("%w: supplier name is required", ErrInvalidPurchaseOrder)
+	}
+	supplier.Email = strings.TrimSpace(supplier.Email)
+	if supplier.Email != "" {
+		if _, err := mail.ParseAddress(supplier.Email); err != nil {
+			return domain.Supplier{}, fmt.Errorf("%w: invalid supplier email", ErrInvalidPurchaseOrder)
+		}
+	}
+	return uc.Suppliers.Save(supplier)
+}
+
+func (uc *PurchasingUseCase) ListSuppliers() ([]domain.Supplier, error) {
+	return uc.Suppliers.FindAll()
+}
+
+// CreatePurchaseOrder создает черновик заказа поставщику. Без точки товар принимается на склад по умолчанию.
+func (uc *PurchasingUseCase) CreatePurchaseOrder(order domain.PurchaseOrder) (domain.PurchaseOrder, error) {
+	if len(order.Lines) == 0 {
+		return domain.PurchaseOrder{}, fmt.Errorf("%w: at least one line is required", ErrInvalidPurchaseOrder)
+	}
+	for _, line := range order.Lines {
+		if line.QuantityOrdered <= 0 {
+			return domain.PurchaseOrder{}, fmt.Errorf("%w: quantity must be greater than zero", ErrInvalidPurchaseOrder)
+		}
+		if line.UnitCost < 0 {
+			return domain.PurchaseOrder{}, fmt.Errorf("%w: unit cost cannot be negative", ErrInvalidPurchaseOrder)
+		}
+		product, err := uc.Products.FindByID(line.ProductID)
+		if err != nil {
+			return domain.PurchaseOrder{}, ErrProductNotFound
+		}
+		// Наборы не закупаются: поставщик привозит компоненты
+		if product.IsBundle() {
+			return domain.PurchaseOrder{}, fmt.Errorf("%w: %v", ErrInvalidPurchaseOrder, domain.ErrBundleStock)
+		}
+	}
+	if order.ExpectedAt != nil && order.ExpectedAt.Before(truncateDay(time.Now())) {
+		return domain.PurchaseOrder{}, fmt.Errorf("%w: expected date is in the past", ErrInvalidPurchaseOrder)
+	}
+
+	if _, err := uc.Suppliers.FindByID(order.SupplierID); err != nil {
+		return domain.PurchaseOrder{}, ErrSupplierNotFound
+	}
+
+	var location domain.Location
+	var err error
+	if order.LocationID == "" {
+		location, err = uc.Locations.FindDefault()
+	} else {
+		location, err = uc.Locations.FindByID(order.LocationID)
+	}
+	if err != nil {
+		return domain.PurchaseOrder{}, ErrLocationNotFound
+	}
+	if !location.Active {
+		return domain.PurchaseOrder{}, fmt.Errorf("%w: location %s is inactive", ErrInvalidPurchaseOrder, location.Code)
+	}
+
+	order.LocationID = location.ID
+	order.Status = domain.PurchaseOrderDraft
+	for i := range order.Lines {
+		order.Lines[i].QuantityReceived = 0
+	}
+	return uc.Orders.Save(order)
+}
+
+func (uc *PurchasingUseCase) GetPurchaseOrder(id string) (domain.PurchaseOrder, error) {
+	order, err := uc.Orders.FindByID(id)
+	if err != nil {
+		return domain.PurchaseOrder{}, ErrPurchaseOrderNotFound
+	}
+	return order, nil
+}
+
+func (uc *PurchasingUseCase) ListPurchaseOrders(filter domain.PurchaseOrderFilter) ([]domain.PurchaseOrder, error) {
+	if filter.Status != "" && !domain.IsValidPurchaseOrderStatus(filter.Status) {
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidPurchaseOrder, filter.Status)
+	}
+	return uc.Orders.FindAll(filter)
+}
+
+// UpdateStatus отправляет заказ поставщику или отменяет его
+func (uc *PurchasingUseCase) UpdateStatus(id, status string) (domain.PurchaseOrder, error) {
+	order, err := uc.GetPurchaseOrder(id)
+	if err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+	if !domain.CanTransitionPurchaseOrder(order.Status, status) {
+		return domain.PurchaseOrder{}, fmt.Errorf("%w: cannot change status from %s to %s", ErrInvalidPurchaseOrder, order.Status, status)
+	}
+
+	if err := uc.Orders.UpdateStatus(id, status); err != nil {
+		return domain.PurchaseOrder{}, err
+	}
+	return uc.GetPurchaseOrder(id)
+}
+
+// Receive принимает товар по строкам заказа: каждая приемка заводится партией на точке заказа.
+// Без номера лота партия получает номер вида PO-1a2b3c4d.
+func (uc *PurchasingUseCase) Receive(id string, receipts []domain.PurchaseReceipt, change domain.StockChange) (domain.PurchaseOrder, []domain.StockBatch, error) {
+	order, err := uc.GetPurchaseOrder(id)
+	if err != nil {
+		return domain.PurchaseOrder{}, nil, err
+	}
+	if len(receipts) == 0 {
+		return domain.PurchaseOrder{}, nil, fmt.Errorf("%w: at least one received line is required", ErrInvalidPurchaseOrder)
+	}
+
+	lines := make(map[string]bool)
+	for _, l := range order.Lines {
+		lines[l.ID] = true
+	}
+	today := truncateDay(time.Now())
+	for i := range receipts {
+		r := &receipts[i]
+		if !lines[r.LineID] {
+			return domain.PurchaseOrder{}, nil, fmt.Errorf("%w: line %s does not belong to the order", ErrInvalidPurchaseOrder, r.LineID)
+		}
+		if r.Quantity <= 0 {
+			return domain.PurchaseOrder{}, nil, fmt.Errorf("%w: quantity must be greater than zero", ErrInvalidPurchaseOrder)
+		}
+		if r.ExpiresAt != nil && r.ExpiresAt.Before(today) {
+			return domain.PurchaseOrder{}, nil, fmt.Errorf("%w: expiry date is in the past", ErrInvalidPurchaseOrder)
+		}
+		r.LotNumber = strings.TrimSpace(r.LotNumber)
+		if r.LotNumber == "" {
+			r.LotNumber = "PO-" + order.ID[:8]
+		}
+	}
+
+	if change.Reason == "" {
+		change.Reason = "purchase order receipt"
+	}
+	return uc.Orders.Receive(id, receipts, change)
+}
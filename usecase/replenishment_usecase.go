@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/repository"
+)
+
+var ErrReorderSuggestionNotFound = errors.New("reorder suggestion not found")
+
+// Параметры расчета предложений на пополнение по умолчанию
+const (
+	DefaultSalesWindowDays = 28
+	DefaultCoverDays       = 14
+)
+
+// ReplenishmentUseCase следит за точками заказа товаров: открывает алерты о низком остатке,
+// рассылает уведомления и готовит черновики пополнения по скорости продаж
+type ReplenishmentUseCase struct {
+	Repo     repository.ReplenishmentRepository
+	Products repository.ProductRepository
+	Notifier repository.Notifier
+	// SalesWindowDays - за сколько последних дней считается скорость продаж
+	SalesWindowDays int
+	// CoverDays - на сколько дней продаж должно хватить пополнения
+	CoverDays int
+}
+
+func NewReplenishmentUseCase(repo repository.ReplenishmentRepository, products repository.ProductRepository, notifier repository.Notifier) *ReplenishmentUseCase {
+	return &ReplenishmentUseCase{
+		Repo:            repo,
+		Products:        products,
+		Notifier:        notifier,
+		SalesWindowDays: DefaultSalesWindowDays,
+		CoverDays:       DefaultCoverDays,
+	}
+}
+
+// SetPolicy задает точку заказа товара; нулевая точка заказа отключает отслеживание
+func (uc *ReplenishmentUseCase) SetPolicy(policy domain.ReorderPolicy) (domain.ReorderPolicy, error) {
+	if policy.ReorderPoint < 0 || policy.ReorderQuantity < 0 {
+		return domain.ReorderPolicy{}, fmt.Errorf("%w: reorder point and quantity cannot be negative", ErrInvalidStockRequest)
+	}
+	if _, err := uc.Products.FindByID(policy.ProductID); err != nil {
+		return domain.ReorderPolicy{}, ErrProductNotFound
+	}
+	if err := uc.Repo.SetPolicy(policy); err != nil {
+		return domain.ReorderPolicy{}, err
+	}
+	return policy, nil
+}
+
+// CheckLowStock сравнивает доступный остаток товаров с точками заказа. По товару, остаток которого
+// опустился до точки заказа, открывается алерт с черновиком пополнения и отправляется уведомление;
+// алерт товара, остаток которого снова выше точки заказа, закрывается.
+// Возвращает только алерты, открытые этой проверкой.
+func (uc *ReplenishmentUseCase) CheckLowStock(ctx context.Context) ([]domain.LowStockAlert, error) {
+	statuses, err := uc.Repo.ReorderStatuses()
+	if err != nil {
+		return nil, err
+	}
+
+	var low []domain.ReorderStatus
+	for _, s := range statuses {
+		switch {
+		case s.BelowReorderPoint() && !s.AlertOpen:
+			low = append(low, s)
+		case !s.BelowReorderPoint() && s.AlertOpen:
+			if err := uc.Repo.ResolveAlert(s.ProductID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(low) == 0 {
+		return nil, nil
+	}
+
+	productIDs := make([]string, 0, len(low))
+	for _, s := range low {
+		productIDs = append(productIDs, s.ProductID)
+	}
+	since := time.Now().AddDate(0, 0, -uc.SalesWindowDays)
+	sold, err := uc.Repo.UnitsSold(productIDs, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var opened []domain.LowStockAlert
+	for _, s := range low {
+		dailySales := float64(sold[s.ProductID]) / float64(uc.SalesWindowDays)
+		alert := domain.LowStockAlert{
+			ProductID:    s.ProductID,
+			ProductName:  s.ProductName,
+			Available:    s.Available,
+			ReorderPoint: s.Policy.ReorderPoint,
+		}
+		suggestion := domain.ReorderSuggestion{
+			ProductID:         s.ProductID,
+			ProductName:       s.ProductName,
+			Available:         s.Available,
+			ReorderPoint:      s.Policy.ReorderPoint,
+			ReorderQuantity:   s.Policy.ReorderQuantity,
+			UnitsSold:         sold[s.ProductID],
+			SalesWindowDays:   uc.SalesWindowDays,
+			DailySales:        dailySales,
+			SuggestedQuantity: domain.SuggestReorderQuantity(s.Available, s.Policy, dailySales, uc.CoverDays),
+			Status:            domain.ReorderSuggestionDraft,
+		}
+
+		alert, suggestion, created, err := uc.Repo.OpenAlert(alert, suggestion)
+		if err != nil {
+			return opened, err
+		}
+		// Алерт уже открыт параллельной проверкой, уведомление отправила она
+		if !created {
+			continue
+		}
+		opened = append(opened, alert)
+
+		// Недоставленное уведомление не откатывает алерт: он виден в списке алертов
+		if err := uc.Notifier.Notify(ctx, lowStockNotification(alert, suggestion)); err != nil {
+			log.Printf("low stock notification for product %s failed: %v", alert.ProductID, err)
+		}
+	}
+	return opened, nil
+}
+
+func lowStockNotification(alert domain.LowStockAlert, suggestion domain.ReorderSuggestion) domain.Notification {
+	return domain.Notification{
+		Type:    domain.NotificationLowStock,
+		Subject: fmt.Sprintf("Low stock: %s", alert.ProductName),
+		Message: fmt.Sprintf("%s: %d available, reorder point %d. Sold %d in the last %d days (%.2f per day), suggested reorder: %d.",
+			alert.ProductName, alert.Available, alert.ReorderPoint, suggestion.UnitsSold,
+			suggestion.SalesWindowDays, suggestion.DailySales, suggestion.SuggestedQuantity),
+		Payload: struct {
+			Alert      domain.LowStockAlert     `json:"alert"`
+			Suggestion domain.ReorderSuggestion `json:"suggestion"`
+		}{alert, suggestion},
+	}
+}
+
+func (uc *ReplenishmentUseCase) ListAlerts(openOnly bool) ([]domain.LowStockAlert, error) {
+	return uc.Repo.FindAlerts(openOnly)
+}
+
+func (uc *ReplenishmentUseCase) ListSuggestions(status string) ([]domain.ReorderSuggestion, error) {
+	if status != "" && !domain.IsValidReorderSuggestionStatus(status) {
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidStockRequest, status)
+	}
+	return uc.Repo.FindSuggestions(status)
+}
+
+// UpdateSuggestionStatus отмечает черновик пополнения заказанным или отклоненным
+func (uc *ReplenishmentUseCase) UpdateSuggestionStatus(id, status string) (domain.ReorderSuggestion, error) {
+	if status != domain.ReorderSuggestionOrdered && status != domain.ReorderSuggestionDismissed {
+		return domain.ReorderSuggestion{}, fmt.Errorf("%w: status must be %s or %s",
+			ErrInvalidStockRequest, domain.ReorderSuggestionOrdered, domain.ReorderSuggestionDismissed)
+	}
+
+	suggestion, err := uc.Repo.FindSuggestionByID(id)
+	if err != nil {
+		return domain.ReorderSuggestion{}, ErrReorderSuggestionNotFound
+	}
+	if suggestion.Status != domain.ReorderSuggestionDraft {
+		return domain.ReorderSuggestion{}, fmt.Errorf("%w: suggestion is already %s", ErrInvalidStockRequest, suggestion.Status)
+	}
+
+	if err := uc.Repo.UpdateSuggestionStatus(id, status); err != nil {
+		return domain.ReorderSuggestion{}, err
+	}
+	return uc.Repo.FindSuggestionByID(id)
+}
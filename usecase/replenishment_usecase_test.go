@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/repository"
+)
+
+// orderStore сохраняет заказы в памяти, как их записал бы OrderRepository
+type orderStore struct {
+	repository.OrderRepository
+	orders []domain.Order
+	items  map[string][]domain.OrderItem
+}
+
+func (s *orderStore) Save(order domain.Order, items []domain.OrderItem) (string, error) {
+	s.orders = append(s.orders, order)
+	s.items[order.ID] = items
+	return order.ID, nil
+}
+
+// salesRepo считает продажи по сохраненным заказам с тем же отбором, что и UnitsSold в Postgres:
+// заказы не раньше since, кроме отмененных
+type salesRepo struct {
+	repository.ReplenishmentRepository
+	orders      *orderStore
+	statuses    []domain.ReorderStatus
+	suggestions []domain.ReorderSuggestion
+}
+
+func (r *salesRepo) ReorderStatuses() ([]domain.ReorderStatus, error) {
+	return r.statuses, nil
+}
+
+func (r *salesRepo) UnitsSold(productIDs []string, since time.Time) (map[string]int, error) {
+	sold := make(map[string]int)
+	for _, o := range r.orders.orders {
+		if o.CreatedAt.Before(since) || o.Status == domain.OrderStatusCancelled {
+			continue
+		}
+		for _, item := range r.orders.items[o.ID] {
+			sold[item.ProductID] += int(item.Quantity)
+		}
+	}
+	return sold, nil
+}
+
+func (r *salesRepo) OpenAlert(alert domain.LowStockAlert, suggestion domain.ReorderSuggestion) (domain.LowStockAlert, domain.ReorderSuggestion, bool, error) {
+	r.suggestions = append(r.suggestions, suggestion)
+	return alert, suggestion, true, nil
+}
+
+type catalogRepo struct {
+	repository.ProductRepository
+	products map[string]domain.Product
+}
+
+func (r *catalogRepo) FindByID(id string) (domain.Product, error) {
+	product, ok := r.products[id]
+	if !ok {
+		return domain.Product{}, ErrProductNotFound
+	}
+	return product, nil
+}
+
+// mainWarehouse собирает любой заказ со склада по умолчанию
+type mainWarehouse struct {
+	StockAllocator
+}
+
+func (mainWarehouse) FulfillingLocations(items []domain.OrderItemRequest) ([]domain.Location, error) {
+	return []domain.Location{{ID: "main"}}, nil
+}
+
+func (mainWarehouse) Allocate(orderID, locationID string, items []domain.OrderItemRequest, change domain.StockChange) ([]domain.BatchAllocation, error) {
+	return nil, nil
+}
+
+type silentNotifier struct{}
+
+func (silentNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	return nil
+}
+
+func TestReorderSuggestionUsesOrderSales(t *testing.T) {
+	store := &orderStore{items: make(map[string][]domain.OrderItem)}
+	orders := &OrderUseCase{
+		OrderRepo:   store,
+		ProductRepo: &catalogRepo{products: map[string]domain.Product{"milk": {ID: "milk", Price: 80, Stock: 100}}},
+		Stock:       mainWarehouse{},
+	}
+	for _, quantity := range []float64{3, 4, 7} {
+		_, err := orders.CreateOrder(domain.OrderRequest{
+			UserID: "u1",
+			Items:  []domain.OrderLineRequest{{ProductID: "milk", Quantity: quantity}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, o := range store.orders {
+		if o.CreatedAt.IsZero() {
+			t.Fatalf("order %s was saved without a creation time", o.ID)
+		}
+	}
+
+	// Продажи вне окна не учитываются
+	store.orders = append(store.orders, domain.Order{ID: "old", Status: domain.OrderStatusCompleted, CreatedAt: time.Now().AddDate(0, 0, -60)})
+	store.items["old"] = []domain.OrderItem{{ProductID: "milk", Quantity: 50}}
+
+	repo := &salesRepo{orders: store, statuses: []domain.ReorderStatus{{
+		ProductID: "milk",
+		Available: 2,
+		Policy:    domain.ReorderPolicy{ProductID: "milk", ReorderPoint: 5, ReorderQuantity: 10},
+	}}}
+	uc := NewReplenishmentUseCase(repo, nil, silentNotifier{})
+	if _, err := uc.CheckLowStock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(repo.suggestions) != 1 {
+		t.Fatalf("got %d suggestion(s), want 1", len(repo.suggestions))
+	}
+	s := repo.suggestions[0]
+	if s.UnitsSold != 14 {
+		t.Errorf("UnitsSold = %d, want 14", s.UnitsSold)
+	}
+	if want := 14.0 / DefaultSalesWindowDays; s.DailySales != want {
+		t.Errorf("DailySales = %v, want %v", s.DailySales, want)
+	}
+}
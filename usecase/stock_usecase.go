@@ -0,0 +1,367 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"FoodStore-AdvProg2/domain"
+	"FoodStore-AdvProg2/repository"
+)
+
+// MaxExpiringDays ограничивает горизонт отчета по истекающим партиям
+const MaxExpiringDays = 365
+
+// ErrInvalidStockRequest - ошибка валидации; сервер возвращает ее клиенту как InvalidArgument
+var ErrInvalidStockRequest = errors.New("invalid stock request")
+
+var ErrBatchNotFound = errors.New("batch not found")
+
+// MaxMovementsPerPage ограничивает размер страницы журнала движений
+const MaxMovementsPerPage = 500
+
+// StockUseCase управляет партиями товаров на складах и в магазинах,
+// списанием остатков под заказы, перемещениями и журналом движений
+type StockUseCase struct {
+	Batches   repository.StockBatchRepository
+	Movements repository.StockMovementRepository
+	Products  repository.ProductRepository
+	Locations repository.LocationRepository
+}
+
+func NewStockUseCase(batches repository.StockBatchRepository, movements repository.StockMovementRepository, products repository.ProductRepository, locations repository.LocationRepository) *StockUseCase {
+	return &StockUseCase{
+		Batches:   batches,
+		Movements: movements,
+		Products:  products,
+		Locations: locations,
+	}
+}
+
+// ReceiveBatch оприходует новую партию товара
+func (uc *StockUseCase) ReceiveBatch(batch domain.StockBatch, change domain.StockChange) (domain.StockBatch, error) {
+	batch.LotNumber = strings.TrimSpace(batch.LotNumber)
+	if batch.LotNumber == "" {
+		return domain.StockBatch{}, fmt.Errorf("%w: lot number is required", ErrInvalidStockRequest)
+	}
+	if batch.Quantity <= 0 {
+		return domain.StockBatch{}, fmt.Errorf("%w: quantity must be greater than zero", ErrInvalidStockRequest)
+	}
+	if batch.ReceivedAt.IsZero() {
+		batch.ReceivedAt = time.Now()
+	}
+	if batch.ExpiresAt != nil && batch.ExpiresAt.Before(truncateDay(batch.ReceivedAt)) {
+		return domain.StockBatch{}, fmt.Errorf("%w: expiry date is before received date", ErrInvalidStockRequest)
+	}
+
+	product, err := uc.Products.FindByID(batch.ProductID)
+	if err != nil {
+		return domain.StockBatch{}, ErrProductNotFound
+	}
+	if product.IsBundle() {
+		return domain.StockBatch{}, fmt.Errorf("%w: %v", ErrInvalidStockRequest, domain.ErrBundleStock)
+	}
+	if batch.LocationID != "" {
+		if _, err := uc.activeLocation(batch.LocationID); err != nil {
+			return domain.StockBatch{}, err
+		}
+	}
+
+	return uc.Batches.Receive(batch, change)
+}
+
+// ListBatches возвращает партии товара; пустой locationID - на всех точках
+func (uc *StockUseCase) ListBatches(productID, locationID string, includeEmpty bool) ([]domain.StockBatch, error) {
+	return uc.Batches.FindByProductID(productID, locationID, includeEmpty)
+}
+
+// ExpiringBatches возвращает партии, срок годности которых истекает в ближайшие days дней,
+// включая уже просроченные, но еще не списанные
+func (uc *StockUseCase) ExpiringBatches(days int) ([]domain.StockBatch, error) {
+	if days < 0 || days > MaxExpiringDays {
+		return nil, fmt.Errorf("%w: days must be between 0 and 365", ErrInvalidStockRequest)
+	}
+	return uc.Batches.FindExpiring(truncateDay(time.Now()).AddDate(0, 0, days))
+}
+
+// Allocate списывает товары под заказ с точки locationID по FEFO; пустой locationID - склад по умолчанию
+func (uc *StockUseCase) Allocate(orderID, locationID string, items []domain.OrderItemRequest, change domain.StockChange) ([]domain.BatchAllocation, error) {
+	if orderID == "" {
+		return nil, fmt.Errorf("%w: order id is required", ErrInvalidStockRequest)
+	}
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity must be greater than zero", ErrInvalidStockRequest)
+		}
+	}
+
+	var location domain.Location
+	var err error
+	if locationID == "" {
+		location, err = uc.Locations.FindDefault()
+	} else {
+		location, err = uc.activeLocation(locationID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Набор списывается компонентами; возврат по ReleaseStock тоже вернет компоненты
+	items, _ = uc.expandBundles(items)
+	return uc.Batches.Allocate(orderID, location.ID, items, change)
+}
+
+// expandBundles заменяет наборы в позициях их компонентами: 2 набора по 3 йогурта - 6 йогуртов.
+// sources связывает каждый товар после замены с позицией, из-за которой он понадобился,
+// чтобы сообщить клиенту о нехватке набора, а не его компонента.
+func (uc *StockUseCase) expandBundles(items []domain.OrderItemRequest) ([]domain.OrderItemRequest, map[string]string) {
+	expanded := make([]domain.OrderItemRequest, 0, len(items))
+	sources := make(map[string]string)
+	add := func(productID string, quantity int, source string) {
+		expanded = append(expanded, domain.OrderItemRequest{ProductID: productID, Quantity: quantity})
+		if _, ok := sources[productID]; !ok {
+			sources[productID] = source
+		}
+	}
+
+	for _, item := range items {
+		product, err := uc.Products.FindByID(item.ProductID)
+		if err != nil || !product.IsBundle() {
+			// Неизвестный товар остается как есть: его не окажется в остатках
+			add(item.ProductID, item.Quantity, item.ProductID)
+			continue
+		}
+		for _, c := range product.Components {
+			add(c.ProductID, c.Quantity*item.Quantity, item.ProductID)
+		}
+	}
+	return expanded, sources
+}
+
+// CheckAvailability возвращает первый товар, которого не хватает на точке locationID,
+// или пустую строку, если в наличии все позиции. Пустой locationID - суммарно по всем активным точкам.
+func (uc *StockUseCase) CheckAvailability(locationID string, items []domain.OrderItemRequest) (string, error) {
+	items, sources := uc.expandBundles(items)
+	levels, err := uc.Batches.StockLevels(orderProductIDs(items))
+	if err != nil {
+		return "", err
+	}
+
+	available := make(map[string]int)
+	for _, l := range levels {
+		if locationID == "" || l.LocationID == locationID {
+			available[l.ProductID] += l.Available
+		}
+	}
+
+	quantities := orderQuantities(items)
+	for _, item := range items {
+		if available[item.ProductID] < quantities[item.ProductID] {
+			return sources[item.ProductID], nil
+		}
+	}
+	return "", nil
+}
+
+// FulfillingLocations возвращает активные точки, на которых в наличии все позиции заказа
+func (uc *StockUseCase) FulfillingLocations(items []domain.OrderItemRequest) ([]domain.Location, error) {
+	items, _ = uc.expandBundles(items)
+	levels, err := uc.Batches.StockLevels(orderProductIDs(items))
+	if err != nil {
+		return nil, err
+	}
+
+	byLocation := make(map[string]map[string]int)
+	for _, l := range levels {
+		if byLocation[l.LocationID] == nil {
+			byLocation[l.LocationID] = make(map[string]int)
+		}
+		byLocation[l.LocationID][l.ProductID] = l.Available
+	}
+
+	locations, err := uc.Locations.FindAll(false)
+	if err != nil {
+		return nil, err
+	}
+
+	quantities := orderQuantities(items)
+	var result []domain.Location
+	for _, location := range locations {
+		fits := true
+		for productID, quantity := range quantities {
+			if byLocation[location.ID][productID] < quantity {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			result = append(result, location)
+		}
+	}
+	return result, nil
+}
+
+// StockLevels возвращает доступный остаток товара по активным точкам.
+// Для набора - сколько наборов можно собрать на каждой точке из ее остатков компонентов.
+func (uc *StockUseCase) StockLevels(productID string) ([]domain.LocationStock, error) {
+	product, err := uc.Products.FindByID(productID)
+	if err != nil {
+		return nil, ErrProductNotFound
+	}
+	if !product.IsBundle() {
+		return uc.Batches.StockLevels([]string{productID})
+	}
+
+	componentIDs := make([]string, 0, len(product.Components))
+	for _, c := range product.Components {
+		componentIDs = append(componentIDs, c.ProductID)
+	}
+	levels, err := uc.Batches.StockLevels(componentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var order []domain.LocationStock
+	byLocation := make(map[string]map[string]int)
+	for _, l := range levels {
+		if byLocation[l.LocationID] == nil {
+			byLocation[l.LocationID] = make(map[string]int)
+			order = append(order, l)
+		}
+		byLocation[l.LocationID][l.ProductID] = l.Available
+	}
+
+	var result []domain.LocationStock
+	for _, l := range order {
+		available := domain.BundleAvailable(product.Components, byLocation[l.LocationID])
+		if available == 0 {
+			continue
+		}
+		l.ProductID = productID
+		l.Available = available
+		result = append(result, l)
+	}
+	return result, nil
+}
+
+// Transfer перемещает товар между точками. Партии переезжают со своими лотами и сроками годности,
+// просроченные партии не перемещаются.
+func (uc *StockUseCase) Transfer(transfer domain.StockTransfer, change domain.StockChange) (domain.StockTransfer, error) {
+	if transfer.Quantity <= 0 {
+		return domain.StockTransfer{}, fmt.Errorf("%w: quantity must be greater than zero", ErrInvalidStockRequest)
+	}
+	if transfer.FromLocationID == "" || transfer.ToLocationID == "" {
+		return domain.StockTransfer{}, fmt.Errorf("%w: source and destination locations are required", ErrInvalidStockRequest)
+	}
+	if transfer.FromLocationID == transfer.ToLocationID {
+		return domain.StockTransfer{}, fmt.Errorf("%w: source and destination must differ", ErrInvalidStockRequest)
+	}
+	product, err := uc.Products.FindByID(transfer.ProductID)
+	if err != nil {
+		return domain.StockTransfer{}, ErrProductNotFound
+	}
+	if product.IsBundle() {
+		return domain.StockTransfer{}, fmt.Errorf("%w: %v", ErrInvalidStockRequest, domain.ErrBundleStock)
+	}
+	if _, err := uc.Locations.FindByID(transfer.FromLocationID); err != nil {
+		return domain.StockTransfer{}, ErrLocationNotFound
+	}
+	if _, err := uc.activeLocation(transfer.ToLocationID); err != nil {
+		return domain.StockTransfer{}, err
+	}
+
+	return uc.Batches.Transfer(transfer, change)
+}
+
+// activeLocation проверяет, что точка существует и принимает товар
+func (uc *StockUseCase) activeLocation(id string) (domain.Location, error) {
+	location, err := uc.Locations.FindByID(id)
+	if err != nil {
+		return domain.Location{}, ErrLocationNotFound
+	}
+	if !location.Active {
+		return domain.Location{}, fmt.Errorf("%w: location %s is inactive", ErrInvalidStockRequest, location.Code)
+	}
+	return location, nil
+}
+
+// orderQuantities суммирует количество по товару, если товар повторяется в заказе
+func orderQuantities(items []domain.OrderItemRequest) map[string]int {
+	quantities := make(map[string]int)
+	for _, item := range items {
+		quantities[item.ProductID] += item.Quantity
+	}
+	return quantities
+}
+
+func orderProductIDs(items []domain.OrderItemRequest) []string {
+	var ids []string
+	for productID := range orderQuantities(items) {
+		ids = append(ids, productID)
+	}
+	return ids
+}
+
+// Release возвращает на склад товары отмененного заказа
+func (uc *StockUseCase) Release(orderID string, change domain.StockChange) ([]domain.BatchAllocation, error) {
+	return uc.Batches.Release(orderID, change)
+}
+
+// WriteOff списывает товар с партии: порча, просрочка, недостача при инвентаризации
+func (uc *StockUseCase) WriteOff(batchID string, quantity int, change domain.StockChange) (domain.StockBatch, error) {
+	if quantity <= 0 {
+		return domain.StockBatch{}, fmt.Errorf("%w: quantity must be greater than zero", ErrInvalidStockRequest)
+	}
+	if strings.TrimSpace(change.Reason) == "" {
+		return domain.StockBatch{}, fmt.Errorf("%w: reason is required for write-off", ErrInvalidStockRequest)
+	}
+	if _, err := uc.Batches.FindByID(batchID); err != nil {
+		return domain.StockBatch{}, ErrBatchNotFound
+	}
+
+	return uc.Batches.WriteOff(batchID, quantity, change)
+}
+
+// ListMovements возвращает страницу журнала движений и общее число записей по фильтру
+func (uc *StockUseCase) ListMovements(filter domain.StockMovementFilter, pagination domain.PaginationParams) ([]domain.StockMovement, int, error) {
+	if !domain.IsValidMovementType(filter.Type) {
+		return nil, 0, fmt.Errorf("%w: unknown movement type %q", ErrInvalidStockRequest, filter.Type)
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, 0, fmt.Errorf("%w: from must be before to", ErrInvalidStockRequest)
+	}
+
+	if pagination.Page < 1 {
+		pagination.Page = 1
+	}
+	if pagination.PerPage < 1 {
+		pagination.PerPage = 50
+	}
+	if pagination.PerPage > MaxMovementsPerPage {
+		pagination.PerPage = MaxMovementsPerPage
+	}
+
+	return uc.Movements.FindAll(filter, pagination.PerPage, (pagination.Page-1)*pagination.PerPage)
+}
+
+// Reconcile сверяет остатки с журналом; onlyMismatched оставляет только расхождения
+func (uc *StockUseCase) Reconcile(productID string, onlyMismatched bool) ([]domain.StockReconciliation, error) {
+	all, err := uc.Movements.Reconcile(productID)
+	if err != nil || !onlyMismatched {
+		return all, err
+	}
+
+	var mismatched []domain.StockReconciliation
+	for _, rec := range all {
+		if !rec.Consistent() {
+			mismatched = append(mismatched, rec)
+		}
+	}
+	return mismatched, nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"math"
+	"sort"
+
+	"FoodStore-AdvProg2/domain"
+)
+
+// TaxLine - облагаемая сумма заказа: позиция после скидок или доставка
+type TaxLine struct {
+	ItemID   string
+	Category string
+	// Amount - стоимость после скидок в ценах политики (с НДС или без него)
+	Amount float64
+}
+
+// LineTax - НДС одной облагаемой суммы
+type LineTax struct {
+	ItemID    string
+	Rate      float64
+	NetAmount float64
+	TaxAmount float64
+}
+
+// CalculateTax считает НДС по каждой строке и итог по ставкам.
+//
+// Правила округления:
+//   - налог считается и округляется до копейки отдельно по каждой строке, половина копейки - вверх;
+//   - в режиме inclusive налог выделяется из стоимости: amount * rate / (100 + rate),
+//     в режиме exclusive начисляется сверху: amount * rate / 100;
+//   - итог по ставке - сумма уже округленных строк, поэтому он всегда сходится с позициями.
+//
+// Расчет ведется в целых копейках и сотых долях процента, чтобы двоичные дроби
+// не сдвигали округление (1.15 * 10% - ровно 0.115, то есть 0.12).
+func CalculateTax(policy domain.TaxPolicy, lines []TaxLine) ([]LineTax, []domain.TaxSummaryLine) {
+	type total struct{ net, tax int64 }
+	totals := make(map[int64]*total)
+
+	taxes := make([]LineTax, 0, len(lines))
+	for _, l := range lines {
+		rate := policy.RateFor(l.Category)
+		bp := int64(math.Round(rate * 100))
+		amount := int64(math.Round(l.Amount * 100))
+
+		var net, tax int64
+		if policy.Mode == domain.TaxExclusive {
+			tax = divRoundHalfUp(amount*bp, 10000)
+			net = amount
+		} else {
+			tax = divRoundHalfUp(amount*bp, 10000+bp)
+			net = amount - tax
+		}
+
+		taxes = append(taxes, LineTax{
+			ItemID:    l.ItemID,
+			Rate:      float64(bp) / 100,
+			NetAmount: float64(net) / 100,
+			TaxAmount: float64(tax) / 100,
+		})
+		t, ok := totals[bp]
+		if !ok {
+			t = &total{}
+			totals[bp] = t
+		}
+		t.net += net
+		t.tax += tax
+	}
+
+	summary := make([]domain.TaxSummaryLine, 0, len(totals))
+	for bp, t := range totals {
+		summary = append(summary, domain.TaxSummaryLine{
+			Rate:      float64(bp) / 100,
+			NetAmount: float64(t.net) / 100,
+			TaxAmount: float64(t.tax) / 100,
+		})
+	}
+	sort.Slice(summary, func(i, j int) bool { return summary[i].Rate > summary[j].Rate })
+	return taxes, summary
+}
+
+// TaxTotal возвращает сумму НДС по итогам ставок
+func TaxTotal(summary []domain.TaxSummaryLine) float64 {
+	sum := 0.0
+	for _, s := range summary {
+		sum += s.TaxAmount
+	}
+	return roundMoney(sum)
+}
+
+// divRoundHalfUp делит неотрицательное a на положительное b с округлением половины вверх
+func divRoundHalfUp(a, b int64) int64 {
+	return (2*a + b) / (2 * b)
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"testing"
+
+	"FoodStore-AdvProg2/domain"
+)
+
+func TestCalculateTaxRounding(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		rate    float64
+		amount  float64
+		wantNet float64
+		wantTax float64
+	}{
+		{"exclusive whole", domain.TaxExclusive, 12, 100, 100, 12},
+		{"inclusive extracts tax", domain.TaxInclusive, 12, 100, 89.29, 10.71},
+		{"exclusive half cent rounds up", domain.TaxExclusive, 5, 0.10, 0.10, 0.01},
+		{"inclusive half cent rounds up", domain.TaxInclusive, 12, 0.14, 0.12, 0.02},
+		{"exclusive below half cent rounds down", domain.TaxExclusive, 12, 0.04, 0.04, 0},
+		{"binary fraction does not shift rounding", domain.TaxExclusive, 10, 1.15, 1.15, 0.12},
+		{"fractional rate", domain.TaxExclusive, 12.5, 10.04, 10.04, 1.26},
+		{"zero rate", domain.TaxInclusive, 0, 57.30, 57.30, 0},
+		{"zero amount", domain.TaxExclusive, 12, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := domain.TaxPolicy{Mode: tt.mode, StandardRate: tt.rate}
+			lines, summary := CalculateTax(policy, []TaxLine{{ItemID: "a", Amount: tt.amount}})
+			if len(lines) != 1 {
+				t.Fatalf("got %d lines, want 1", len(lines))
+			}
+			if lines[0].NetAmount != tt.wantNet || lines[0].TaxAmount != tt.wantTax {
+				t.Errorf("got net %v tax %v, want net %v tax %v",
+					lines[0].NetAmount, lines[0].TaxAmount, tt.wantNet, tt.wantTax)
+			}
+			if len(summary) != 1 || summary[0].TaxAmount != tt.wantTax || summary[0].Rate != tt.rate {
+				t.Errorf("got summary %+v, want one line at %v%% with tax %v", summary, tt.rate, tt.wantTax)
+			}
+		})
+	}
+}
+
+func TestCalculateTaxSummaryMatchesLines(t *testing.T) {
+	policy := domain.TaxPolicy{
+		Mode:          domain.TaxInclusive,
+		StandardRate:  12,
+		CategoryRates: map[string]float64{"dairy": 5, "bakery": 5},
+	}
+	// Три позиции по 0.14 при 12% дают по 0.015 налога: построчное округление - 0.02 каждая,
+	// итог ставки равен сумме строк (0.06), а не округлению общей суммы (0.05)
+	lines, summary := CalculateTax(policy, []TaxLine{
+		{ItemID: "milk", Category: "dairy", Amount: 10.50},
+		{ItemID: "bread", Category: "bakery", Amount: 2.10},
+		{ItemID: "soap1", Category: "household", Amount: 0.14},
+		{ItemID: "soap2", Category: "household", Amount: 0.14},
+		{ItemID: "soap3", Amount: 0.14},
+	})
+
+	wantLines := map[string]LineTax{
+		"milk":  {ItemID: "milk", Rate: 5, NetAmount: 10, TaxAmount: 0.50},
+		"bread": {ItemID: "bread", Rate: 5, NetAmount: 2, TaxAmount: 0.10},
+		"soap1": {ItemID: "soap1", Rate: 12, NetAmount: 0.12, TaxAmount: 0.02},
+		"soap2": {ItemID: "soap2", Rate: 12, NetAmount: 0.12, TaxAmount: 0.02},
+		"soap3": {ItemID: "soap3", Rate: 12, NetAmount: 0.12, TaxAmount: 0.02},
+	}
+	for _, l := range lines {
+		if want := wantLines[l.ItemID]; l != want {
+			t.Errorf("line %s: got %+v, want %+v", l.ItemID, l, want)
+		}
+	}
+
+	want := []domain.TaxSummaryLine{
+		{Rate: 12, NetAmount: 0.36, TaxAmount: 0.06},
+		{Rate: 5, NetAmount: 12, TaxAmount: 0.60},
+	}
+	if len(summary) != len(want) {
+		t.Fatalf("got summary %+v, want %+v", summary, want)
+	}
+	for i := range want {
+		if summary[i] != want[i] {
+			t.Errorf("summary[%d]: got %+v, want %+v", i, summary[i], want[i])
+		}
+	}
+	if total := TaxTotal(summary); total != 0.66 {
+		t.Errorf("got tax total %v, want 0.66", total)
+	}
+}
+
+func TestParseCategoryRates(t *testing.T) {
+	rates, err := domain.ParseCategoryRates(" Dairy=5, bakery = 5,produce=0,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rates) != 3 || rates["dairy"] != 5 || rates["bakery"] != 5 || rates["produce"] != 0 {
+		t.Errorf("got %v", rates)
+	}
+
+	for _, value := range []string{"dairy", "dairy=abc", "=5", "dai ry=5"} {
+		if _, err := domain.ParseCategoryRates(value); err == nil {
+			t.Errorf("ParseCategoryRates(%q): expected error", value)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// ActorMetadataKey - ключ gRPC метаданных с инициатором изменения (пользователь или сервис).
+// Используется для журнала движения товаров.
+const ActorMetadataKey = "x-actor"
+
+// WithActor добавляет инициатора в исходящие метаданные gRPC вызова
+func WithActor(ctx context.Context, actor string) context.Context {
+	if actor == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, ActorMetadataKey, actor)
+}
+
+// ActorFromContext возвращает инициатора из входящих метаданных gRPC вызова или fallback
+func ActorFromContext(ctx context.Context, fallback string) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(ActorMetadataKey); len(values) > 0 && values[0] != "" {
+			return values[0]
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+)
+
+// ResizeToFit уменьшает изображение так, чтобы оно вписалось в maxW x maxH с сохранением пропорций.
+// Используется усреднение по площади (box filter), что дает приемлемое качество для миниатюр.
+// Прозрачные пиксели накладываются на белый фон, так как результат кодируется в JPEG.
+// Изображения меньше заданного размера не увеличиваются.
+func ResizeToFit(src image.Image, maxW, maxH int) *image.RGBA {
+	b := src.Bounds()
+	srcW, srcH := b.Dx(), b.Dy()
+
+	dstW, dstH := srcW, srcH
+	if dstW > maxW {
+		dstH = dstH * maxW / dstW
+		dstW = maxW
+	}
+	if dstH > maxH {
+		dstW = dstW * maxH / dstH
+		dstH = maxH
+	}
+	if dstW < 1 {
+		dstW = 1
+	}
+	if dstH < 1 {
+		dstH = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+
+	for y := 0; y < dstH; y++ {
+		y0 := b.Min.Y + y*srcH/dstH
+		y1 := b.Min.Y + (y+1)*srcH/dstH
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+
+		for x := 0; x < dstW; x++ {
+			x0 := b.Min.X + x*srcW/dstW
+			x1 := b.Min.X + (x+1)*srcW/dstW
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, bl, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					// Смешиваем с белым фоном: значения RGBA уже умножены на альфу
+					white := 0xffff - uint64(ca)
+					r += uint64(cr) + white
+					g += uint64(cg) + white
+					bl += uint64(cb) + white
+					n++
+				}
+			}
+
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(bl / n >> 8),
+				A: 0xff,
+			})
+		}
+	}
+
+	return dst
+}
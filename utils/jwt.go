@@ -7,20 +7,27 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 )
 
-// JWT секретный ключ для подписи токенов
-var jwtSecret = []byte("your-secret-key") // В реальном приложении храните в переменной окружения
+// JWT секретный ключ для подписи токенов; сервис задает его через SetJWTSecret при старте
+var jwtSecret = []byte("your-secret-key")
+
+// SetJWTSecret задает ключ подписи и проверки токенов
+func SetJWTSecret(secret []byte) {
+	jwtSecret = secret
+}
 
 // Claims представляет данные, хранимые в JWT токене
 type Claims struct {
 	UserID string `json:"user_id"`
+	Role   string `json:"role,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken генерирует JWT токен для пользователя
-func GenerateToken(userID string) (string, error) {
+// GenerateToken генерирует JWT токен для пользователя с указанной ролью
+func GenerateToken(userID, role string) (string, error) {
 	// Создаем новый токен с алгоритмом подписи HS256
 	claims := &Claims{
 		UserID: userID,
+		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // токен действителен 24 часа
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -39,8 +46,8 @@ func GenerateToken(userID string) (string, error) {
 	return tokenString, nil
 }
 
-// ValidateToken проверяет JWT токен и возвращает ID пользователя
-func ValidateToken(tokenString string) (string, error) {
+// ParseToken проверяет подпись и срок действия JWT токена и возвращает его данные
+func ParseToken(tokenString string) (*Claims, error) {
 	// Парсим токен
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		// Проверяем, что используемый алгоритм является HMAC
@@ -51,13 +58,22 @@ func ValidateToken(tokenString string) (string, error) {
 	})
 
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Проверяем, действителен ли токен
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims.UserID, nil
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid && claims.UserID != "" {
+		return claims, nil
 	}
 
-	return "", errors.New("недействительный токен")
+	return nil, errors.New("недействительный токен")
+}
+
+// ValidateToken проверяет JWT токен и возвращает ID пользователя
+func ValidateToken(tokenString string) (string, error) {
+	claims, err := ParseToken(tokenString)
+	if err != nil {
+		return "", err
+	}
+	return claims.UserID, nil
 }